@@ -8,6 +8,8 @@ package curves
 
 import (
 	"crypto/ecdsa"
+	"errors"
+	"fmt"
 	"math/big"
 )
 
@@ -37,3 +39,132 @@ func VerifyEcdsa(pk *EcPoint, hash []byte, sig *EcdsaSignature) bool {
 		},
 		hash, sig.R, sig.S)
 }
+
+// ErrInvalidEcdsaSignature is returned by FinalizeEcdsaSignature when the signature does not verify.
+var ErrInvalidEcdsaSignature = errors.New("the ECDSA signature does not verify")
+
+// EcdsaR returns r, the x coordinate of the instance key bigR reduced mod q, along with the recovery id of bigR:
+// bit 0 is the parity of R.y and bit 1 is set when R.x overflowed the group order.
+func EcdsaR(curve *Curve, bigR Point) (Scalar, int, error) {
+	affineCompressedForm := bigR.ToAffineCompressed()
+	if len(affineCompressedForm) != 33 {
+		return nil, 0, fmt.Errorf("the compressed form must be exactly 33 bytes")
+	}
+	rXInt := new(big.Int).SetBytes(affineCompressedForm[1:])
+	rX, err := curve.Scalar.SetBigInt(rXInt) // reduces R.x mod q
+	if err != nil {
+		return nil, 0, fmt.Errorf("setting rX scalar from big int: %w", err)
+	}
+	recoveryId := int(affineCompressedForm[0] & 0x1)
+	if rXInt.Cmp(rX.BigInt()) != 0 {
+		recoveryId |= 2
+	}
+	return rX, recoveryId, nil
+}
+
+// FinalizeEcdsaSignature assembles the signature (r, s) of digest made with the instance key bigR, normalizes it to
+// low-S with the matching recovery id, and verifies it against publicKey. It returns ErrInvalidEcdsaSignature if the
+// signature does not verify.
+func FinalizeEcdsaSignature(curve *Curve, bigR Point, s Scalar, publicKey Point, digest []byte) (*EcdsaSignature, error) {
+	rX, recoveryId, err := EcdsaR(curve, bigR)
+	if err != nil {
+		return nil, err
+	}
+	ellipticCurve, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, fmt.Errorf("invalid curve: %w", err)
+	}
+	signature := &EcdsaSignature{R: rX.BigInt(), S: s.BigInt(), V: recoveryId}
+	// Normalize to low-S; negating S corresponds to negating R, which flips the parity of R.y.
+	halfOrder := new(big.Int).Rsh(ellipticCurve.Params().N, 1)
+	if signature.S.Cmp(halfOrder) > 0 {
+		signature.S = s.Neg().BigInt()
+		signature.V ^= 1
+	}
+	unCompressedAffinePublicKey := publicKey.ToAffineUncompressed()
+	if len(unCompressedAffinePublicKey) != 65 {
+		return nil, fmt.Errorf("the uncompressed form must have exactly 65 bytes")
+	}
+	x := new(big.Int).SetBytes(unCompressedAffinePublicKey[1:33])
+	y := new(big.Int).SetBytes(unCompressedAffinePublicKey[33:])
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: ellipticCurve, X: x, Y: y}, digest, signature.R, signature.S) {
+		return nil, ErrInvalidEcdsaSignature
+	}
+	return signature, nil
+}
+
+// RecoverEcdsaPublicKey recovers the public key that produced sig over hash, using the recovery id
+// carried in sig.V: bit 0 is the parity of R.y and bit 1 is set when R.x overflowed the group order.
+func RecoverEcdsaPublicKey(curve *Curve, hash []byte, sig *EcdsaSignature) (Point, error) {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	ec, err := curve.ToEllipticCurve()
+	if err != nil {
+		return nil, err
+	}
+	params := ec.Params()
+	n := params.N
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 || sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("signature values out of range")
+	}
+	if sig.V < 0 || sig.V > 3 {
+		return nil, fmt.Errorf("invalid recovery id %d", sig.V)
+	}
+
+	// Rebuild R from its x coordinate and the parity of y
+	x := new(big.Int).Set(sig.R)
+	if sig.V&2 != 0 {
+		x.Add(x, n)
+	}
+	if x.Cmp(params.P) >= 0 {
+		return nil, fmt.Errorf("invalid recovery id %d for r", sig.V)
+	}
+	byteLen := (params.BitSize + 7) / 8
+	compressed := make([]byte, 1+byteLen)
+	compressed[0] = 0x02 | byte(sig.V&1)
+	x.FillBytes(compressed[1:])
+	r, err := curve.NewIdentityPoint().FromAffineCompressed(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid r: %w", err)
+	}
+
+	// Q = r^-1 (sR - eG)
+	e := hashToInt(hash, n)
+	eScalar, err := curve.Scalar.SetBigInt(e)
+	if err != nil {
+		return nil, err
+	}
+	sScalar, err := curve.Scalar.SetBigInt(sig.S)
+	if err != nil {
+		return nil, err
+	}
+	rScalar, err := curve.Scalar.SetBigInt(sig.R)
+	if err != nil {
+		return nil, err
+	}
+	rInv, err := rScalar.Invert()
+	if err != nil {
+		return nil, err
+	}
+	q := r.Mul(sScalar).Sub(curve.ScalarBaseMult(eScalar)).Mul(rInv)
+	if q.IsIdentity() {
+		return nil, fmt.Errorf("recovered the identity point")
+	}
+	return q, nil
+}
+
+// hashToInt converts a hash value to an integer, truncated to the bit length of the group order
+// as specified by SEC 1.
+func hashToInt(hash []byte, n *big.Int) *big.Int {
+	orderBytes := (n.BitLen() + 7) / 8
+	if len(hash) > orderBytes {
+		hash = hash[:orderBytes]
+	}
+	ret := new(big.Int).SetBytes(hash)
+	excess := len(hash)*8 - n.BitLen()
+	if excess > 0 {
+		ret.Rsh(ret, uint(excess))
+	}
+	return ret
+}
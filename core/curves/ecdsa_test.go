@@ -0,0 +1,42 @@
+package curves
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinalizeEcdsaSignature(t *testing.T) {
+	for _, curve := range []*Curve{K256(), P256()} {
+		ec, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		halfOrder := new(big.Int).Rsh(ec.Params().N, 1)
+		secretKey := curve.Scalar.Random(crand.Reader)
+		publicKey := curve.ScalarBaseMult(secretKey)
+		digest := sha256.Sum256([]byte("finalize"))
+		h, err := curve.Scalar.SetBigInt(new(big.Int).SetBytes(digest[:]))
+		require.NoError(t, err)
+
+		for i := 0; i < 8; i++ {
+			// s = k^-1 (h + r . sk), as a single signer would compute it
+			k := curve.Scalar.Random(crand.Reader)
+			bigR := curve.ScalarBaseMult(k)
+			r, _, err := EcdsaR(curve, bigR)
+			require.NoError(t, err)
+			s := h.Add(r.Mul(secretKey)).Div(k)
+
+			signature, err := FinalizeEcdsaSignature(curve, bigR, s, publicKey, digest[:])
+			require.NoError(t, err)
+			require.True(t, signature.S.Cmp(halfOrder) <= 0)
+			recovered, err := RecoverEcdsaPublicKey(curve, digest[:], signature)
+			require.NoError(t, err)
+			require.True(t, publicKey.Equal(recovered))
+
+			_, err = FinalizeEcdsaSignature(curve, bigR, s, publicKey.Double(), digest[:])
+			require.ErrorIs(t, err, ErrInvalidEcdsaSignature)
+		}
+	}
+}
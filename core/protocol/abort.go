@@ -0,0 +1,59 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Evidence is the part of a protocol transcript that shows a party failed a check, in a form a third party can verify.
+// It holds the messages of the accused party the check ran on, along with the values of the accusing party the check
+// needs, which the accusing party can reveal since the run is aborted and its outputs discarded. The messages of the
+// accused party come with its Attestation, so an accuser cannot make up messages that fail the check: Verify checks
+// the attestation before it re-runs the check. A third party must still check that the identity key in the evidence
+// is the key of the accused party, e.g. the key a validator registered, before it penalizes that party.
+type Evidence interface {
+	// Verify checks the attestation of the messages of the accused party and re-runs the failed check on the
+	// evidence. It returns nil if the check fails, which confirms the accusation, and an error if the messages are
+	// not attested, the evidence is inconsistent or the check passes.
+	Verify() error
+}
+
+// AbortError reports that a protocol run aborted because a party failed a check, as opposed to failing for a local
+// or network fault. Evidence is nil when the check runs on secrets of the accusing party that outlive the run, or when
+// the parties run without identity keys so the messages are not attested, in which case the error identifies the
+// misbehaving party to the accusing party alone.
+type AbortError struct {
+	// Party is the misbehaving party, named by its role or identifier in the protocol.
+	Party string
+
+	// Check describes the check the party failed.
+	Check string
+
+	// Evidence lets the check be re-run on the messages it failed on, if it is not nil.
+	Evidence Evidence
+
+	// Err is the underlying error of the check, if any.
+	Err error
+}
+
+// Error implements error.
+func (e *AbortError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("party %s failed %s", e.Party, e.Check)
+	}
+	return fmt.Sprintf("party %s failed %s: %v", e.Party, e.Check, e.Err)
+}
+
+// Unwrap returns the underlying error of the check.
+func (e *AbortError) Unwrap() error { return e.Err }
+
+// Reattribute names the misbehaving party of err after its role or identifier in an enclosing protocol, when the
+// check failed in a sub-protocol where the party has another role. Errors that are not an AbortError are returned
+// unchanged.
+func Reattribute(err error, party string) error {
+	var abort *AbortError
+	if !errors.As(err, &abort) {
+		return err
+	}
+	return &AbortError{Party: party, Check: abort.Check, Evidence: abort.Evidence, Err: abort.Err}
+}
@@ -0,0 +1,75 @@
+package protocol
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrNoAttestation is returned when a message that must be attested carries no attestation.
+var ErrNoAttestation = errors.New("the message is not attested")
+
+// Attestation is the signature of a party, with its long-term ed25519 identity key, on a message it sent in a run of a
+// protocol. The message is the statement made of a label, which names the protocol and round, and the values the
+// message carries or answers to, so an attestation binds them to the session and cannot be reused for another message.
+// Evidence built from attested messages holds the attestations, which lets a third party that knows the identity key
+// of the accused party check that it sent them.
+type Attestation struct {
+	// PublicKey is the identity key of the party that sent the message.
+	PublicKey ed25519.PublicKey
+
+	// Signature is the signature of the statement under PublicKey.
+	Signature []byte
+}
+
+// Attest signs the statement made of label and parts with the identity key key. It returns nil if key is nil, for
+// parties that run without an identity key.
+func Attest(key ed25519.PrivateKey, label string, parts ...[]byte) *Attestation {
+	if key == nil {
+		return nil
+	}
+	return &Attestation{
+		PublicKey: key.Public().(ed25519.PublicKey),
+		Signature: ed25519.Sign(key, statement(label, parts)),
+	}
+}
+
+// Verify checks that the attestation is a signature of the statement made of label and parts under its public key.
+func (a *Attestation) Verify(label string, parts ...[]byte) error {
+	if a == nil {
+		return ErrNoAttestation
+	}
+	if len(a.PublicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid identity key in attestation")
+	}
+	if !ed25519.Verify(a.PublicKey, statement(label, parts), a.Signature) {
+		return errors.New("invalid attestation signature")
+	}
+	return nil
+}
+
+// VerifyFrom checks that the attestation is a signature of the statement made of label and parts under key, the
+// identity key of the party expected to have sent the message.
+func (a *Attestation) VerifyFrom(key ed25519.PublicKey, label string, parts ...[]byte) error {
+	if a == nil {
+		return ErrNoAttestation
+	}
+	if !key.Equal(a.PublicKey) {
+		return errors.New("the message is attested by another identity key")
+	}
+	return a.Verify(label, parts...)
+}
+
+// statement hashes label and parts, each prefixed by its length, into the digest an attestation signs.
+func statement(label string, parts [][]byte) []byte {
+	hash := sha3.New256()
+	_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, uint32(len(label))))
+	_, _ = hash.Write([]byte(label))
+	for _, part := range parts {
+		_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, uint32(len(part))))
+		_, _ = hash.Write(part)
+	}
+	return hash.Sum(nil)
+}
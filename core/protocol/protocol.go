@@ -27,6 +27,15 @@ const (
 	// Dkls18Refresh specifies the DKG protocol of the DKLs18 potocol.
 	Dkls18Refresh = "DKLs18-Refresh"
 
+	// Dkls23Dkg specifies the t-of-n DKG protocol of the DKLs23 protocol.
+	Dkls23Dkg = "DKLs23-DKG"
+
+	// Dkls23Sign specifies the t-of-n sign protocol of the DKLs23 protocol.
+	Dkls23Sign = "DKLs23-Sign"
+
+	// Dkls23Refresh specifies the t-of-n refresh protocol of the DKLs23 protocol.
+	Dkls23Refresh = "DKLs23-Refresh"
+
 	// versions will increment in 100 intervals, to leave room for adding other versions in between them if it is
 	// ever needed in the future.
 
@@ -35,6 +44,10 @@ const (
 
 	// Version1 is version 2!
 	Version1 = 200
+
+	// Version2 is version 3. It adds batched payloads, which hold a vector of version 1 values, one per item of the
+	// batch.
+	Version2 = 300
 )
 
 // Message provides serializers and deserializer for the inputs and outputs of each step of the protocol.
@@ -80,23 +93,16 @@ func (m *Message) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON unmarshals the message from JSON.
 func (m *Message) UnmarshalJSON(data []byte) error {
-	var obj map[string]interface{}
-	if err := json.Unmarshal(data, &obj); err != nil {
+	type Alias Message // Use type alias to avoid infinite recursion
+	aux := (*Alias)(m)
+	if err := json.Unmarshal(data, aux); err != nil {
 		return err
 	}
-	m.Payloads = make(map[string][]byte)
-	m.Metadata = make(map[string]string)
-	for k, v := range obj {
-		switch k {
-		case "payloads":
-			m.Payloads = v.(map[string][]byte)
-		case "metadata":
-			m.Metadata = v.(map[string]string)
-		case "protocol":
-			m.Protocol = v.(string)
-		case "version":
-			m.Version = uint(v.(float64))
-		}
+	if m.Payloads == nil {
+		m.Payloads = make(map[string][]byte)
+	}
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]string)
 	}
 	return nil
 }
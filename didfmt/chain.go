@@ -0,0 +1,168 @@
+package didfmt
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// Fragments of the verification methods of blockchain account DIDs
+const (
+	controllerFragment    = "#controller"
+	controllerKeyFragment = "#controllerKey"
+)
+
+// EthrNetworks maps the network names of did:ethr identifiers to their chain id
+var EthrNetworks = map[string]*big.Int{
+	"mainnet": big.NewInt(1),
+	"goerli":  big.NewInt(5),
+	"sepolia": big.NewInt(11155111),
+	"holesky": big.NewInt(17000),
+}
+
+// EthrResolver resolves did:ethr identifiers offline, as if their ERC-1056 registry held no
+// changes. Identifiers are an address or a compressed secp256k1 public key, optionally preceded
+// by a network name or a hex chain id: did:ethr:[network:](0x<address>|0x<public key>).
+// [did:ethr]: https://github.com/decentralized-identity/ethr-did-resolver/blob/master/doc/did-method-spec.md
+type EthrResolver struct {
+	// Format of the verification method of the public key, Multikey by default
+	Format KeyFormat
+}
+
+// Resolve implements Resolver
+func (r EthrResolver) Resolve(ctx context.Context, did string) (*Document, error) {
+	id, ok := strings.CutPrefix(did, "did:ethr:")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a did:ethr", ErrInvalidDID, did)
+	}
+	chainID := EthrNetworks["mainnet"]
+	if network, rest, ok := strings.Cut(id, ":"); ok {
+		var err error
+		if chainID, err = ethrChainID(network); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+		}
+		id = rest
+	}
+
+	var pub []byte
+	var addr common.Address
+	switch {
+	case common.IsHexAddress(id) && strings.HasPrefix(id, "0x"):
+		addr = common.HexToAddress(id)
+	case len(id) == 2+2*btcec.PubKeyBytesLenCompressed && strings.HasPrefix(id, "0x"):
+		var err error
+		if pub, err = hex.DecodeString(id[2:]); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+		}
+		key, err := ethcrypto.DecompressPubkey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+		}
+		addr = ethcrypto.PubkeyToAddress(*key)
+	default:
+		return nil, fmt.Errorf("%w: %q is neither an address nor a compressed public key", ErrInvalidDID, id)
+	}
+	return accountDocument(did, fmt.Sprintf("eip155:%s:%s", chainID, addr.Hex()), pub, r.Format)
+}
+
+// ethrChainID returns the chain id of a did:ethr network, given by name or as a hex chain id
+func ethrChainID(network string) (*big.Int, error) {
+	if id, ok := EthrNetworks[network]; ok {
+		return id, nil
+	}
+	if hexID, ok := strings.CutPrefix(network, "0x"); ok {
+		if id, ok := new(big.Int).SetString(hexID, 16); ok && id.Sign() > 0 {
+			return id, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown network %q", network)
+}
+
+// BtcrNetworks maps the network names of did:btcr identifiers to their chain parameters
+var BtcrNetworks = map[string]*chaincfg.Params{
+	"mainnet": &chaincfg.MainNetParams,
+	"testnet": &chaincfg.TestNet3Params,
+}
+
+// BtcrResolver resolves address based did:btcr identifiers offline. Identifiers are a P2PKH or
+// P2WPKH address or a compressed secp256k1 public key, which stands for its P2PKH address,
+// optionally preceded by a network name: did:btcr:[network:](<address>|<public key hex>).
+type BtcrResolver struct {
+	// Format of the verification method of the public key, Multikey by default
+	Format KeyFormat
+}
+
+// Resolve implements Resolver
+func (r BtcrResolver) Resolve(ctx context.Context, did string) (*Document, error) {
+	id, ok := strings.CutPrefix(did, "did:btcr:")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a did:btcr", ErrInvalidDID, did)
+	}
+	params := BtcrNetworks["mainnet"]
+	if network, rest, ok := strings.Cut(id, ":"); ok {
+		if params, ok = BtcrNetworks[network]; !ok {
+			return nil, fmt.Errorf("%w: unknown network %q", ErrInvalidDID, network)
+		}
+		id = rest
+	}
+
+	var pub []byte
+	var addr btcutil.Address
+	if len(id) == 2*btcec.PubKeyBytesLenCompressed {
+		var err error
+		if pub, err = hex.DecodeString(id); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+		}
+		if _, err := btcec.ParsePubKey(pub); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+		}
+		if addr, err = btcutil.NewAddressPubKeyHash(btcutil.Hash160(pub), params); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if addr, err = btcutil.DecodeAddress(id, params); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+		}
+		switch addr.(type) {
+		case *btcutil.AddressPubKeyHash, *btcutil.AddressWitnessPubKeyHash:
+		default:
+			return nil, fmt.Errorf("%w: %s is not a P2PKH or P2WPKH address", ErrInvalidDID, id)
+		}
+		if !addr.IsForNet(params) {
+			return nil, fmt.Errorf("%w: %s is not a %s address", ErrInvalidDID, id, params.Name)
+		}
+	}
+	// CAIP-2 identifies bitcoin networks by the first 16 bytes of their genesis block hash
+	chainID := params.GenesisHash.String()[:32]
+	return accountDocument(did, fmt.Sprintf("bip122:%s:%s", chainID, addr.EncodeAddress()), pub, r.Format)
+}
+
+// accountDocument creates the document of a DID controlled by a blockchain account, with the
+// account recovery method and, when the DID gives it, the secp256k1 public key of the account
+func accountDocument(did, accountID string, pub []byte, format KeyFormat) (*Document, error) {
+	doc := &Document{Context: []string{ContextDIDv1, ContextSecp256k1Rec}, ID: did}
+	doc.addVerificationMethod(&VerificationMethod{
+		ID:                  did + controllerFragment,
+		Type:                TypeSecp256k1Recovery2020,
+		Controller:          did,
+		BlockchainAccountID: accountID,
+	})
+	if pub != nil {
+		vm, err := newVerificationMethod(did+controllerKeyFragment, did, Secp256k1, pub, format)
+		if err != nil {
+			return nil, err
+		}
+		doc.addContext(format.context())
+		doc.addVerificationMethod(vm)
+	}
+	return doc, nil
+}
@@ -178,7 +178,14 @@ func ToPubKey(s string) (crypto.PubKey, error) {
 func codeForCurve(pubKey crypto.PubKey) (multicodec.Code, error) {
 	stdPub, err := crypto.PubKeyToStdKey(pubKey)
 	if err != nil {
-		return multicodec.Identity, err
+		// ECDSA keys other than the libp2p ones are read from their PKIX encoding
+		pkix, rawErr := pubKey.Raw()
+		if rawErr != nil {
+			return multicodec.Identity, err
+		}
+		if stdPub, err = x509.ParsePKIXPublicKey(pkix); err != nil {
+			return multicodec.Identity, err
+		}
 	}
 
 	ecdsaPub, ok := stdPub.(*ecdsa.PublicKey)
@@ -1,7 +1,6 @@
 package didfmt
 
 import (
-	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/x509"
 	"fmt"
@@ -24,6 +23,9 @@ const (
 	P521      = multicodec.P521Pub
 	Secp256k1 = multicodec.Secp256k1Pub // UCAN required
 	RSA       = multicodec.RsaPub
+
+	BLS12381G1 = multicodec.Bls12_381G1Pub
+	BLS12381G2 = multicodec.Bls12_381G2Pub
 )
 
 // Undef can be used to represent a nil or undefined DID, using DID{}
@@ -55,16 +57,11 @@ func Parse(str string) (DID, error) {
 	if baseCodec != mbase.Base58BTC {
 		return Undef, fmt.Errorf("not Base58BTC encoded")
 	}
-	code, _, err := varint.FromUvarint(bytes)
+	code, n, err := varint.FromUvarint(bytes)
 	if err != nil {
 		return Undef, err
 	}
-	switch multicodec.Code(code) {
-	case Ed25519, P256, Secp256k1, RSA:
-		return DID{bytes: string(bytes), code: multicodec.Code(code)}, nil
-	default:
-		return Undef, fmt.Errorf("unsupported did:key multicodec: 0x%x", code)
-	}
+	return New(multicodec.Code(code), bytes[n:])
 }
 
 // MustParse is like Parse but panics instead of returning an error.
@@ -81,10 +78,10 @@ func (d DID) Defined() bool {
 	return d.code != 0 || len(d.bytes) > 0
 }
 
-// PubKey returns the public key encapsulated by the did:key.
+// PubKey returns the public key encapsulated by the did:key. X25519 and BLS12-381 keys have no
+// libp2p representation.
 func (d DID) PubKey() (crypto.PubKey, error) {
 	unmarshaler, ok := map[multicodec.Code]crypto.PubKeyUnmarshaller{
-		X25519:    crypto.UnmarshalEd25519PublicKey,
 		Ed25519:   crypto.UnmarshalEd25519PublicKey,
 		P256:      ECDSAPubKeyUnmarshaler(elliptic.P256()),
 		P384:      ECDSAPubKeyUnmarshaler(elliptic.P384()),
@@ -93,11 +90,9 @@ func (d DID) PubKey() (crypto.PubKey, error) {
 		RSA:       RSAPubKeyUnmarshaler,
 	}[d.code]
 	if !ok {
-		return nil, fmt.Errorf("unsupported multicodec: %d", d.code)
+		return nil, fmt.Errorf("%w: no libp2p key for multicodec %s", ErrUnsupportedKey, d.code)
 	}
-
-	codeSize := varint.UvarintSize(uint64(d.code))
-	return unmarshaler([]byte(d.bytes)[codeSize:])
+	return unmarshaler(d.KeyBytes())
 }
 
 // String formats the decentralized identity document (DID) as a string.
@@ -106,14 +101,13 @@ func (d DID) String() string {
 	return "did:key:" + key
 }
 
+// ECDSAPubKeyUnmarshaler unmarshals the compressed or uncompressed SEC 1 encoding of a point of
+// curve into a libp2p key
 func ECDSAPubKeyUnmarshaler(curve elliptic.Curve) crypto.PubKeyUnmarshaller {
 	return func(data []byte) (crypto.PubKey, error) {
-		x, y := elliptic.UnmarshalCompressed(curve, data)
-
-		ecdsaPublicKey := &ecdsa.PublicKey{
-			Curve: curve,
-			X:     x,
-			Y:     y,
+		ecdsaPublicKey, err := unmarshalECDSA(curve, data)
+		if err != nil {
+			return nil, err
 		}
 
 		pkix, err := x509.MarshalPKIXPublicKey(ecdsaPublicKey)
@@ -125,8 +119,10 @@ func ECDSAPubKeyUnmarshaler(curve elliptic.Curve) crypto.PubKeyUnmarshaller {
 	}
 }
 
+// RSAPubKeyUnmarshaler unmarshals an RSA key in its PKCS #1 encoding, or the PKIX encoding older
+// did:key identifiers used, into a libp2p key
 func RSAPubKeyUnmarshaler(data []byte) (crypto.PubKey, error) {
-	rsaPublicKey, err := x509.ParsePKCS1PublicKey(data)
+	rsaPublicKey, err := unmarshalRSA(data)
 	if err != nil {
 		return nil, err
 	}
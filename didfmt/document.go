@@ -0,0 +1,206 @@
+package didfmt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
+	mbase "github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-multicodec"
+	varint "github.com/multiformats/go-varint"
+)
+
+// JSON-LD contexts of DID documents
+const (
+	ContextDIDv1        = "https://www.w3.org/ns/did/v1"
+	ContextMultikey     = "https://w3id.org/security/multikey/v1"
+	ContextJWS2020      = "https://w3id.org/security/suites/jws-2020/v1"
+	ContextSecp256k1Rec = "https://w3id.org/security/suites/secp256k1recovery-2020/v2"
+)
+
+// Verification method types
+const (
+	// TypeMultikey holds the multicodec prefixed key in publicKeyMultibase
+	TypeMultikey = "Multikey"
+	// TypeJSONWebKey2020 holds the key in publicKeyJwk
+	TypeJSONWebKey2020 = "JsonWebKey2020"
+	// TypeSecp256k1Recovery2020 names a blockchain account, the secp256k1 key of which is recovered
+	// from signatures
+	TypeSecp256k1Recovery2020 = "EcdsaSecp256k1RecoveryMethod2020"
+)
+
+var (
+	// ErrInvalidDID is returned for identifiers that are not well-formed for their method
+	ErrInvalidDID = errors.New("invalid DID")
+	// ErrMethodNotSupported is returned when no resolver handles the method of a DID
+	ErrMethodNotSupported = errors.New("DID method not supported")
+	// ErrNotFound is returned when a DID does not resolve to a document
+	ErrNotFound = errors.New("DID not found")
+	// ErrNoPublicKey is returned by verification methods that do not hold a public key
+	ErrNoPublicKey = errors.New("verification method holds no public key")
+)
+
+// KeyFormat selects how the public keys of generated documents are expressed
+type KeyFormat int
+
+const (
+	// FormatMultikey expresses keys as Multikey verification methods
+	FormatMultikey KeyFormat = iota
+	// FormatJWK expresses keys as JsonWebKey2020 verification methods
+	FormatJWK
+)
+
+// Document is a W3C DID document.
+// [DID Core]: https://www.w3.org/TR/did-core/#core-properties
+type Document struct {
+	Context              []string              `json:"@context"`
+	ID                   string                `json:"id"`
+	Controller           []string              `json:"controller,omitempty"`
+	AlsoKnownAs          []string              `json:"alsoKnownAs,omitempty"`
+	VerificationMethod   []*VerificationMethod `json:"verificationMethod,omitempty"`
+	Authentication       []string              `json:"authentication,omitempty"`
+	AssertionMethod      []string              `json:"assertionMethod,omitempty"`
+	KeyAgreement         []string              `json:"keyAgreement,omitempty"`
+	CapabilityInvocation []string              `json:"capabilityInvocation,omitempty"`
+	CapabilityDelegation []string              `json:"capabilityDelegation,omitempty"`
+	Service              []*Service            `json:"service,omitempty"`
+}
+
+// VerificationMethod is a public key, or the means to recover one, a DID subject proves control with
+type VerificationMethod struct {
+	ID                  string `json:"id"`
+	Type                string `json:"type"`
+	Controller          string `json:"controller"`
+	PublicKeyMultibase  string `json:"publicKeyMultibase,omitempty"`
+	PublicKeyJwk        *JWK   `json:"publicKeyJwk,omitempty"`
+	BlockchainAccountID string `json:"blockchainAccountId,omitempty"`
+}
+
+// Service is a service endpoint of a DID subject
+type Service struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// VerificationMethodByID returns the verification method with the given id, which may be relative to
+// the document, e.g. "#controller"
+func (doc *Document) VerificationMethodByID(id string) (*VerificationMethod, bool) {
+	if strings.HasPrefix(id, "#") {
+		id = doc.ID + id
+	}
+	for _, vm := range doc.VerificationMethod {
+		if vm.ID == id {
+			return vm, true
+		}
+	}
+	return nil, false
+}
+
+// SigningKey returns the first public key among the assertion and authentication methods
+func (doc *Document) SigningKey() (crypto.PubKey, error) {
+	for _, refs := range [][]string{doc.AssertionMethod, doc.Authentication} {
+		for _, ref := range refs {
+			vm, ok := doc.VerificationMethodByID(ref)
+			if !ok {
+				continue
+			}
+			if pub, err := vm.PubKey(); err == nil {
+				return pub, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", doc.ID, ErrNoPublicKey)
+}
+
+// addVerificationMethod adds vm, referencing it from every verification relationship but key
+// agreement
+func (doc *Document) addVerificationMethod(vm *VerificationMethod) {
+	doc.VerificationMethod = append(doc.VerificationMethod, vm)
+	doc.Authentication = append(doc.Authentication, vm.ID)
+	doc.AssertionMethod = append(doc.AssertionMethod, vm.ID)
+	doc.CapabilityInvocation = append(doc.CapabilityInvocation, vm.ID)
+	doc.CapabilityDelegation = append(doc.CapabilityDelegation, vm.ID)
+}
+
+// addContext adds ctx to the document unless it is already present
+func (doc *Document) addContext(ctx string) {
+	for _, c := range doc.Context {
+		if c == ctx {
+			return
+		}
+	}
+	doc.Context = append(doc.Context, ctx)
+}
+
+// PubKey returns the public key of a Multikey or JsonWebKey2020 verification method
+func (vm *VerificationMethod) PubKey() (crypto.PubKey, error) {
+	switch {
+	case vm.PublicKeyMultibase != "":
+		code, key, err := decodeMultikey(vm.PublicKeyMultibase)
+		if err != nil {
+			return nil, err
+		}
+		d, err := New(code, key)
+		if err != nil {
+			return nil, err
+		}
+		return d.PubKey()
+	case vm.PublicKeyJwk != nil:
+		return vm.PublicKeyJwk.PubKey()
+	default:
+		return nil, fmt.Errorf("%s: %w", vm.ID, ErrNoPublicKey)
+	}
+}
+
+// newVerificationMethod creates the verification method of a multicodec public key
+func newVerificationMethod(id, controller string, code multicodec.Code, key []byte, format KeyFormat) (*VerificationMethod, error) {
+	vm := &VerificationMethod{ID: id, Controller: controller}
+	switch format {
+	case FormatMultikey:
+		vm.Type = TypeMultikey
+		vm.PublicKeyMultibase = encodeMultikey(code, key)
+	case FormatJWK:
+		d, err := New(code, key)
+		if err != nil {
+			return nil, err
+		}
+		jwk, err := d.JWK()
+		if err != nil {
+			return nil, err
+		}
+		vm.Type = TypeJSONWebKey2020
+		vm.PublicKeyJwk = jwk
+	default:
+		return nil, fmt.Errorf("unknown key format %d", format)
+	}
+	return vm, nil
+}
+
+// context returns the JSON-LD context of the verification methods of a key format
+func (f KeyFormat) context() string {
+	if f == FormatJWK {
+		return ContextJWS2020
+	}
+	return ContextMultikey
+}
+
+// encodeMultikey encodes a key the way did:key does, as base58btc of its multicodec prefixed bytes
+func encodeMultikey(code multicodec.Code, key []byte) string {
+	s, _ := mbase.Encode(mbase.Base58BTC, append(varint.ToUvarint(uint64(code)), key...))
+	return s
+}
+
+// decodeMultikey decodes the publicKeyMultibase of a Multikey
+func decodeMultikey(s string) (multicodec.Code, []byte, error) {
+	_, data, err := mbase.Decode(s)
+	if err != nil {
+		return 0, nil, err
+	}
+	code, n, err := varint.FromUvarint(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return multicodec.Code(code), data[n:], nil
+}
@@ -0,0 +1,270 @@
+package didfmt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compressed secp256k1 public key of the private key 1, the generator point
+const generatorPubKey = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+func TestKeyResolver_Ed25519(t *testing.T) {
+	// example of the did:key specification
+	const did = "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	doc, err := NewRegistry().Resolve(context.Background(), did)
+	require.NoError(t, err)
+
+	vmID := did + "#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	kaID := did + "#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p"
+	assert.Equal(t, []string{ContextDIDv1, ContextMultikey}, doc.Context)
+	assert.Equal(t, did, doc.ID)
+	for _, refs := range [][]string{doc.Authentication, doc.AssertionMethod, doc.CapabilityInvocation, doc.CapabilityDelegation} {
+		assert.Equal(t, []string{vmID}, refs)
+	}
+	assert.Equal(t, []string{kaID}, doc.KeyAgreement)
+	require.Len(t, doc.VerificationMethod, 2)
+	assert.Equal(t, TypeMultikey, doc.VerificationMethod[0].Type)
+	assert.Equal(t, did, doc.VerificationMethod[0].Controller)
+
+	ka, ok := doc.VerificationMethodByID("#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p")
+	require.True(t, ok)
+	assert.Equal(t, kaID, ka.ID)
+	_, err = ka.PubKey()
+	assert.Error(t, err)
+
+	want, err := ToPubKey(did)
+	require.NoError(t, err)
+	pub, err := doc.SigningKey()
+	require.NoError(t, err)
+	assert.True(t, want.Equals(pub))
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded Document
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, doc, &decoded)
+}
+
+func TestKeyResolver_Formats(t *testing.T) {
+	keyGens := []struct {
+		name     string
+		generate func() (crypto.PrivKey, DID, error)
+	}{
+		{"ed25519", GenerateEd25519},
+		{"secp256k1", GenerateSecp256k1},
+		{"p256", GenerateECDSA},
+		{"rsa", GenerateRSA},
+	}
+	formats := []struct {
+		format KeyFormat
+		typ    string
+		ctx    string
+	}{
+		{FormatMultikey, TypeMultikey, ContextMultikey},
+		{FormatJWK, TypeJSONWebKey2020, ContextJWS2020},
+	}
+	for _, kg := range keyGens {
+		priv, did, err := kg.generate()
+		require.NoError(t, err)
+		for _, f := range formats {
+			t.Run(kg.name+"/"+f.typ, func(t *testing.T) {
+				doc, err := KeyResolver{Format: f.format}.Resolve(context.Background(), did.String())
+				require.NoError(t, err)
+				assert.Contains(t, doc.Context, f.ctx)
+				for _, vm := range doc.VerificationMethod {
+					assert.Equal(t, f.typ, vm.Type)
+				}
+				pub, err := doc.SigningKey()
+				require.NoError(t, err)
+				assert.True(t, priv.GetPublic().Equals(pub))
+			})
+		}
+	}
+}
+
+func TestJWK(t *testing.T) {
+	doc, err := KeyResolver{Format: FormatJWK}.Resolve(context.Background(), "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme")
+	require.NoError(t, err)
+	jwk := doc.VerificationMethod[0].PublicKeyJwk
+	assert.Equal(t, &JWK{
+		Kty: "EC",
+		Crv: "secp256k1",
+		X:   "h0wVx_2iDlOcblulc8E5iEw1EYh5n1RYtLQfeSTyNc0",
+		Y:   "O2EATIGbu6DezKFptj5scAIRntgfecanVNXxat1rnwE",
+	}, jwk)
+
+	invalid := []*JWK{
+		{Kty: "oct"},
+		{Kty: "OKP", Crv: "X25519", X: jwk.X},
+		{Kty: "EC", Crv: "P-256", X: jwk.X, Y: jwk.Y},
+		{Kty: "EC", Crv: "secp256k1", X: jwk.X, Y: jwk.X},
+		{Kty: "EC", Crv: "brainpoolP256r1", X: jwk.X, Y: jwk.Y},
+	}
+	for _, k := range invalid {
+		_, err := k.PubKey()
+		assert.Error(t, err, "%+v", k)
+	}
+}
+
+func TestEthrResolver(t *testing.T) {
+	const address = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+	tests := []struct {
+		did     string
+		account string
+		hasKey  bool
+	}{
+		{"did:ethr:0x7e5f4552091a69125d5dfcb7b8c2659029395bdf", "eip155:1:" + address, false},
+		{"did:ethr:sepolia:" + address, "eip155:11155111:" + address, false},
+		{"did:ethr:0x89:" + address, "eip155:137:" + address, false},
+		{"did:ethr:0x" + generatorPubKey, "eip155:1:" + address, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.did, func(t *testing.T) {
+			doc, err := NewRegistry().Resolve(context.Background(), tt.did)
+			require.NoError(t, err)
+			assert.Equal(t, tt.did, doc.ID)
+			assert.Contains(t, doc.Context, ContextSecp256k1Rec)
+
+			vm, ok := doc.VerificationMethodByID("#controller")
+			require.True(t, ok)
+			assert.Equal(t, TypeSecp256k1Recovery2020, vm.Type)
+			assert.Equal(t, tt.account, vm.BlockchainAccountID)
+			_, err = vm.PubKey()
+			assert.ErrorIs(t, err, ErrNoPublicKey)
+
+			_, ok = doc.VerificationMethodByID("#controllerKey")
+			assert.Equal(t, tt.hasKey, ok)
+			_, err = doc.SigningKey()
+			if tt.hasKey {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{tt.did + "#controller", tt.did + "#controllerKey"}, doc.Authentication)
+			} else {
+				assert.ErrorIs(t, err, ErrNoPublicKey)
+			}
+		})
+	}
+
+	for _, did := range []string{
+		"did:ethr:0x7e5f4552091a69125d5dfcb7b8c2659029395bd",
+		"did:ethr:ropsten:" + address,
+		"did:ethr:0x04" + generatorPubKey[2:],
+		"did:ethr:" + address[2:],
+	} {
+		_, err := EthrResolver{}.Resolve(context.Background(), did)
+		assert.ErrorIs(t, err, ErrInvalidDID, did)
+	}
+}
+
+func TestBtcrResolver(t *testing.T) {
+	const (
+		mainnet = "bip122:000000000019d6689c085ae165831e93:"
+		testnet = "bip122:000000000933ea01ad0ee984209779ba:"
+	)
+	tests := []struct {
+		did     string
+		account string
+		hasKey  bool
+	}{
+		{"did:btcr:1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", mainnet + "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", false},
+		{"did:btcr:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", mainnet + "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", false},
+		{"did:btcr:testnet:mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", testnet + "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", false},
+		{"did:btcr:" + generatorPubKey, mainnet + "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.did, func(t *testing.T) {
+			doc, err := NewRegistry().Resolve(context.Background(), tt.did)
+			require.NoError(t, err)
+			vm, ok := doc.VerificationMethodByID("#controller")
+			require.True(t, ok)
+			assert.Equal(t, tt.account, vm.BlockchainAccountID)
+			_, ok = doc.VerificationMethodByID("#controllerKey")
+			assert.Equal(t, tt.hasKey, ok)
+		})
+	}
+
+	for _, did := range []string{
+		"did:btcr:testnet:1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		"did:btcr:3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+		"did:btcr:regtest:1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		"did:btcr:xyz",
+	} {
+		_, err := BtcrResolver{}.Resolve(context.Background(), did)
+		assert.ErrorIs(t, err, ErrInvalidDID, did)
+	}
+}
+
+type sonrRegistryFunc func(ctx context.Context, did string) (*Document, error)
+
+func (f sonrRegistryFunc) LookupDocument(ctx context.Context, did string) (*Document, error) {
+	return f(ctx, did)
+}
+
+func TestSonrResolver(t *testing.T) {
+	ctx := context.Background()
+	priv, keyDID, err := GenerateSecp256k1()
+	require.NoError(t, err)
+	raw, err := priv.GetPublic().Raw()
+	require.NoError(t, err)
+	addr, err := bech32.ConvertAndEncode(SonrAddressHRP, raw)
+	require.NoError(t, err)
+	did := "did:sonr:" + addr
+
+	doc, err := NewRegistry().Resolve(ctx, did)
+	require.NoError(t, err)
+	pub, err := doc.SigningKey()
+	require.NoError(t, err)
+	assert.True(t, priv.GetPublic().Equals(pub))
+
+	registered, err := keyDID.Document(FormatMultikey)
+	require.NoError(t, err)
+	registered.ID = did
+	lookups := 0
+	r := NewRegistry()
+	r.Register("sonr", SonrResolver{Registry: sonrRegistryFunc(func(ctx context.Context, d string) (*Document, error) {
+		lookups++
+		if d == did {
+			return registered, nil
+		}
+		return nil, ErrNotFound
+	})})
+	doc, err = r.Resolve(ctx, did)
+	require.NoError(t, err)
+	assert.Same(t, registered, doc)
+
+	other, err := bech32.ConvertAndEncode(SonrAddressHRP, []byte{1, 2, 3})
+	require.NoError(t, err)
+	_, err = r.Resolve(ctx, "did:sonr:"+other)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, 2, lookups)
+
+	wrongHRP, err := bech32.ConvertAndEncode("cosmos", raw)
+	require.NoError(t, err)
+	_, err = r.Resolve(ctx, "did:sonr:"+wrongHRP)
+	assert.ErrorIs(t, err, ErrInvalidDID)
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewRegistry().Resolve(ctx, "did:web:example.com")
+	assert.ErrorIs(t, err, ErrMethodNotSupported)
+
+	for _, did := range []string{"", "key:z6Mk", "did:key", "did::z6Mk", "did:key:"} {
+		_, err := NewRegistry().Resolve(ctx, did)
+		assert.ErrorIs(t, err, ErrInvalidDID, did)
+	}
+
+	r := NewRegistry()
+	web := &Document{ID: "did:web:example.com"}
+	r.Register("web", ResolverFunc(func(ctx context.Context, did string) (*Document, error) {
+		return web, nil
+	}))
+	doc, err := r.Resolve(ctx, "did:web:example.com")
+	require.NoError(t, err)
+	assert.Same(t, web, doc)
+}
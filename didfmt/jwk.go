@@ -0,0 +1,166 @@
+package didfmt
+
+import (
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/multiformats/go-multicodec"
+)
+
+// JWK is a public JSON Web Key, limited to the members DID documents use
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// okpCurves names the curves of the OKP keys of a multicodec, BLS12-381 keys being given in
+// their compressed form
+var okpCurves = map[multicodec.Code]string{
+	Ed25519:    "Ed25519",
+	X25519:     "X25519",
+	BLS12381G1: "Bls12381G1",
+	BLS12381G2: "Bls12381G2",
+}
+
+// ecCurves names the curves of the EC keys of a multicodec
+var ecCurves = map[multicodec.Code]string{
+	Secp256k1: "secp256k1",
+	P256:      "P-256",
+	P384:      "P-384",
+	P521:      "P-521",
+}
+
+// JWK returns the public JSON Web Key of the did:key
+func (d DID) JWK() (*JWK, error) {
+	if !d.Defined() {
+		return nil, errors.New("undefined DID")
+	}
+	c, err := d.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	key := c.KeyBytes()
+	if crv, ok := okpCurves[d.code]; ok {
+		return &JWK{Kty: "OKP", Crv: crv, X: b64.EncodeToString(key)}, nil
+	}
+	switch d.code {
+	case Secp256k1:
+		pub, err := btcec.ParsePubKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return ecJWK(ecCurves[d.code], pub.X(), pub.Y(), 32), nil
+	case P256, P384, P521:
+		pub, err := unmarshalECDSA(ecdsaCurve(d.code), key)
+		if err != nil {
+			return nil, err
+		}
+		return ecJWK(ecCurves[d.code], pub.X, pub.Y, (pub.Curve.Params().BitSize+7)/8), nil
+	case RSA:
+		pub, err := unmarshalRSA(key)
+		if err != nil {
+			return nil, err
+		}
+		return &JWK{
+			Kty: "RSA",
+			N:   b64.EncodeToString(pub.N.Bytes()),
+			E:   b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: no JWK for multicodec %s", ErrUnsupportedKey, d.code)
+	}
+}
+
+func ecJWK(crv string, x, y *big.Int, size int) *JWK {
+	return &JWK{
+		Kty: "EC",
+		Crv: crv,
+		X:   b64.EncodeToString(x.FillBytes(make([]byte, size))),
+		Y:   b64.EncodeToString(y.FillBytes(make([]byte, size))),
+	}
+}
+
+// FromJWK returns the did:key of a public JSON Web Key, points being given in their compressed
+// form
+func FromJWK(k *JWK) (DID, error) {
+	switch k.Kty {
+	case "OKP":
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return Undef, err
+		}
+		for code, crv := range okpCurves {
+			if crv == k.Crv {
+				return New(code, x)
+			}
+		}
+		return Undef, fmt.Errorf("%w: OKP curve %q", ErrUnsupportedKey, k.Crv)
+	case "EC":
+		x, err := b64.DecodeString(k.X)
+		if err != nil {
+			return Undef, err
+		}
+		y, err := b64.DecodeString(k.Y)
+		if err != nil {
+			return Undef, err
+		}
+		if k.Crv == ecCurves[Secp256k1] {
+			var fx, fy btcec.FieldVal
+			if fx.SetByteSlice(x) || fy.SetByteSlice(y) {
+				return Undef, errors.New("secp256k1 coordinate overflows the field")
+			}
+			pub := btcec.NewPublicKey(&fx, &fy)
+			if !pub.IsOnCurve() {
+				return Undef, errors.New("secp256k1 point is not on the curve")
+			}
+			return New(Secp256k1, pub.SerializeCompressed())
+		}
+		for code, crv := range ecCurves {
+			if crv != k.Crv {
+				continue
+			}
+			curve := ecdsaCurve(code)
+			px, py := new(big.Int).SetBytes(x), new(big.Int).SetBytes(y)
+			if !curve.IsOnCurve(px, py) {
+				return Undef, fmt.Errorf("%s point is not on the curve", k.Crv)
+			}
+			return New(code, elliptic.MarshalCompressed(curve, px, py))
+		}
+		return Undef, fmt.Errorf("%w: EC curve %q", ErrUnsupportedKey, k.Crv)
+	case "RSA":
+		n, err := b64.DecodeString(k.N)
+		if err != nil {
+			return Undef, err
+		}
+		e, err := b64.DecodeString(k.E)
+		if err != nil {
+			return Undef, err
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return New(RSA, x509.MarshalPKCS1PublicKey(pub))
+	default:
+		return Undef, fmt.Errorf("%w: key type %q", ErrUnsupportedKey, k.Kty)
+	}
+}
+
+// PubKey returns the libp2p public key of the JWK
+func (k *JWK) PubKey() (crypto.PubKey, error) {
+	d, err := FromJWK(k)
+	if err != nil {
+		return nil, err
+	}
+	return d.PubKey()
+}
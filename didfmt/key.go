@@ -0,0 +1,173 @@
+package didfmt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/multiformats/go-multicodec"
+	varint "github.com/multiformats/go-varint"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// ErrUnsupportedKey is returned for keys of a multicodec or curve a conversion does not handle
+var ErrUnsupportedKey = errors.New("unsupported key")
+
+// New returns the did:key of a public key in the encoding of its multicodec: raw Ed25519 and
+// X25519 keys, compressed or uncompressed SEC 1 points for secp256k1 and the NIST curves,
+// compressed or uncompressed BLS12-381 points, and PKCS #1 RSA keys. The PKIX RSA keys of older
+// did:key identifiers are also accepted.
+func New(code multicodec.Code, key []byte) (DID, error) {
+	d := DID{code: code, bytes: string(append(varint.ToUvarint(uint64(code)), key...))}
+	var err error
+	switch code {
+	case Ed25519, X25519:
+		if len(key) != 32 {
+			err = fmt.Errorf("%s key of %d bytes", code, len(key))
+		}
+	case Secp256k1:
+		_, err = btcec.ParsePubKey(key)
+	case P256, P384, P521:
+		_, err = unmarshalECDSA(ecdsaCurve(code), key)
+	case BLS12381G1, BLS12381G2:
+		_, err = d.Point()
+	case RSA:
+		_, err = unmarshalRSA(key)
+	default:
+		return Undef, fmt.Errorf("unsupported did:key multicodec: 0x%x", uint64(code))
+	}
+	if err != nil {
+		return Undef, fmt.Errorf("invalid %s key: %w", code, err)
+	}
+	return d, nil
+}
+
+// Code returns the multicodec of the key
+func (d DID) Code() multicodec.Code {
+	return d.code
+}
+
+// KeyBytes returns the key without its multicodec prefix
+func (d DID) KeyBytes() []byte {
+	return []byte(d.bytes)[varint.UvarintSize(uint64(d.code)):]
+}
+
+// Compressed returns the did:key of the compressed form of an uncompressed point, keys that are
+// not points or already compressed are returned as is
+func (d DID) Compressed() (DID, error) {
+	key := d.KeyBytes()
+	switch d.code {
+	case Secp256k1:
+		pub, err := btcec.ParsePubKey(key)
+		if err != nil {
+			return Undef, err
+		}
+		return New(d.code, pub.SerializeCompressed())
+	case P256, P384, P521:
+		pub, err := unmarshalECDSA(ecdsaCurve(d.code), key)
+		if err != nil {
+			return Undef, err
+		}
+		return New(d.code, elliptic.MarshalCompressed(pub.Curve, pub.X, pub.Y))
+	case BLS12381G1, BLS12381G2:
+		p, err := d.Point()
+		if err != nil {
+			return Undef, err
+		}
+		return FromPoint(p)
+	default:
+		return d, nil
+	}
+}
+
+// pointCurves maps the multicodecs of points to their curve
+var pointCurves = map[multicodec.Code]func() *curves.Curve{
+	Ed25519:    curves.ED25519,
+	Secp256k1:  curves.K256,
+	P256:       curves.P256,
+	BLS12381G1: curves.BLS12381G1,
+	BLS12381G2: curves.BLS12381G2,
+}
+
+// pointCodes maps curve names to the multicodec of their points
+var pointCodes = map[string]multicodec.Code{
+	curves.ED25519Name:    Ed25519,
+	curves.K256Name:       Secp256k1,
+	curves.P256Name:       P256,
+	curves.BLS12381G1Name: BLS12381G1,
+	curves.BLS12381G2Name: BLS12381G2,
+}
+
+// Point returns the key as a curve point, for Ed25519, secp256k1, P-256 and BLS12-381 keys
+func (d DID) Point() (curves.Point, error) {
+	crv, ok := pointCurves[d.code]
+	if !ok {
+		return nil, fmt.Errorf("%w: no curve point for multicodec %s", ErrUnsupportedKey, d.code)
+	}
+	key := d.KeyBytes()
+	pt := crv().Point
+	if len(key) == len(pt.ToAffineCompressed()) {
+		return pt.FromAffineCompressed(key)
+	}
+	if d.code == Ed25519 {
+		return nil, fmt.Errorf("Ed25519 key of %d bytes", len(key))
+	}
+	return pt.FromAffineUncompressed(key)
+}
+
+// FromPoint returns the did:key of a curve point, in its compressed form
+func FromPoint(p curves.Point) (DID, error) {
+	code, ok := pointCodes[p.CurveName()]
+	if !ok {
+		return Undef, fmt.Errorf("%w: no multicodec for curve %s", ErrUnsupportedKey, p.CurveName())
+	}
+	if p.IsIdentity() {
+		return Undef, errors.New("the identity is not a public key")
+	}
+	return New(code, p.ToAffineCompressed())
+}
+
+// ecdsaCurve returns the NIST curve of a multicodec
+func ecdsaCurve(code multicodec.Code) elliptic.Curve {
+	switch code {
+	case P384:
+		return elliptic.P384()
+	case P521:
+		return elliptic.P521()
+	default:
+		return elliptic.P256()
+	}
+}
+
+// unmarshalECDSA parses the compressed or uncompressed SEC 1 encoding of a point of curve
+func unmarshalECDSA(curve elliptic.Curve, data []byte) (*ecdsa.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, data)
+	if x == nil {
+		x, y = elliptic.Unmarshal(curve, data)
+	}
+	if x == nil {
+		return nil, fmt.Errorf("invalid %s point", curve.Params().Name)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// unmarshalRSA parses a PKCS #1 or PKIX RSA public key
+func unmarshalRSA(data []byte) (*rsa.PublicKey, error) {
+	if pub, err := x509.ParsePKCS1PublicKey(data); err == nil {
+		return pub, nil
+	}
+	pub, err := x509.ParsePKIXPublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%T is not an RSA key", pub)
+	}
+	return rsaPub, nil
+}
@@ -0,0 +1,190 @@
+package didfmt
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"testing"
+
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
+	mbase "github.com/multiformats/go-multibase"
+	"github.com/multiformats/go-multicodec"
+	varint "github.com/multiformats/go-varint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+func mustDID(t *testing.T, generate func() (crypto.PrivKey, DID, error)) DID {
+	t.Helper()
+	_, d, err := generate()
+	require.NoError(t, err)
+	return d
+}
+
+func TestKeyTypes(t *testing.T) {
+	x25519 := make([]byte, 32)
+	_, err := rand.Read(x25519)
+	require.NoError(t, err)
+	g1, err := FromPoint(curves.BLS12381G1().Point.Random(rand.Reader))
+	require.NoError(t, err)
+	g2, err := FromPoint(curves.BLS12381G2().Point.Random(rand.Reader))
+	require.NoError(t, err)
+	xd, err := New(X25519, x25519)
+	require.NoError(t, err)
+	p384 := mustDID(t, func() (crypto.PrivKey, DID, error) { return GenerateECDSAWithCurve(P384) })
+
+	tests := []struct {
+		name     string
+		did      DID
+		code     multicodec.Code
+		hasPoint bool
+		hasPub   bool
+	}{
+		{"ed25519", mustDID(t, GenerateEd25519), Ed25519, true, true},
+		{"x25519", xd, X25519, false, false},
+		{"secp256k1", mustDID(t, GenerateSecp256k1), Secp256k1, true, true},
+		{"p256", mustDID(t, GenerateECDSA), P256, true, true},
+		{"p384", p384, P384, false, true},
+		{"bls12381 g1", g1, BLS12381G1, true, false},
+		{"bls12381 g2", g2, BLS12381G2, true, false},
+		{"rsa", mustDID(t, GenerateRSA), RSA, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, tt.did.Code())
+			parsed, err := Parse(tt.did.String())
+			require.NoError(t, err)
+			assert.Equal(t, tt.did, parsed)
+
+			jwk, err := tt.did.JWK()
+			require.NoError(t, err)
+			fromJWK, err := FromJWK(jwk)
+			require.NoError(t, err)
+			assert.Equal(t, tt.did, fromJWK)
+
+			pt, err := tt.did.Point()
+			if tt.hasPoint {
+				require.NoError(t, err)
+				fromPoint, err := FromPoint(pt)
+				require.NoError(t, err)
+				assert.Equal(t, tt.did, fromPoint)
+			} else {
+				assert.ErrorIs(t, err, ErrUnsupportedKey)
+			}
+
+			pub, err := tt.did.PubKey()
+			if tt.hasPub {
+				require.NoError(t, err)
+				fromPub, err := FromPubKey(pub)
+				require.NoError(t, err)
+				assert.Equal(t, tt.did, fromPub)
+			} else {
+				assert.ErrorIs(t, err, ErrUnsupportedKey)
+			}
+		})
+	}
+}
+
+func TestUncompressedKeys(t *testing.T) {
+	k256 := curves.K256().Point.Random(rand.Reader)
+	p256 := curves.P256().Point.Random(rand.Reader)
+	g1 := curves.BLS12381G1().Point.Random(rand.Reader)
+	g2 := curves.BLS12381G2().Point.Random(rand.Reader)
+	p384 := mustDID(t, func() (crypto.PrivKey, DID, error) { return GenerateECDSAWithCurve(P384) })
+	x, y := elliptic.UnmarshalCompressed(elliptic.P384(), p384.KeyBytes())
+
+	tests := []struct {
+		name         string
+		code         multicodec.Code
+		uncompressed []byte
+		compressed   []byte
+	}{
+		{"secp256k1", Secp256k1, k256.ToAffineUncompressed(), k256.ToAffineCompressed()},
+		{"p256", P256, p256.ToAffineUncompressed(), p256.ToAffineCompressed()},
+		{"p384", P384, elliptic.Marshal(elliptic.P384(), x, y), p384.KeyBytes()},
+		{"bls12381 g1", BLS12381G1, g1.ToAffineUncompressed(), g1.ToAffineCompressed()},
+		{"bls12381 g2", BLS12381G2, g2.ToAffineUncompressed(), g2.ToAffineCompressed()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := New(tt.code, tt.uncompressed)
+			require.NoError(t, err)
+			c, err := New(tt.code, tt.compressed)
+			require.NoError(t, err)
+			assert.NotEqual(t, u.String(), c.String())
+
+			parsed, err := Parse(u.String())
+			require.NoError(t, err)
+			compressed, err := parsed.Compressed()
+			require.NoError(t, err)
+			assert.Equal(t, c, compressed)
+
+			uj, err := u.JWK()
+			require.NoError(t, err)
+			cj, err := c.JWK()
+			require.NoError(t, err)
+			assert.Equal(t, cj, uj)
+
+			if upub, err := u.PubKey(); err == nil {
+				cpub, err := c.PubKey()
+				require.NoError(t, err)
+				assert.True(t, cpub.Equals(upub))
+			}
+		})
+	}
+}
+
+func TestLegacyRSAKey(t *testing.T) {
+	priv, d, err := GenerateRSA()
+	require.NoError(t, err)
+	pkix, err := priv.GetPublic().Raw()
+	require.NoError(t, err)
+	_, err = x509.ParsePKIXPublicKey(pkix)
+	require.NoError(t, err)
+
+	// older did:key identifiers hold the PKIX encoding of RSA keys
+	legacy, err := mbase.Encode(mbase.Base58BTC, append(varint.ToUvarint(uint64(RSA)), pkix...))
+	require.NoError(t, err)
+	parsed, err := Parse("did:key:" + legacy)
+	require.NoError(t, err)
+	pub, err := parsed.PubKey()
+	require.NoError(t, err)
+	assert.True(t, priv.GetPublic().Equals(pub))
+	jwk, err := parsed.JWK()
+	require.NoError(t, err)
+	fromJWK, err := FromJWK(jwk)
+	require.NoError(t, err)
+	assert.Equal(t, d, fromJWK)
+}
+
+func TestInvalidKeys(t *testing.T) {
+	k256, err := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	require.NoError(t, err)
+	tests := []struct {
+		name string
+		code multicodec.Code
+		key  []byte
+	}{
+		{"short ed25519", Ed25519, k256[:31]},
+		{"short x25519", X25519, k256[:31]},
+		{"secp256k1 not on curve", Secp256k1, append([]byte{0x02}, make([]byte, 32)...)},
+		{"p256 from secp256k1", P256, append([]byte{0x04}, k256[1:]...)},
+		{"bls12381 g1 from secp256k1", BLS12381G1, k256},
+		{"rsa garbage", RSA, k256},
+		{"unknown multicodec", multicodec.Sha2_256, k256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.code, tt.key)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = FromPoint(curves.K256().Point.Identity())
+	assert.Error(t, err)
+	_, err = FromPoint(curves.PALLAS().Point.Generator())
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
@@ -0,0 +1,123 @@
+package didfmt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"filippo.io/edwards25519"
+	varint "github.com/multiformats/go-varint"
+)
+
+// Resolver resolves a DID into its DID document
+type Resolver interface {
+	Resolve(ctx context.Context, did string) (*Document, error)
+}
+
+// ResolverFunc adapts a function into a Resolver
+type ResolverFunc func(ctx context.Context, did string) (*Document, error)
+
+// Resolve calls f
+func (f ResolverFunc) Resolve(ctx context.Context, did string) (*Document, error) {
+	return f(ctx, did)
+}
+
+// Method returns the method of a DID, e.g. "key" for "did:key:z6Mk..."
+func Method(did string) (string, error) {
+	rest, ok := strings.CutPrefix(did, "did:")
+	if !ok {
+		return "", fmt.Errorf("%w: %q does not start with 'did:'", ErrInvalidDID, did)
+	}
+	method, id, ok := strings.Cut(rest, ":")
+	if !ok || method == "" || id == "" {
+		return "", fmt.Errorf("%w: %q has no method specific identifier", ErrInvalidDID, did)
+	}
+	return method, nil
+}
+
+// Registry is a Resolver dispatching DIDs to the resolver registered for their method
+type Registry struct {
+	resolversLk sync.RWMutex
+	resolvers   map[string]Resolver
+}
+
+// NewRegistry creates a registry resolving did:key, did:ethr and did:btcr offline, and did:sonr
+// from the keys embedded in their addresses. Use Register to replace the resolver of a method,
+// e.g. to back did:sonr with a SonrRegistry.
+func NewRegistry() *Registry {
+	r := &Registry{resolvers: map[string]Resolver{}}
+	r.Register("key", KeyResolver{})
+	r.Register("ethr", EthrResolver{})
+	r.Register("btcr", BtcrResolver{})
+	r.Register("sonr", SonrResolver{})
+	return r
+}
+
+// Register sets the resolver of a method
+func (r *Registry) Register(method string, res Resolver) {
+	r.resolversLk.Lock()
+	defer r.resolversLk.Unlock()
+
+	r.resolvers[method] = res
+}
+
+// Resolve resolves did with the resolver of its method
+func (r *Registry) Resolve(ctx context.Context, did string) (*Document, error) {
+	method, err := Method(did)
+	if err != nil {
+		return nil, err
+	}
+	r.resolversLk.RLock()
+	res, ok := r.resolvers[method]
+	r.resolversLk.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMethodNotSupported, method)
+	}
+	return res.Resolve(ctx, did)
+}
+
+// KeyResolver resolves did:key identifiers, deriving their document from the embedded key.
+// [did:key document]: https://w3c-ccg.github.io/did-method-key/#document-creation-algorithm
+type KeyResolver struct {
+	// Format of the verification methods, Multikey by default
+	Format KeyFormat
+}
+
+// Resolve implements Resolver
+func (r KeyResolver) Resolve(ctx context.Context, did string) (*Document, error) {
+	d, err := Parse(did)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+	}
+	return d.Document(r.Format)
+}
+
+// Document returns the DID document of a did:key. Ed25519 keys are also given their X25519 key
+// agreement key.
+func (d DID) Document(format KeyFormat) (*Document, error) {
+	id := d.String()
+	doc := &Document{Context: []string{ContextDIDv1, format.context()}, ID: id}
+
+	key := []byte(d.bytes)[varint.UvarintSize(uint64(d.code)):]
+	vm, err := newVerificationMethod(id+"#"+encodeMultikey(d.code, key), id, d.code, key, format)
+	if err != nil {
+		return nil, err
+	}
+	doc.addVerificationMethod(vm)
+
+	if d.code == Ed25519 {
+		p, err := new(edwards25519.Point).SetBytes(key)
+		if err != nil {
+			return nil, err
+		}
+		xkey := p.BytesMontgomery()
+		ka, err := newVerificationMethod(id+"#"+encodeMultikey(X25519, xkey), id, X25519, xkey, format)
+		if err != nil {
+			return nil, err
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, ka)
+		doc.KeyAgreement = append(doc.KeyAgreement, ka.ID)
+	}
+	return doc, nil
+}
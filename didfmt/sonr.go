@@ -0,0 +1,66 @@
+package didfmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// SonrAddressHRP is the bech32 prefix of the addresses did:sonr identifiers are made of
+const SonrAddressHRP = "idx"
+
+// SonrRegistry looks up the DID documents registered on the sonr chain
+type SonrRegistry interface {
+	// LookupDocument returns the registered document of did, or ErrNotFound
+	LookupDocument(ctx context.Context, did string) (*Document, error)
+}
+
+// SonrResolver resolves did:sonr identifiers, did:sonr:<bech32 address>. Documents are looked up
+// in the registry, falling back to a document derived from the compressed secp256k1 public key
+// the address encodes when the registry is nil or does not know the DID.
+type SonrResolver struct {
+	Registry SonrRegistry
+	// Format of the verification method of derived documents, Multikey by default
+	Format KeyFormat
+}
+
+// Resolve implements Resolver
+func (r SonrResolver) Resolve(ctx context.Context, did string) (*Document, error) {
+	addr, ok := strings.CutPrefix(did, "did:sonr:")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a did:sonr", ErrInvalidDID, did)
+	}
+	if r.Registry != nil {
+		doc, err := r.Registry.LookupDocument(ctx, did)
+		switch {
+		case err == nil && doc.ID != did:
+			return nil, fmt.Errorf("registry returned the document of %s for %s", doc.ID, did)
+		case err == nil:
+			return doc, nil
+		case !errors.Is(err, ErrNotFound):
+			return nil, err
+		}
+	}
+
+	hrp, pub, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDID, err)
+	}
+	if hrp != SonrAddressHRP {
+		return nil, fmt.Errorf("%w: unexpected address prefix %q", ErrInvalidDID, hrp)
+	}
+	if _, err := btcec.ParsePubKey(pub); err != nil {
+		return nil, fmt.Errorf("%w: %s does not encode a secp256k1 public key: %w", ErrNotFound, did, err)
+	}
+	vm, err := newVerificationMethod(did+controllerFragment, did, Secp256k1, pub, r.Format)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{Context: []string{ContextDIDv1, r.Format.context()}, ID: did}
+	doc.addVerificationMethod(vm)
+	return doc, nil
+}
@@ -27,6 +27,9 @@ type DkgParticipant struct {
 	verifiers              *sharing.FeldmanVerifier
 	secretShares           []*sharing.ShamirShare
 	ctx                    byte
+	resharing              *sharing.Feldman        // sharing of the new committee a dealer reshares to
+	previous               *sharing.Feldman        // sharing of the old committee a new member receives sub-shares from
+	publicShares           map[uint32]curves.Point // VkShare of the dealers a new member receives sub-shares from
 }
 
 type dkgParticipantData struct {
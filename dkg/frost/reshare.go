@@ -0,0 +1,231 @@
+package frost
+
+import (
+	crand "crypto/rand"
+	"fmt"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/internal"
+	"github.com/sonr-io/crypto/sharing"
+)
+
+// Resharing moves the group key of a DKG to a new committee with another threshold and limit, keeping the same
+// verification key. A qualified set of old participants deal their shares with ReshareRound1; each member of the new
+// committee, created with NewResharingParticipant, verifies and combines the sub-shares with ReshareRound2; then every
+// old participant checks that the whole new committee holds the group key and erases its share, with ReshareRound3
+// for the dealers and ReshareRetire for the others. Any threshold old participants that kept their shares could still
+// recover the secret key, so the old committee must retire as a whole.
+// A new member ends up in the same state as a participant of the DKG, so it can sign and reshare again.
+
+const (
+	// rounds of resharing, which follow the rounds of the DKG
+	roundReshareDealt   = 4 // a dealer waits for the new committee to report the group key
+	roundReshareReceive = 5 // a new member waits for the sub-shares of the dealers
+	roundRetired        = 6 // an old participant erased its share
+)
+
+// ReshareBcast are values that a dealer broadcasts to the new committee after resharing round 1 completes
+type ReshareBcast struct {
+	Verifiers *sharing.FeldmanVerifier
+}
+
+// ReshareP2PSend are the sub-shares that a dealer sends to each member of the new committee after resharing round 1
+// completes
+type ReshareP2PSend = map[uint32]*sharing.ShamirShare
+
+// NewResharingParticipant creates the member id of a new committee of threshold out of limit members, ids running
+// from 1 to limit, that receives a share of the group key verificationKey. oldThreshold is the threshold of the old
+// committee, and publicShares are the VkShare of all its participants, as broadcast at the end of the DKG or of the
+// previous resharing.
+func NewResharingParticipant(id, threshold, limit uint32, curve *curves.Curve, verificationKey curves.Point, oldThreshold uint32, publicShares map[uint32]curves.Point) (*DkgParticipant, error) {
+	if curve == nil || verificationKey == nil || len(publicShares) == 0 {
+		return nil, internal.ErrNilArguments
+	}
+	if id == 0 || id > limit {
+		return nil, fmt.Errorf("invalid participant id %d", id)
+	}
+	feldman, err := sharing.NewFeldman(threshold, limit, curve)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := sharing.NewFeldman(oldThreshold, uint32(len(publicShares)), curve)
+	if err != nil {
+		return nil, err
+	}
+	otherParticipantShares := make(map[uint32]*dkgParticipantData, limit-1)
+	for other := uint32(1); other <= limit; other++ {
+		if other != id {
+			otherParticipantShares[other] = &dkgParticipantData{
+				Id: other,
+			}
+		}
+	}
+	return &DkgParticipant{
+		Id:                     id,
+		round:                  roundReshareReceive,
+		Curve:                  curve,
+		feldman:                feldman,
+		previous:               previous,
+		otherParticipantShares: otherParticipantShares,
+		VerificationKey:        verificationKey,
+		publicShares:           publicShares,
+	}, nil
+}
+
+// ReshareRound1 deals the share of the participant to a new committee of threshold out of limit members, as one of
+// dealers, a set of at least as many participants as the threshold of the DKG. The participant must have completed
+// the DKG, or the resharing that made it a member.
+func (dp *DkgParticipant) ReshareRound1(dealers []uint32, threshold, limit uint32) (*ReshareBcast, ReshareP2PSend, error) {
+	// Make sure dkg participant is not empty
+	if dp == nil || dp.Curve == nil {
+		return nil, nil, internal.ErrNilArguments
+	}
+
+	// Make sure round number is correct
+	if dp.round != 3 {
+		return nil, nil, internal.ErrInvalidRound
+	}
+
+	// Check the dealers can reconstruct the group key
+	if uint32(len(dealers)) < dp.feldman.Threshold {
+		return nil, nil, fmt.Errorf("at least %d dealers are needed", dp.feldman.Threshold)
+	}
+	for _, dealer := range dealers {
+		if dealer != dp.Id {
+			if _, ok := dp.otherParticipantShares[dealer]; !ok {
+				return nil, nil, fmt.Errorf("dealer %d is not a participant", dealer)
+			}
+		}
+	}
+
+	resharing, err := sharing.NewFeldman(threshold, limit, dp.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	share := &sharing.ShamirShare{Id: dp.Id, Value: dp.SkShare.Bytes()}
+	verifiers, shares, err := resharing.Reshare(dp.feldman, share, dealers, crand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	p2pSend := make(ReshareP2PSend, limit)
+	for _, subShare := range shares {
+		p2pSend[subShare.Id] = subShare
+	}
+
+	// Update internal state
+	dp.resharing = resharing
+	dp.round = roundReshareDealt
+
+	return &ReshareBcast{verifiers}, p2pSend, nil
+}
+
+// ReshareRound2 verifies the sub-shares the member of the new committee received from each dealer, and combines them
+// into its share of the group key.
+func (dp *DkgParticipant) ReshareRound2(bcast map[uint32]*ReshareBcast, p2psend map[uint32]*sharing.ShamirShare) (*Round2Bcast, error) {
+	// Make sure dkg participant is not empty
+	if dp == nil || dp.Curve == nil {
+		return nil, internal.ErrNilArguments
+	}
+
+	// Check dkg participant has the correct round number
+	if dp.round != roundReshareReceive {
+		return nil, internal.ErrInvalidRound
+	}
+
+	// Check the input is valid
+	if bcast == nil || p2psend == nil || len(bcast) == 0 {
+		return nil, internal.ErrNilArguments
+	}
+
+	verifiers := make(map[uint32]*sharing.FeldmanVerifier, len(bcast))
+	for id, b := range bcast {
+		if b == nil || b.Verifiers == nil {
+			return nil, fmt.Errorf("missing commitments from dealer %d", id)
+		}
+		verifiers[id] = b.Verifiers
+	}
+	share, verifier, err := dp.feldman.CombineReshares(dp.previous, dp.Id, p2psend, verifiers, dp.publicShares)
+	if err != nil {
+		return nil, err
+	}
+
+	// The constant terms of the sub-sharings only add up to the secret key if the dealers are a qualified set
+	if !verifier.Commitments[0].Equal(dp.VerificationKey) {
+		return nil, fmt.Errorf("the dealers did not reshare the group key")
+	}
+
+	sk, err := dp.Curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store signing key share and verification key share
+	dp.SkShare = sk
+	dp.VkShare = dp.Curve.ScalarBaseMult(sk)
+	dp.previous = nil
+	dp.publicShares = nil
+
+	// Update round number
+	dp.round = 3
+
+	// Broadcast
+	return &Round2Bcast{
+		dp.VerificationKey,
+		dp.VkShare,
+	}, nil
+}
+
+// ReshareRound3 checks that every member of the new committee reported the group key, and then erases the share of
+// the dealer, which can no longer take part in signing or resharing.
+func (dp *DkgParticipant) ReshareRound3(bcast map[uint32]*Round2Bcast) error {
+	// Make sure dkg participant is not empty
+	if dp == nil || dp.Curve == nil {
+		return internal.ErrNilArguments
+	}
+
+	// Check dkg participant has the correct round number
+	if dp.round != roundReshareDealt {
+		return internal.ErrInvalidRound
+	}
+
+	return dp.retire(dp.resharing.Limit, bcast)
+}
+
+// ReshareRetire is ReshareRound3 for an old participant that did not deal: it checks that every member of the new
+// committee of limit members reported the group key, and then erases the share of the participant.
+func (dp *DkgParticipant) ReshareRetire(limit uint32, bcast map[uint32]*Round2Bcast) error {
+	// Make sure dkg participant is not empty
+	if dp == nil || dp.Curve == nil {
+		return internal.ErrNilArguments
+	}
+
+	// Check dkg participant has the correct round number
+	if dp.round != 3 {
+		return internal.ErrInvalidRound
+	}
+
+	return dp.retire(limit, bcast)
+}
+
+// retire erases the share of the participant once the limit members of the new committee reported the group key.
+func (dp *DkgParticipant) retire(limit uint32, bcast map[uint32]*Round2Bcast) error {
+	if limit == 0 || uint32(len(bcast)) != limit {
+		return fmt.Errorf("expected the report of all %d members of the new committee", limit)
+	}
+	for id := uint32(1); id <= limit; id++ {
+		b, ok := bcast[id]
+		if !ok || b == nil || b.VerificationKey == nil {
+			return fmt.Errorf("missing report of member %d", id)
+		}
+		if !b.VerificationKey.Equal(dp.VerificationKey) {
+			return fmt.Errorf("member %d did not receive the group key", id)
+		}
+	}
+
+	// Invalidate the old share
+	dp.SkShare = dp.Curve.Scalar.Zero()
+	dp.secretShares = nil
+	dp.resharing = nil
+	dp.round = roundRetired
+	return nil
+}
@@ -0,0 +1,193 @@
+package frost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/sharing"
+)
+
+// runDkg runs the DKG among participants 1 to limit, and returns them with their round 2 broadcasts.
+func runDkg(t *testing.T, threshold, limit uint32) (map[uint32]*DkgParticipant, map[uint32]*Round2Bcast) {
+	t.Helper()
+	participants := make(map[uint32]*DkgParticipant, limit)
+	for id := uint32(1); id <= limit; id++ {
+		others := make([]uint32, 0, limit-1)
+		for other := uint32(1); other <= limit; other++ {
+			if other != id {
+				others = append(others, other)
+			}
+		}
+		p, err := NewDkgParticipant(id, threshold, Ctx, testCurve, others...)
+		require.NoError(t, err)
+		participants[id] = p
+	}
+	bcast := make(map[uint32]*Round1Bcast, limit)
+	p2p := make(map[uint32]map[uint32]*sharing.ShamirShare, limit)
+	for id, p := range participants {
+		b, send, err := p.Round1(nil)
+		require.NoError(t, err)
+		bcast[id] = b
+		for to, share := range send {
+			if p2p[to] == nil {
+				p2p[to] = make(map[uint32]*sharing.ShamirShare, limit-1)
+			}
+			p2p[to][id] = share
+		}
+	}
+	reports := make(map[uint32]*Round2Bcast, limit)
+	for id, p := range participants {
+		report, err := p.Round2(bcast, p2p[id])
+		require.NoError(t, err)
+		reports[id] = report
+	}
+	return participants, reports
+}
+
+// reshare has the dealers reshare to a new committee of threshold out of limit, retires the old committee of
+// oldThreshold, and returns the new committee with its reports.
+func reshare(t *testing.T, old map[uint32]*DkgParticipant, oldReports map[uint32]*Round2Bcast, oldThreshold uint32, dealers []uint32, threshold, limit uint32) (map[uint32]*DkgParticipant, map[uint32]*Round2Bcast) {
+	t.Helper()
+	publicShares := make(map[uint32]curves.Point, len(oldReports))
+	for id, report := range oldReports {
+		publicShares[id] = report.VkShare
+	}
+	bcast := make(map[uint32]*ReshareBcast, len(dealers))
+	p2p := make(map[uint32]map[uint32]*sharing.ShamirShare, limit)
+	for _, dealer := range dealers {
+		b, send, err := old[dealer].ReshareRound1(dealers, threshold, limit)
+		require.NoError(t, err)
+		bcast[dealer] = b
+		for to, share := range send {
+			if p2p[to] == nil {
+				p2p[to] = make(map[uint32]*sharing.ShamirShare, len(dealers))
+			}
+			p2p[to][dealer] = share
+		}
+	}
+	committee := make(map[uint32]*DkgParticipant, limit)
+	reports := make(map[uint32]*Round2Bcast, limit)
+	for id := uint32(1); id <= limit; id++ {
+		p, err := NewResharingParticipant(id, threshold, limit, testCurve, old[dealers[0]].VerificationKey, oldThreshold, publicShares)
+		require.NoError(t, err)
+		report, err := p.ReshareRound2(bcast, p2p[id])
+		require.NoError(t, err)
+		committee[id], reports[id] = p, report
+	}
+	for id, p := range old {
+		if bcast[id] != nil {
+			require.NoError(t, p.ReshareRound3(reports))
+		} else {
+			require.NoError(t, p.ReshareRetire(limit, reports))
+		}
+	}
+	return committee, reports
+}
+
+// requireSharesKey checks that any threshold members of the committee recombine the secret key of vk.
+func requireSharesKey(t *testing.T, committee map[uint32]*DkgParticipant, threshold, limit uint32, vk curves.Point) {
+	t.Helper()
+	shamir, err := sharing.NewShamir(threshold, limit, testCurve)
+	require.NoError(t, err)
+	for first := uint32(1); first+threshold-1 <= limit; first++ {
+		shares := make([]*sharing.ShamirShare, 0, threshold)
+		for id := first; id < first+threshold; id++ {
+			shares = append(shares, &sharing.ShamirShare{Id: id, Value: committee[id].SkShare.Bytes()})
+		}
+		sk, err := shamir.Combine(shares...)
+		require.NoError(t, err)
+		require.True(t, vk.Equal(testCurve.ScalarBaseMult(sk)))
+	}
+}
+
+func TestReshareKeepsVerificationKey(t *testing.T) {
+	participants, reports := runDkg(t, 2, 3)
+	vk := participants[1].VerificationKey
+
+	// 2-of-3 to 3-of-5
+	committee, committeeReports := reshare(t, participants, reports, 2, []uint32{1, 3}, 3, 5)
+	for _, p := range committee {
+		require.True(t, vk.Equal(p.VerificationKey))
+		require.True(t, p.VkShare.Equal(testCurve.ScalarBaseMult(p.SkShare)))
+	}
+	requireSharesKey(t, committee, 3, 5, vk)
+
+	// the shares of the dealers are invalidated
+	for _, dealer := range []uint32{1, 3} {
+		require.True(t, participants[dealer].SkShare.IsZero())
+		_, _, err := participants[dealer].ReshareRound1([]uint32{1, 3}, 2, 3)
+		require.Error(t, err)
+	}
+
+	// and the new committee can reshare again, back to 2-of-3
+	next, _ := reshare(t, committee, committeeReports, 3, []uint32{2, 3, 5}, 2, 3)
+	for _, p := range next {
+		require.True(t, vk.Equal(p.VerificationKey))
+	}
+	requireSharesKey(t, next, 2, 3, vk)
+}
+
+func TestReshareRejectsBadDealings(t *testing.T) {
+	participants, reports := runDkg(t, 2, 3)
+	vk := participants[1].VerificationKey
+	publicShares := map[uint32]curves.Point{1: reports[1].VkShare, 2: reports[2].VkShare, 3: reports[3].VkShare}
+
+	// too few dealers to reconstruct the group key
+	_, _, err := participants[1].ReshareRound1([]uint32{1}, 3, 5)
+	require.Error(t, err)
+
+	bcast := make(map[uint32]*ReshareBcast, 2)
+	sends := make(map[uint32]ReshareP2PSend, 2)
+	for _, dealer := range []uint32{1, 2} {
+		bcast[dealer], sends[dealer], err = participants[dealer].ReshareRound1([]uint32{1, 2}, 3, 5)
+		require.NoError(t, err)
+	}
+
+	// a sub-share that does not match the commitments of its dealer
+	p, err := NewResharingParticipant(1, 3, 5, testCurve, vk, 2, publicShares)
+	require.NoError(t, err)
+	_, err = p.ReshareRound2(bcast, map[uint32]*sharing.ShamirShare{
+		1: sends[1][1],
+		2: {Id: 1, Value: sends[2][2].Value},
+	})
+	require.Error(t, err)
+
+	// the dealers keep their shares until the whole new committee reported the group key
+	require.Error(t, participants[1].ReshareRound3(map[uint32]*Round2Bcast{1: reports[1]}))
+	require.False(t, participants[1].SkShare.IsZero())
+	require.Error(t, participants[3].ReshareRetire(5, map[uint32]*Round2Bcast{1: reports[1]}))
+	require.False(t, participants[3].SkShare.IsZero())
+}
+
+func TestReshareRetiresOldCommittee(t *testing.T) {
+	participants, reports := runDkg(t, 2, 5)
+	vk := participants[1].VerificationKey
+
+	// 2-of-5 to 3-of-5 with two dealers, so that three old participants do not deal
+	committee, _ := reshare(t, participants, reports, 2, []uint32{1, 4}, 3, 5)
+	requireSharesKey(t, committee, 3, 5, vk)
+
+	// no threshold of old shares recombine the secret key anymore
+	old, err := sharing.NewShamir(2, 5, testCurve)
+	require.NoError(t, err)
+	for first := uint32(1); first < 5; first++ {
+		for second := first + 1; second <= 5; second++ {
+			sk, err := old.Combine(
+				&sharing.ShamirShare{Id: first, Value: participants[first].SkShare.Bytes()},
+				&sharing.ShamirShare{Id: second, Value: participants[second].SkShare.Bytes()},
+			)
+			if err == nil {
+				require.False(t, vk.Equal(testCurve.ScalarBaseMult(sk)))
+			}
+		}
+	}
+
+	// and no old participant can deal again
+	for _, p := range participants {
+		require.True(t, p.SkShare.IsZero())
+		_, _, err = p.ReshareRound1([]uint32{2, 3}, 2, 5)
+		require.Error(t, err)
+	}
+}
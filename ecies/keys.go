@@ -55,3 +55,13 @@ func HashSeed(seed []byte) []byte {
 	bz := blake3.Sum512(seed)
 	return bz[:]
 }
+
+// NewPublicKeyFromBytes parses a compressed or uncompressed secp256k1 public key
+func NewPublicKeyFromBytes(b []byte) (*PublicKey, error) {
+	return eciesgo.NewPublicKeyFromBytes(b)
+}
+
+// NewPrivateKeyFromBytes returns the secp256k1 key pair of a 32-byte private scalar
+func NewPrivateKeyFromBytes(b []byte) *PrivateKey {
+	return eciesgo.NewPrivateKeyFromBytes(b)
+}
@@ -5,17 +5,16 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
-	"strings"
 
 	"github.com/libp2p/go-libp2p/core/crypto"
-	mb "github.com/multiformats/go-multibase"
-	varint "github.com/multiformats/go-varint"
+
+	"github.com/sonr-io/crypto/didfmt"
 )
 
 const (
 	// KeyPrefix indicates a decentralized identifier that uses the key method
 	KeyPrefix = "did:key"
-	// MulticodecKindRSAPubKey rsa-x509-pub https://github.com/multiformats/multicodec/pull/226
+	// MulticodecKindRSAPubKey rsa-pub
 	MulticodecKindRSAPubKey = 0x1205
 	// MulticodecKindEd25519PubKey ed25519-pub
 	MulticodecKindEd25519PubKey = 0xed
@@ -23,19 +22,17 @@ const (
 	MulticodecKindSecp256k1PubKey = 0x1206
 )
 
-// DID is a DID:key identifier
+// DID is a DID:key identifier of a libp2p public key, encoded and parsed by didfmt.DID
 type DID struct {
 	crypto.PubKey
 }
 
 // NewDID constructs an Identifier from a public key
 func NewDID(pub crypto.PubKey) (DID, error) {
-	switch pub.Type() {
-	case crypto.Ed25519, crypto.RSA, crypto.Secp256k1:
-		return DID{PubKey: pub}, nil
-	default:
-		return DID{}, fmt.Errorf("unsupported key type: %s", pub.Type())
+	if _, err := didfmt.FromPubKey(pub); err != nil {
+		return DID{}, fmt.Errorf("unsupported key type %s: %w", pub.Type(), err)
 	}
+	return DID{PubKey: pub}, nil
 }
 
 // NewFromPubKey constructs an Identifier from a public key
@@ -43,43 +40,32 @@ func NewFromPubKey(pub PubKey) DID {
 	return DID{PubKey: pub}
 }
 
+// DIDKey returns the did:key of the identifier
+func (id DID) DIDKey() (didfmt.DID, error) {
+	return didfmt.FromPubKey(id.PubKey)
+}
+
 // MulticodecType indicates the type for this multicodec
 func (id DID) MulticodecType() uint64 {
-	switch id.Type() {
-	case crypto.RSA:
-		return MulticodecKindRSAPubKey
-	case crypto.Ed25519:
-		return MulticodecKindEd25519PubKey
-	case crypto.Secp256k1:
-		return MulticodecKindSecp256k1PubKey
-	default:
+	d, err := id.DIDKey()
+	if err != nil {
 		panic("unexpected crypto type")
 	}
+	return uint64(d.Code())
 }
 
-// String returns this did:key formatted as a string
+// String returns this did:key formatted as a string. RSA keys are encoded in PKCS #1, where earlier versions encoded
+// them in PKIX, so the did:key of an RSA key differs from the one these versions returned. Parse accepts both.
 func (id DID) String() string {
-	raw, err := id.Raw()
-	if err != nil {
-		return ""
-	}
-
-	t := id.MulticodecType()
-	size := varint.UvarintSize(t)
-	data := make([]byte, size+len(raw))
-	n := varint.PutUvarint(data, t)
-	copy(data[n:], raw)
-
-	b58BKeyStr, err := mb.Encode(mb.Base58BTC, data)
+	d, err := id.DIDKey()
 	if err != nil {
 		return ""
 	}
-
-	return fmt.Sprintf("%s:%s", KeyPrefix, b58BKeyStr)
+	return d.String()
 }
 
 // VerifyKey returns the backing implementation for a public key, one of:
-// *rsa.PublicKey, ed25519.PublicKey
+// *rsa.PublicKey, ed25519.PublicKey, *ecdsa.PublicKey, or the raw bytes of secp256k1 keys
 func (id DID) VerifyKey() (interface{}, error) {
 	rawPubBytes, err := id.Raw()
 	if err != nil {
@@ -104,59 +90,23 @@ func (id DID) VerifyKey() (interface{}, error) {
 			return rawPubBytes, nil
 		}
 		return nil, fmt.Errorf("invalid Secp256k1 public key length: %d", len(rawPubBytes))
+	case crypto.ECDSA:
+		return crypto.PubKeyToStdKey(id.PubKey)
 	default:
 		return nil, fmt.Errorf("unrecognized Public Key type: %s", id.Type())
 	}
 }
 
-// Parse turns a string into a key method ID
+// Parse turns a string into a key method ID. Keys without a libp2p representation, X25519 and
+// BLS12-381 keys, are parsed with didfmt.Parse.
 func Parse(keystr string) (DID, error) {
-	var id DID
-	if !strings.HasPrefix(keystr, KeyPrefix) {
-		return id, fmt.Errorf("decentralized identifier is not a 'key' type")
-	}
-
-	keystr = strings.TrimPrefix(keystr, KeyPrefix+":")
-
-	enc, data, err := mb.Decode(keystr)
+	d, err := didfmt.Parse(keystr)
 	if err != nil {
-		return id, fmt.Errorf("decoding multibase: %w", err)
-	}
-
-	if enc != mb.Base58BTC {
-		return id, fmt.Errorf("unexpected multibase encoding: %s", mb.EncodingToStr[enc])
+		return DID{}, err
 	}
-
-	keyType, n, err := varint.FromUvarint(data)
+	pub, err := d.PubKey()
 	if err != nil {
-		return id, err
-	}
-
-	switch keyType {
-	case MulticodecKindRSAPubKey:
-		pub, err := crypto.UnmarshalRsaPublicKey(data[n:])
-		if err != nil {
-			return id, err
-		}
-		return DID{pub}, nil
-	case MulticodecKindEd25519PubKey:
-		pub, err := crypto.UnmarshalEd25519PublicKey(data[n:])
-		if err != nil {
-			return id, err
-		}
-		return DID{pub}, nil
-	case MulticodecKindSecp256k1PubKey:
-		// Handle both compressed and uncompressed formats
-		keyData := data[n:]
-		if len(keyData) != 33 && len(keyData) != 65 {
-			return id, fmt.Errorf("invalid Secp256k1 public key length: %d", len(keyData))
-		}
-		pub, err := crypto.UnmarshalSecp256k1PublicKey(keyData)
-		if err != nil {
-			return id, fmt.Errorf("failed to unmarshal Secp256k1 key: %w", err)
-		}
-		return DID{pub}, nil
+		return DID{}, err
 	}
-
-	return id, fmt.Errorf("unrecognized key type multicodec prefix: %x", data[0])
+	return DID{PubKey: pub}, nil
 }
@@ -0,0 +1,45 @@
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	mbase "github.com/multiformats/go-multibase"
+	varint "github.com/multiformats/go-varint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyRSADIDKey(t *testing.T) {
+	_, pub, err := crypto.GenerateRSAKeyPair(2048, rand.Reader)
+	require.NoError(t, err)
+	id, err := NewDID(pub)
+	require.NoError(t, err)
+
+	// did:key strings used to hold the PKIX encoding of RSA keys, and now hold their PKCS #1 encoding
+	pkix, err := pub.Raw()
+	require.NoError(t, err)
+	legacy, err := mbase.Encode(mbase.Base58BTC, append(varint.ToUvarint(MulticodecKindRSAPubKey), pkix...))
+	require.NoError(t, err)
+	legacy = KeyPrefix + ":" + legacy
+	assert.NotEqual(t, legacy, id.String())
+
+	fromLegacy, err := Parse(legacy)
+	require.NoError(t, err)
+	fromCurrent, err := Parse(id.String())
+	require.NoError(t, err)
+	assert.True(t, pub.Equals(fromLegacy.PubKey))
+	assert.True(t, fromCurrent.PubKey.Equals(fromLegacy.PubKey))
+
+	legacyKey, err := fromLegacy.VerifyKey()
+	require.NoError(t, err)
+	currentKey, err := fromCurrent.VerifyKey()
+	require.NoError(t, err)
+	require.IsType(t, &rsa.PublicKey{}, legacyKey)
+	assert.True(t, legacyKey.(*rsa.PublicKey).Equal(currentKey))
+
+	// a legacy identifier is re-encoded in the current form
+	assert.Equal(t, id.String(), fromLegacy.String())
+}
@@ -0,0 +1,242 @@
+package jose
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/didfmt"
+	"github.com/sonr-io/crypto/mpc"
+)
+
+func privateJWK(t *testing.T, generate func() (crypto.PrivKey, didfmt.DID, error)) *JWK {
+	t.Helper()
+	priv, _, err := generate()
+	require.NoError(t, err)
+	k, err := FromPrivKey(priv)
+	require.NoError(t, err)
+	return k
+}
+
+func TestThumbprint(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "rfc 7638 rsa",
+			key:  `{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw","e":"AQAB","alg":"RS256","kid":"2011-04-29"}`,
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			name: "rfc 8037 ed25519",
+			key:  `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+			want: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var k JWK
+			require.NoError(t, json.Unmarshal([]byte(tt.key), &k))
+			tp, err := k.Thumbprint()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tp)
+		})
+	}
+}
+
+func TestJWKPoints(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.ED25519(), curves.K256(), curves.P256(), curves.BLS12381G1(), curves.BLS12381G2()} {
+		t.Run(curve.Name, func(t *testing.T) {
+			p := curve.Point.Random(rand.Reader)
+			k, err := FromPoint(p)
+			require.NoError(t, err)
+			data, err := json.Marshal(k)
+			require.NoError(t, err)
+			var parsed JWK
+			require.NoError(t, json.Unmarshal(data, &parsed))
+			got, err := parsed.Point()
+			require.NoError(t, err)
+			assert.True(t, p.Equal(got))
+			_, err = parsed.Thumbprint()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEd25519Vector(t *testing.T) {
+	// RFC 8037 appendix A.4
+	k := &JWK{D: "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"}
+	k.Kty, k.Crv, k.X = "OKP", "Ed25519", "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	signer, err := NewSigner(k)
+	require.NoError(t, err)
+	jws, err := Sign([]byte("Example of Ed25519 signing"), signer, Header{})
+	require.NoError(t, err)
+	compact, err := jws.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg", compact)
+}
+
+func TestJWS(t *testing.T) {
+	tests := []struct {
+		name string
+		key  *JWK
+		alg  Algorithm
+	}{
+		{"ed25519", privateJWK(t, didfmt.GenerateEd25519), EdDSA},
+		{"secp256k1", privateJWK(t, didfmt.GenerateSecp256k1), ES256K},
+		{"p256", privateJWK(t, didfmt.GenerateECDSA), ES256},
+	}
+	other := privateJWK(t, didfmt.GenerateSecp256k1).Public()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := NewSigner(tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, signer.Algorithm())
+			pub := signer.Public()
+			assert.False(t, pub.IsPrivate())
+
+			jws, err := Sign([]byte(`{"iss":"sonr"}`), signer, Header{Typ: "JWT"})
+			require.NoError(t, err)
+			compact, err := jws.Compact()
+			require.NoError(t, err)
+			parsed, err := ParseSigned(compact)
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsed.Signatures[0].Protected.Alg)
+			assert.Equal(t, "JWT", parsed.Signatures[0].Protected.Typ)
+			assert.NoError(t, parsed.Verify(pub))
+			assert.Error(t, parsed.Verify(other))
+
+			flat, err := jws.Flattened()
+			require.NoError(t, err)
+			var fromFlat JWS
+			require.NoError(t, json.Unmarshal(flat, &fromFlat))
+			assert.NoError(t, fromFlat.Verify(pub))
+
+			parsed.Payload = []byte(`{"iss":"mallory"}`)
+			assert.ErrorIs(t, parsed.Verify(pub), ErrInvalidSignature)
+		})
+	}
+}
+
+func TestJWSGeneral(t *testing.T) {
+	keys := []*JWK{
+		privateJWK(t, didfmt.GenerateEd25519),
+		privateJWK(t, didfmt.GenerateSecp256k1),
+		privateJWK(t, didfmt.GenerateECDSA),
+	}
+	jws := &JWS{Payload: []byte("payload")}
+	for i, k := range keys {
+		k.Kid = string(rune('a' + i))
+		signer, err := NewSigner(k)
+		require.NoError(t, err)
+		require.NoError(t, jws.AddSignature(signer, Header{Kid: k.Kid}))
+	}
+	_, err := jws.Compact()
+	assert.ErrorIs(t, err, ErrInvalidJWS)
+
+	data, err := json.Marshal(jws)
+	require.NoError(t, err)
+	var parsed JWS
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	require.Len(t, parsed.Signatures, len(keys))
+	for _, k := range keys {
+		assert.NoError(t, parsed.Verify(k.Public()))
+	}
+}
+
+func TestJWSErrors(t *testing.T) {
+	ed := privateJWK(t, didfmt.GenerateEd25519)
+	k256 := privateJWK(t, didfmt.GenerateSecp256k1)
+
+	_, err := NewSigner(ed.Public())
+	assert.ErrorIs(t, err, ErrNoPrivateKey)
+	mismatched := *k256
+	mismatched.D = privateJWK(t, didfmt.GenerateSecp256k1).D
+	_, err = NewSigner(&mismatched)
+	assert.Error(t, err)
+	bls, err := FromPoint(curves.BLS12381G1().Point.Random(rand.Reader))
+	require.NoError(t, err)
+	_, err = KeyAlgorithm(bls)
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+
+	signer, err := NewSigner(ed)
+	require.NoError(t, err)
+	_, err = Sign([]byte("payload"), signer, Header{Alg: ES256K})
+	assert.Error(t, err)
+
+	for _, s := range []string{"a.b", "!.e30.AA", "eyJ0eXAiOiJKV1QifQ.e30.AA"} {
+		_, err = ParseSigned(s)
+		assert.ErrorIs(t, err, ErrInvalidJWS, s)
+	}
+}
+
+func TestEnclaveSigner(t *testing.T) {
+	enclave, err := mpc.NewEnclave(mpc.K256Name)
+	require.NoError(t, err)
+	signer, err := NewEnclaveSigner(enclave)
+	require.NoError(t, err)
+	assert.Equal(t, ES256K, signer.Algorithm())
+
+	jws, err := Sign([]byte("payload"), signer, Header{})
+	require.NoError(t, err)
+	compact, err := jws.Compact()
+	require.NoError(t, err)
+	parsed, err := ParseSigned(compact)
+	require.NoError(t, err)
+	assert.NoError(t, parsed.Verify(signer.Public()))
+}
+
+func TestJWE(t *testing.T) {
+	alice := privateJWK(t, didfmt.GenerateSecp256k1)
+	alice.Kid = "alice"
+	bob := privateJWK(t, didfmt.GenerateSecp256k1)
+	bob.Kid = "bob"
+	eve := privateJWK(t, didfmt.GenerateSecp256k1)
+	plaintext := []byte("the vault key share")
+
+	jwe, err := Encrypt(plaintext, Header{Cty: "text/plain"}, alice.Public(), bob.Public())
+	require.NoError(t, err)
+	_, err = jwe.Compact()
+	assert.ErrorIs(t, err, ErrInvalidJWE)
+	data, err := json.Marshal(jwe)
+	require.NoError(t, err)
+	var parsed JWE
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, "text/plain", parsed.Protected.Cty)
+	for _, k := range []*JWK{alice, bob} {
+		got, err := parsed.Decrypt(k)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, got)
+	}
+	_, err = parsed.Decrypt(eve)
+	assert.ErrorIs(t, err, ErrDecryption)
+	_, err = parsed.Decrypt(alice.Public())
+	assert.ErrorIs(t, err, ErrNoPrivateKey)
+
+	single, err := Encrypt(plaintext, Header{}, bob.Public())
+	require.NoError(t, err)
+	assert.Equal(t, "bob", single.Protected.Kid)
+	assert.Equal(t, ECIES, single.Protected.Alg)
+	assert.Equal(t, A256SIV, single.Protected.Enc)
+	compact, err := single.Compact()
+	require.NoError(t, err)
+	fromCompact, err := ParseEncrypted(compact)
+	require.NoError(t, err)
+	got, err := fromCompact.Decrypt(bob)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got)
+
+	fromCompact.Ciphertext[0] ^= 1
+	_, err = fromCompact.Decrypt(bob)
+	assert.Error(t, err)
+
+	_, err = Encrypt(plaintext, Header{}, privateJWK(t, didfmt.GenerateEd25519).Public())
+	assert.ErrorIs(t, err, ErrUnsupportedKey)
+}
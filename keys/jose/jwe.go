@@ -0,0 +1,273 @@
+package jose
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sonr-io/crypto/daed"
+	"github.com/sonr-io/crypto/ecies"
+)
+
+var (
+	// ErrInvalidJWE is returned for malformed JWE serializations
+	ErrInvalidJWE = errors.New("invalid jwe")
+	// ErrDecryption is returned when no recipient of a JWE can be decrypted with a key
+	ErrDecryption = errors.New("jwe decryption failed")
+)
+
+// ECIES wraps the content encryption key of a JWE with ECIES to a secp256k1 recipient key. It is
+// not a registered JOSE algorithm, so it has a private name that cannot collide with one.
+const ECIES Algorithm = "x-sonr-ECIES-secp256k1"
+
+// Encryption is a JWE content encryption algorithm
+type Encryption string
+
+// A256SIV encrypts the content with AES-SIV-CMAC under a 512-bit key. The synthetic IV is the
+// authentication tag of the JWE, and its initialization vector is empty. It is not a registered
+// JOSE encryption, so it has a private name that cannot collide with one.
+const A256SIV Encryption = "x-sonr-A256SIV"
+
+// sivSize is the size of the synthetic IV prepended to AES-SIV ciphertexts
+const sivSize = 16
+
+// JWE is a JSON Web Encryption of a plaintext to one or more recipients
+type JWE struct {
+	Protected  Header
+	Recipients []Recipient
+	Ciphertext []byte
+	Tag        []byte
+
+	protected string // protected is the encoded protected header, the additional authenticated data
+}
+
+// Recipient is the content encryption key of a JWE wrapped to one recipient
+type Recipient struct {
+	Header       *Header
+	EncryptedKey []byte
+}
+
+// Encrypt returns the JWE of a plaintext to secp256k1 recipient keys. The content encryption key
+// is wrapped to each recipient with ECIES, and the plaintext is encrypted with A256SIV. The key id
+// of a single recipient is set in the protected header, the ones of several in their recipient
+// headers.
+func Encrypt(plaintext []byte, protected Header, recipients ...*JWK) (*JWE, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipients", ErrInvalidJWE)
+	}
+	if (protected.Alg != "" && protected.Alg != ECIES) || (protected.Enc != "" && protected.Enc != A256SIV) {
+		return nil, fmt.Errorf("%w: unsupported algorithms %s and %s", ErrInvalidJWE, protected.Alg, protected.Enc)
+	}
+	protected.Alg, protected.Enc = ECIES, A256SIV
+	if len(recipients) == 1 && protected.Kid == "" {
+		protected.Kid = recipients[0].Kid
+	}
+
+	cek := make([]byte, daed.AESSIVKeySize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, err
+	}
+	jwe := &JWE{Protected: protected}
+	for _, r := range recipients {
+		pub, err := eciesPublicKey(r)
+		if err != nil {
+			return nil, err
+		}
+		ek, err := ecies.Encrypt(pub, cek)
+		if err != nil {
+			return nil, err
+		}
+		recipient := Recipient{EncryptedKey: ek}
+		if len(recipients) > 1 && r.Kid != "" {
+			recipient.Header = &Header{Kid: r.Kid}
+		}
+		jwe.Recipients = append(jwe.Recipients, recipient)
+	}
+
+	data, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+	jwe.protected = b64.EncodeToString(data)
+	siv, err := daed.NewAESSIV(cek)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := siv.EncryptDeterministically(plaintext, []byte(jwe.protected))
+	if err != nil {
+		return nil, err
+	}
+	jwe.Tag, jwe.Ciphertext = ct[:sivSize], ct[sivSize:]
+	return jwe, nil
+}
+
+// Decrypt returns the plaintext of the JWE with a private secp256k1 JWK, trying the recipients
+// whose key id is unset or matches the one of the key
+func (j *JWE) Decrypt(k *JWK) ([]byte, error) {
+	if j.Protected.Alg != ECIES || j.Protected.Enc != A256SIV {
+		return nil, fmt.Errorf("%w: unsupported algorithms %s and %s", ErrInvalidJWE, j.Protected.Alg, j.Protected.Enc)
+	}
+	if _, err := eciesPublicKey(k); err != nil {
+		return nil, err
+	}
+	d, err := k.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	priv := ecies.NewPrivateKeyFromBytes(d)
+	for _, r := range j.Recipients {
+		kid := j.Protected.Kid
+		if r.Header != nil && r.Header.Kid != "" {
+			kid = r.Header.Kid
+		}
+		if kid != "" && k.Kid != "" && kid != k.Kid {
+			continue
+		}
+		cek, err := ecies.Decrypt(priv, r.EncryptedKey)
+		if err != nil {
+			continue
+		}
+		siv, err := daed.NewAESSIV(cek)
+		if err != nil {
+			continue
+		}
+		ct := append(append([]byte{}, j.Tag...), j.Ciphertext...)
+		return siv.DecryptDeterministically(ct, []byte(j.protected))
+	}
+	return nil, ErrDecryption
+}
+
+// eciesPublicKey returns the ECIES public key of a secp256k1 JWK
+func eciesPublicKey(k *JWK) (*ecies.PublicKey, error) {
+	if k.Kty != "EC" || k.Crv != "secp256k1" {
+		return nil, fmt.Errorf("%w: %s keys are not %s keys", ErrUnsupportedKey, ECIES, k.Crv)
+	}
+	did, err := k.DID()
+	if err != nil {
+		return nil, err
+	}
+	return ecies.NewPublicKeyFromBytes(did.KeyBytes())
+}
+
+// Compact returns the compact serialization of a JWE with a single recipient and no recipient
+// header
+func (j *JWE) Compact() (string, error) {
+	if len(j.Recipients) != 1 || j.Recipients[0].Header != nil {
+		return "", fmt.Errorf("%w: compact serialization needs a single recipient without header", ErrInvalidJWE)
+	}
+	return strings.Join([]string{
+		j.protected,
+		b64.EncodeToString(j.Recipients[0].EncryptedKey),
+		"",
+		b64.EncodeToString(j.Ciphertext),
+		b64.EncodeToString(j.Tag),
+	}, "."), nil
+}
+
+// ParseEncrypted parses the compact serialization of a JWE
+func ParseEncrypted(s string) (*JWE, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("%w: compact serialization has %d parts", ErrInvalidJWE, len(parts))
+	}
+	jwe := &JWE{}
+	err := jwe.fromJSON(jsonJWE{
+		Protected:    parts[0],
+		EncryptedKey: parts[1],
+		IV:           parts[2],
+		Ciphertext:   parts[3],
+		Tag:          parts[4],
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jwe, nil
+}
+
+type jsonRecipient struct {
+	Header       *Header `json:"header,omitempty"`
+	EncryptedKey string  `json:"encrypted_key,omitempty"`
+}
+
+// jsonJWE is the general JSON serialization of a JWE, or its flattened form when the members of
+// the recipient are set at the top level
+type jsonJWE struct {
+	Protected    string          `json:"protected"`
+	Recipients   []jsonRecipient `json:"recipients,omitempty"`
+	Header       *Header         `json:"header,omitempty"`
+	EncryptedKey string          `json:"encrypted_key,omitempty"`
+	IV           string          `json:"iv"`
+	Ciphertext   string          `json:"ciphertext"`
+	Tag          string          `json:"tag"`
+}
+
+// MarshalJSON returns the general JSON serialization of the JWE
+func (j *JWE) MarshalJSON() ([]byte, error) {
+	out := jsonJWE{
+		Protected:  j.protected,
+		Ciphertext: b64.EncodeToString(j.Ciphertext),
+		Tag:        b64.EncodeToString(j.Tag),
+	}
+	for _, r := range j.Recipients {
+		out.Recipients = append(out.Recipients, jsonRecipient{
+			Header:       r.Header,
+			EncryptedKey: b64.EncodeToString(r.EncryptedKey),
+		})
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON parses the general or flattened JSON serialization of a JWE
+func (j *JWE) UnmarshalJSON(data []byte) error {
+	var in jsonJWE
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJWE, err)
+	}
+	return j.fromJSON(in)
+}
+
+func (j *JWE) fromJSON(in jsonJWE) error {
+	if in.IV != "" {
+		return fmt.Errorf("%w: %s has no initialization vector", ErrInvalidJWE, A256SIV)
+	}
+	recipients := in.Recipients
+	if in.EncryptedKey != "" {
+		if len(recipients) != 0 {
+			return fmt.Errorf("%w: both general and flattened recipients", ErrInvalidJWE)
+		}
+		recipients = []jsonRecipient{{Header: in.Header, EncryptedKey: in.EncryptedKey}}
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrInvalidJWE)
+	}
+
+	var out JWE
+	header, err := b64.DecodeString(in.Protected)
+	if err != nil {
+		return fmt.Errorf("%w: protected header: %v", ErrInvalidJWE, err)
+	}
+	if err := json.Unmarshal(header, &out.Protected); err != nil {
+		return fmt.Errorf("%w: protected header: %v", ErrInvalidJWE, err)
+	}
+	out.protected = in.Protected
+	for _, r := range recipients {
+		ek, err := b64.DecodeString(r.EncryptedKey)
+		if err != nil {
+			return fmt.Errorf("%w: encrypted key: %v", ErrInvalidJWE, err)
+		}
+		out.Recipients = append(out.Recipients, Recipient{Header: r.Header, EncryptedKey: ek})
+	}
+	if out.Ciphertext, err = b64.DecodeString(in.Ciphertext); err != nil {
+		return fmt.Errorf("%w: ciphertext: %v", ErrInvalidJWE, err)
+	}
+	if out.Tag, err = b64.DecodeString(in.Tag); err != nil {
+		return fmt.Errorf("%w: tag: %v", ErrInvalidJWE, err)
+	}
+	if len(out.Tag) != sivSize {
+		return fmt.Errorf("%w: tag of %d bytes", ErrInvalidJWE, len(out.Tag))
+	}
+	*j = out
+	return nil
+}
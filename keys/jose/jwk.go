@@ -0,0 +1,154 @@
+// Package jose implements JSON Web Keys, JSON Web Signatures and JSON Web Encryption for the
+// key types of the keys and core/curves packages.
+//
+// The JWS algorithms are the registered ones, so other JOSE libraries can verify the signatures.
+// The JWE is sonr-only: neither its key wrapping, ECIES to secp256k1, nor its content encryption,
+// AES-SIV, is a registered JOSE algorithm. They use the private names of ECIES and A256SIV, and
+// the OIDC and WebAuthn tooling built on other JOSE libraries cannot decrypt the JWEs.
+package jose
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	crypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/crypto/pb"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/didfmt"
+)
+
+var (
+	// ErrUnsupportedKey is returned for keys an operation has no algorithm for
+	ErrUnsupportedKey = errors.New("unsupported jose key")
+	// ErrNoPrivateKey is returned when signing or decrypting with a public JWK
+	ErrNoPrivateKey = errors.New("jwk has no private key")
+)
+
+var b64 = base64.RawURLEncoding
+
+// JWK is a JSON Web Key. It holds a private key when D is set.
+type JWK struct {
+	didfmt.JWK
+	D   string `json:"d,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+}
+
+// FromDID returns the public JWK of a did:key
+func FromDID(d didfmt.DID) (*JWK, error) {
+	k, err := d.JWK()
+	if err != nil {
+		return nil, err
+	}
+	return &JWK{JWK: *k}, nil
+}
+
+// FromPoint returns the public JWK of a curve point. Ed25519, secp256k1, P-256 and BLS12-381
+// points are supported.
+func FromPoint(p curves.Point) (*JWK, error) {
+	d, err := didfmt.FromPoint(p)
+	if err != nil {
+		return nil, err
+	}
+	return FromDID(d)
+}
+
+// FromPubKey returns the public JWK of a libp2p or keys public key
+func FromPubKey(pub crypto.PubKey) (*JWK, error) {
+	d, err := didfmt.FromPubKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	return FromDID(d)
+}
+
+// FromPrivKey returns the private JWK of an Ed25519, secp256k1 or NIST curve libp2p private key
+func FromPrivKey(priv crypto.PrivKey) (*JWK, error) {
+	k, err := FromPubKey(priv.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := priv.Raw()
+	if err != nil {
+		return nil, err
+	}
+	switch priv.Type() {
+	case pb.KeyType_Ed25519:
+		k.D = b64.EncodeToString(raw[:32])
+	case pb.KeyType_Secp256k1:
+		k.D = b64.EncodeToString(raw)
+	case pb.KeyType_ECDSA:
+		ec, err := x509.ParseECPrivateKey(raw)
+		if err != nil {
+			return nil, err
+		}
+		size := (ec.Curve.Params().BitSize + 7) / 8
+		k.D = b64.EncodeToString(ec.D.FillBytes(make([]byte, size)))
+	default:
+		return nil, fmt.Errorf("%w: %s private key", ErrUnsupportedKey, priv.Type())
+	}
+	return k, nil
+}
+
+// DID returns the did:key of the public key of the JWK
+func (k *JWK) DID() (didfmt.DID, error) {
+	return didfmt.FromJWK(&k.JWK)
+}
+
+// Point returns the public key of the JWK as a curve point
+func (k *JWK) Point() (curves.Point, error) {
+	d, err := k.DID()
+	if err != nil {
+		return nil, err
+	}
+	return d.Point()
+}
+
+// Public returns the JWK without its private key
+func (k *JWK) Public() *JWK {
+	pub := *k
+	pub.D = ""
+	return &pub
+}
+
+// IsPrivate reports whether the JWK holds a private key
+func (k *JWK) IsPrivate() bool {
+	return k.D != ""
+}
+
+// Thumbprint returns the base64url SHA-256 thumbprint of the JWK as defined by RFC 7638, the
+// hash of its required members in lexicographic order
+func (k *JWK) Thumbprint() (string, error) {
+	var members map[string]string
+	switch k.Kty {
+	case "EC":
+		members = map[string]string{"crv": k.Crv, "kty": k.Kty, "x": k.X, "y": k.Y}
+	case "OKP":
+		members = map[string]string{"crv": k.Crv, "kty": k.Kty, "x": k.X}
+	case "RSA":
+		members = map[string]string{"e": k.E, "kty": k.Kty, "n": k.N}
+	default:
+		return "", fmt.Errorf("%w: key type %q", ErrUnsupportedKey, k.Kty)
+	}
+	// maps are marshaled with sorted keys and without whitespace
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return b64.EncodeToString(sum[:]), nil
+}
+
+// privateKey returns the decoded private key of the JWK
+func (k *JWK) privateKey() ([]byte, error) {
+	if !k.IsPrivate() {
+		return nil, ErrNoPrivateKey
+	}
+	return b64.DecodeString(k.D)
+}
@@ -0,0 +1,224 @@
+package jose
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidJWS is returned for malformed JWS serializations
+var ErrInvalidJWS = errors.New("invalid jws")
+
+// Header is a JOSE header, the members of the protected or unprotected header of a JWS signature
+// or JWE
+type Header struct {
+	Alg Algorithm  `json:"alg,omitempty"`
+	Enc Encryption `json:"enc,omitempty"`
+	Kid string     `json:"kid,omitempty"`
+	Typ string     `json:"typ,omitempty"`
+	Cty string     `json:"cty,omitempty"`
+	JWK *JWK       `json:"jwk,omitempty"`
+}
+
+// JWS is a JSON Web Signature of a payload by one or more signers
+type JWS struct {
+	Payload    []byte
+	Signatures []Signature
+}
+
+// Signature is a signature of a JWS with its protected and unprotected header
+type Signature struct {
+	Protected Header
+	Header    *Header
+	Signature []byte
+
+	protected string // protected is the encoded protected header the signature covers
+}
+
+// Algorithm returns the algorithm of the signature, from its protected or unprotected header
+func (s *Signature) Algorithm() Algorithm {
+	if s.Protected.Alg == "" && s.Header != nil {
+		return s.Header.Alg
+	}
+	return s.Protected.Alg
+}
+
+// Sign returns the JWS of a payload signed by the signer. The algorithm of the signer is set in
+// the protected header.
+func Sign(payload []byte, signer Signer, protected Header) (*JWS, error) {
+	jws := &JWS{Payload: payload}
+	if err := jws.AddSignature(signer, protected); err != nil {
+		return nil, err
+	}
+	return jws, nil
+}
+
+// AddSignature signs the payload with another signer
+func (j *JWS) AddSignature(signer Signer, protected Header) error {
+	if protected.Alg != "" && protected.Alg != signer.Algorithm() {
+		return fmt.Errorf("header algorithm %s does not match signer algorithm %s", protected.Alg, signer.Algorithm())
+	}
+	protected.Alg = signer.Algorithm()
+	data, err := json.Marshal(protected)
+	if err != nil {
+		return err
+	}
+	sig := Signature{Protected: protected, protected: b64.EncodeToString(data)}
+	if sig.Signature, err = signer.Sign(j.signingInput(sig.protected)); err != nil {
+		return err
+	}
+	j.Signatures = append(j.Signatures, sig)
+	return nil
+}
+
+func (j *JWS) signingInput(protected string) []byte {
+	return []byte(protected + "." + b64.EncodeToString(j.Payload))
+}
+
+// Verify checks that the JWS has a signature by the key, using the signatures whose key id is
+// unset or matches the one of the key
+func (j *JWS) Verify(k *JWK) error {
+	if len(j.Signatures) == 0 {
+		return fmt.Errorf("%w: no signatures", ErrInvalidJWS)
+	}
+	err := ErrInvalidSignature
+	for _, sig := range j.Signatures {
+		if kid := sig.kid(); kid != "" && k.Kid != "" && kid != k.Kid {
+			continue
+		}
+		if err = Verify(sig.Algorithm(), k, j.signingInput(sig.protected), sig.Signature); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (s *Signature) kid() string {
+	if s.Protected.Kid == "" && s.Header != nil {
+		return s.Header.Kid
+	}
+	return s.Protected.Kid
+}
+
+// Compact returns the compact serialization of a JWS with a single signature and no unprotected
+// header
+func (j *JWS) Compact() (string, error) {
+	if len(j.Signatures) != 1 || j.Signatures[0].Header != nil {
+		return "", fmt.Errorf("%w: compact serialization needs a single signature without unprotected header", ErrInvalidJWS)
+	}
+	sig := j.Signatures[0]
+	return string(j.signingInput(sig.protected)) + "." + b64.EncodeToString(sig.Signature), nil
+}
+
+// ParseSigned parses the compact serialization of a JWS
+func ParseSigned(s string) (*JWS, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: compact serialization has %d parts", ErrInvalidJWS, len(parts))
+	}
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrInvalidJWS, err)
+	}
+	sig, err := parseSignature(jsonSignature{Protected: parts[0], Signature: parts[2]})
+	if err != nil {
+		return nil, err
+	}
+	return &JWS{Payload: payload, Signatures: []Signature{sig}}, nil
+}
+
+type jsonSignature struct {
+	Protected string  `json:"protected,omitempty"`
+	Header    *Header `json:"header,omitempty"`
+	Signature string  `json:"signature"`
+}
+
+// jsonJWS is the general JSON serialization of a JWS, or its flattened form when the members of
+// the signature are set at the top level
+type jsonJWS struct {
+	Payload    string          `json:"payload"`
+	Signatures []jsonSignature `json:"signatures,omitempty"`
+	Protected  string          `json:"protected,omitempty"`
+	Header     *Header         `json:"header,omitempty"`
+	Signature  string          `json:"signature,omitempty"`
+}
+
+// MarshalJSON returns the general JSON serialization of the JWS
+func (j *JWS) MarshalJSON() ([]byte, error) {
+	out := jsonJWS{Payload: b64.EncodeToString(j.Payload)}
+	for _, sig := range j.Signatures {
+		out.Signatures = append(out.Signatures, jsonSignature{
+			Protected: sig.protected,
+			Header:    sig.Header,
+			Signature: b64.EncodeToString(sig.Signature),
+		})
+	}
+	return json.Marshal(out)
+}
+
+// Flattened returns the flattened JSON serialization of a JWS with a single signature
+func (j *JWS) Flattened() ([]byte, error) {
+	if len(j.Signatures) != 1 {
+		return nil, fmt.Errorf("%w: flattened serialization needs a single signature", ErrInvalidJWS)
+	}
+	sig := j.Signatures[0]
+	return json.Marshal(jsonJWS{
+		Payload:   b64.EncodeToString(j.Payload),
+		Protected: sig.protected,
+		Header:    sig.Header,
+		Signature: b64.EncodeToString(sig.Signature),
+	})
+}
+
+// UnmarshalJSON parses the general or flattened JSON serialization of a JWS
+func (j *JWS) UnmarshalJSON(data []byte) error {
+	var in jsonJWS
+	if err := json.Unmarshal(data, &in); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJWS, err)
+	}
+	payload, err := b64.DecodeString(in.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: payload: %v", ErrInvalidJWS, err)
+	}
+	sigs := in.Signatures
+	if in.Signature != "" {
+		if len(sigs) != 0 {
+			return fmt.Errorf("%w: both general and flattened signatures", ErrInvalidJWS)
+		}
+		sigs = []jsonSignature{{Protected: in.Protected, Header: in.Header, Signature: in.Signature}}
+	}
+	if len(sigs) == 0 {
+		return fmt.Errorf("%w: no signatures", ErrInvalidJWS)
+	}
+	j.Payload, j.Signatures = payload, nil
+	for _, s := range sigs {
+		sig, err := parseSignature(s)
+		if err != nil {
+			return err
+		}
+		j.Signatures = append(j.Signatures, sig)
+	}
+	return nil
+}
+
+func parseSignature(s jsonSignature) (Signature, error) {
+	sig := Signature{Header: s.Header, protected: s.Protected}
+	if s.Protected != "" {
+		data, err := b64.DecodeString(s.Protected)
+		if err != nil {
+			return Signature{}, fmt.Errorf("%w: protected header: %v", ErrInvalidJWS, err)
+		}
+		if err := json.Unmarshal(data, &sig.Protected); err != nil {
+			return Signature{}, fmt.Errorf("%w: protected header: %v", ErrInvalidJWS, err)
+		}
+	}
+	if sig.Algorithm() == "" {
+		return Signature{}, fmt.Errorf("%w: signature has no algorithm", ErrInvalidJWS)
+	}
+	var err error
+	if sig.Signature, err = b64.DecodeString(s.Signature); err != nil {
+		return Signature{}, fmt.Errorf("%w: signature: %v", ErrInvalidJWS, err)
+	}
+	return sig, nil
+}
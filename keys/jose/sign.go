@@ -0,0 +1,211 @@
+package jose
+
+import (
+	"bytes"
+	stdecdsa "crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+
+	"github.com/sonr-io/crypto/mpc"
+)
+
+// ErrInvalidSignature is returned when a signature does not verify against a key
+var ErrInvalidSignature = errors.New("invalid jws signature")
+
+// Algorithm is a JWS signature or JWE key management algorithm
+type Algorithm string
+
+const (
+	// ES256K is a 64 byte r || s secp256k1 signature over the SHA-256 of the signing input
+	ES256K Algorithm = "ES256K"
+	// ES256 is a 64 byte r || s P-256 signature over the SHA-256 of the signing input
+	ES256 Algorithm = "ES256"
+	// EdDSA is an Ed25519 signature of the signing input
+	EdDSA Algorithm = "EdDSA"
+)
+
+// KeyAlgorithm returns the signature algorithm of the key of a JWK
+func KeyAlgorithm(k *JWK) (Algorithm, error) {
+	switch {
+	case k.Kty == "EC" && k.Crv == "secp256k1":
+		return ES256K, nil
+	case k.Kty == "EC" && k.Crv == "P-256":
+		return ES256, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		return EdDSA, nil
+	default:
+		return "", fmt.Errorf("%w: no signature algorithm for %s %s keys", ErrUnsupportedKey, k.Kty, k.Crv)
+	}
+}
+
+// Signer signs the signing input of a JWS
+type Signer interface {
+	Algorithm() Algorithm
+	Public() *JWK
+	Sign(signingInput []byte) ([]byte, error)
+}
+
+// NewSigner returns a signer for a private JWK
+func NewSigner(k *JWK) (Signer, error) {
+	alg, err := KeyAlgorithm(k)
+	if err != nil {
+		return nil, err
+	}
+	d, err := k.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	signer := &keySigner{alg: alg, public: k.Public()}
+	var pub []byte
+	switch alg {
+	case ES256K:
+		priv, _ := btcec.PrivKeyFromBytes(d)
+		pub = priv.PubKey().SerializeCompressed()
+		signer.sign = func(digest []byte) ([]byte, error) {
+			sig := ecdsa.Sign(priv, digest)
+			r, s := sig.R(), sig.S()
+			rb, sb := r.Bytes(), s.Bytes()
+			return append(rb[:], sb[:]...), nil
+		}
+	case ES256:
+		curve := elliptic.P256()
+		priv := &stdecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+		priv.Curve = curve
+		priv.X, priv.Y = curve.ScalarBaseMult(d)
+		pub = elliptic.MarshalCompressed(curve, priv.X, priv.Y)
+		signer.sign = func(digest []byte) ([]byte, error) {
+			r, s, err := stdecdsa.Sign(rand.Reader, priv, digest)
+			if err != nil {
+				return nil, err
+			}
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...), nil
+		}
+	case EdDSA:
+		if len(d) != ed25519.SeedSize {
+			return nil, fmt.Errorf("Ed25519 private key of %d bytes", len(d))
+		}
+		priv := ed25519.NewKeyFromSeed(d)
+		pub = priv.Public().(ed25519.PublicKey)
+		signer.sign = func(input []byte) ([]byte, error) {
+			return ed25519.Sign(priv, input), nil
+		}
+	}
+	did, err := k.DID()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(did.KeyBytes(), pub) {
+		return nil, errors.New("jwk private key does not match its public key")
+	}
+	return signer, nil
+}
+
+// keySigner signs with a private JWK
+type keySigner struct {
+	alg    Algorithm
+	public *JWK
+	sign   func(data []byte) ([]byte, error)
+}
+
+func (s *keySigner) Algorithm() Algorithm { return s.alg }
+func (s *keySigner) Public() *JWK         { return s.public }
+
+func (s *keySigner) Sign(signingInput []byte) ([]byte, error) {
+	if s.alg == EdDSA {
+		return s.sign(signingInput)
+	}
+	digest := sha256.Sum256(signingInput)
+	return s.sign(digest[:])
+}
+
+// NewEnclaveSigner returns a signer for an MPC enclave. Enclaves over secp256k1 sign with ES256K
+// and enclaves over P-256 with ES256.
+func NewEnclaveSigner(e mpc.Enclave) (Signer, error) {
+	var alg Algorithm
+	switch e.GetData().Curve {
+	case mpc.K256Name:
+		alg = ES256K
+	case mpc.P256Name:
+		alg = ES256
+	default:
+		return nil, fmt.Errorf("%w: enclave curve %s", ErrUnsupportedKey, e.GetData().Curve)
+	}
+	p, err := e.GetData().GetPubPoint()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := FromPoint(p)
+	if err != nil {
+		return nil, err
+	}
+	return &enclaveSigner{enclave: e, alg: alg, public: pub}, nil
+}
+
+// enclaveSigner signs with both key shares of an MPC enclave, over the SHA-256 of the signing input
+type enclaveSigner struct {
+	enclave mpc.Enclave
+	alg     Algorithm
+	public  *JWK
+}
+
+func (s *enclaveSigner) Algorithm() Algorithm { return s.alg }
+func (s *enclaveSigner) Public() *JWK         { return s.public }
+
+func (s *enclaveSigner) Sign(signingInput []byte) ([]byte, error) {
+	return s.enclave.Sign(signingInput, mpc.WithSHA256())
+}
+
+// Verify checks a signature of the signing input by the public key of a JWK
+func Verify(alg Algorithm, k *JWK, signingInput, sig []byte) error {
+	keyAlg, err := KeyAlgorithm(k)
+	if err != nil {
+		return err
+	}
+	if keyAlg != alg {
+		return fmt.Errorf("%w: %s key cannot verify %s signatures", ErrInvalidSignature, keyAlg, alg)
+	}
+	did, err := k.DID()
+	if err != nil {
+		return err
+	}
+	var valid bool
+	switch alg {
+	case EdDSA:
+		valid = ed25519.Verify(did.KeyBytes(), signingInput, sig)
+	case ES256K:
+		if len(sig) != 64 {
+			return fmt.Errorf("%w: ES256K signature of %d bytes", ErrInvalidSignature, len(sig))
+		}
+		pub, err := btcec.ParsePubKey(did.KeyBytes())
+		if err != nil {
+			return err
+		}
+		var r, s btcec.ModNScalar
+		if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) {
+			return fmt.Errorf("%w: ES256K signature overflows the group order", ErrInvalidSignature)
+		}
+		digest := sha256.Sum256(signingInput)
+		valid = ecdsa.NewSignature(&r, &s).Verify(digest[:], pub)
+	case ES256:
+		if len(sig) != 64 {
+			return fmt.Errorf("%w: ES256 signature of %d bytes", ErrInvalidSignature, len(sig))
+		}
+		curve := elliptic.P256()
+		x, y := elliptic.UnmarshalCompressed(curve, did.KeyBytes())
+		pub := &stdecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		digest := sha256.Sum256(signingInput)
+		valid = stdecdsa.Verify(pub, digest[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:]))
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+
+	p2ppb "github.com/libp2p/go-libp2p/core/crypto/pb"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/keys"
+)
+
+var (
+	// ErrInvalidAddress is returned for addresses that are not well-formed for their chain
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrUnsupportedCurve is returned when a chain has no addresses for the curve of a key
+	ErrUnsupportedCurve = errors.New("unsupported curve")
+)
+
+// AddressParser derives, validates and decodes the account addresses of a chain
+type AddressParser interface {
+	// Address derives the address of a public key
+	Address(pub curves.Point) (string, error)
+	// Validate checks addr is a well-formed address of the chain
+	Validate(addr string) error
+	// Decode returns the payload of an address, the public key hash or public key it encodes
+	Decode(addr string) ([]byte, error)
+}
+
+// PubKeyAddress derives the address of a public key with parser
+func PubKeyAddress(parser AddressParser, pub keys.PubKey) (string, error) {
+	pt, err := PubKeyPoint(pub)
+	if err != nil {
+		return "", err
+	}
+	return parser.Address(pt)
+}
+
+// PubKeyPoint returns the curve point of a public key
+func PubKeyPoint(pub keys.PubKey) (curves.Point, error) {
+	var crv *curves.Curve
+	switch pub.Type() {
+	case p2ppb.KeyType_Secp256k1:
+		crv = curves.K256()
+	case p2ppb.KeyType_Ed25519:
+		crv = curves.ED25519()
+	case p2ppb.KeyType_ECDSA:
+		crv = curves.P256()
+	case keys.KeyTypeBLS12381G1:
+		crv = curves.BLS12381G1()
+	case keys.KeyTypeBLS12381G2:
+		crv = curves.BLS12381G2()
+	case keys.KeyTypeSchnorr:
+		// x-only keys are the points with an even Y coordinate
+		return curves.K256().Point.FromAffineCompressed(append([]byte{0x02}, pub.Bytes()...))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, pub.Type())
+	}
+	return crv.Point.FromAffineCompressed(pub.Bytes())
+}
+
+// requireCurve checks pub is a point of the named curve
+func requireCurve(pub curves.Point, names ...string) error {
+	for _, name := range names {
+		if pub.CurveName() == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedCurve, pub.CurveName())
+}
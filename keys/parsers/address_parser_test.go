@@ -0,0 +1,244 @@
+package parsers
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/keys"
+)
+
+// Test keys. Expected addresses were cross-checked against BIP-86, BIP-173, the TON documentation
+// and an independent implementation of each encoding.
+const (
+	// secp256k1 public key of the private key 1
+	k256One = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	// secp256k1 public key of the BIP-340 test vector private key b7e15162...
+	k256Two = "02dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659"
+	// Ed25519 public key of RFC 8032 test 1
+	ed25519Key = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+)
+
+func point(t *testing.T, crv *curves.Curve, pubHex string) curves.Point {
+	t.Helper()
+	b, err := hex.DecodeString(pubHex)
+	require.NoError(t, err)
+	pt, err := crv.Point.FromAffineCompressed(b)
+	require.NoError(t, err)
+	return pt
+}
+
+type addressVector struct {
+	name   string
+	parser AddressParser
+	pub    curves.Point
+	addr   string
+}
+
+func assertAddresses(t *testing.T, vectors []addressVector) {
+	t.Helper()
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			addr, err := v.parser.Address(v.pub)
+			require.NoError(t, err)
+			assert.Equal(t, v.addr, addr)
+			assert.NoError(t, v.parser.Validate(v.addr))
+		})
+	}
+}
+
+func assertInvalid(t *testing.T, parser AddressParser, addrs ...string) {
+	t.Helper()
+	for _, addr := range addrs {
+		_, err := parser.Decode(addr)
+		assert.ErrorIs(t, err, ErrInvalidAddress, addr)
+	}
+}
+
+func TestBitcoinParser(t *testing.T) {
+	one, two := point(t, curves.K256(), k256One), point(t, curves.K256(), k256Two)
+	testnet := &chaincfg.TestNet3Params
+	assertAddresses(t, []addressVector{
+		{"p2pkh", BitcoinParser{Type: P2PKH}, one, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{"p2pkh 2", BitcoinParser{Type: P2PKH}, two, "17AuHzfJXnqt5EU2eKszga6uYGE2Bx28sC"},
+		{"p2pkh testnet", BitcoinParser{Type: P2PKH, Params: testnet}, one, "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r"},
+		{"p2wpkh", BitcoinParser{Type: P2WPKH}, one, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{"p2wpkh 2", BitcoinParser{Type: P2WPKH}, two, "bc1qgwczvl0vjclng5lujgujurk820ddfwuyd2luxd"},
+		{"p2wpkh testnet", BitcoinParser{Type: P2WPKH, Params: testnet}, one, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"},
+		{"p2tr", BitcoinParser{Type: P2TR}, one, "bc1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5sspknck9"},
+		{"p2tr 2", BitcoinParser{Type: P2TR}, two, "bc1p0t2rw5pjcw8t5n7xphk2wharpgaxhhe0kw8huctj3r3dxampzl9slnrkml"},
+		// BIP-86 first receiving address, an odd key lifts to the same output key
+		{"p2tr bip86", BitcoinParser{Type: P2TR}, point(t, curves.K256(), "03cc8a4bc64d897bddc5fbc2f670f7a8ba0b386779106cf1223c6fc5d7cd6fc115"), "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	})
+
+	program, err := BitcoinParser{Type: P2WPKH}.Decode("BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4")
+	require.NoError(t, err)
+	assert.Equal(t, "751e76e8199196d454941c45d1b3a323f1433bd6", hex.EncodeToString(program))
+
+	assertInvalid(t, BitcoinParser{Type: P2PKH},
+		"mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r",
+		"3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy",
+		"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMJ",
+	)
+	assertInvalid(t, BitcoinParser{Type: P2WPKH},
+		"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5",
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7Kv8f3t4",
+		"bc1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5sspknck9",
+	)
+	assertInvalid(t, BitcoinParser{Type: P2TR},
+		"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+		// BIP-350: a v1 program with a bech32 checksum
+		"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd",
+	)
+
+	_, err = BitcoinParser{}.Address(point(t, curves.ED25519(), ed25519Key))
+	assert.ErrorIs(t, err, ErrUnsupportedCurve)
+}
+
+func TestEthereumParser(t *testing.T) {
+	assertAddresses(t, []addressVector{
+		{"key 1", EthereumParser{}, point(t, curves.K256(), k256One), "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+	})
+
+	// EIP-55 test vectors
+	for _, addr := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		"0x52908400098527886e0f7030069857d2e4169ee7",
+		"0xDE709F2102306220921060314715629080E2FB77",
+	} {
+		assert.NoError(t, EthereumParser{}.Validate(addr), addr)
+	}
+	assertInvalid(t, EthereumParser{},
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD",
+		"5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz",
+	)
+}
+
+func TestFilecoinParser(t *testing.T) {
+	g1 := curves.BLS12381G1().Point.Generator()
+	assertAddresses(t, []addressVector{
+		{"f1", FilecoinParser{}, point(t, curves.K256(), k256One), "f1wcuzrs736zqzbbjjdgl2wvyyufuk4pefbymzf2i"},
+		{"f1 2", FilecoinParser{}, point(t, curves.K256(), k256Two), "f1l76re7xmh65k4c6vgqkod4jflymqz65yp35wtfy"},
+		{"t1", FilecoinParser{Network: FilecoinTestnet}, point(t, curves.K256(), k256One), "t1wcuzrs736zqzbbjjdgl2wvyyufuk4pefbymzf2i"},
+		{"f3", FilecoinParser{}, g1, "f3s7y5hjzrs7lzijuvmoge7knmb7bwrdcps52lsbnbjy5d6fy3vrmgyvpih74xugxp7m5pacw3eldlw5rocaha"},
+	})
+
+	payload, err := FilecoinParser{}.Decode("f3s7y5hjzrs7lzijuvmoge7knmb7bwrdcps52lsbnbjy5d6fy3vrmgyvpih74xugxp7m5pacw3eldlw5rocaha")
+	require.NoError(t, err)
+	assert.Equal(t, g1.ToAffineCompressed(), payload)
+
+	assertInvalid(t, FilecoinParser{},
+		"t1wcuzrs736zqzbbjjdgl2wvyyufuk4pefbymzf2i",
+		"f1wcuzrs736zqzbbjjdgl2wvyyufuk4pefbymzf3i",
+		"F1WCUZRS736ZQZBBJJDGL2WVYYUFUK4PEFBYMZF2I",
+		"f2wcuzrs736zqzbbjjdgl2wvyyufuk4pefbymzf2i",
+		"f1wcuzrs736zqzbbjjdgl2wvyyufuk4pef",
+	)
+}
+
+func TestSolanaParser(t *testing.T) {
+	assertAddresses(t, []addressVector{
+		{"rfc8032", SolanaParser{}, point(t, curves.ED25519(), ed25519Key), "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z"},
+	})
+	// the system program
+	assert.NoError(t, SolanaParser{}.Validate("11111111111111111111111111111111"))
+	assertInvalid(t, SolanaParser{}, "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9", "0Ven3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z")
+
+	_, err := SolanaParser{}.Address(point(t, curves.K256(), k256One))
+	assert.ErrorIs(t, err, ErrUnsupportedCurve)
+}
+
+func TestTonAddress(t *testing.T) {
+	const raw = "0:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e"
+	tests := []struct {
+		addr       string
+		bounceable bool
+		testnet    bool
+	}{
+		{"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHF", true, false},
+		{"UQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPuwA", false, false},
+		{"kQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPgpP", true, true},
+		{"0QDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPleK", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			a, err := ParseTonAddress(tt.addr)
+			require.NoError(t, err)
+			assert.Equal(t, raw, a.Raw())
+			assert.Equal(t, tt.bounceable, a.Bounceable)
+			assert.Equal(t, tt.testnet, a.Testnet)
+			assert.Equal(t, tt.addr, a.String())
+		})
+	}
+
+	a, err := ParseTonAddress(raw)
+	require.NoError(t, err)
+	assert.Equal(t, tests[0].addr, a.String())
+	a, err = ParseTonAddress("-1:ca6e321c7cce9ecedf0a8ca2492ec8592494aa5fb5ce0387dff96ef6af982a3e")
+	require.NoError(t, err)
+	assert.Equal(t, int8(-1), a.Workchain)
+
+	assertInvalid(t, TonParser{},
+		"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPrHG",
+		"EQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPr",
+		"kQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPgpP",
+		"0:ca6e32",
+	)
+	assert.NoError(t, TonParser{Testnet: true}.Validate("kQDKbjIcfM6ezt8KjKJJLshZJJSqX7XOA4ff-W72r5gqPgpP"))
+}
+
+func TestTonParser(t *testing.T) {
+	pub := point(t, curves.ED25519(), ed25519Key)
+	v3 := TonWalletV3R2
+	v3.SubwalletID = 42
+	assertAddresses(t, []addressVector{
+		{"default", TonParser{}, pub, "UQDNrJfJFisuFBrURjgosqcO_fh2K5foNWPzUr7PkC6Ipteq"},
+		{"v3r2", TonParser{Wallet: TonWalletV3R2}, pub, "UQB3V1d93mD6v4qWoRPnlm0UrYpcHZz2lCD_10u84NHWuSCK"},
+		{"v3r2 subwallet", TonParser{Wallet: v3}, pub, "UQD0RT1CS1nMRVhXlO0nJraqw4MfaDqwHpgCmtUv-jRGIBPe"},
+		{"v3r2 masterchain", TonParser{Wallet: TonWalletV3R2, Workchain: -1}, pub, "Uf-ZLVb4cagFlzk8_f159LK83j1AX0Y14K43czlyISLifChS"},
+		{"v4r2", TonParser{Wallet: TonWalletV4R2}, pub, "UQDNrJfJFisuFBrURjgosqcO_fh2K5foNWPzUr7PkC6Ipteq"},
+		{"v4r2 bounceable", TonParser{Wallet: TonWalletV4R2, Bounceable: true}, pub, "EQDNrJfJFisuFBrURjgosqcO_fh2K5foNWPzUr7PkC6Ipopv"},
+		{"v4r2 masterchain", TonParser{Wallet: TonWalletV4R2, Workchain: -1}, pub, "Uf_eBpt1_Nrq8OiKphgs9B79bKvLnjID-gObYCogAOkY4gKL"},
+		{"v5r1", TonParser{Wallet: TonWalletV5R1}, pub, "UQCUp64SJJ505dIdcgHDG1oD8JKMLXpQzu5W6lLFdQGtA8kY"},
+		{"v5r1 testnet", TonParser{Wallet: TonWalletV5R1, Testnet: true}, pub, "0QBlzTcyZhQiECg0bU2xZyOorM5qAU6Kwxu9X76R2XsOnWNx"},
+	})
+
+	// the default id of v5 wallets is that of the first client wallet on the network
+	assert.Equal(t, uint32(2147483409), TonWalletV5R1.walletID(0, false))
+	assert.Equal(t, uint32(2147483645), TonWalletV5R1.walletID(0, true))
+}
+
+func TestCosmosParser(t *testing.T) {
+	one, two := point(t, curves.K256(), k256One), point(t, curves.K256(), k256Two)
+	assertAddresses(t, []addressVector{
+		{"cosmos", CosmosParser{Prefix: ATOMPrefix}, one, "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60c"},
+		{"cosmos 2", CosmosParser{Prefix: ATOMPrefix}, two, "cosmos1gwczvl0vjclng5lujgujurk820ddfwuymsphzq"},
+		{"axelar", CosmosParser{Prefix: AXELARPrefix}, one, "axelar1w508d6qejxtdg4y5r3zarvary0c5xw7k7npjye"},
+		{"osmo", CosmosParser{Prefix: OSMOPrefix}, one, "osmo1w508d6qejxtdg4y5r3zarvary0c5xw7kjxy2e2"},
+		{"sonr", CosmosParser{Prefix: SONRPrefix}, one, "idx1w508d6qejxtdg4y5r3zarvary0c5xw7k9fy8sk"},
+		{"stars", CosmosParser{Prefix: STARSPrefix}, two, "stars1gwczvl0vjclng5lujgujurk820ddfwuy0vk2f3"},
+		{"evmos", CosmosParser{Prefix: EVMOSPrefix}, one, "evmos10e0525sfrf53yh2aljmm3sn9jq5njk7lxpag6e"},
+	})
+	assertInvalid(t, CosmosParser{Prefix: ATOMPrefix},
+		"osmo1w508d6qejxtdg4y5r3zarvary0c5xw7kjxy2e2",
+		"cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k6ah60d",
+		"cosmos1qqqsyqcyq5rqwzqfys8f67",
+	)
+}
+
+func TestPubKeyAddress(t *testing.T) {
+	pt := point(t, curves.K256(), k256One)
+	addr, err := PubKeyAddress(EthereumParser{}, keys.NewPubKey(pt))
+	require.NoError(t, err)
+	assert.Equal(t, "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf", addr)
+}
@@ -1 +1,245 @@
 package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/signatures/schnorr/bip340"
+)
+
+// BitcoinAddressType is the output script type a bitcoin address pays to
+type BitcoinAddressType int
+
+const (
+	// P2PKH pays to the hash of a public key, base58check encoded
+	P2PKH BitcoinAddressType = iota
+	// P2WPKH pays to the hash of a public key with a segwit v0 program, bech32 encoded
+	P2WPKH
+	// P2TR pays to the BIP-86 tweaked x-only public key with a segwit v1 program, bech32m encoded
+	P2TR
+)
+
+func (t BitcoinAddressType) String() string {
+	switch t {
+	case P2PKH:
+		return "p2pkh"
+	case P2WPKH:
+		return "p2wpkh"
+	case P2TR:
+		return "p2tr"
+	default:
+		return fmt.Sprintf("BitcoinAddressType(%d)", int(t))
+	}
+}
+
+// BitcoinParser handles the secp256k1 addresses of a bitcoin network
+type BitcoinParser struct {
+	Type BitcoinAddressType
+	// Params of the network, mainnet when nil
+	Params *chaincfg.Params
+}
+
+var _ AddressParser = BitcoinParser{}
+
+func (p BitcoinParser) params() *chaincfg.Params {
+	if p.Params == nil {
+		return &chaincfg.MainNetParams
+	}
+	return p.Params
+}
+
+// Address derives the address of a secp256k1 public key
+func (p BitcoinParser) Address(pub curves.Point) (string, error) {
+	if err := requireCurve(pub, curves.K256Name); err != nil {
+		return "", err
+	}
+	params := p.params()
+	compressed := pub.ToAffineCompressed()
+	switch p.Type {
+	case P2PKH:
+		return base58.CheckEncode(btcutil.Hash160(compressed), params.PubKeyHashAddrID), nil
+	case P2WPKH:
+		return encodeSegwit(params.Bech32HRPSegwit, 0, btcutil.Hash160(compressed))
+	case P2TR:
+		key, err := taprootOutputKey(pub)
+		if err != nil {
+			return "", err
+		}
+		return encodeSegwit(params.Bech32HRPSegwit, 1, key)
+	default:
+		return "", fmt.Errorf("unknown address type %s", p.Type)
+	}
+}
+
+// Validate checks addr is an address of the parser type on its network
+func (p BitcoinParser) Validate(addr string) error {
+	_, err := p.Decode(addr)
+	return err
+}
+
+// Decode returns the public key hash of P2PKH and P2WPKH addresses, and the x-only output key of
+// P2TR addresses
+func (p BitcoinParser) Decode(addr string) ([]byte, error) {
+	params := p.params()
+	switch p.Type {
+	case P2PKH:
+		hash, version, err := base58.CheckDecode(addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+		}
+		if version != params.PubKeyHashAddrID || len(hash) != 20 {
+			return nil, fmt.Errorf("%w: %s is not a %s P2PKH address", ErrInvalidAddress, addr, params.Name)
+		}
+		return hash, nil
+	case P2WPKH, P2TR:
+		version, program, err := decodeSegwit(params.Bech32HRPSegwit, addr)
+		if err != nil {
+			return nil, err
+		}
+		if p.Type == P2WPKH && (version != 0 || len(program) != 20) {
+			return nil, fmt.Errorf("%w: %s is not a P2WPKH address", ErrInvalidAddress, addr)
+		}
+		if p.Type == P2TR && (version != 1 || len(program) != 32) {
+			return nil, fmt.Errorf("%w: %s is not a P2TR address", ErrInvalidAddress, addr)
+		}
+		return program, nil
+	default:
+		return nil, fmt.Errorf("unknown address type %s", p.Type)
+	}
+}
+
+// taprootOutputKey tweaks a public key into the x-only output key of a BIP-86 key path only
+// taproot output, Q = lift_x(P) + H_TapTweak(P)·G
+func taprootOutputKey(pub curves.Point) ([]byte, error) {
+	internal, err := bip340.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	q, _, err := bip340.TweakPublicKey(internal, nil)
+	if err != nil {
+		return nil, err
+	}
+	return q.Bytes(), nil
+}
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// checksum constants of bech32 (BIP-173) and bech32m (BIP-350)
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups data of fromBits wide values into toBits wide values
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, bool) {
+	var acc, bits uint
+	maxv := uint(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		acc = acc<<fromBits | uint(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
+
+// encodeSegwit encodes a witness program, with bech32 for version 0 and bech32m above
+func encodeSegwit(hrp string, version byte, program []byte) (string, error) {
+	data, _ := convertBits(program, 8, 5, true)
+	data = append([]byte{version}, data...)
+	c := uint32(bech32Const)
+	if version > 0 {
+		c = bech32mConst
+	}
+	mod := bech32Polymod(append(append(bech32HRPExpand(hrp), data...), 0, 0, 0, 0, 0, 0)) ^ c
+
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, d := range data {
+		sb.WriteByte(bech32Charset[d])
+	}
+	for i := 0; i < 6; i++ {
+		sb.WriteByte(bech32Charset[(mod>>(5*(5-i)))&31])
+	}
+	return sb.String(), nil
+}
+
+// decodeSegwit decodes the witness program of a segwit address of hrp, checking the checksum
+// variant matches the witness version
+func decodeSegwit(hrp, addr string) (byte, []byte, error) {
+	if len(addr) > 90 || (strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr) {
+		return 0, nil, fmt.Errorf("%w: %s is not a bech32 string", ErrInvalidAddress, addr)
+	}
+	addr = strings.ToLower(addr)
+	pos := strings.LastIndexByte(addr, '1')
+	if pos < 1 || pos+7 > len(addr) || addr[:pos] != hrp {
+		return 0, nil, fmt.Errorf("%w: %s is not a %s1 address", ErrInvalidAddress, addr, hrp)
+	}
+	data := make([]byte, 0, len(addr)-pos-1)
+	for i := pos + 1; i < len(addr); i++ {
+		d := strings.IndexByte(bech32Charset, addr[i])
+		if d < 0 {
+			return 0, nil, fmt.Errorf("%w: invalid bech32 character %q", ErrInvalidAddress, addr[i])
+		}
+		data = append(data, byte(d))
+	}
+	if len(data) < 7 {
+		return 0, nil, fmt.Errorf("%w: %s has no witness program", ErrInvalidAddress, addr)
+	}
+	version := data[0]
+	c := uint32(bech32Const)
+	if version > 0 {
+		c = bech32mConst
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), data...)) != c {
+		return 0, nil, fmt.Errorf("%w: invalid checksum", ErrInvalidAddress)
+	}
+	program, ok := convertBits(data[1:len(data)-6], 5, 8, false)
+	if !ok || version > 16 || len(program) < 2 || len(program) > 40 {
+		return 0, nil, fmt.Errorf("%w: invalid witness program", ErrInvalidAddress)
+	}
+	return version, program, nil
+}
@@ -1,5 +1,14 @@
 package parsers
 
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
 type CosmosPrefix string
 
 const (
@@ -10,3 +19,47 @@ const (
 	SONRPrefix   CosmosPrefix = "idx"
 	STARSPrefix  CosmosPrefix = "stars"
 )
+
+// CosmosParser handles the bech32 account addresses of a cosmos chain
+type CosmosParser struct {
+	Prefix CosmosPrefix
+}
+
+var _ AddressParser = CosmosParser{}
+
+// Address derives the account address of a secp256k1 public key, the RIPEMD160 of the SHA256 of
+// its compressed encoding. Evmos accounts use ethereum keys and take the ethereum address.
+func (p CosmosParser) Address(pub curves.Point) (string, error) {
+	if err := requireCurve(pub, curves.K256Name); err != nil {
+		return "", err
+	}
+	var account []byte
+	if p.Prefix == EVMOSPrefix {
+		account = ethereumAddressBytes(pub)
+	} else {
+		account = btcutil.Hash160(pub.ToAffineCompressed())
+	}
+	return bech32.ConvertAndEncode(string(p.Prefix), account)
+}
+
+// Validate checks addr is an address of the parser prefix
+func (p CosmosParser) Validate(addr string) error {
+	_, err := p.Decode(addr)
+	return err
+}
+
+// Decode returns the account of an address, 20 bytes for accounts and 32 bytes for the accounts of
+// modules and interchain accounts
+func (p CosmosParser) Decode(addr string) ([]byte, error) {
+	hrp, account, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if hrp != string(p.Prefix) {
+		return nil, fmt.Errorf("%w: %s is not a %s address", ErrInvalidAddress, addr, p.Prefix)
+	}
+	if len(account) != 20 && len(account) != 32 {
+		return nil, fmt.Errorf("%w: %s has a %d byte account", ErrInvalidAddress, addr, len(account))
+	}
+	return account, nil
+}
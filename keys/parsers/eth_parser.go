@@ -1 +1,54 @@
 package parsers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// EthereumParser handles EIP-55 checksummed ethereum addresses
+type EthereumParser struct{}
+
+var _ AddressParser = EthereumParser{}
+
+// Address derives the checksummed address of a secp256k1 public key, the last 20 bytes of the
+// keccak256 hash of its uncompressed encoding
+func (EthereumParser) Address(pub curves.Point) (string, error) {
+	if err := requireCurve(pub, curves.K256Name); err != nil {
+		return "", err
+	}
+	return common.BytesToAddress(ethereumAddressBytes(pub)).Hex(), nil
+}
+
+// ethereumAddressBytes returns the 20 byte account of a secp256k1 public key
+func ethereumAddressBytes(pub curves.Point) []byte {
+	return ethcrypto.Keccak256(pub.ToAffineUncompressed()[1:])[12:]
+}
+
+// Validate checks addr is 0x prefixed hex of 20 bytes. Mixed case addresses must carry a valid
+// EIP-55 checksum, all lower or upper case addresses carry none.
+func (p EthereumParser) Validate(addr string) error {
+	_, err := p.Decode(addr)
+	return err
+}
+
+// Decode returns the 20 byte account of an address
+func (EthereumParser) Decode(addr string) ([]byte, error) {
+	digits, ok := strings.CutPrefix(addr, "0x")
+	if !ok || len(digits) != 2*common.AddressLength {
+		return nil, fmt.Errorf("%w: %s is not 0x prefixed hex of 20 bytes", ErrInvalidAddress, addr)
+	}
+	b, err := hex.DecodeString(digits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && common.BytesToAddress(b).Hex() != addr {
+		return nil, fmt.Errorf("%w: %s has an invalid EIP-55 checksum", ErrInvalidAddress, addr)
+	}
+	return b, nil
+}
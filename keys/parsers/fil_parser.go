@@ -1 +1,106 @@
 package parsers
+
+import (
+	"bytes"
+	"encoding/base32"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// Filecoin networks, the first character of addresses
+const (
+	FilecoinMainnet byte = 'f'
+	FilecoinTestnet byte = 't'
+)
+
+// Filecoin address protocols
+const (
+	filecoinSecp256k1 byte = 1 // f1, blake2b-160 of an uncompressed secp256k1 key
+	filecoinBLS       byte = 3 // f3, a BLS12-381 G1 public key
+)
+
+var filecoinEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// FilecoinParser handles the f1 addresses of secp256k1 keys and the f3 addresses of BLS keys
+type FilecoinParser struct {
+	// Network is FilecoinMainnet or FilecoinTestnet, mainnet when zero
+	Network byte
+}
+
+var _ AddressParser = FilecoinParser{}
+
+func (p FilecoinParser) network() byte {
+	if p.Network == 0 {
+		return FilecoinMainnet
+	}
+	return p.Network
+}
+
+// Address derives the f1 address of a secp256k1 key or the f3 address of a BLS12-381 G1 key
+func (p FilecoinParser) Address(pub curves.Point) (string, error) {
+	if err := requireCurve(pub, curves.K256Name, curves.BLS12381G1Name); err != nil {
+		return "", err
+	}
+	if pub.CurveName() == curves.BLS12381G1Name {
+		return p.encode(filecoinBLS, pub.ToAffineCompressed()), nil
+	}
+	return p.encode(filecoinSecp256k1, blake2bSum(pub.ToAffineUncompressed(), 20)), nil
+}
+
+func (p FilecoinParser) encode(protocol byte, payload []byte) string {
+	data := append(append([]byte{}, payload...), filecoinChecksum(protocol, payload)...)
+	return fmt.Sprintf("%c%d%s", p.network(), protocol, filecoinEncoding.EncodeToString(data))
+}
+
+// filecoinChecksum is the blake2b-32 hash of the protocol and payload of an address
+func filecoinChecksum(protocol byte, payload []byte) []byte {
+	return blake2bSum(append([]byte{protocol}, payload...), 4)
+}
+
+func blake2bSum(data []byte, size int) []byte {
+	h, _ := blake2b.New(size, nil)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Validate checks addr is an f1 or f3 address of the parser network
+func (p FilecoinParser) Validate(addr string) error {
+	_, err := p.Decode(addr)
+	return err
+}
+
+// Decode returns the public key hash of f1 addresses and the public key of f3 addresses
+func (p FilecoinParser) Decode(addr string) ([]byte, error) {
+	if len(addr) < 3 || addr[0] != p.network() {
+		return nil, fmt.Errorf("%w: %s is not a %c address", ErrInvalidAddress, addr, p.network())
+	}
+	var size int
+	protocol := addr[1] - '0'
+	switch protocol {
+	case filecoinSecp256k1:
+		size = 20
+	case filecoinBLS:
+		size = 48
+	default:
+		return nil, fmt.Errorf("%w: unsupported protocol %c", ErrInvalidAddress, addr[1])
+	}
+	if strings.ToLower(addr) != addr {
+		return nil, fmt.Errorf("%w: %s is not lower case", ErrInvalidAddress, addr)
+	}
+	data, err := filecoinEncoding.DecodeString(addr[2:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if len(data) != size+4 {
+		return nil, fmt.Errorf("%w: %s has a payload of %d bytes", ErrInvalidAddress, addr, len(data)-4)
+	}
+	payload := data[:size]
+	if !bytes.Equal(data[size:], filecoinChecksum(protocol, payload)) {
+		return nil, fmt.Errorf("%w: invalid checksum", ErrInvalidAddress)
+	}
+	return payload, nil
+}
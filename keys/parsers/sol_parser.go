@@ -1 +1,41 @@
 package parsers
+
+import (
+	"fmt"
+
+	"github.com/mr-tron/base58"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// SolanaParser handles solana addresses, the base58 encoding of an Ed25519 public key
+type SolanaParser struct{}
+
+var _ AddressParser = SolanaParser{}
+
+// Address returns the address of an Ed25519 public key
+func (SolanaParser) Address(pub curves.Point) (string, error) {
+	if err := requireCurve(pub, curves.ED25519Name); err != nil {
+		return "", err
+	}
+	return base58.Encode(pub.ToAffineCompressed()), nil
+}
+
+// Validate checks addr is the base58 encoding of 32 bytes. Program derived addresses are valid
+// addresses though they are not on the curve.
+func (p SolanaParser) Validate(addr string) error {
+	_, err := p.Decode(addr)
+	return err
+}
+
+// Decode returns the 32 bytes of an address
+func (SolanaParser) Decode(addr string) ([]byte, error) {
+	b, err := base58.Decode(addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if len(b) != 32 {
+		return nil, fmt.Errorf("%w: %s encodes %d bytes", ErrInvalidAddress, addr, len(b))
+	}
+	return b, nil
+}
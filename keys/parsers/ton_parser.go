@@ -1 +1,274 @@
 package parsers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// flags of user-friendly TON addresses
+const (
+	tonBounceable    byte = 0x11
+	tonNonBounceable byte = 0x51
+	tonTestOnly      byte = 0x80
+)
+
+// DefaultTonSubwalletID is the subwallet id of the first v3 or v4 wallet of a key on the basechain.
+// Wallets on other workchains add the workchain to it.
+const DefaultTonSubwalletID uint32 = 698983191
+
+// global ids of the TON networks, which v5 wallet ids are derived from
+const (
+	tonMainnetGlobalID int32 = -239
+	tonTestnetGlobalID int32 = -3
+)
+
+// TonWalletLayout is the layout of the data of a wallet contract
+type TonWalletLayout int
+
+const (
+	// TonLayoutV3 is the data of v3 wallets: seqno:uint32 subwallet_id:uint32 public_key:bits256
+	TonLayoutV3 TonWalletLayout = iota
+	// TonLayoutV4 appends the empty plugin dictionary of v4 wallets to the v3 data
+	TonLayoutV4
+	// TonLayoutV5 is the data of v5 wallets: is_signature_allowed:Bool seqno:uint32 wallet_id:uint32
+	// public_key:bits256 extensions:(HashmapE 256 int1)
+	TonLayoutV5
+)
+
+// TonWallet is the wallet contract TON addresses are derived for, the address of a key being the
+// hash of the initial state of its wallet. The code of the contract is given by the representation
+// hash and depth of its root cell.
+type TonWallet struct {
+	CodeHash  [32]byte
+	CodeDepth uint16
+	Layout    TonWalletLayout
+	// SubwalletID in the wallet data. When zero, v3 and v4 wallets use DefaultTonSubwalletID plus the
+	// workchain, and v5 wallets the wallet id of the first client wallet of the network and workchain.
+	SubwalletID uint32
+}
+
+// Wallet contracts of the standard wallets, whose code hashes and depths are those of the code
+// cells deployed by TON wallet apps
+var (
+	TonWalletV3R2 = TonWallet{CodeHash: tonCodeHash("84dafa449f98a6987789ba232358072bc0f76dc4524002a5d0918b9a75d2d599"), CodeDepth: 0, Layout: TonLayoutV3}
+	TonWalletV4R2 = TonWallet{CodeHash: tonCodeHash("feb5ff6820e2ff0d9483e7e0d62c817d846789fb4ae580c878866d959dabd5c0"), CodeDepth: 7, Layout: TonLayoutV4}
+	TonWalletV5R1 = TonWallet{CodeHash: tonCodeHash("20834b7b72b112147e1b2fb457b84e74d1a30f04f737d4f62a668e9552d2b72f"), CodeDepth: 6, Layout: TonLayoutV5}
+)
+
+func tonCodeHash(s string) [32]byte {
+	var h [32]byte
+	if n, err := hex.Decode(h[:], []byte(s)); err != nil || n != len(h) {
+		panic("invalid TON code hash " + s)
+	}
+	return h
+}
+
+// TonAddress is a TON account address
+type TonAddress struct {
+	Workchain  int8
+	Account    [32]byte
+	Bounceable bool
+	Testnet    bool
+}
+
+// String returns the user-friendly form of the address, the url-safe base64 encoding of its
+// flags, workchain, account and CRC16 checksum
+func (a TonAddress) String() string {
+	tag := tonNonBounceable
+	if a.Bounceable {
+		tag = tonBounceable
+	}
+	if a.Testnet {
+		tag |= tonTestOnly
+	}
+	data := make([]byte, 0, 36)
+	data = append(data, tag, byte(a.Workchain))
+	data = append(data, a.Account[:]...)
+	data = binary.BigEndian.AppendUint16(data, crc16XModem(data))
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// Raw returns the raw form of the address, <workchain>:<account hex>
+func (a TonAddress) Raw() string {
+	return fmt.Sprintf("%d:%s", a.Workchain, hex.EncodeToString(a.Account[:]))
+}
+
+// ParseTonAddress parses the user-friendly, in standard or url-safe base64, or the raw form of an
+// address. Raw addresses carry no flags and are parsed as bounceable mainnet addresses.
+func ParseTonAddress(s string) (TonAddress, error) {
+	if wc, account, ok := strings.Cut(s, ":"); ok {
+		w, err := strconv.ParseInt(wc, 10, 8)
+		if err != nil {
+			return TonAddress{}, fmt.Errorf("%w: workchain: %w", ErrInvalidAddress, err)
+		}
+		b, err := hex.DecodeString(account)
+		if err != nil || len(b) != 32 {
+			return TonAddress{}, fmt.Errorf("%w: %s has no 32 byte account", ErrInvalidAddress, s)
+		}
+		addr := TonAddress{Workchain: int8(w), Bounceable: true}
+		copy(addr.Account[:], b)
+		return addr, nil
+	}
+
+	if len(s) != 48 {
+		return TonAddress{}, fmt.Errorf("%w: %s is not a 48 character address", ErrInvalidAddress, s)
+	}
+	data, err := base64.URLEncoding.DecodeString(strings.NewReplacer("+", "-", "/", "_").Replace(s))
+	if err != nil {
+		return TonAddress{}, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if crc16XModem(data[:34]) != binary.BigEndian.Uint16(data[34:]) {
+		return TonAddress{}, fmt.Errorf("%w: invalid checksum", ErrInvalidAddress)
+	}
+	addr := TonAddress{Workchain: int8(data[1]), Testnet: data[0]&tonTestOnly != 0}
+	switch data[0] &^ tonTestOnly {
+	case tonBounceable:
+		addr.Bounceable = true
+	case tonNonBounceable:
+	default:
+		return TonAddress{}, fmt.Errorf("%w: invalid flags 0x%x", ErrInvalidAddress, data[0])
+	}
+	copy(addr.Account[:], data[2:34])
+	return addr, nil
+}
+
+// crc16XModem is the CRC-16/XMODEM checksum of user-friendly addresses
+func crc16XModem(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// TonParser handles the user-friendly addresses of the wallets of Ed25519 keys. The zero parser
+// derives the non-bounceable mainnet addresses of TonWalletV4R2 wallets on the basechain.
+type TonParser struct {
+	// Wallet the addresses are derived for, TonWalletV4R2 when zero
+	Wallet     TonWallet
+	Workchain  int8
+	Bounceable bool
+	Testnet    bool
+}
+
+var _ AddressParser = TonParser{}
+
+// Address derives the address of the wallet of an Ed25519 public key
+func (p TonParser) Address(pub curves.Point) (string, error) {
+	if err := requireCurve(pub, curves.ED25519Name); err != nil {
+		return "", err
+	}
+	wallet := p.Wallet
+	if wallet == (TonWallet{}) {
+		wallet = TonWalletV4R2
+	}
+	addr := TonAddress{Workchain: p.Workchain, Bounceable: p.Bounceable, Testnet: p.Testnet}
+	copy(addr.Account[:], wallet.stateInitHash(pub.ToAffineCompressed(), p.Workchain, p.Testnet))
+	return addr.String(), nil
+}
+
+// walletID returns the id of the wallet in its data on a network and workchain
+func (w TonWallet) walletID(workchain int8, testnet bool) uint32 {
+	if w.SubwalletID != 0 {
+		return w.SubwalletID
+	}
+	if w.Layout != TonLayoutV5 {
+		return DefaultTonSubwalletID + uint32(int32(workchain))
+	}
+	// the wallet id of v5 wallets is the network global id masked with the context of the wallet:
+	// client:1 workchain:int8 version:uint8 subwallet_number:uint15, which is 1, workchain, 0, 0
+	network := tonMainnetGlobalID
+	if testnet {
+		network = tonTestnetGlobalID
+	}
+	return uint32(network) ^ (1<<31 | uint32(uint8(workchain))<<23)
+}
+
+// stateInitHash returns the representation hash of the initial state of the wallet of a key, the
+// StateInit cell holding the wallet code and data, laid out as given by the wallet layout
+func (w TonWallet) stateInitHash(pub []byte, workchain int8, testnet bool) []byte {
+	data := make([]byte, 4, 42)
+	data = binary.BigEndian.AppendUint32(data, w.walletID(workchain, testnet))
+	data = append(data, pub...)
+	bits := 8 * len(data)
+	switch w.Layout {
+	case TonLayoutV4:
+		// no plugins
+		data = append(data, 0)
+		bits++
+	case TonLayoutV5:
+		// a leading 1 bit allows signatures, and a trailing 0 bit leaves out extensions
+		shifted := make([]byte, len(data)+1)
+		shifted[0] = 0x80
+		for i, b := range data {
+			shifted[i] |= b >> 1
+			shifted[i+1] = b << 7
+		}
+		data = shifted
+		bits += 2
+	}
+	dataHash := tonCellHash(data, bits)
+
+	// split_depth:(Maybe) special:(Maybe) code:(Maybe ^Cell) data:(Maybe ^Cell) library:(HashmapE)
+	return tonCellHash([]byte{0b00110000}, 5,
+		tonCellRef{hash: w.CodeHash[:], depth: w.CodeDepth},
+		tonCellRef{hash: dataHash, depth: 0},
+	)
+}
+
+type tonCellRef struct {
+	hash  []byte
+	depth uint16
+}
+
+// tonCellHash returns the representation hash of an ordinary cell of the first bits of data
+func tonCellHash(data []byte, bits int, refs ...tonCellRef) []byte {
+	h := sha256.New()
+	h.Write([]byte{byte(len(refs)), byte(bits/8 + (bits+7)/8)})
+	padded := append([]byte{}, data[:(bits+7)/8]...)
+	if bits%8 != 0 {
+		// incomplete bytes are completed with a 1 bit followed by zeros
+		last := len(padded) - 1
+		padded[last] = padded[last]&(0xff<<(8-bits%8)) | 1<<(7-bits%8)
+	}
+	h.Write(padded)
+	for _, r := range refs {
+		h.Write(binary.BigEndian.AppendUint16(nil, r.depth))
+	}
+	for _, r := range refs {
+		h.Write(r.hash)
+	}
+	return h.Sum(nil)
+}
+
+// Validate checks addr is a TON address of the parser network
+func (p TonParser) Validate(addr string) error {
+	_, err := p.Decode(addr)
+	return err
+}
+
+// Decode returns the 32 byte account of an address
+func (p TonParser) Decode(addr string) ([]byte, error) {
+	a, err := ParseTonAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	if a.Testnet && !p.Testnet {
+		return nil, fmt.Errorf("%w: %s is a testnet address", ErrInvalidAddress, addr)
+	}
+	return a.Account[:], nil
+}
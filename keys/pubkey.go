@@ -3,12 +3,34 @@ package keys
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
 
 	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	p2ppb "github.com/libp2p/go-libp2p/core/crypto/pb"
-	"github.com/sonr-io/crypto/core/curves"
 	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/signatures/bls/bls_sig"
+	"github.com/sonr-io/crypto/signatures/schnorr/bip340"
+)
+
+// ErrUnsupportedCurve is returned for public keys of a curve no signature scheme is implemented for
+var ErrUnsupportedCurve = errors.New("unsupported public key curve")
+
+// Key types of the public keys libp2p has no key type for, numbered clear of the libp2p ones
+const (
+	// KeyTypeSchnorr is a BIP-340 x-only secp256k1 key
+	KeyTypeSchnorr p2ppb.KeyType = iota + 0x100
+	// KeyTypeBLS12381G1 is a BLS12-381 key in G1, for signatures in G2
+	KeyTypeBLS12381G1
+	// KeyTypeBLS12381G2 is a BLS12-381 key in G2, for signatures in G1
+	KeyTypeBLS12381G2
 )
 
 type PubKey interface {
@@ -22,25 +44,60 @@ type PubKey interface {
 
 type pubKey struct {
 	publicPoint curves.Point
-	method      string
+	schnorr     bool
 }
 
+// NewPubKey returns the public key of a curve point, verifying the signature scheme of its curve:
+//   - ECDSA over secp256k1 and P-256, of the SHA3-256 digest of the message. Signatures are 64 byte
+//     r || s, or 66 byte V || R || S.
+//   - Ed25519 over curves.ED25519
+//   - BLS12-381 with proofs of possession, keys in G1 verifying signatures in G2 and keys in G2
+//     signatures in G1
 func NewPubKey(pk curves.Point) PubKey {
 	return &pubKey{
 		publicPoint: pk,
 	}
 }
 
+// NewSchnorrPubKey returns the BIP-340 public key of a secp256k1 point, verifying Schnorr
+// signatures of the SHA-256 digest of the message. The point is replaced by the one with an even Y
+// coordinate and the same x-only encoding.
+func NewSchnorrPubKey(pk curves.Point) (PubKey, error) {
+	if pk.CurveName() != curves.K256Name {
+		return nil, fmt.Errorf("%w: %s schnorr key", ErrUnsupportedCurve, pk.CurveName())
+	}
+	if pk.IsIdentity() {
+		return nil, errors.New("the identity is not a public key")
+	}
+	if pk.ToAffineCompressed()[0] == 0x03 {
+		pk = pk.Neg()
+	}
+	return &pubKey{publicPoint: pk, schnorr: true}, nil
+}
+
+// Bytes returns the compressed point of the key, or its x-only encoding for Schnorr keys
 func (p pubKey) Bytes() []byte {
+	if p.schnorr {
+		return p.publicPoint.ToAffineCompressed()[1:]
+	}
 	return p.publicPoint.ToAffineCompressed()
 }
 
+// Raw returns the key in the raw encoding of its libp2p key type: compressed secp256k1 points, PKIX
+// NIST points, and the point encodings of the other curves
 func (p pubKey) Raw() ([]byte, error) {
-	return p.publicPoint.ToAffineCompressed(), nil
+	if p.Type() != p2ppb.KeyType_ECDSA {
+		return p.Bytes(), nil
+	}
+	pub, err := p.ecdsaKey()
+	if err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKIXPublicKey(pub)
 }
 
 func (p pubKey) Equals(b p2pcrypto.Key) bool {
-	if b == nil {
+	if b == nil || b.Type() != p.Type() {
 		return false
 	}
 	apbz, err := b.Raw()
@@ -55,27 +112,64 @@ func (p pubKey) Equals(b p2pcrypto.Key) bool {
 }
 
 func (p pubKey) Hex() string {
-	return hex.EncodeToString(p.publicPoint.ToAffineCompressed())
+	return hex.EncodeToString(p.Bytes())
 }
 
+// Type returns the libp2p key type of the key, or one of the KeyType constants for Schnorr and BLS
+// keys. Keys of other curves have the type -1.
 func (p pubKey) Type() p2ppb.KeyType {
-	return p2ppb.KeyType_Secp256k1
+	if p.schnorr {
+		return KeyTypeSchnorr
+	}
+	switch p.publicPoint.CurveName() {
+	case curves.K256Name:
+		return p2ppb.KeyType_Secp256k1
+	case curves.P256Name:
+		return p2ppb.KeyType_ECDSA
+	case curves.ED25519Name:
+		return p2ppb.KeyType_Ed25519
+	case curves.BLS12381G1Name:
+		return KeyTypeBLS12381G1
+	case curves.BLS12381G2Name:
+		return KeyTypeBLS12381G2
+	default:
+		return -1
+	}
 }
 
 func (p pubKey) Verify(data []byte, sigBz []byte) (bool, error) {
-	sig, err := deserializeSignature(sigBz)
-	if err != nil {
+	switch p.Type() {
+	case p2ppb.KeyType_Secp256k1, p2ppb.KeyType_ECDSA:
+		return p.verifyECDSA(data, sigBz)
+	case p2ppb.KeyType_Ed25519:
+		if len(sigBz) != ed25519.SignatureSize {
+			return false, fmt.Errorf("malformed signature: Ed25519 signature of %d bytes", len(sigBz))
+		}
+		return ed25519.Verify(p.Bytes(), data, sigBz), nil
+	case KeyTypeSchnorr:
+		return p.verifySchnorr(data, sigBz)
+	case KeyTypeBLS12381G1, KeyTypeBLS12381G2:
+		return p.verifyBLS(data, sigBz)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedCurve, p.publicPoint.CurveName())
+	}
+}
+
+func (p pubKey) verifyECDSA(data []byte, sigBz []byte) (bool, error) {
+	var sig *curves.EcdsaSignature
+	var err error
+	if len(sigBz) == 64 {
+		sig = &curves.EcdsaSignature{
+			R: new(big.Int).SetBytes(sigBz[:32]),
+			S: new(big.Int).SetBytes(sigBz[32:]),
+		}
+	} else if sig, err = deserializeSignature(sigBz); err != nil {
 		return false, err
 	}
-	pp, err := getEcdsaPoint(p.Bytes())
+	pk, err := p.ecdsaKey()
 	if err != nil {
 		return false, err
 	}
-	pk := &ecdsa.PublicKey{
-		Curve: pp.Curve,
-		X:     pp.X,
-		Y:     pp.Y,
-	}
 
 	// Hash the message using SHA3-256
 	hash := sha3.New256()
@@ -84,3 +178,52 @@ func (p pubKey) Verify(data []byte, sigBz []byte) (bool, error) {
 
 	return ecdsa.Verify(pk, digest, sig.R, sig.S), nil
 }
+
+func (p pubKey) verifySchnorr(data []byte, sigBz []byte) (bool, error) {
+	var sig bip340.Signature
+	if err := sig.UnmarshalBinary(sigBz); err != nil {
+		return false, fmt.Errorf("malformed signature: %w", err)
+	}
+	pk, err := bip340.NewPublicKey(p.publicPoint)
+	if err != nil {
+		return false, err
+	}
+	digest := sha256.Sum256(data)
+	return bip340.Verify(pk, digest[:], &sig), nil
+}
+
+func (p pubKey) verifyBLS(data []byte, sigBz []byte) (bool, error) {
+	if p.Type() == KeyTypeBLS12381G1 {
+		pk := new(bls_sig.PublicKey)
+		if err := pk.UnmarshalBinary(p.Bytes()); err != nil {
+			return false, err
+		}
+		sig := new(bls_sig.Signature)
+		if err := sig.UnmarshalBinary(sigBz); err != nil {
+			return false, fmt.Errorf("malformed signature: %w", err)
+		}
+		return bls_sig.NewSigPop().Verify(pk, data, sig)
+	}
+	pk := new(bls_sig.PublicKeyVt)
+	if err := pk.UnmarshalBinary(p.Bytes()); err != nil {
+		return false, err
+	}
+	sig := new(bls_sig.SignatureVt)
+	if err := sig.UnmarshalBinary(sigBz); err != nil {
+		return false, fmt.Errorf("malformed signature: %w", err)
+	}
+	return bls_sig.NewSigPopVt().Verify(pk, data, sig)
+}
+
+// ecdsaKey returns the standard library key of a secp256k1 or P-256 point
+func (p pubKey) ecdsaKey() (*ecdsa.PublicKey, error) {
+	pp, err := getEcdsaPoint(p.publicPoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{
+		Curve: pp.Curve,
+		X:     pp.X,
+		Y:     pp.Y,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	p2ppb "github.com/libp2p/go-libp2p/core/crypto/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/didfmt"
+	"github.com/sonr-io/crypto/signatures/bls/bls_sig"
+)
+
+var msg = []byte("sonr public key verification")
+
+// ecdsaKey signs msg over the SHA3-256 digest with a random key of curve
+func ecdsaKey(t *testing.T, curve *curves.Curve) (PubKey, []byte) {
+	ec, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	priv, err := ecdsa.GenerateKey(ec, rand.Reader)
+	require.NoError(t, err)
+	digest := sha3.Sum256(msg)
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	uncompressed := append([]byte{0x04}, priv.X.FillBytes(make([]byte, 32))...)
+	uncompressed = append(uncompressed, priv.Y.FillBytes(make([]byte, 32))...)
+	pt, err := curve.Point.FromAffineUncompressed(uncompressed)
+	require.NoError(t, err)
+	return NewPubKey(pt), append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+}
+
+func TestPubKeyVerify(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edPoint, err := curves.ED25519().Point.FromAffineCompressed(edPub)
+	require.NoError(t, err)
+
+	schnorrPriv, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	digest := sha256.Sum256(msg)
+	schnorrSig, err := schnorr.Sign(schnorrPriv, digest[:])
+	require.NoError(t, err)
+	schnorrPoint, err := curves.K256().Point.FromAffineCompressed(schnorrPriv.PubKey().SerializeCompressed())
+	require.NoError(t, err)
+	schnorrPub, err := NewSchnorrPubKey(schnorrPoint.Neg())
+	require.NoError(t, err)
+
+	g1Pub, g1Priv, err := bls_sig.NewSigPop().Keygen()
+	require.NoError(t, err)
+	g1Sig, err := bls_sig.NewSigPop().Sign(g1Priv, msg)
+	require.NoError(t, err)
+	g1Bytes, err := g1Pub.MarshalBinary()
+	require.NoError(t, err)
+	g1Point, err := curves.BLS12381G1().Point.FromAffineCompressed(g1Bytes)
+	require.NoError(t, err)
+	g1SigBytes, err := g1Sig.MarshalBinary()
+	require.NoError(t, err)
+
+	g2Pub, g2Priv, err := bls_sig.NewSigPopVt().Keygen()
+	require.NoError(t, err)
+	g2Sig, err := bls_sig.NewSigPopVt().Sign(g2Priv, msg)
+	require.NoError(t, err)
+	g2Bytes, err := g2Pub.MarshalBinary()
+	require.NoError(t, err)
+	g2Point, err := curves.BLS12381G2().Point.FromAffineCompressed(g2Bytes)
+	require.NoError(t, err)
+	g2SigBytes, err := g2Sig.MarshalBinary()
+	require.NoError(t, err)
+
+	k256Pub, k256Sig := ecdsaKey(t, curves.K256())
+	p256Pub, p256Sig := ecdsaKey(t, curves.P256())
+
+	tests := []struct {
+		name    string
+		pub     PubKey
+		sig     []byte
+		keyType p2ppb.KeyType
+		rawSize int
+	}{
+		{"secp256k1", k256Pub, k256Sig, p2ppb.KeyType_Secp256k1, 33},
+		{"p256", p256Pub, p256Sig, p2ppb.KeyType_ECDSA, 91},
+		{"ed25519", NewPubKey(edPoint), ed25519.Sign(edPriv, msg), p2ppb.KeyType_Ed25519, 32},
+		{"schnorr", schnorrPub, schnorrSig.Serialize(), KeyTypeSchnorr, 32},
+		{"bls12381 g1", NewPubKey(g1Point), g1SigBytes, KeyTypeBLS12381G1, 48},
+		{"bls12381 g2", NewPubKey(g2Point), g2SigBytes, KeyTypeBLS12381G2, 96},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.keyType, tt.pub.Type())
+			raw, err := tt.pub.Raw()
+			require.NoError(t, err)
+			assert.Len(t, raw, tt.rawSize)
+
+			ok, err := tt.pub.Verify(msg, tt.sig)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			ok, _ = tt.pub.Verify([]byte("another message"), tt.sig)
+			assert.False(t, ok)
+		})
+	}
+
+	assert.Equal(t, schnorrPriv.PubKey().SerializeCompressed()[1:], schnorrPub.Bytes())
+	_, err = NewSchnorrPubKey(edPoint)
+	assert.ErrorIs(t, err, ErrUnsupportedCurve)
+	_, err = NewPubKey(curves.PALLAS().Point.Generator()).Verify(msg, k256Sig)
+	assert.ErrorIs(t, err, ErrUnsupportedCurve)
+}
+
+func TestPubKeyLibp2p(t *testing.T) {
+	tests := []struct {
+		name     string
+		generate func() (p2pcrypto.PrivKey, didfmt.DID, error)
+		curve    *curves.Curve
+	}{
+		{"secp256k1", didfmt.GenerateSecp256k1, curves.K256()},
+		{"p256", didfmt.GenerateECDSA, curves.P256()},
+		{"ed25519", didfmt.GenerateEd25519, curves.ED25519()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, d, err := tt.generate()
+			require.NoError(t, err)
+			pt, err := d.Point()
+			require.NoError(t, err)
+			pub := NewPubKey(pt)
+			libp2pPub, err := d.PubKey()
+			require.NoError(t, err)
+			assert.True(t, pub.Equals(libp2pPub))
+
+			id, err := NewDID(pub)
+			require.NoError(t, err)
+			assert.Equal(t, d.String(), id.String())
+		})
+	}
+}
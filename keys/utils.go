@@ -1,6 +1,7 @@
 package keys
 
 import (
+	"crypto/elliptic"
 	"errors"
 	"fmt"
 	"math/big"
@@ -8,15 +9,21 @@ import (
 	"github.com/sonr-io/crypto/core/curves"
 )
 
-// getEcdsaPoint builds an elliptic curve point from a compressed byte slice
-func getEcdsaPoint(pubKey []byte) (*curves.EcPoint, error) {
-	crv := curves.K256()
-	x := new(big.Int).SetBytes(pubKey[1:33])
-	y := new(big.Int).SetBytes(pubKey[33:])
-	ecCurve, err := crv.ToEllipticCurve()
-	if err != nil {
-		return nil, fmt.Errorf("error converting curve: %v", err)
+// getEcdsaPoint returns the elliptic curve point of a secp256k1 or P-256 point
+func getEcdsaPoint(pubKey curves.Point) (*curves.EcPoint, error) {
+	var ecCurve elliptic.Curve
+	switch pubKey.CurveName() {
+	case curves.K256Name:
+		ecCurve = curves.K256Curve()
+	case curves.P256Name:
+		// the standard library curve, which x509 can marshal
+		ecCurve = elliptic.P256()
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, pubKey.CurveName())
 	}
+	uncompressed := pubKey.ToAffineUncompressed()
+	x := new(big.Int).SetBytes(uncompressed[1:33])
+	y := new(big.Int).SetBytes(uncompressed[33:])
 	return &curves.EcPoint{X: x, Y: y, Curve: ecCurve}, nil
 }
 
@@ -2,6 +2,8 @@ package mpc
 
 import (
 	"crypto/rand"
+	"errors"
+	"fmt"
 
 	"github.com/sonr-io/crypto/core/curves"
 	"github.com/sonr-io/crypto/core/protocol"
@@ -27,6 +29,7 @@ func (c CurveName) String() string {
 	return string(c)
 }
 
+// Curve returns the curve with the given name, or nil if the name is unknown.
 func (c CurveName) Curve() *curves.Curve {
 	switch c {
 	case K256Name:
@@ -50,10 +53,24 @@ func (c CurveName) Curve() *curves.Curve {
 	case BLS12377Name:
 		return curves.BLS12377G1()
 	default:
-		return curves.K256()
+		return nil
 	}
 }
 
+// ECDSACurve returns the curve if the enclave can run DKLs ECDSA over it.
+// Only secp256k1 and P-256 are supported; any other name is rejected.
+func (c CurveName) ECDSACurve() (*curves.Curve, error) {
+	switch c {
+	case K256Name, P256Name:
+		return c.Curve(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCurve, string(c))
+	}
+}
+
+// ErrUnsupportedCurve is returned when an enclave is asked to use a curve DKLs cannot sign over.
+var ErrUnsupportedCurve = errors.New("unsupported enclave curve")
+
 // ╭───────────────────────────────────────────────────────────╮
 // │                    Exported Generics                      │
 // ╰───────────────────────────────────────────────────────────╯
@@ -82,16 +99,17 @@ func randNonce() []byte {
 
 // Enclave defines the interface for key management operations
 type Enclave interface {
-	GetData() *EnclaveData                                    // GetData returns the data of the keyEnclave
-	GetEnclave() Enclave                                      // GetEnclave returns the enclave of the keyEnclave
-	Decrypt(key []byte, encryptedData []byte) ([]byte, error) // Decrypt returns decrypted enclave data
-	Encrypt(key []byte) ([]byte, error)                       // Encrypt returns encrypted enclave data
-	IsValid() bool                                            // IsValid returns true if the keyEnclave is valid
-	PubKeyBytes() []byte                                      // PubKeyBytes returns the public key of the keyEnclave
-	PubKeyHex() string                                        // PubKeyHex returns the public key of the keyEnclave
-	Refresh() (Enclave, error)                                // Refresh returns a new keyEnclave
-	Marshal() ([]byte, error)                                 // Serialize returns the serialized keyEnclave
-	Sign(data []byte) ([]byte, error)                         // Sign returns the signature of the data
-	Unmarshal(data []byte) error                              // Verify returns true if the signature is valid
-	Verify(data []byte, sig []byte) (bool, error)             // Verify returns true if the signature is valid
+	GetData() *EnclaveData                                            // GetData returns the data of the keyEnclave
+	GetEnclave() Enclave                                              // GetEnclave returns the enclave of the keyEnclave
+	Decrypt(key []byte, encryptedData []byte) ([]byte, error)         // Decrypt returns decrypted enclave data
+	Encrypt(key []byte) ([]byte, error)                               // Encrypt returns encrypted enclave data
+	IsValid() bool                                                    // IsValid returns true if the keyEnclave is valid
+	PubKeyBytes() []byte                                              // PubKeyBytes returns the public key of the keyEnclave
+	PubKeyHex() string                                                // PubKeyHex returns the public key of the keyEnclave
+	Refresh() (Enclave, error)                                        // Refresh returns a new keyEnclave
+	Marshal() ([]byte, error)                                         // Serialize returns the serialized keyEnclave
+	Sign(data []byte, opts ...SignOption) ([]byte, error)             // Sign returns the signature of the data
+	SignBatch(data [][]byte, opts ...SignOption) ([][]byte, error)    // SignBatch returns the signatures of every item of data
+	Unmarshal(data []byte) error                                      // Verify returns true if the signature is valid
+	Verify(data []byte, sig []byte, opts ...SignOption) (bool, error) // Verify returns true if the signature is valid
 }
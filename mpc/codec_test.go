@@ -10,7 +10,7 @@ import (
 func TestKeyShareGeneration(t *testing.T) {
 	t.Run("Generate Valid Enclave", func(t *testing.T) {
 		// Generate enclave
-		enclave, err := NewEnclave()
+		enclave, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 		require.NotNil(t, enclave)
 
@@ -20,7 +20,7 @@ func TestKeyShareGeneration(t *testing.T) {
 
 	t.Run("Export and Import", func(t *testing.T) {
 		// Generate original enclave
-		original, err := NewEnclave()
+		original, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 
 		// Test key for encryption/decryption (32 bytes)
@@ -34,7 +34,7 @@ func TestKeyShareGeneration(t *testing.T) {
 			require.NotEmpty(t, data)
 
 			// Create new empty enclave
-			newEnclave, err := NewEnclave()
+			newEnclave, err := NewEnclave(K256Name)
 			require.NoError(t, err)
 
 			// Verify the imported enclave works by signing
@@ -49,7 +49,7 @@ func TestKeyShareGeneration(t *testing.T) {
 
 	t.Run("Encrypt and Decrypt", func(t *testing.T) {
 		// Generate enclave
-		enclave, err := NewEnclave()
+		enclave, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 
 		// Get the enclave data
@@ -71,6 +71,7 @@ func TestKeyShareGeneration(t *testing.T) {
 
 		// Verify decrypted data can be used to restore the enclave
 		restoredEnclave := &EnclaveData{}
+		err = restoredEnclave.Unmarshal(decrypted)
 		require.NoError(t, err)
 
 		// Ensure restored enclave is valid
@@ -86,7 +87,7 @@ func TestKeyShareGeneration(t *testing.T) {
 func TestEnclaveOperations(t *testing.T) {
 	t.Run("Signing and Verification", func(t *testing.T) {
 		// Generate valid enclave
-		enclave, err := NewEnclave()
+		enclave, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 
 		// Test signing
@@ -108,7 +109,7 @@ func TestEnclaveOperations(t *testing.T) {
 	})
 
 	t.Run("Refresh Operation", func(t *testing.T) {
-		enclave, err := NewEnclave()
+		enclave, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 
 		// Test refresh
@@ -124,7 +125,7 @@ func TestEnclaveOperations(t *testing.T) {
 func TestEnclaveDataAccess(t *testing.T) {
 	t.Run("GetData", func(t *testing.T) {
 		// Generate enclave
-		enclave, err := NewEnclave()
+		enclave, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 		require.NotNil(t, enclave)
 
@@ -141,7 +142,7 @@ func TestEnclaveDataAccess(t *testing.T) {
 
 	t.Run("PubKeyHex", func(t *testing.T) {
 		// Generate enclave
-		enclave, err := NewEnclave()
+		enclave, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 		require.NotNil(t, enclave)
 
@@ -158,7 +159,7 @@ func TestEnclaveDataAccess(t *testing.T) {
 		assert.Equal(t, data.PubKeyHex(), pubKeyHex, "Public key hex should match the one from GetData")
 
 		// Verify that two different enclaves have different public keys
-		enclave2, err := NewEnclave()
+		enclave2, err := NewEnclave(K256Name)
 		require.NoError(t, err)
 		require.NotNil(t, enclave2)
 
@@ -0,0 +1,295 @@
+package mpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/sonr-io/crypto/bip32"
+	"github.com/sonr-io/crypto/core/curves"
+	"golang.org/x/crypto/ripemd160"
+)
+
+var (
+	// ErrHardenedDerivation is returned for hardened path components. Hardened children are derived from the
+	// joint private key, which neither party of an enclave ever holds.
+	ErrHardenedDerivation = errors.New("hardened derivation is not possible on a joint key")
+
+	// ErrNoChainCode is returned when deriving from a keyshare created before DKG produced a chain code.
+	ErrNoChainCode = errors.New("keyshare has no chain code")
+)
+
+// DerivationPath is a list of BIP32 child indices, relative to the joint key of an enclave.
+type DerivationPath []uint32
+
+// ParseDerivationPath parses a path such as "m/0/5". Hardened components ("0'" or "0h") are parsed but
+// cannot be derived; the joint key takes the place of the last hardened level, e.g. the account key of
+// m/44'/60'/0', and paths are relative to it.
+func ParseDerivationPath(path string) (DerivationPath, error) {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "m" {
+		return DerivationPath{}, nil
+	}
+	path = strings.TrimPrefix(path, "m/")
+	components := strings.Split(path, "/")
+	out := make(DerivationPath, 0, len(components))
+	for _, c := range components {
+		hardened := strings.HasSuffix(c, "'") || strings.HasSuffix(c, "h")
+		if hardened {
+			c = c[:len(c)-1]
+		}
+		idx, err := strconv.ParseUint(c, 10, 32)
+		if err != nil || idx >= uint64(bip32.FirstHardenedChild) {
+			return nil, fmt.Errorf("invalid derivation path component %q", c)
+		}
+		if hardened {
+			idx += uint64(bip32.FirstHardenedChild)
+		}
+		out = append(out, uint32(idx))
+	}
+	return out, nil
+}
+
+// String returns the path in "m/0/5" notation.
+func (p DerivationPath) String() string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, idx := range p {
+		sb.WriteString("/")
+		if idx >= bip32.FirstHardenedChild {
+			sb.WriteString(strconv.FormatUint(uint64(idx-bip32.FirstHardenedChild), 10) + "'")
+			continue
+		}
+		sb.WriteString(strconv.FormatUint(uint64(idx), 10))
+	}
+	return sb.String()
+}
+
+// ChildKey is a non-hardened descendant of the joint key of an enclave. Signing for it only requires
+// both parties to fold the same public tweak into the DKLs signing protocol; the keyshares are unchanged.
+type ChildKey struct {
+	Curve             CurveName
+	Path              DerivationPath
+	PubKey            Point
+	ChainCode         []byte
+	ParentFingerprint []byte // ParentFingerprint is the first 4 bytes of HASH160 of the parent public key
+
+	tweak curves.Scalar // tweak is the sum of the intermediate keys, child sk = joint sk + tweak
+}
+
+// DeriveChildKey derives the child of a joint public key along path with BIP32 CKDpub.
+func DeriveChildKey(curveName CurveName, pubKey []byte, chainCode []byte, path DerivationPath) (*ChildKey, error) {
+	curve, err := curveName.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	if len(chainCode) == 0 {
+		return nil, ErrNoChainCode
+	}
+	ec, err := GetCurveECDSAPoint(curveName, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	point, err := curve.NewIdentityPoint().Set(ec.X, ec.Y)
+	if err != nil {
+		return nil, err
+	}
+	order := ec.Curve.Params().N
+
+	child := &ChildKey{
+		Curve:             curveName,
+		Path:              path,
+		PubKey:            point,
+		ChainCode:         chainCode,
+		ParentFingerprint: []byte{0, 0, 0, 0},
+		tweak:             curve.Scalar.Zero(),
+	}
+	for _, idx := range path {
+		if idx >= bip32.FirstHardenedChild {
+			return nil, fmt.Errorf("%w: %s", ErrHardenedDerivation, path)
+		}
+		parent := child.PubKey.ToAffineCompressed()
+		mac := hmac.New(sha512.New, child.ChainCode)
+		mac.Write(parent)
+		mac.Write(binary.BigEndian.AppendUint32(nil, idx))
+		intermediary := mac.Sum(nil)
+
+		il := new(big.Int).SetBytes(intermediary[:32])
+		if il.Cmp(order) >= 0 {
+			return nil, fmt.Errorf("invalid child key at index %d, use the next index", idx)
+		}
+		ilScalar, err := curve.Scalar.SetBigInt(il)
+		if err != nil {
+			return nil, err
+		}
+		next := child.PubKey.Add(curve.ScalarBaseMult(ilScalar))
+		if next.IsIdentity() {
+			return nil, fmt.Errorf("invalid child key at index %d, use the next index", idx)
+		}
+		child.PubKey = next
+		child.ChainCode = intermediary[32:]
+		child.ParentFingerprint = fingerprint(parent)
+		child.tweak = child.tweak.Add(ilScalar)
+	}
+	return child, nil
+}
+
+// PubKeyBytes returns the uncompressed child public key
+func (c *ChildKey) PubKeyBytes() []byte {
+	return c.PubKey.ToAffineUncompressed()
+}
+
+// PubKeyHex returns the hex-encoded compressed child public key
+func (c *ChildKey) PubKeyHex() string {
+	return hex.EncodeToString(c.PubKey.ToAffineCompressed())
+}
+
+// Verify returns true if the signature is valid for the child public key
+func (c *ChildKey) Verify(data []byte, sig []byte, opts ...SignOption) (bool, error) {
+	return VerifyWithCurve(c.Curve, c.PubKeyBytes(), data, sig, opts...)
+}
+
+// ExtendedPublicKey returns the child as a BIP32 extended public key. The joint key is exported at depth 0,
+// so xpubs derived from it with any BIP32 library yield the same children. Only secp256k1 keys can be exported.
+func (c *ChildKey) ExtendedPublicKey() (*bip32.Key, error) {
+	if c.Curve != K256Name {
+		return nil, fmt.Errorf("%w: bip32 extended keys are secp256k1 only", ErrUnsupportedCurve)
+	}
+	if len(c.Path) > 255 {
+		return nil, fmt.Errorf("derivation path too deep: %d", len(c.Path))
+	}
+	childNumber := []byte{0, 0, 0, 0}
+	if len(c.Path) > 0 {
+		childNumber = binary.BigEndian.AppendUint32(nil, c.Path[len(c.Path)-1])
+	}
+	return &bip32.Key{
+		Key:         c.PubKey.ToAffineCompressed(),
+		Version:     bip32.PublicWalletVersion,
+		ChildNumber: childNumber,
+		FingerPrint: c.ParentFingerprint,
+		ChainCode:   c.ChainCode,
+		Depth:       byte(len(c.Path)),
+		IsPrivate:   false,
+	}, nil
+}
+
+// fingerprint returns the BIP32 fingerprint of a compressed public key.
+func fingerprint(pubKey []byte) []byte {
+	sha := sha256.Sum256(pubKey)
+	rmd := ripemd160.New()
+	rmd.Write(sha[:])
+	return rmd.Sum(nil)[:4]
+}
+
+// ChainCode returns the BIP32 chain code agreed on during DKG
+func (k *EnclaveData) ChainCode() ([]byte, error) {
+	out, err := GetAliceOut(k.ValShare)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.ChainCode) == 0 {
+		return nil, ErrNoChainCode
+	}
+	return out.ChainCode, nil
+}
+
+// DeriveChild derives the non-hardened child key of the joint key at path
+func (k *EnclaveData) DeriveChild(path string) (*ChildKey, error) {
+	return deriveChild(k.Curve, k.PubBytes, k.ChainCode, path)
+}
+
+// ExtendedPublicKey returns the joint key as a depth 0 BIP32 extended public key
+func (k *EnclaveData) ExtendedPublicKey() (*bip32.Key, error) {
+	child, err := k.DeriveChild("m")
+	if err != nil {
+		return nil, err
+	}
+	return child.ExtendedPublicKey()
+}
+
+// ChainCode returns the BIP32 chain code agreed on during DKG
+func (p *partyData) ChainCode() ([]byte, error) {
+	var chainCode []byte
+	switch p.Party {
+	case RoleVal:
+		out, err := GetAliceOut(p.Share)
+		if err != nil {
+			return nil, err
+		}
+		chainCode = out.ChainCode
+	case RoleUser:
+		out, err := GetBobOut(p.Share)
+		if err != nil {
+			return nil, err
+		}
+		chainCode = out.ChainCode
+	default:
+		return nil, fmt.Errorf("unknown party role %q", p.Party)
+	}
+	if len(chainCode) == 0 {
+		return nil, ErrNoChainCode
+	}
+	return chainCode, nil
+}
+
+// DeriveChild derives the non-hardened child key of the joint key at path
+func (p *partyData) DeriveChild(path string) (*ChildKey, error) {
+	return deriveChild(p.Curve, p.PubBytes, p.ChainCode, path)
+}
+
+// ExtendedPublicKey returns the joint key as a depth 0 BIP32 extended public key
+func (p *partyData) ExtendedPublicKey() (*bip32.Key, error) {
+	child, err := p.DeriveChild("m")
+	if err != nil {
+		return nil, err
+	}
+	return child.ExtendedPublicKey()
+}
+
+func deriveChild(curve CurveName, pubKey []byte, chainCode func() ([]byte, error), path string) (*ChildKey, error) {
+	parsed, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	cc, err := chainCode()
+	if err != nil {
+		return nil, err
+	}
+	return DeriveChildKey(curve, pubKey, cc, parsed)
+}
+
+// keyTweak returns the tweak for the derivation path in cfg, or nil to sign with the joint key.
+func (c *signConfig) keyTweak(derive func(path string) (*ChildKey, error)) (curves.Scalar, error) {
+	if c.path == "" {
+		return nil, nil
+	}
+	child, err := derive(c.path)
+	if err != nil {
+		return nil, err
+	}
+	return child.tweak, nil
+}
+
+// verificationKey returns the public key a signature made with cfg must verify against.
+func (c *signConfig) verificationKey(pubKey []byte, derive func(path string) (*ChildKey, error)) ([]byte, error) {
+	if c.path == "" {
+		return pubKey, nil
+	}
+	child, err := derive(c.path)
+	if err != nil {
+		return nil, err
+	}
+	return child.PubKeyBytes(), nil
+}
+
+// withoutDerivationPath returns opts with the derivation path cleared, once it has been resolved to a key.
+func withoutDerivationPath(opts []SignOption) []SignOption {
+	return append(opts[:len(opts):len(opts)], WithDerivationPath(""))
+}
@@ -0,0 +1,137 @@
+package mpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/bip32"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44'/60h/0'/0/7")
+	require.NoError(t, err)
+	assert.Equal(t, DerivationPath{
+		44 + bip32.FirstHardenedChild,
+		60 + bip32.FirstHardenedChild,
+		bip32.FirstHardenedChild,
+		0,
+		7,
+	}, path)
+	assert.Equal(t, "m/44'/60'/0'/0/7", path.String())
+
+	path, err = ParseDerivationPath("m")
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	for _, bad := range []string{"m/x", "m//1", "m/2147483648", "m/-1"} {
+		_, err := ParseDerivationPath(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDeriveChild_MatchesBip32(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	data := enclave.GetData()
+
+	chainCode, err := data.ChainCode()
+	require.NoError(t, err)
+	require.Len(t, chainCode, 32)
+
+	xpub, err := data.ExtendedPublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, byte(0), xpub.Depth)
+	decoded, err := bip32.B58Deserialize(xpub.B58Serialize())
+	require.NoError(t, err)
+	assert.Equal(t, xpub.Key, decoded.Key)
+
+	// Deriving from the exported xpub with the bip32 package gives the same children
+	expected, err := xpub.NewChildKey(0)
+	require.NoError(t, err)
+	expected, err = expected.NewChildKey(5)
+	require.NoError(t, err)
+
+	child, err := data.DeriveChild("m/0/5")
+	require.NoError(t, err)
+	childXpub, err := child.ExtendedPublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, expected.B58Serialize(), childXpub.B58Serialize())
+	assert.NotEqual(t, data.PubKeyHex(), child.PubKeyHex())
+
+	_, err = data.DeriveChild("m/44'/0")
+	require.ErrorIs(t, err, ErrHardenedDerivation)
+}
+
+func TestSignDerived(t *testing.T) {
+	for _, curve := range []CurveName{K256Name, P256Name} {
+		t.Run(curve.String(), func(t *testing.T) {
+			enclave, err := NewEnclave(curve)
+			require.NoError(t, err)
+			data := enclave.GetData()
+
+			msg := []byte("derived message")
+			opt := WithDerivationPath("m/0/3")
+			sig, err := data.Sign(msg, opt)
+			require.NoError(t, err)
+
+			child, err := data.DeriveChild("m/0/3")
+			require.NoError(t, err)
+			valid, err := child.Verify(msg, sig)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			valid, err = data.Verify(msg, sig, opt)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			valid, err = data.Verify(msg, sig)
+			require.NoError(t, err)
+			assert.False(t, valid, "derived signature must not verify against the joint key")
+
+			_, err = VerifyWithCurve(curve, data.PubKeyBytes(), msg, sig, opt)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSignDerived_AfterRefresh(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	before, err := enclave.GetData().DeriveChild("m/1")
+	require.NoError(t, err)
+
+	refreshed, err := enclave.Refresh()
+	require.NoError(t, err)
+	after, err := refreshed.GetData().DeriveChild("m/1")
+	require.NoError(t, err)
+	assert.Equal(t, before.PubKeyHex(), after.PubKeyHex())
+
+	msg := []byte("after refresh")
+	sig, err := refreshed.Sign(msg, WithDerivationPath("m/1"))
+	require.NoError(t, err)
+	valid, err := after.Verify(msg, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestSignDerived_Party(t *testing.T) {
+	val, user := newTestParties(t)
+	userXpub, err := user.ExtendedPublicKey()
+	require.NoError(t, err)
+	valXpub, err := val.ExtendedPublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, userXpub.B58Serialize(), valXpub.B58Serialize())
+
+	msg := []byte("party derived message")
+	opt := WithDerivationPath("m/0/9")
+	sig := signWithParties(t, val, user, msg, opt)
+
+	valid, err := val.Verify(msg, sig, opt)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = user.Verify(msg, sig)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
@@ -1,14 +1,10 @@
 package mpc
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 
 	"github.com/sonr-io/crypto/core/curves"
-	"golang.org/x/crypto/sha3"
 )
 
 // EnclaveData implements the Enclave interface
@@ -17,7 +13,7 @@ type EnclaveData struct {
 	PubBytes  []byte    `json:"pub_bytes"` // PubBytes is the uncompressed public key
 	ValShare  Message   `json:"val_share"`
 	UserShare Message   `json:"user_share"`
-	Nonce     []byte    `json:"nonce"`
+	Nonce     []byte    `json:"nonce"` // Nonce is only used to decrypt legacy, pre-envelope blobs
 	Curve     CurveName `json:"curve"`
 }
 
@@ -33,7 +29,10 @@ func (k *EnclaveData) GetEnclave() Enclave {
 
 // GetPubPoint returns the public point of the keyEnclave
 func (k *EnclaveData) GetPubPoint() (curves.Point, error) {
-	curve := k.Curve.Curve()
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
 	return curve.NewIdentityPoint().FromAffineUncompressed(k.PubBytes)
 }
 
@@ -47,46 +46,34 @@ func (k *EnclaveData) PubKeyBytes() []byte {
 	return k.PubBytes
 }
 
-// Decrypt returns decrypted enclave data
+// Decrypt returns decrypted enclave data. Both versioned envelopes and legacy blobs sealed
+// with the enclave nonce are accepted.
 func (k *EnclaveData) Decrypt(key []byte, encryptedData []byte) ([]byte, error) {
-	hashedKey := GetHashKey(key)
-	block, err := aes.NewCipher(hashedKey)
-	if err != nil {
-		return nil, err
-	}
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+	if IsEnvelope(encryptedData) {
+		return Open(encryptedData, key, k.associatedData())
 	}
-
-	// Decrypt the data using AES-GCM
-	plaintext, err := aesgcm.Open(nil, k.Nonce, encryptedData, nil)
-	if err != nil {
-		return nil, fmt.Errorf("decryption failed: %w", err)
-	}
-	return plaintext, nil
+	return openLegacy(encryptedData, key, k.Nonce)
 }
 
-// Export returns encrypted enclave data
+// Encrypt returns encrypted enclave data sealed in a versioned envelope bound to the public key
 func (k *EnclaveData) Encrypt(key []byte) ([]byte, error) {
+	return k.EncryptWithOptions(key)
+}
+
+// EncryptWithOptions returns encrypted enclave data using the given KDF and cipher options
+func (k *EnclaveData) EncryptWithOptions(key []byte, opts ...SealOption) ([]byte, error) {
 	data, err := k.Marshal()
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize enclave: %w", err)
 	}
+	return Seal(data, key, k.associatedData(), opts...)
+}
 
-	hashedKey := GetHashKey(key)
-	block, err := aes.NewCipher(hashedKey)
-	if err != nil {
-		return nil, err
-	}
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
+func (k *EnclaveData) associatedData() []byte {
+	if len(k.PubBytes) == 0 {
+		return nil
 	}
-
-	return aesgcm.Seal(nil, k.Nonce, data, nil), nil
+	return k.PubBytes
 }
 
 // IsValid returns true if the keyEnclave is valid
@@ -107,41 +94,40 @@ func (k *EnclaveData) Refresh() (Enclave, error) {
 	return ExecuteRefresh(refreshFuncVal, refreshFuncUser, k.Curve)
 }
 
-// Sign returns the signature of the data
-func (k *EnclaveData) Sign(data []byte) ([]byte, error) {
-	userSign, err := GetBobSignFunc(k, data)
+// Sign returns the signature of the data, hashed with SHA3-256 unless options say otherwise
+func (k *EnclaveData) Sign(data []byte, opts ...SignOption) ([]byte, error) {
+	userSign, err := GetBobSignFunc(k, data, opts...)
 	if err != nil {
 		return nil, err
 	}
-	valSign, err := GetAliceSignFunc(k, data)
+	valSign, err := GetAliceSignFunc(k, data, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return ExecuteSigning(valSign, userSign)
 }
 
-// Verify returns true if the signature is valid
-func (k *EnclaveData) Verify(data []byte, sig []byte) (bool, error) {
-	edSig, err := DeserializeSignature(sig)
+// SignBatch returns the signatures of every item of data, in order, from a single run of the signing protocol
+func (k *EnclaveData) SignBatch(data [][]byte, opts ...SignOption) ([][]byte, error) {
+	userSign, err := GetBobBatchSignFunc(k, data, opts...)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	ePub, err := GetECDSAPoint(k.PubBytes)
+	valSign, err := GetAliceBatchSignFunc(k, data, opts...)
 	if err != nil {
-		return false, err
-	}
-	pk := &ecdsa.PublicKey{
-		Curve: ePub.Curve,
-		X:     ePub.X,
-		Y:     ePub.Y,
+		return nil, err
 	}
+	return ExecuteBatchSigning(valSign, userSign)
+}
 
-	// Hash the message using SHA3-256
-	hash := sha3.New256()
-	hash.Write(data)
-	digest := hash.Sum(nil)
-
-	return ecdsa.Verify(pk, digest, edSig.R, edSig.S), nil
+// Verify returns true if the signature is valid for the joint public key, or for the child key
+// selected with WithDerivationPath
+func (k *EnclaveData) Verify(data []byte, sig []byte, opts ...SignOption) (bool, error) {
+	pubKey, err := newSignConfig(opts).verificationKey(k.PubBytes, k.DeriveChild)
+	if err != nil {
+		return false, err
+	}
+	return VerifyWithCurve(k.Curve, pubKey, data, sig, withoutDerivationPath(opts)...)
 }
 
 // Marshal returns the JSON encoding of keyEnclave
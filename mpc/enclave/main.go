@@ -16,7 +16,7 @@ func main() {
 
 //go:wasmexport generate
 func generate() int32 {
-	e, err := mpc.NewEnclave()
+	e, err := mpc.NewEnclave(mpc.K256Name)
 	if err != nil {
 		pdk.SetError(err)
 		return 1
@@ -11,7 +11,7 @@ import (
 
 func TestEnclaveData_GetData(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -28,7 +28,7 @@ func TestEnclaveData_GetData(t *testing.T) {
 
 func TestEnclaveData_GetEnclave(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -46,7 +46,7 @@ func TestEnclaveData_GetEnclave(t *testing.T) {
 
 func TestEnclaveData_GetPubPoint(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -66,7 +66,7 @@ func TestEnclaveData_GetPubPoint(t *testing.T) {
 
 func TestEnclaveData_PubKeyHex(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -88,7 +88,7 @@ func TestEnclaveData_PubKeyHex(t *testing.T) {
 
 func TestEnclaveData_PubKeyBytes(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -106,7 +106,7 @@ func TestEnclaveData_PubKeyBytes(t *testing.T) {
 
 func TestEnclaveData_EncryptDecrypt(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -142,7 +142,7 @@ func TestEnclaveData_EncryptDecrypt(t *testing.T) {
 
 func TestEnclaveData_IsValid(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -167,7 +167,7 @@ func TestEnclaveData_IsValid(t *testing.T) {
 
 func TestEnclaveData_RefreshAndSign(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -179,8 +179,8 @@ func TestEnclaveData_RefreshAndSign(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, refreshedEnclave)
 
-	// Verify the public key changes after refresh
-	assert.NotEqual(t, originalPubKey, refreshedEnclave.PubKeyHex())
+	// Verify the public key is preserved across refresh
+	assert.Equal(t, originalPubKey, refreshedEnclave.PubKeyHex())
 
 	// Test signing
 	testData := []byte("test message")
@@ -202,7 +202,7 @@ func TestEnclaveData_RefreshAndSign(t *testing.T) {
 
 func TestEnclaveData_MarshalUnmarshal(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -235,7 +235,7 @@ func TestEnclaveData_MarshalUnmarshal(t *testing.T) {
 
 func TestEnclaveData_Verify(t *testing.T) {
 	// Create a new enclave
-	enclave, err := NewEnclave()
+	enclave, err := NewEnclave(K256Name)
 	require.NoError(t, err)
 	require.NotNil(t, enclave)
 
@@ -270,3 +270,94 @@ func TestEnclaveData_Verify(t *testing.T) {
 	// as we already verified the Sign and Verify functions work together.
 	// This completes the verification of the enclave's signature functionality.
 }
+
+func TestEnclaveData_SignBatch(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+
+	messages := [][]byte{[]byte("first message"), []byte("second message"), []byte("third message")}
+	for _, opts := range [][]SignOption{nil, {WithDerivationPath("m/0/1")}} {
+		sigs, err := enclave.SignBatch(messages, opts...)
+		require.NoError(t, err)
+		require.Len(t, sigs, len(messages))
+		for i, sig := range sigs {
+			valid, err := enclave.Verify(messages[i], sig, opts...)
+			require.NoError(t, err)
+			assert.True(t, valid)
+			valid, err = enclave.Verify(messages[(i+1)%len(messages)], sig, opts...)
+			require.NoError(t, err)
+			assert.False(t, valid)
+		}
+	}
+
+	_, err = enclave.SignBatch(nil)
+	assert.Error(t, err)
+}
+
+func TestEnclaveData_Curves(t *testing.T) {
+	for _, curve := range []CurveName{K256Name, P256Name} {
+		t.Run(curve.String(), func(t *testing.T) {
+			enclave, err := NewEnclave(curve)
+			require.NoError(t, err)
+			assert.Equal(t, curve, enclave.GetData().Curve)
+
+			pubPoint, err := enclave.GetData().GetPubPoint()
+			require.NoError(t, err)
+			assert.Equal(t, curve.Curve().Name, pubPoint.CurveName())
+
+			testMessage := []byte("test message")
+			signature, err := enclave.Sign(testMessage)
+			require.NoError(t, err)
+
+			valid, err := enclave.Verify(testMessage, signature)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			valid, err = VerifyWithCurve(curve, enclave.PubKeyBytes(), testMessage, signature)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			// Compressed keys are accepted as well
+			compressed, err := hex.DecodeString(enclave.PubKeyHex())
+			require.NoError(t, err)
+			valid, err = VerifyWithCurve(curve, compressed, testMessage, signature)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			refreshed, err := enclave.Refresh()
+			require.NoError(t, err)
+			assert.Equal(t, enclave.PubKeyHex(), refreshed.PubKeyHex())
+			signature, err = refreshed.Sign(testMessage)
+			require.NoError(t, err)
+			valid, err = refreshed.Verify(testMessage, signature)
+			require.NoError(t, err)
+			assert.True(t, valid)
+		})
+	}
+
+	t.Run("P-256 signature does not verify as secp256k1", func(t *testing.T) {
+		enclave, err := NewEnclave(P256Name)
+		require.NoError(t, err)
+		signature, err := enclave.Sign([]byte("msg"))
+		require.NoError(t, err)
+		_, err = VerifyWithPubKey(enclave.PubKeyBytes(), []byte("msg"), signature)
+		assert.Error(t, err, "a P-256 point is not on secp256k1")
+	})
+
+	t.Run("Unsupported curves are rejected", func(t *testing.T) {
+		for _, curve := range []CurveName{ED25519Name, BLS12381G1Name, PallasName, "unknown"} {
+			_, err := NewEnclave(curve)
+			assert.ErrorIs(t, err, ErrUnsupportedCurve, curve)
+		}
+		assert.Nil(t, CurveName("unknown").Curve())
+
+		enclave, err := NewEnclave(K256Name)
+		require.NoError(t, err)
+		data := *enclave.GetData()
+		data.Curve = "unknown"
+		_, err = data.Sign([]byte("msg"))
+		assert.ErrorIs(t, err, ErrUnsupportedCurve)
+		_, err = data.Verify([]byte("msg"), make([]byte, 64))
+		assert.ErrorIs(t, err, ErrUnsupportedCurve)
+	})
+}
@@ -0,0 +1,296 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDF names the key derivation function used to turn a password into an encryption key.
+type KDF string
+
+const (
+	KDFArgon2id KDF = "argon2id" // KDFArgon2id is the default, memory-hard KDF
+	KDFScrypt   KDF = "scrypt"   // KDFScrypt is available for platforms without argon2 tuning
+	KDFSHA3     KDF = "sha3-256" // KDFSHA3 is a single hash, only suitable for high entropy keys
+)
+
+// AEAD names the authenticated cipher used to encrypt the payload.
+type AEAD string
+
+const (
+	AEADAES256GCM         AEAD = "aes-256-gcm"
+	AEADXChaCha20Poly1305 AEAD = "xchacha20-poly1305"
+)
+
+// EnvelopeVersion1 is the first versioned envelope format. Data without the envelope
+// magic is treated as a legacy blob sealed with GetHashKey and the enclave nonce.
+const EnvelopeVersion1 = 1
+
+// Upper bounds on the KDF cost parameters of an envelope. They are enforced before deriving a key,
+// so that a crafted envelope cannot make Open allocate unbounded memory or spin for a long time.
+const (
+	MaxArgon2idTime    = 10         // MaxArgon2idTime bounds the argon2id iterations
+	MaxArgon2idMemory  = 256 * 1024 // MaxArgon2idMemory bounds the argon2id memory, in KiB
+	MaxArgon2idThreads = 16         // MaxArgon2idThreads bounds the argon2id parallelism
+	MaxScryptN         = 1 << 20    // MaxScryptN bounds the scrypt CPU/memory cost
+	MaxScryptR         = 16         // MaxScryptR bounds the scrypt block size
+	MaxScryptP         = 16         // MaxScryptP bounds the scrypt parallelism
+)
+
+var (
+	envelopeMagic = []byte("SNRE")
+
+	// ErrUnsupportedEnvelope is returned for envelopes with an unknown version, KDF or AEAD.
+	ErrUnsupportedEnvelope = errors.New("unsupported envelope")
+
+	// ErrAssociatedData is returned when an envelope is bound to a different public key.
+	ErrAssociatedData = errors.New("envelope associated data mismatch")
+)
+
+// KDFParams holds the salt and cost parameters of the KDF.
+type KDFParams struct {
+	Salt    []byte `json:"salt,omitempty"`
+	Time    uint32 `json:"time,omitempty"`    // argon2id iterations
+	Memory  uint32 `json:"memory,omitempty"`  // argon2id memory in KiB
+	Threads uint8  `json:"threads,omitempty"` // argon2id parallelism
+	N       int    `json:"n,omitempty"`       // scrypt CPU/memory cost
+	R       int    `json:"r,omitempty"`       // scrypt block size
+	P       int    `json:"p,omitempty"`       // scrypt parallelism
+}
+
+// Envelope is the self-describing container for encrypted enclave data and keyshares.
+type Envelope struct {
+	Version    uint8     `json:"v"`
+	KDF        KDF       `json:"kdf"`
+	KDFParams  KDFParams `json:"kdf_params"`
+	AEAD       AEAD      `json:"aead"`
+	Nonce      []byte    `json:"nonce"`
+	AD         []byte    `json:"ad,omitempty"` // AD is authenticated but not encrypted, e.g. the joint public key
+	Ciphertext []byte    `json:"ct"`
+}
+
+// SealOption configures Seal.
+type SealOption func(*Envelope)
+
+// WithArgon2id selects argon2id with the given cost parameters.
+func WithArgon2id(time, memory uint32, threads uint8) SealOption {
+	return func(e *Envelope) {
+		e.KDF = KDFArgon2id
+		e.KDFParams = KDFParams{Time: time, Memory: memory, Threads: threads}
+	}
+}
+
+// WithScrypt selects scrypt with the given cost parameters.
+func WithScrypt(n, r, p int) SealOption {
+	return func(e *Envelope) {
+		e.KDF = KDFScrypt
+		e.KDFParams = KDFParams{N: n, R: r, P: p}
+	}
+}
+
+// WithRawKey selects a single SHA3-256 over the key. Use only for keys that are already uniformly random.
+func WithRawKey() SealOption {
+	return func(e *Envelope) {
+		e.KDF = KDFSHA3
+		e.KDFParams = KDFParams{}
+	}
+}
+
+// WithAEAD selects the authenticated cipher.
+func WithAEAD(aead AEAD) SealOption {
+	return func(e *Envelope) {
+		e.AEAD = aead
+	}
+}
+
+// defaultEnvelope follows the RFC 9106 second recommended argon2id parameter set: 3 iterations
+// over 64 MiB with 4 lanes.
+func defaultEnvelope() *Envelope {
+	return &Envelope{
+		Version:   EnvelopeVersion1,
+		KDF:       KDFArgon2id,
+		KDFParams: KDFParams{Time: 3, Memory: 64 * 1024, Threads: 4},
+		AEAD:      AEADAES256GCM,
+	}
+}
+
+// Seal encrypts plaintext under a key derived from password, binding ad to the ciphertext.
+// A fresh salt and nonce are drawn for every call.
+//
+// By default the key is derived with argon2id over 64 MiB and 4 threads, and opening the envelope
+// costs as much. Memory constrained platforms such as wasm should pass WithArgon2id with a lower
+// cost, e.g. the OWASP minimum of 2 iterations over 19 MiB with 1 thread, WithArgon2id(2, 19*1024, 1).
+func Seal(plaintext, password, ad []byte, opts ...SealOption) ([]byte, error) {
+	env := defaultEnvelope()
+	for _, opt := range opts {
+		opt(env)
+	}
+	if env.KDF != KDFSHA3 {
+		env.KDFParams.Salt = make([]byte, 16)
+		if _, err := rand.Read(env.KDFParams.Salt); err != nil {
+			return nil, err
+		}
+	}
+	key, err := env.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := env.cipher(key)
+	if err != nil {
+		return nil, err
+	}
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, err
+	}
+	env.AD = ad
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plaintext, env.additionalData())
+	return env.MarshalBinary()
+}
+
+// Open decrypts an envelope produced by Seal. If ad is not nil it must match the associated
+// data the envelope was sealed with.
+func Open(data, password, ad []byte) ([]byte, error) {
+	env := &Envelope{}
+	if err := env.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if ad != nil && !bytes.Equal(ad, env.AD) {
+		return nil, ErrAssociatedData
+	}
+	key, err := env.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := env.cipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(env.Nonce))
+	}
+	plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, env.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+// IsEnvelope reports whether data carries the versioned envelope header.
+func IsEnvelope(data []byte) bool {
+	return len(data) > len(envelopeMagic) && bytes.Equal(data[:len(envelopeMagic)], envelopeMagic)
+}
+
+// MarshalBinary encodes the envelope as the magic header, a version byte and its JSON body.
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(envelopeMagic)+1+len(body))
+	out = append(out, envelopeMagic...)
+	out = append(out, e.Version)
+	return append(out, body...), nil
+}
+
+// UnmarshalBinary decodes an envelope produced by MarshalBinary.
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	if !IsEnvelope(data) {
+		return fmt.Errorf("%w: missing header", ErrUnsupportedEnvelope)
+	}
+	version := data[len(envelopeMagic)]
+	if version != EnvelopeVersion1 {
+		return fmt.Errorf("%w: version %d", ErrUnsupportedEnvelope, version)
+	}
+	if err := json.Unmarshal(data[len(envelopeMagic)+1:], e); err != nil {
+		return err
+	}
+	if e.Version != version {
+		return fmt.Errorf("%w: header version %d does not match body version %d", ErrUnsupportedEnvelope, version, e.Version)
+	}
+	return nil
+}
+
+// additionalData binds the envelope header to the ciphertext so that the KDF and cipher
+// choices cannot be downgraded without detection.
+func (e *Envelope) additionalData() []byte {
+	header := struct {
+		Version   uint8     `json:"v"`
+		KDF       KDF       `json:"kdf"`
+		KDFParams KDFParams `json:"kdf_params"`
+		AEAD      AEAD      `json:"aead"`
+		AD        []byte    `json:"ad,omitempty"`
+	}{e.Version, e.KDF, e.KDFParams, e.AEAD, e.AD}
+	bz, _ := json.Marshal(header)
+	return bz
+}
+
+func (e *Envelope) deriveKey(password []byte) ([]byte, error) {
+	p := e.KDFParams
+	switch e.KDF {
+	case KDFArgon2id:
+		if len(p.Salt) == 0 || p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+			return nil, fmt.Errorf("%w: invalid argon2id parameters", ErrUnsupportedEnvelope)
+		}
+		if p.Time > MaxArgon2idTime || p.Memory > MaxArgon2idMemory || p.Threads > MaxArgon2idThreads {
+			return nil, fmt.Errorf("%w: argon2id parameters exceed the allowed cost", ErrUnsupportedEnvelope)
+		}
+		return argon2.IDKey(password, p.Salt, p.Time, p.Memory, p.Threads, 32), nil
+	case KDFScrypt:
+		if len(p.Salt) == 0 {
+			return nil, fmt.Errorf("%w: missing scrypt salt", ErrUnsupportedEnvelope)
+		}
+		if p.N <= 1 || p.R <= 0 || p.P <= 0 {
+			return nil, fmt.Errorf("%w: invalid scrypt parameters", ErrUnsupportedEnvelope)
+		}
+		if p.N > MaxScryptN || p.R > MaxScryptR || p.P > MaxScryptP {
+			return nil, fmt.Errorf("%w: scrypt parameters exceed the allowed cost", ErrUnsupportedEnvelope)
+		}
+		return scrypt.Key(password, p.Salt, p.N, p.R, p.P, 32)
+	case KDFSHA3:
+		return GetHashKey(password), nil
+	default:
+		return nil, fmt.Errorf("%w: kdf %q", ErrUnsupportedEnvelope, e.KDF)
+	}
+}
+
+func (e *Envelope) cipher(key []byte) (cipher.AEAD, error) {
+	switch e.AEAD {
+	case AEADAES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case AEADXChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("%w: aead %q", ErrUnsupportedEnvelope, e.AEAD)
+	}
+}
+
+// openLegacy decrypts a blob sealed before the envelope format existed.
+func openLegacy(data, key, nonce []byte) ([]byte, error) {
+	block, err := aes.NewCipher(GetHashKey(key))
+	if err != nil {
+		return nil, err
+	}
+	aesgcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aesgcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,193 @@
+package mpc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/protocol"
+)
+
+// fastArgon keeps the tests quick; production callers use the defaults.
+var fastArgon = WithArgon2id(1, 1024, 1)
+
+func TestEnvelope_SealOpen(t *testing.T) {
+	password := []byte("correct horse battery staple")
+	ad := []byte("public key")
+	plaintext := []byte("enclave data")
+
+	tests := []struct {
+		name string
+		opts []SealOption
+		kdf  KDF
+		aead AEAD
+	}{
+		{"argon2id", []SealOption{fastArgon}, KDFArgon2id, AEADAES256GCM},
+		{"scrypt", []SealOption{WithScrypt(1<<10, 8, 1)}, KDFScrypt, AEADAES256GCM},
+		{"raw key", []SealOption{WithRawKey()}, KDFSHA3, AEADAES256GCM},
+		{"xchacha", []SealOption{fastArgon, WithAEAD(AEADXChaCha20Poly1305)}, KDFArgon2id, AEADXChaCha20Poly1305},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := Seal(plaintext, password, ad, tt.opts...)
+			require.NoError(t, err)
+			require.True(t, IsEnvelope(sealed))
+
+			env := &Envelope{}
+			require.NoError(t, env.UnmarshalBinary(sealed))
+			assert.Equal(t, uint8(EnvelopeVersion1), env.Version)
+			assert.Equal(t, tt.kdf, env.KDF)
+			assert.Equal(t, tt.aead, env.AEAD)
+			assert.Equal(t, ad, env.AD)
+
+			opened, err := Open(sealed, password, ad)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, opened)
+
+			_, err = Open(sealed, []byte("wrong password"), ad)
+			assert.Error(t, err)
+			_, err = Open(sealed, password, []byte("other key"))
+			assert.ErrorIs(t, err, ErrAssociatedData)
+		})
+	}
+}
+
+func TestEnvelope_FreshNonceAndSalt(t *testing.T) {
+	a, err := Seal([]byte("data"), []byte("pw"), nil, fastArgon)
+	require.NoError(t, err)
+	b, err := Seal([]byte("data"), []byte("pw"), nil, fastArgon)
+	require.NoError(t, err)
+
+	envA, envB := &Envelope{}, &Envelope{}
+	require.NoError(t, envA.UnmarshalBinary(a))
+	require.NoError(t, envB.UnmarshalBinary(b))
+	assert.NotEqual(t, envA.Nonce, envB.Nonce)
+	assert.NotEqual(t, envA.KDFParams.Salt, envB.KDFParams.Salt)
+	assert.NotEqual(t, envA.Ciphertext, envB.Ciphertext)
+}
+
+func TestEnvelope_HeaderIsAuthenticated(t *testing.T) {
+	sealed, err := Seal([]byte("data"), []byte("pw"), []byte("ad"), WithRawKey())
+	require.NoError(t, err)
+
+	env := &Envelope{}
+	require.NoError(t, env.UnmarshalBinary(sealed))
+	env.AEAD = AEADXChaCha20Poly1305
+	env.Nonce = make([]byte, 24)
+	tampered, err := env.MarshalBinary()
+	require.NoError(t, err)
+	_, err = Open(tampered, []byte("pw"), nil)
+	assert.Error(t, err)
+
+	env.Version = 2
+	future, err := env.MarshalBinary()
+	require.NoError(t, err)
+	_, err = Open(future, []byte("pw"), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedEnvelope)
+}
+
+func TestEnvelope_KDFCostIsBounded(t *testing.T) {
+	tests := []struct {
+		name   string
+		kdf    KDF
+		params KDFParams
+	}{
+		{"argon2id time", KDFArgon2id, KDFParams{Time: MaxArgon2idTime + 1, Memory: 1024, Threads: 1}},
+		{"argon2id memory", KDFArgon2id, KDFParams{Time: 1, Memory: 1 << 31, Threads: 1}},
+		{"argon2id threads", KDFArgon2id, KDFParams{Time: 1, Memory: 1024, Threads: 255}},
+		{"scrypt n", KDFScrypt, KDFParams{N: 1 << 30, R: 8, P: 1}},
+		{"scrypt r", KDFScrypt, KDFParams{N: 1 << 10, R: 1 << 20, P: 1}},
+		{"scrypt p", KDFScrypt, KDFParams{N: 1 << 10, R: 8, P: 1 << 20}},
+		{"scrypt zero", KDFScrypt, KDFParams{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := Seal([]byte("data"), []byte("pw"), nil, fastArgon)
+			require.NoError(t, err)
+
+			// an attacker controlled blob must not make Open derive with arbitrary costs
+			env := &Envelope{}
+			require.NoError(t, env.UnmarshalBinary(sealed))
+			env.KDF = tt.kdf
+			tt.params.Salt = env.KDFParams.Salt
+			env.KDFParams = tt.params
+			crafted, err := env.MarshalBinary()
+			require.NoError(t, err)
+			_, err = Open(crafted, []byte("pw"), nil)
+			assert.ErrorIs(t, err, ErrUnsupportedEnvelope)
+
+			// and Seal refuses them too
+			_, err = Seal([]byte("data"), []byte("pw"), nil, func(e *Envelope) {
+				e.KDF = tt.kdf
+				e.KDFParams = tt.params
+			})
+			assert.ErrorIs(t, err, ErrUnsupportedEnvelope)
+		})
+	}
+}
+
+func TestEnvelope_LegacyEnclaveStillDecrypts(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	data := enclave.GetData()
+	key := []byte("test-key-12345678-test-key-123456")
+
+	// Seal the way enclaves were stored before the envelope format
+	plaintext, err := data.Marshal()
+	require.NoError(t, err)
+	block, err := aes.NewCipher(GetHashKey(key))
+	require.NoError(t, err)
+	aesgcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	legacy := aesgcm.Seal(nil, data.Nonce, plaintext, nil)
+	require.False(t, IsEnvelope(legacy))
+
+	decrypted, err := data.Decrypt(key, legacy)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// New blobs use the envelope and are bound to the enclave public key
+	sealed, err := data.EncryptWithOptions(key, fastArgon)
+	require.NoError(t, err)
+	require.True(t, IsEnvelope(sealed))
+	decrypted, err = data.Decrypt(key, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	other, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	_, err = other.Decrypt(key, sealed)
+	assert.ErrorIs(t, err, ErrAssociatedData)
+}
+
+func TestEnvelope_Keyshare(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	data := enclave.GetData()
+	key := []byte("keyshare password")
+
+	sealed, err := SealKeyshare(data.UserShare, key, data.PubBytes, fastArgon)
+	require.NoError(t, err)
+	share, err := OpenKeyshare(sealed, key, data.PubBytes)
+	require.NoError(t, err)
+	assert.Equal(t, data.UserShare.Payloads, share.Payloads)
+
+	// Legacy keyshares keep working through DecryptKeyshare
+	nonce := randNonce()
+	legacy, err := EncryptKeyshare(data.ValShare, key, nonce)
+	require.NoError(t, err)
+	plaintext, err := DecryptKeyshare(legacy, key, nonce)
+	require.NoError(t, err)
+	decoded, err := protocol.DecodeMessage(string(plaintext))
+	require.NoError(t, err)
+	assert.Equal(t, data.ValShare.Payloads, decoded.Payloads)
+
+	plaintext, err = DecryptKeyshare(sealed, key, nil)
+	require.NoError(t, err)
+	decoded, err = protocol.DecodeMessage(string(plaintext))
+	require.NoError(t, err)
+	assert.Equal(t, data.UserShare.Payloads, decoded.Payloads)
+}
@@ -0,0 +1,192 @@
+package mpc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// SignOption selects how a message is turned into the digest that is signed or verified.
+// Both parties of a signing session must be given the same options.
+type SignOption func(*signConfig)
+
+type signConfig struct {
+	newHash func() hash.Hash
+	encode  func(data []byte) ([]byte, error)
+	path    string // path is the BIP32 derivation path of the signing key, empty for the joint key
+}
+
+func newSignConfig(opts []SignOption) *signConfig {
+	cfg := &signConfig{newHash: sha3.New256}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// message returns the bytes that are written into the hash.
+func (c *signConfig) message(data []byte) ([]byte, error) {
+	if c.encode == nil {
+		return data, nil
+	}
+	return c.encode(data)
+}
+
+// messages returns the messages that are signed for each item of data.
+func (c *signConfig) messages(data [][]byte) ([][]byte, error) {
+	msgs := make([][]byte, len(data))
+	for i, bz := range data {
+		msg, err := c.message(bz)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = msg
+	}
+	return msgs, nil
+}
+
+// digest returns the digest that is signed for data.
+func (c *signConfig) digest(data []byte) ([]byte, error) {
+	msg, err := c.message(data)
+	if err != nil {
+		return nil, err
+	}
+	h := c.newHash()
+	h.Write(msg)
+	return h.Sum(nil), nil
+}
+
+// WithHash signs the digest produced by a custom hash function. The hash must have a 32-byte output.
+func WithHash(newHash func() hash.Hash) SignOption {
+	return func(c *signConfig) {
+		c.newHash = newHash
+	}
+}
+
+// WithSHA3 signs SHA3-256(data). This is the default.
+func WithSHA3() SignOption {
+	return WithHash(sha3.New256)
+}
+
+// WithSHA256 signs SHA-256(data), as used by Cosmos SDK sign docs and WebAuthn.
+func WithSHA256() SignOption {
+	return WithHash(sha256.New)
+}
+
+// WithKeccak256 signs Keccak-256(data), as used by Ethereum transactions.
+func WithKeccak256() SignOption {
+	return WithHash(sha3.NewLegacyKeccak256)
+}
+
+// WithDoubleSHA256 signs SHA-256(SHA-256(data)), as used by Bitcoin sighashes.
+func WithDoubleSHA256() SignOption {
+	return WithHash(newDoubleSHA256)
+}
+
+// WithPrehashed signs data as-is. Data must already be a 32-byte digest.
+func WithPrehashed() SignOption {
+	return func(c *signConfig) {
+		c.newHash = newPrehash
+		c.encode = func(data []byte) ([]byte, error) {
+			if len(data) != 32 {
+				return nil, fmt.Errorf("prehashed digest must be 32 bytes, got %d", len(data))
+			}
+			return data, nil
+		}
+	}
+}
+
+// WithEthereumPersonal signs data as an EIP-191 personal message:
+// Keccak-256("\x19Ethereum Signed Message:\n" + len(data) + data).
+func WithEthereumPersonal() SignOption {
+	return func(c *signConfig) {
+		c.newHash = sha3.NewLegacyKeccak256
+		c.encode = func(data []byte) ([]byte, error) {
+			return EthereumPersonalMessage(data), nil
+		}
+	}
+}
+
+// WithDerivationPath signs with, or verifies against, the non-hardened child of the joint key at path,
+// e.g. "m/0/5". It only applies to enclaves, which know the chain code the child is derived with.
+func WithDerivationPath(path string) SignOption {
+	return func(c *signConfig) {
+		c.path = path
+	}
+}
+
+// EthereumPersonalMessage returns data with the EIP-191 personal message prefix.
+func EthereumPersonalMessage(data []byte) []byte {
+	prefix := "\x19Ethereum Signed Message:\n" + strconv.Itoa(len(data))
+	return append([]byte(prefix), data...)
+}
+
+// EIP712Message returns the EIP-712 encoding 0x19 0x01 || domainSeparator || hashStruct(message),
+// which is signed with Keccak-256.
+func EIP712Message(domainSeparator, structHash []byte) ([]byte, error) {
+	if len(domainSeparator) != 32 || len(structHash) != 32 {
+		return nil, errors.New("eip712 domain separator and struct hash must be 32 bytes")
+	}
+	msg := make([]byte, 0, 66)
+	msg = append(msg, 0x19, 0x01)
+	msg = append(msg, domainSeparator...)
+	return append(msg, structHash...), nil
+}
+
+// SignDigest signs a 32-byte digest computed by the caller
+func (k *EnclaveData) SignDigest(digest []byte) ([]byte, error) {
+	return k.Sign(digest, WithPrehashed())
+}
+
+// SignEthereumPersonal signs data as an EIP-191 personal_sign message
+func (k *EnclaveData) SignEthereumPersonal(data []byte) ([]byte, error) {
+	return k.Sign(data, WithEthereumPersonal())
+}
+
+// SignEIP712 signs EIP-712 typed data given its domain separator and struct hash
+func (k *EnclaveData) SignEIP712(domainSeparator, structHash []byte) ([]byte, error) {
+	msg, err := EIP712Message(domainSeparator, structHash)
+	if err != nil {
+		return nil, err
+	}
+	return k.Sign(msg, WithKeccak256())
+}
+
+// SignCosmosDirect signs the SIGN_MODE_DIRECT sign bytes of a Cosmos SDK transaction
+func (k *EnclaveData) SignCosmosDirect(signDocBytes []byte) ([]byte, error) {
+	return k.Sign(signDocBytes, WithSHA256())
+}
+
+// SignBitcoin signs the double SHA-256 of a Bitcoin sighash preimage
+func (k *EnclaveData) SignBitcoin(preimage []byte) ([]byte, error) {
+	return k.Sign(preimage, WithDoubleSHA256())
+}
+
+// prehash is a hash.Hash that returns its input unchanged.
+type prehash struct{ buf []byte }
+
+func newPrehash() hash.Hash { return &prehash{} }
+
+func (p *prehash) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	return len(b), nil
+}
+func (p *prehash) Sum(b []byte) []byte { return append(b, p.buf...) }
+func (p *prehash) Reset()              { p.buf = nil }
+func (p *prehash) Size() int           { return 32 }
+func (p *prehash) BlockSize() int      { return 32 }
+
+// doubleSHA256 is a hash.Hash computing SHA-256(SHA-256(m)).
+type doubleSHA256 struct{ hash.Hash }
+
+func newDoubleSHA256() hash.Hash { return doubleSHA256{sha256.New()} }
+
+func (d doubleSHA256) Sum(b []byte) []byte {
+	inner := d.Hash.Sum(nil)
+	outer := sha256.Sum256(inner)
+	return append(b, outer[:]...)
+}
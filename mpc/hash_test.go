@@ -0,0 +1,124 @@
+package mpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// verifyDigest checks a signature against an independently computed digest.
+func verifyDigest(t *testing.T, data *EnclaveData, digest []byte, sig []byte) bool {
+	t.Helper()
+	pub, err := GetCurveECDSAPoint(data.Curve, data.PubBytes)
+	require.NoError(t, err)
+	s, err := DeserializeSignature(sig)
+	require.NoError(t, err)
+	return ecdsa.Verify(&ecdsa.PublicKey{Curve: pub.Curve, X: pub.X, Y: pub.Y}, digest, s.R, s.S)
+}
+
+func TestSignOptions(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	data := enclave.GetData()
+	msg := []byte("hello world")
+
+	sha := sha256.Sum256(msg)
+	doubleSha := sha256.Sum256(sha[:])
+	sha3Digest := sha3.Sum256(msg)
+
+	tests := []struct {
+		name   string
+		sign   func() ([]byte, error)
+		opts   []SignOption
+		digest []byte
+	}{
+		{"default sha3", func() ([]byte, error) { return data.Sign(msg) }, nil, sha3Digest[:]},
+		{"cosmos direct", func() ([]byte, error) { return data.SignCosmosDirect(msg) }, []SignOption{WithSHA256()}, sha[:]},
+		{"bitcoin", func() ([]byte, error) { return data.SignBitcoin(msg) }, []SignOption{WithDoubleSHA256()}, doubleSha[:]},
+		{"keccak", func() ([]byte, error) { return data.Sign(msg, WithKeccak256()) }, []SignOption{WithKeccak256()}, keccak256(msg)},
+		{
+			"ethereum personal",
+			func() ([]byte, error) { return data.SignEthereumPersonal(msg) },
+			[]SignOption{WithEthereumPersonal()},
+			keccak256([]byte("\x19Ethereum Signed Message:\n11"), msg),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := tt.sign()
+			require.NoError(t, err)
+			assert.True(t, verifyDigest(t, data, tt.digest, sig))
+
+			valid, err := data.Verify(msg, sig, tt.opts...)
+			require.NoError(t, err)
+			assert.True(t, valid)
+			valid, err = VerifyWithPubKey(data.PubBytes, msg, sig, tt.opts...)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			// The digest can also be verified as prehashed
+			valid, err = data.Verify(tt.digest, sig, WithPrehashed())
+			require.NoError(t, err)
+			assert.True(t, valid)
+		})
+	}
+
+	t.Run("mismatched options fail", func(t *testing.T) {
+		sig, err := data.SignCosmosDirect(msg)
+		require.NoError(t, err)
+		valid, err := data.Verify(msg, sig)
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("sign digest", func(t *testing.T) {
+		sig, err := data.SignDigest(sha[:])
+		require.NoError(t, err)
+		assert.True(t, verifyDigest(t, data, sha[:], sig))
+		valid, err := data.Verify(msg, sig, WithSHA256())
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		_, err = data.SignDigest(msg)
+		assert.Error(t, err)
+	})
+
+	t.Run("eip712", func(t *testing.T) {
+		domain := keccak256([]byte("domain"))
+		structHash := keccak256([]byte("struct"))
+		sig, err := data.SignEIP712(domain, structHash)
+		require.NoError(t, err)
+		assert.True(t, verifyDigest(t, data, keccak256([]byte{0x19, 0x01}, domain, structHash), sig))
+
+		encoded, err := EIP712Message(domain, structHash)
+		require.NoError(t, err)
+		valid, err := data.Verify(encoded, sig, WithKeccak256())
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		_, err = data.SignEIP712(domain[:31], structHash)
+		assert.Error(t, err)
+	})
+}
+
+func TestSignOptions_Party(t *testing.T) {
+	val, user := newTestParties(t)
+	msg := []byte("cosmos sign doc")
+	sig := signWithParties(t, val, user, msg, WithSHA256())
+
+	valid, err := user.Verify(msg, sig, WithSHA256())
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
@@ -9,15 +9,68 @@ import (
 // ImportEnclave creates an Enclave instance from various import options.
 // It prioritizes enclave bytes over keyshares if both are provided.
 func ImportEnclave(options ...ImportOption) (Enclave, error) {
-	if len(options) == 0 {
-		return nil, errors.New("no import options provided")
+	opts, err := collectOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Apply()
+}
+
+// ImportValidatorEnclave creates a ValidatorEnclave from the validator keyshare or the
+// serialized validator enclave in the import options.
+func ImportValidatorEnclave(options ...ImportOption) (*ValidatorEnclave, error) {
+	opts, err := collectOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if opts.initialShares {
+		return BuildValidatorEnclave(opts.valKeyshare, opts.curve)
+	}
+	if len(opts.enclaveBytes) == 0 {
+		return nil, errors.New("enclave bytes cannot be empty")
+	}
+	v := &ValidatorEnclave{}
+	if err := v.Unmarshal(opts.enclaveBytes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal enclave: %w", err)
+	}
+	if v.Role() != RoleVal {
+		return nil, fmt.Errorf("expected %s enclave, got %q", RoleVal, v.Role())
+	}
+	return v, nil
+}
+
+// ImportUserEnclave creates a UserEnclave from the user keyshare or the serialized user
+// enclave in the import options.
+func ImportUserEnclave(options ...ImportOption) (*UserEnclave, error) {
+	opts, err := collectOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if opts.initialShares {
+		return BuildUserEnclave(opts.userKeyshare, opts.curve)
+	}
+	if len(opts.enclaveBytes) == 0 {
+		return nil, errors.New("enclave bytes cannot be empty")
+	}
+	u := &UserEnclave{}
+	if err := u.Unmarshal(opts.enclaveBytes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal enclave: %w", err)
 	}
+	if u.Role() != RoleUser {
+		return nil, fmt.Errorf("expected %s enclave, got %q", RoleUser, u.Role())
+	}
+	return u, nil
+}
 
+func collectOptions(options []ImportOption) (Options, error) {
+	if len(options) == 0 {
+		return Options{}, errors.New("no import options provided")
+	}
 	opts := Options{}
 	for _, opt := range options {
 		opts = opt(opts)
 	}
-	return opts.Apply()
+	return opts, nil
 }
 
 // Options is a struct that holds the import options
@@ -43,6 +96,26 @@ func WithInitialShares(valKeyshare Message, userKeyshare Message, curve CurveNam
 	}
 }
 
+// WithValidatorShare creates an option to import the validator half of an enclave from its keyshare.
+func WithValidatorShare(valKeyshare Message, curve CurveName) ImportOption {
+	return func(opts Options) Options {
+		opts.valKeyshare = valKeyshare
+		opts.initialShares = true
+		opts.curve = curve
+		return opts
+	}
+}
+
+// WithUserShare creates an option to import the user half of an enclave from its keyshare.
+func WithUserShare(userKeyshare Message, curve CurveName) ImportOption {
+	return func(opts Options) Options {
+		opts.userKeyshare = userKeyshare
+		opts.initialShares = true
+		opts.curve = curve
+		return opts
+	}
+}
+
 // WithEnclaveJSON creates an option to import an enclave from serialized bytes.
 func WithEnclaveJSON(enclaveBytes []byte) ImportOption {
 	return func(opts Options) Options {
@@ -79,6 +152,9 @@ func BuildEnclave(valShare, userShare Message, options Options) (Enclave, error)
 	if userShare == nil {
 		return nil, errors.New("user share cannot be nil")
 	}
+	if _, err := options.curve.ECDSACurve(); err != nil {
+		return nil, err
+	}
 
 	pubPoint, err := GetAlicePublicPoint(valShare)
 	if err != nil {
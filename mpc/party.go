@@ -0,0 +1,384 @@
+package mpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/mpc/transport"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1"
+)
+
+// PartyEnclave is one half of a 2-of-2 enclave. It holds only its own keyshare and the joint
+// public key, and can only sign or refresh by exchanging messages with the peer half.
+type PartyEnclave interface {
+	Role() Role                                                       // Role returns the role of the party holding the share
+	GetPubPoint() (curves.Point, error)                               // GetPubPoint returns the joint public point
+	PubKeyBytes() []byte                                              // PubKeyBytes returns the joint public key
+	PubKeyHex() string                                                // PubKeyHex returns the joint public key
+	IsValid() bool                                                    // IsValid returns true if the party holds a share
+	Decrypt(key []byte, encryptedData []byte) ([]byte, error)         // Decrypt returns decrypted party data
+	Encrypt(key []byte) ([]byte, error)                               // Encrypt returns encrypted party data
+	Marshal() ([]byte, error)                                         // Marshal returns the serialized party data
+	Unmarshal(data []byte) error                                      // Unmarshal restores the party data
+	Verify(data []byte, sig []byte, opts ...SignOption) (bool, error) // Verify returns true if the signature is valid
+}
+
+var (
+	_ PartyEnclave = (*ValidatorEnclave)(nil)
+	_ PartyEnclave = (*UserEnclave)(nil)
+)
+
+// partyData holds the fields shared by both halves of a split enclave.
+type partyData struct {
+	PubHex   string    `json:"pub_hex"`   // PubHex is the hex-encoded compressed public key
+	PubBytes []byte    `json:"pub_bytes"` // PubBytes is the uncompressed public key
+	Share    Message   `json:"share"`
+	Nonce    []byte    `json:"nonce"`
+	Curve    CurveName `json:"curve"`
+	Party    Role      `json:"role"`
+}
+
+// ValidatorEnclave is the validator (DKLs Alice) half of an enclave.
+type ValidatorEnclave struct {
+	partyData
+}
+
+// UserEnclave is the user (DKLs Bob) half of an enclave. Only the user half learns the signature.
+type UserEnclave struct {
+	partyData
+}
+
+// NewValidatorEnclave runs the DKG as the validator against a user reachable through t.
+func NewValidatorEnclave(ctx context.Context, t transport.Transport, session transport.Session, curve CurveName) (*ValidatorEnclave, error) {
+	crv, err := curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	dkg := dklsv1.NewAliceDkg(crv, protocol.Version1)
+	if err := transport.Run(ctx, t, session, dkg, false); err != nil {
+		return nil, err
+	}
+	share, err := dkg.Result(protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	return BuildValidatorEnclave(share, curve)
+}
+
+// NewUserEnclave runs the DKG as the user against a validator reachable through t.
+func NewUserEnclave(ctx context.Context, t transport.Transport, session transport.Session, curve CurveName) (*UserEnclave, error) {
+	crv, err := curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	dkg := dklsv1.NewBobDkg(crv, protocol.Version1)
+	if err := transport.Run(ctx, t, session, dkg, true); err != nil {
+		return nil, err
+	}
+	share, err := dkg.Result(protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	return BuildUserEnclave(share, curve)
+}
+
+// BuildValidatorEnclave creates a validator enclave from a validator keyshare.
+func BuildValidatorEnclave(share Message, curve CurveName) (*ValidatorEnclave, error) {
+	if share == nil {
+		return nil, errors.New("validator share cannot be nil")
+	}
+	if _, err := curve.ECDSACurve(); err != nil {
+		return nil, err
+	}
+	pubPoint, err := GetAlicePublicPoint(share)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public point: %w", err)
+	}
+	return &ValidatorEnclave{partyData: newPartyData(pubPoint, share, curve, RoleVal)}, nil
+}
+
+// BuildUserEnclave creates a user enclave from a user keyshare.
+func BuildUserEnclave(share Message, curve CurveName) (*UserEnclave, error) {
+	if share == nil {
+		return nil, errors.New("user share cannot be nil")
+	}
+	if _, err := curve.ECDSACurve(); err != nil {
+		return nil, err
+	}
+	pubPoint, err := GetBobPubPoint(share)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public point: %w", err)
+	}
+	return &UserEnclave{partyData: newPartyData(pubPoint, share, curve, RoleUser)}, nil
+}
+
+func newPartyData(pubPoint Point, share Message, curve CurveName, role Role) partyData {
+	return partyData{
+		PubBytes: pubPoint.ToAffineUncompressed(),
+		PubHex:   hex.EncodeToString(pubPoint.ToAffineCompressed()),
+		Share:    share,
+		Nonce:    randNonce(),
+		Curve:    curve,
+		Party:    role,
+	}
+}
+
+// Split separates a combined enclave into its validator and user halves.
+func (k *EnclaveData) Split() (*ValidatorEnclave, *UserEnclave, error) {
+	if !k.IsValid() {
+		return nil, nil, errors.New("enclave does not hold both shares")
+	}
+	val, err := BuildValidatorEnclave(k.ValShare, k.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := BuildUserEnclave(k.UserShare, k.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	return val, user, nil
+}
+
+// Role returns the role of the party holding the share
+func (p *partyData) Role() Role {
+	return p.Party
+}
+
+// GetPubPoint returns the joint public point
+func (p *partyData) GetPubPoint() (curves.Point, error) {
+	curve, err := p.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	return curve.NewIdentityPoint().FromAffineUncompressed(p.PubBytes)
+}
+
+// PubKeyHex returns the joint public key
+func (p *partyData) PubKeyHex() string {
+	return p.PubHex
+}
+
+// PubKeyBytes returns the joint public key
+func (p *partyData) PubKeyBytes() []byte {
+	return p.PubBytes
+}
+
+// IsValid returns true if the party holds a share
+func (p *partyData) IsValid() bool {
+	return p.Share != nil && len(p.PubBytes) > 0
+}
+
+// Verify returns true if the signature is valid for the joint public key, or for the child key
+// selected with WithDerivationPath
+func (p *partyData) Verify(data []byte, sig []byte, opts ...SignOption) (bool, error) {
+	pubKey, err := newSignConfig(opts).verificationKey(p.PubBytes, p.DeriveChild)
+	if err != nil {
+		return false, err
+	}
+	return VerifyWithCurve(p.Curve, pubKey, data, sig, withoutDerivationPath(opts)...)
+}
+
+// Decrypt returns decrypted party data
+func (p *partyData) Decrypt(key []byte, encryptedData []byte) ([]byte, error) {
+	if IsEnvelope(encryptedData) {
+		return Open(encryptedData, key, p.associatedData())
+	}
+	return openLegacy(encryptedData, key, p.Nonce)
+}
+
+// Encrypt returns encrypted party data
+func (p *partyData) Encrypt(key []byte) ([]byte, error) {
+	return p.EncryptWithOptions(key)
+}
+
+// EncryptWithOptions returns encrypted party data using the given KDF and cipher options
+func (p *partyData) EncryptWithOptions(key []byte, opts ...SealOption) ([]byte, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize enclave: %w", err)
+	}
+	return Seal(data, key, p.associatedData(), opts...)
+}
+
+// associatedData binds a sealed half to both its role and the joint public key, so that
+// the validator and user blobs cannot be swapped for one another.
+func (p *partyData) associatedData() []byte {
+	if len(p.PubBytes) == 0 {
+		return nil
+	}
+	return append([]byte(p.Party+":"), p.PubBytes...)
+}
+
+// Marshal returns the JSON encoding of the party data
+func (p *partyData) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// Unmarshal parses the JSON-encoded party data
+func (p *partyData) Unmarshal(data []byte) error {
+	return json.Unmarshal(data, p)
+}
+
+// Sign takes part in signing data as the validator. The validator never learns the signature;
+// it is produced by the user on the other side of t.
+func (v *ValidatorEnclave) Sign(ctx context.Context, t transport.Transport, session transport.Session, data []byte, opts ...SignOption) error {
+	curve, err := v.Curve.ECDSACurve()
+	if err != nil {
+		return err
+	}
+	cfg := newSignConfig(opts)
+	msg, err := cfg.message(data)
+	if err != nil {
+		return err
+	}
+	tweak, err := cfg.keyTweak(v.DeriveChild)
+	if err != nil {
+		return err
+	}
+	signFunc, err := dklsv1.NewAliceSign(curve, cfg.newHash(), msg, v.Share, protocol.Version1)
+	if err != nil {
+		return err
+	}
+	signFunc.SetKeyTweak(tweak)
+	return transport.Run(ctx, t, session, signFunc, true)
+}
+
+// SignBatch takes part in signing every item of data, in a single run of the signing protocol, as the validator.
+// See Sign.
+func (v *ValidatorEnclave) SignBatch(ctx context.Context, t transport.Transport, session transport.Session, data [][]byte, opts ...SignOption) error {
+	curve, err := v.Curve.ECDSACurve()
+	if err != nil {
+		return err
+	}
+	cfg := newSignConfig(opts)
+	msgs, err := cfg.messages(data)
+	if err != nil {
+		return err
+	}
+	tweak, err := cfg.keyTweak(v.DeriveChild)
+	if err != nil {
+		return err
+	}
+	signFunc, err := dklsv1.NewAliceBatchSign(curve, cfg.newHash, msgs, v.Share, protocol.Version2)
+	if err != nil {
+		return err
+	}
+	signFunc.SetKeyTweak(tweak)
+	return transport.Run(ctx, t, session, signFunc, true)
+}
+
+// Refresh rotates the validator share together with the user on the other side of t.
+func (v *ValidatorEnclave) Refresh(ctx context.Context, t transport.Transport, session transport.Session) (*ValidatorEnclave, error) {
+	curve, err := v.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	refreshFunc, err := dklsv1.NewAliceRefresh(curve, v.Share, protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	if err := transport.Run(ctx, t, session, refreshFunc, true); err != nil {
+		return nil, err
+	}
+	share, err := refreshFunc.Result(protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	return BuildValidatorEnclave(share, v.Curve)
+}
+
+// Sign computes the signature of data together with the validator on the other side of t.
+func (u *UserEnclave) Sign(ctx context.Context, t transport.Transport, session transport.Session, data []byte, opts ...SignOption) ([]byte, error) {
+	s, err := u.SignRecoverable(ctx, t, session, data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return SerializeSignature(s)
+}
+
+// SignRecoverable computes the signature of data with its recovery id together with the
+// validator on the other side of t.
+func (u *UserEnclave) SignRecoverable(ctx context.Context, t transport.Transport, session transport.Session, data []byte, opts ...SignOption) (Signature, error) {
+	curve, err := u.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := newSignConfig(opts)
+	msg, err := cfg.message(data)
+	if err != nil {
+		return nil, err
+	}
+	tweak, err := cfg.keyTweak(u.DeriveChild)
+	if err != nil {
+		return nil, err
+	}
+	signFunc, err := dklsv1.NewBobSign(curve, cfg.newHash(), msg, u.Share, protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	signFunc.SetKeyTweak(tweak)
+	if err := transport.Run(ctx, t, session, signFunc, false); err != nil {
+		return nil, err
+	}
+	out, err := signFunc.Result(protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	return dklsv1.DecodeSignature(out)
+}
+
+// SignBatch computes the signatures of every item of data, in order, in a single run of the signing protocol
+// together with the validator on the other side of t.
+func (u *UserEnclave) SignBatch(ctx context.Context, t transport.Transport, session transport.Session, data [][]byte, opts ...SignOption) ([][]byte, error) {
+	curve, err := u.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := newSignConfig(opts)
+	msgs, err := cfg.messages(data)
+	if err != nil {
+		return nil, err
+	}
+	tweak, err := cfg.keyTweak(u.DeriveChild)
+	if err != nil {
+		return nil, err
+	}
+	signFunc, err := dklsv1.NewBobBatchSign(curve, cfg.newHash, msgs, u.Share, protocol.Version2)
+	if err != nil {
+		return nil, err
+	}
+	signFunc.SetKeyTweak(tweak)
+	if err := transport.Run(ctx, t, session, signFunc, false); err != nil {
+		return nil, err
+	}
+	out, err := signFunc.Result(protocol.Version2)
+	if err != nil {
+		return nil, err
+	}
+	return serializeBatch(out)
+}
+
+// Refresh rotates the user share together with the validator on the other side of t.
+func (u *UserEnclave) Refresh(ctx context.Context, t transport.Transport, session transport.Session) (*UserEnclave, error) {
+	curve, err := u.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	refreshFunc, err := dklsv1.NewBobRefresh(curve, u.Share, protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	if err := transport.Run(ctx, t, session, refreshFunc, false); err != nil {
+		return nil, err
+	}
+	share, err := refreshFunc.Result(protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	return BuildUserEnclave(share, u.Curve)
+}
@@ -0,0 +1,177 @@
+package mpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/mpc/transport"
+)
+
+func newTestParties(t *testing.T) (*ValidatorEnclave, *UserEnclave) {
+	t.Helper()
+	valT, userT := transport.NewMemoryPair()
+	defer valT.Close()
+
+	ctx := context.Background()
+	session := transport.Session{ID: "dkg", Timeout: 10 * time.Second}
+	valCh := make(chan *ValidatorEnclave, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		val, err := NewValidatorEnclave(ctx, valT, session, K256Name)
+		errCh <- err
+		valCh <- val
+	}()
+	user, err := NewUserEnclave(ctx, userT, session, K256Name)
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	return <-valCh, user
+}
+
+func signWithParties(t *testing.T, val *ValidatorEnclave, user *UserEnclave, data []byte, opts ...SignOption) []byte {
+	t.Helper()
+	valT, userT := transport.NewMemoryPair()
+	defer valT.Close()
+
+	ctx := context.Background()
+	session := transport.Session{ID: "sign", Timeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() { errCh <- val.Sign(ctx, valT, session, data, opts...) }()
+	sig, err := user.Sign(ctx, userT, session, data, opts...)
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	return sig
+}
+
+func TestPartyEnclave_DkgSignVerify(t *testing.T) {
+	val, user := newTestParties(t)
+	require.True(t, val.IsValid())
+	require.True(t, user.IsValid())
+	assert.Equal(t, val.PubKeyHex(), user.PubKeyHex())
+	assert.Equal(t, Role(RoleVal), val.Role())
+	assert.Equal(t, Role(RoleUser), user.Role())
+
+	data := []byte("test message")
+	sig := signWithParties(t, val, user, data)
+
+	valid, err := val.Verify(data, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = VerifyWithPubKey(user.PubKeyBytes(), data, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestPartyEnclave_SignBatch(t *testing.T) {
+	val, user := newTestParties(t)
+	valT, userT := transport.NewMemoryPair()
+	defer valT.Close()
+
+	ctx := context.Background()
+	session := transport.Session{ID: "sign-batch", Timeout: 10 * time.Second}
+	messages := [][]byte{[]byte("first message"), []byte("second message")}
+	opt := WithDerivationPath("m/0/2")
+	errCh := make(chan error, 1)
+	go func() { errCh <- val.SignBatch(ctx, valT, session, messages, opt) }()
+	sigs, err := user.SignBatch(ctx, userT, session, messages, opt)
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	require.Len(t, sigs, len(messages))
+	for i, sig := range sigs {
+		valid, err := user.Verify(messages[i], sig, opt)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+}
+
+func TestPartyEnclave_Refresh(t *testing.T) {
+	val, user := newTestParties(t)
+
+	valT, userT := transport.NewMemoryPair()
+	defer valT.Close()
+	ctx := context.Background()
+	session := transport.Session{ID: "refresh", Timeout: 10 * time.Second}
+
+	valCh := make(chan *ValidatorEnclave, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		refreshed, err := val.Refresh(ctx, valT, session)
+		errCh <- err
+		valCh <- refreshed
+	}()
+	newUser, err := user.Refresh(ctx, userT, session)
+	require.NoError(t, err)
+	require.NoError(t, <-errCh)
+	newVal := <-valCh
+
+	assert.Equal(t, val.PubKeyHex(), newVal.PubKeyHex())
+	assert.Equal(t, user.PubKeyHex(), newUser.PubKeyHex())
+
+	data := []byte("after refresh")
+	sig := signWithParties(t, newVal, newUser, data)
+	valid, err := newUser.Verify(data, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestPartyEnclave_Split(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+
+	val, user, err := enclave.GetData().Split()
+	require.NoError(t, err)
+	assert.Equal(t, enclave.PubKeyHex(), val.PubKeyHex())
+	assert.Equal(t, enclave.PubKeyHex(), user.PubKeyHex())
+
+	data := []byte("split enclave")
+	sig := signWithParties(t, val, user, data)
+	valid, err := enclave.Verify(data, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestPartyEnclave_ImportExport(t *testing.T) {
+	val, user := newTestParties(t)
+	key := []byte("test-key-12345678-test-key-123456")
+
+	valBytes, err := val.Marshal()
+	require.NoError(t, err)
+	userBytes, err := user.Marshal()
+	require.NoError(t, err)
+
+	importedVal, err := ImportValidatorEnclave(WithEnclaveJSON(valBytes))
+	require.NoError(t, err)
+	importedUser, err := ImportUserEnclave(WithEnclaveJSON(userBytes))
+	require.NoError(t, err)
+	assert.Equal(t, val.PubKeyHex(), importedVal.PubKeyHex())
+	assert.Equal(t, user.PubKeyHex(), importedUser.PubKeyHex())
+
+	// Each half refuses the other's blob
+	_, err = ImportValidatorEnclave(WithEnclaveJSON(userBytes))
+	assert.Error(t, err)
+	_, err = ImportUserEnclave(WithEnclaveJSON(valBytes))
+	assert.Error(t, err)
+
+	// Halves can be rebuilt from their shares alone
+	fromShare, err := ImportUserEnclave(WithUserShare(user.Share, K256Name))
+	require.NoError(t, err)
+	assert.Equal(t, user.PubKeyHex(), fromShare.PubKeyHex())
+
+	encrypted, err := importedVal.Encrypt(key)
+	require.NoError(t, err)
+	decrypted, err := importedVal.Decrypt(key, encrypted)
+	require.NoError(t, err)
+	restored, err := ImportValidatorEnclave(WithEnclaveJSON(decrypted))
+	require.NoError(t, err)
+	_, err = user.Decrypt(key, encrypted)
+	assert.ErrorIs(t, err, ErrAssociatedData, "sealed halves are bound to their role")
+
+	data := []byte("imported halves")
+	sig := signWithParties(t, restored, importedUser, data)
+	valid, err := restored.Verify(data, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
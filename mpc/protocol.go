@@ -5,9 +5,13 @@ import (
 	"github.com/sonr-io/crypto/tecdsa/dklsv1"
 )
 
-// NewEnclave generates a new MPC keyshare
-func NewEnclave() (Enclave, error) {
-	curve := K256Name.Curve()
+// NewEnclave generates a new MPC keyshare over the given curve. Use K256Name for
+// blockchain accounts and P256Name for WebAuthn and Secure Enclave interop.
+func NewEnclave(curveName CurveName) (Enclave, error) {
+	curve, err := curveName.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
 	valKs := dklsv1.NewAliceDkg(curve, protocol.Version1)
 	userKs := dklsv1.NewBobDkg(curve, protocol.Version1)
 	aErr, bErr := RunProtocol(userKs, valKs)
@@ -22,28 +26,44 @@ func NewEnclave() (Enclave, error) {
 	if err != nil {
 		return nil, err
 	}
-	return ImportEnclave(WithInitialShares(valRes, userRes, K256Name))
+	return ImportEnclave(WithInitialShares(valRes, userRes, curveName))
 }
 
 // ExecuteSigning runs the MPC signing protocol
 func ExecuteSigning(signFuncVal SignFunc, signFuncUser SignFunc) ([]byte, error) {
+	s, err := ExecuteSigningRecoverable(signFuncVal, signFuncUser)
+	if err != nil {
+		return nil, err
+	}
+	return SerializeSignature(s)
+}
+
+// ExecuteBatchSigning runs the MPC batch signing protocol and returns the signatures in the order of the messages
+func ExecuteBatchSigning(signFuncVal SignFunc, signFuncUser SignFunc) ([][]byte, error) {
 	aErr, bErr := RunProtocol(signFuncVal, signFuncUser)
 	if err := CheckIteratedErrors(aErr, bErr); err != nil {
 		return nil, err
 	}
-	out, err := signFuncUser.Result(protocol.Version1)
+	out, err := signFuncUser.Result(protocol.Version2)
 	if err != nil {
 		return nil, err
 	}
-	s, err := dklsv1.DecodeSignature(out)
+	return serializeBatch(out)
+}
+
+// serializeBatch decodes the output of a batch sign protocol and serializes each signature
+func serializeBatch(out *protocol.Message) ([][]byte, error) {
+	sigs, err := dklsv1.DecodeSignatures(out)
 	if err != nil {
 		return nil, err
 	}
-	sig, err := SerializeSignature(s)
-	if err != nil {
-		return nil, err
+	serialized := make([][]byte, len(sigs))
+	for i, sig := range sigs {
+		if serialized[i], err = SerializeSignature(sig); err != nil {
+			return nil, err
+		}
 	}
-	return sig, nil
+	return serialized, nil
 }
 
 // ExecuteRefresh runs the MPC refresh protocol
@@ -0,0 +1,174 @@
+package mpc
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1"
+)
+
+// ExecuteSigningRecoverable runs the MPC signing protocol and returns the low-S signature
+// together with the recovery id computed by the DKLs sign output.
+func ExecuteSigningRecoverable(signFuncVal SignFunc, signFuncUser SignFunc) (Signature, error) {
+	aErr, bErr := RunProtocol(signFuncVal, signFuncUser)
+	if err := CheckIteratedErrors(aErr, bErr); err != nil {
+		return nil, err
+	}
+	out, err := signFuncUser.Result(protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	return dklsv1.DecodeSignature(out)
+}
+
+// SignRecoverable returns the signature of the data with its recovery id
+func (k *EnclaveData) SignRecoverable(data []byte, opts ...SignOption) (Signature, error) {
+	userSign, err := GetBobSignFunc(k, data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	valSign, err := GetAliceSignFunc(k, data, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ExecuteSigningRecoverable(valSign, userSign)
+}
+
+// SerializeEthereumSignature encodes a signature as the 65-byte r || s || v form accepted by
+// ecrecover, with v = 27 + recovery id.
+func SerializeEthereumSignature(sig Signature) ([]byte, error) {
+	rs, err := SerializeSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	if sig.V < 0 || sig.V > 1 {
+		return nil, fmt.Errorf("recovery id %d cannot be encoded in an ethereum signature", sig.V)
+	}
+	return append(rs, byte(27+sig.V)), nil
+}
+
+// SerializeCompactSignature encodes a signature in the 65-byte Bitcoin compact form
+// header || r || s, where header = 27 + recovery id (+4 for compressed public keys).
+func SerializeCompactSignature(sig Signature, compressed bool) ([]byte, error) {
+	rs, err := SerializeSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	if sig.V < 0 || sig.V > 3 {
+		return nil, fmt.Errorf("invalid recovery id %d", sig.V)
+	}
+	header := byte(27 + sig.V)
+	if compressed {
+		header += 4
+	}
+	return append([]byte{header}, rs...), nil
+}
+
+// SerializeDERSignature encodes a signature as ASN.1 DER, normalizing S to the lower half of
+// the curve order.
+func SerializeDERSignature(curve CurveName, sig Signature) ([]byte, error) {
+	if sig == nil || sig.R == nil || sig.S == nil {
+		return nil, errors.New("nil signature")
+	}
+	crv, err := curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	ec, err := crv.ToEllipticCurve()
+	if err != nil {
+		return nil, err
+	}
+	n := ec.Params().N
+	s := new(big.Int).Set(sig.S)
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{sig.R, s})
+}
+
+// DeserializeEthereumSignature parses a 65-byte r || s || v signature. Both raw recovery ids
+// and the legacy 27/28 encoding are accepted for v.
+func DeserializeEthereumSignature(sigBytes []byte) (Signature, error) {
+	if len(sigBytes) != 65 {
+		return nil, fmt.Errorf("invalid signature length: expected 65 bytes, got %d", len(sigBytes))
+	}
+	sig, err := DeserializeSignature(sigBytes[:64])
+	if err != nil {
+		return nil, err
+	}
+	v := int(sigBytes[64])
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("invalid recovery id %d", sigBytes[64])
+	}
+	sig.V = v
+	return sig, nil
+}
+
+// DeserializeCompactSignature parses a 65-byte Bitcoin compact signature and reports whether it
+// commits to a compressed public key.
+func DeserializeCompactSignature(sigBytes []byte) (Signature, bool, error) {
+	if len(sigBytes) != 65 {
+		return nil, false, fmt.Errorf("invalid signature length: expected 65 bytes, got %d", len(sigBytes))
+	}
+	header := int(sigBytes[0]) - 27
+	if header < 0 || header > 7 {
+		return nil, false, fmt.Errorf("invalid compact signature header %d", sigBytes[0])
+	}
+	sig, err := DeserializeSignature(sigBytes[1:])
+	if err != nil {
+		return nil, false, err
+	}
+	sig.V = header & 3
+	return sig, header&4 != 0, nil
+}
+
+// RecoverPublicKey recovers the uncompressed public key from a 65-byte Ethereum-style signature
+// over data. The options must match the ones the data was signed with.
+func RecoverPublicKey(curve CurveName, data []byte, sig []byte, opts ...SignOption) ([]byte, error) {
+	parsed, err := DeserializeEthereumSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	return recoverPublicKey(curve, data, parsed, opts)
+}
+
+// RecoverPublicKeyCompact recovers the public key from a 65-byte Bitcoin compact signature over
+// data, in the compressed or uncompressed form the signature header commits to.
+func RecoverPublicKeyCompact(curve CurveName, data []byte, sig []byte, opts ...SignOption) ([]byte, error) {
+	parsed, compressed, err := DeserializeCompactSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := recoverPublicKey(curve, data, parsed, opts)
+	if err != nil || !compressed {
+		return pub, err
+	}
+	point, err := curve.Curve().NewIdentityPoint().FromAffineUncompressed(pub)
+	if err != nil {
+		return nil, err
+	}
+	return point.ToAffineCompressed(), nil
+}
+
+func recoverPublicKey(curve CurveName, data []byte, sig Signature, opts []SignOption) ([]byte, error) {
+	crv, err := curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := newSignConfig(opts).digest(data)
+	if err != nil {
+		return nil, err
+	}
+	point, err := curves.RecoverEcdsaPublicKey(crv, digest, sig)
+	if err != nil {
+		return nil, err
+	}
+	return point.ToAffineUncompressed(), nil
+}
@@ -0,0 +1,102 @@
+package mpc
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	btcecdsa "github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignRecoverable(t *testing.T) {
+	for _, curve := range []CurveName{K256Name, P256Name} {
+		t.Run(curve.String(), func(t *testing.T) {
+			enclave, err := NewEnclave(curve)
+			require.NoError(t, err)
+			data := enclave.GetData()
+			msg := []byte("recover me")
+
+			sig, err := data.SignRecoverable(msg, WithEthereumPersonal())
+			require.NoError(t, err)
+
+			ethSig, err := SerializeEthereumSignature(sig)
+			require.NoError(t, err)
+			require.Len(t, ethSig, 65)
+			assert.Contains(t, []byte{27, 28}, ethSig[64])
+
+			pub, err := RecoverPublicKey(curve, msg, ethSig, WithEthereumPersonal())
+			require.NoError(t, err)
+			assert.Equal(t, data.PubBytes, pub)
+
+			// A flipped recovery id yields a different key
+			ethSig[64] ^= 1
+			pub, err = RecoverPublicKey(curve, msg, ethSig, WithEthereumPersonal())
+			if err == nil {
+				assert.NotEqual(t, data.PubBytes, pub)
+			}
+
+			compact, err := SerializeCompactSignature(sig, true)
+			require.NoError(t, err)
+			pub, err = RecoverPublicKeyCompact(curve, msg, compact, WithEthereumPersonal())
+			require.NoError(t, err)
+			assert.Equal(t, data.PubHex, hex.EncodeToString(pub))
+
+			der, err := SerializeDERSignature(curve, sig)
+			require.NoError(t, err)
+			ePub, err := GetCurveECDSAPoint(curve, data.PubBytes)
+			require.NoError(t, err)
+			digest, err := newSignConfig([]SignOption{WithEthereumPersonal()}).digest(msg)
+			require.NoError(t, err)
+			assert.True(t, ecdsa.VerifyASN1(&ecdsa.PublicKey{Curve: ePub.Curve, X: ePub.X, Y: ePub.Y}, digest, der))
+		})
+	}
+}
+
+func TestSignRecoverable_MatchesBtcec(t *testing.T) {
+	enclave, err := NewEnclave(K256Name)
+	require.NoError(t, err)
+	data := enclave.GetData()
+	msg := []byte("bitcoin message")
+
+	for i := 0; i < 4; i++ {
+		sig, err := data.SignRecoverable(msg, WithDoubleSHA256())
+		require.NoError(t, err)
+		compact, err := SerializeCompactSignature(sig, true)
+		require.NoError(t, err)
+
+		digest, err := newSignConfig([]SignOption{WithDoubleSHA256()}).digest(msg)
+		require.NoError(t, err)
+		pub, wasCompressed, err := btcecdsa.RecoverCompact(compact, digest)
+		require.NoError(t, err)
+		assert.True(t, wasCompressed)
+		assert.Equal(t, data.PubBytes, pub.SerializeUncompressed())
+
+		// btcec rejects high-S signatures when parsing DER strictly
+		der, err := SerializeDERSignature(K256Name, sig)
+		require.NoError(t, err)
+		parsed, err := btcecdsa.ParseDERSignature(der)
+		require.NoError(t, err)
+		btcPub, err := btcec.ParsePubKey(data.PubBytes)
+		require.NoError(t, err)
+		assert.True(t, parsed.Verify(digest, btcPub))
+	}
+}
+
+func TestDeserializeEthereumSignature(t *testing.T) {
+	sig := make([]byte, 65)
+	sig[31], sig[63] = 1, 1
+	for v, want := range map[byte]int{0: 0, 1: 1, 27: 0, 28: 1} {
+		sig[64] = v
+		parsed, err := DeserializeEthereumSignature(sig)
+		require.NoError(t, err)
+		assert.Equal(t, want, parsed.V)
+	}
+	sig[64] = 29
+	_, err := DeserializeEthereumSignature(sig)
+	assert.Error(t, err)
+	_, err = DeserializeEthereumSignature(sig[:64])
+	assert.Error(t, err)
+}
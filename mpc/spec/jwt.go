@@ -1,12 +1,16 @@
 package spec
 
 import (
-	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/sonr-io/crypto/mpc"
 )
 
-// MPCSigningMethod implements the SigningMethod interface for MPC-based signing
+// MPCSigningMethod implements the SigningMethod interface for MPC-based signing.
+// The signing string is hashed with SHA-256 and signed as a 64-byte r || s secp256k1 signature.
 type MPCSigningMethod struct {
 	Name string
 	ks   ucanKeyshare
@@ -25,52 +29,38 @@ func (m *MPCSigningMethod) Alg() string {
 	return m.Name
 }
 
-// Verify verifies the signature using the MPC public key
+// Verify verifies the signature using the MPC public key. The key is the compressed or uncompressed
+// secp256k1 public key of the issuer, as returned by keys.DID.VerifyKey.
 func (m *MPCSigningMethod) Verify(signingString, signature string, key any) error {
-	// // Decode the signature
-	// sig, err := base64.RawURLEncoding.DecodeString(signature)
-	// if err != nil {
-	// 	return err
-	// }
-	//
-	// // Hash the signing string
-	// hasher := sha256.New()
-	// hasher.Write([]byte(signingString))
-	// digest := hasher.Sum(nil)
-	// valid, err := m.ks.valShare.PublicKey().Verify(digest, sig)
-	// if !valid || err != nil {
-	// 	return fmt.Errorf("invalid signature")
-	// }
+	pubKey, ok := key.([]byte)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return err
+	}
+	valid, err := mpc.VerifyWithPubKey(pubKey, []byte(signingString), sig, mpc.WithSHA256())
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if !valid {
+		return jwt.ErrSignatureInvalid
+	}
 	return nil
 }
 
-// Sign signs the data using MPC
+// Sign signs the data using MPC. The key is ignored; the signature is produced by the enclave of the
+// keyshare source the method was created with.
 func (m *MPCSigningMethod) Sign(signingString string, key any) (string, error) {
-	// Hash the signing string
-	hasher := sha256.New()
-	hasher.Write([]byte(signingString))
-	// digest := hasher.Sum(nil)
-	//
-	// // Create signing functions
-	// signFunc, err := m.ks.userShare.SignFunc(digest)
-	// if err != nil {
-	// 	return "", fmt.Errorf("failed to create sign function: %w", err)
-	// }
-	//
-	// valSignFunc, err := m.ks.valShare.SignFunc(digest)
-	// if err != nil {
-	// 	return "", fmt.Errorf("failed to create validator sign function: %w", err)
-	// }
-
-	// // Run the signing protocol
-	// sig, err := mpc.ExecuteSigning(valSignFunc, signFunc)
-	// if err != nil {
-	// 	return "", fmt.Errorf("failed to run sign protocol: %w", err)
-	// }
-
-	// Encode the signature
-	// encoded := base64.RawURLEncoding.EncodeToString(sig)
-	return "", nil
+	if m.ks.enclave == nil {
+		return "", errors.New("mpc signing method has no enclave")
+	}
+	sig, err := m.ks.enclave.Sign([]byte(signingString), mpc.WithSHA256())
+	if err != nil {
+		return "", fmt.Errorf("failed to run sign protocol: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(sig), nil
 }
 
 func init() {
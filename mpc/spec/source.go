@@ -2,12 +2,14 @@ package spec
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sonr-io/crypto/keys"
+	"github.com/sonr-io/crypto/mpc"
 	"github.com/sonr-io/crypto/ucan"
-	"lukechampine.com/blake3"
 )
 
 type KeyshareSource interface {
@@ -20,20 +22,34 @@ type KeyshareSource interface {
 	SignData(data []byte) ([]byte, error)
 	VerifyData(data []byte, sig []byte) (bool, error)
 	UCANParser() *ucan.TokenParser
+	Signer() ucan.Signer
+}
+
+// NewSource creates a UCAN source that signs tokens with an MPC enclave. The issuer DID and address
+// are derived from the joint public key, which must be on secp256k1.
+func NewSource(ks mpc.Enclave) (KeyshareSource, error) {
+	if ks == nil || !ks.IsValid() {
+		return nil, errors.New("enclave must hold both keyshares")
+	}
+	data := ks.GetData()
+	if data.Curve != mpc.K256Name {
+		return nil, fmt.Errorf("%w: ucan issuers must be secp256k1", mpc.ErrUnsupportedCurve)
+	}
+	pubPoint, err := data.GetPubPoint()
+	if err != nil {
+		return nil, err
+	}
+	iss, addr, err := GetIssuerDID(keys.NewPubKey(pubPoint))
+	if err != nil {
+		return nil, err
+	}
+	return ucanKeyshare{
+		enclave:   ks,
+		issuerDID: iss,
+		addr:      addr,
+	}, nil
 }
 
-//	func NewSource(ks mpc.KeyEnclave) (KeyshareSource, error) {
-//		iss, addr, err := getIssuerDID(val.PublicKey())
-//		if err != nil {
-//			return nil, err
-//		}
-//
-//		return ucanKeyshare{
-//			issuerDID: iss,
-//			addr:      addr,
-//		}, nil
-//	}
-//
 // Address returns the address of the keyshare
 func (k ucanKeyshare) Address() string {
 	return k.addr
@@ -44,51 +60,72 @@ func (k ucanKeyshare) Issuer() string {
 	return k.issuerDID
 }
 
-// ChainCode returns the chain code of the keyshare
+// ChainCode returns the BIP32 chain code agreed on by the keyshares during DKG
 func (k ucanKeyshare) ChainCode() ([]byte, error) {
-	sig, err := k.SignData([]byte(k.addr))
-	if err != nil {
-		return nil, err
-	}
-	hash := blake3.Sum256(sig)
-	// Return the first 32 bytes of the hash
-	return hash[:32], nil
+	return k.enclave.GetData().ChainCode()
 }
 
-// DefaultOriginToken returns a default token with the keyshare's issuer as the audience
+// OriginToken returns a token with the keyshare's issuer as the audience, granting every account
+// and vault capability over the enclave address
 func (k ucanKeyshare) OriginToken() (*Token, error) {
-	// att := ucan.NewSmartAccount(k.addr)
+	var att Attenuations
+	for _, kind := range []ucan.Permissions{ucan.AccountPermissions, ucan.VaultPermissions} {
+		a, err := kind.NewAttenuation(kind.GetCapabilities().Root().String(), k.addr)
+		if err != nil {
+			return nil, err
+		}
+		att = append(att, a)
+	}
 	zero := time.Time{}
-	// return k.NewOriginToken(k.issuerDID, att, nil, zero, zero)
-	return k.newToken(k.issuerDID, nil, nil, nil, zero, zero)
+	return k.NewOriginToken(k.issuerDID, att, nil, zero, zero)
 }
 
+// SignData signs data with the enclave by running the DKLs signing protocol
 func (k ucanKeyshare) SignData(data []byte) ([]byte, error) {
-	// // Create signing functions
-	// signFunc, err := k.userShare.SignFunc(data)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to create sign function: %w", err)
-	// }
-	//
-	// valSignFunc, err := k.valShare.SignFunc(data)
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to create validator sign function: %w", err)
-	// }
-
-	// Run the signing protocol
-	// return mpc.ExecuteSigning(valSignFunc, signFunc)
-	return nil, nil
+	if k.enclave == nil {
+		return nil, errors.New("keyshare source has no enclave")
+	}
+	return k.enclave.Sign(data)
 }
 
+// VerifyData returns true if sig is a signature of data by the enclave
 func (k ucanKeyshare) VerifyData(data []byte, sig []byte) (bool, error) {
-	return false, nil
-	// return k.valShare.PublicKey().Verify(data, sig)
+	if k.enclave == nil {
+		return false, errors.New("keyshare source has no enclave")
+	}
+	return k.enclave.Verify(data, sig)
 }
 
-// TokenParser returns a token parser that can be used to parse tokens
+// Signer returns a signer for UCAN 1.0 envelopes issued by the enclave
+func (k ucanKeyshare) Signer() ucan.Signer {
+	return enclaveSigner{k}
+}
+
+// enclaveSigner signs envelopes with the enclave as 64 byte r || s signatures over the SHA-256 of
+// the payload, the same encoding MPCSigningMethod uses for JWTs
+type enclaveSigner struct {
+	ks ucanKeyshare
+}
+
+func (s enclaveSigner) Issuer() string                  { return s.ks.issuerDID }
+func (s enclaveSigner) Algorithm() ucan.VarsigAlgorithm { return ucan.VarsigES256K }
+
+func (s enclaveSigner) Sign(payload []byte) ([]byte, error) {
+	if s.ks.enclave == nil {
+		return nil, errors.New("keyshare source has no enclave")
+	}
+	return s.ks.enclave.Sign(payload, mpc.WithSHA256())
+}
+
+// UCANParser returns a token parser that can be used to parse tokens. Attenuations of the ucan
+// permission types are parsed into their capability hierarchies, others into string resources and
+// capabilities.
 func (k ucanKeyshare) UCANParser() *ucan.TokenParser {
-	caps := ucan.AccountPermissions.GetCapabilities()
 	ac := func(m map[string]any) (ucan.Attenuation, error) {
+		att, err := ucan.ParseAttenuationData(m)
+		if !errors.Is(err, ucan.ErrUnknownPermissions) {
+			return att, err
+		}
 		var (
 			cap string
 			rsc ucan.Resource
@@ -102,12 +139,16 @@ func (k ucanKeyshare) UCANParser() *ucan.TokenParser {
 			if key == ucan.CapKey {
 				cap = val
 			} else {
+				rsc = stringResource{typ: key, value: val}
 			}
 		}
+		if cap == "" || rsc == nil {
+			return ucan.Attenuation{}, fmt.Errorf("attenuation must have a capability and a resource")
+		}
 
 		return ucan.Attenuation{
 			Rsc: rsc,
-			Cap: caps.Cap(cap),
+			Cap: stringCapability(cap),
 		}, nil
 	}
 
@@ -115,12 +156,55 @@ func (k ucanKeyshare) UCANParser() *ucan.TokenParser {
 	return ucan.NewTokenParser(ac, customDIDPubKeyResolver{}, store.(ucan.CIDBytesResolver))
 }
 
+// stringResource is a resource identified by its type and value. A value ending in "*" contains
+// every value with the same prefix.
+type stringResource struct {
+	typ   string
+	value string
+}
+
+// NewResource returns a resource that MPC-issued UCANs can be attenuated to. Use ucan.NewResource
+// for the ucan permission types.
+func NewResource(typ, value string) ucan.Resource {
+	return stringResource{typ: typ, value: value}
+}
+
+func (r stringResource) Type() string  { return r.typ }
+func (r stringResource) Value() string { return r.value }
+
+func (r stringResource) Contains(b ucan.Resource) bool {
+	if b == nil || r.typ != b.Type() {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.value, "*"); ok {
+		return strings.HasPrefix(b.Value(), prefix)
+	}
+	return r.value == b.Value()
+}
+
+// stringCapability is a capability that only contains itself, or every capability if it is "*".
+type stringCapability string
+
+// NewCapability returns a capability that MPC-issued UCANs can be attenuated to
+func NewCapability(cap string) ucan.Capability {
+	return stringCapability(cap)
+}
+
+func (c stringCapability) String() string { return string(c) }
+
+func (c stringCapability) Contains(b ucan.Capability) bool {
+	return c == "*" || (b != nil && string(c) == b.String())
+}
+
 // customDIDPubKeyResolver implements the DIDPubKeyResolver interface without
 // any network backing. Works if the key string given contains the public key
-// itself
+// itself, which is the case for did:key and for the did:sonr issuers of MPC enclaves
 type customDIDPubKeyResolver struct{}
 
-// ResolveDIDKey extracts a public key from  a did:key string
+// ResolveDIDKey extracts a public key from a did:key or did:sonr string
 func (customDIDPubKeyResolver) ResolveDIDKey(ctx context.Context, didStr string) (keys.DID, error) {
+	if strings.HasPrefix(didStr, sonrDIDPrefix) {
+		return ParseIssuerDID(didStr)
+	}
 	return keys.Parse(didStr)
 }
@@ -0,0 +1,147 @@
+package spec
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/mpc"
+	"github.com/sonr-io/crypto/ucan"
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+func newTestSource(t *testing.T) KeyshareSource {
+	t.Helper()
+	enclave, err := mpc.NewEnclave(mpc.K256Name)
+	require.NoError(t, err)
+	source, err := NewSource(enclave)
+	require.NoError(t, err)
+	return source
+}
+
+func TestNewSource(t *testing.T) {
+	source := newTestSource(t)
+	assert.Equal(t, sonrDIDPrefix+source.Address(), source.Issuer())
+
+	id, err := ParseIssuerDID(source.Issuer())
+	require.NoError(t, err)
+	raw, err := id.Raw()
+	require.NoError(t, err)
+	assert.Len(t, raw, 33)
+
+	chainCode, err := source.ChainCode()
+	require.NoError(t, err)
+	assert.Len(t, chainCode, 32)
+
+	p256, err := mpc.NewEnclave(mpc.P256Name)
+	require.NoError(t, err)
+	_, err = NewSource(p256)
+	require.ErrorIs(t, err, mpc.ErrUnsupportedCurve)
+}
+
+func TestSource_SignVerifyData(t *testing.T) {
+	source := newTestSource(t)
+	data := []byte("hello")
+	sig, err := source.SignData(data)
+	require.NoError(t, err)
+
+	valid, err := source.VerifyData(data, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = source.VerifyData([]byte("other"), sig)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestSource_TokensVerify(t *testing.T) {
+	ctx := context.Background()
+	source := newTestSource(t)
+	parser := source.UCANParser()
+
+	origin, err := source.OriginToken()
+	require.NoError(t, err)
+	parsed, err := parser.ParseAndVerify(ctx, origin.Raw)
+	require.NoError(t, err)
+	issuer, err := ParseIssuerDID(source.Issuer())
+	require.NoError(t, err)
+	assert.True(t, issuer.Equals(parsed.Issuer.PubKey))
+	require.Len(t, parsed.Attenuations, 2)
+	assert.Equal(t, "account", parsed.Attenuations[0].Cap.String())
+	assert.Equal(t, source.Address(), parsed.Attenuations[1].Rsc.Value())
+
+	vaultRoot, err := ucan.VaultPermissions.NewAttenuation("/vault", "*")
+	require.NoError(t, err)
+	att := Attenuations{vaultRoot}
+	root, err := source.NewOriginToken(source.Issuer(), att, nil, time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = parser.ParseAndVerify(ctx, root.Raw)
+	require.NoError(t, err)
+
+	childAtt := Attenuations{{Rsc: ucan.NewResource(ucan.VaultPermissions, "keys/1"), Cap: ucan.VaultPermissions.NewCap("sign")}}
+	child, err := source.NewAttenuatedToken(root, source.Issuer(), childAtt, nil, time.Time{}, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []ucan.Proof{ucan.Proof(root.Raw)}, child.Proofs)
+
+	parsed, err = parser.ParseAndVerify(ctx, child.Raw)
+	require.NoError(t, err)
+	require.Len(t, parsed.Attenuations, 1)
+	assert.Equal(t, "sign", parsed.Attenuations[0].Cap.String())
+	assert.Equal(t, []ucan.Proof{ucan.Proof(root.Raw)}, parsed.Proofs)
+
+	// Attenuations can only narrow the parent's scope
+	_, err = source.NewAttenuatedToken(child, source.Issuer(), att, nil, time.Time{}, time.Time{})
+	require.Error(t, err)
+}
+
+func TestSource_TamperedTokenFails(t *testing.T) {
+	source := newTestSource(t)
+	other := newTestSource(t)
+
+	tok, err := source.OriginToken()
+	require.NoError(t, err)
+	otherTok, err := other.OriginToken()
+	require.NoError(t, err)
+
+	// Swap the signature of another enclave's token in
+	parts := strings.Split(tok.Raw, ".")
+	otherParts := strings.Split(otherTok.Raw, ".")
+	forged := parts[0] + "." + parts[1] + "." + otherParts[2]
+	_, err = source.UCANParser().ParseAndVerify(context.Background(), forged)
+	require.Error(t, err)
+}
+
+func TestSource_SignsEnvelopes(t *testing.T) {
+	ctx := context.Background()
+	source := newTestSource(t)
+	signer := source.Signer()
+	assert.Equal(t, source.Issuer(), signer.Issuer())
+
+	d, err := ucan.NewDelegation(signer, &ucanv1.DelegationPayload{
+		Audience: source.Issuer(),
+		Subject:  source.Issuer(),
+		Command:  "/vault/sign",
+	})
+	require.NoError(t, err)
+
+	parser := source.UCANParser()
+	parsed, err := parser.ParseDelegation(ctx, d.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucan.VarsigES256K, parsed.Algorithm)
+
+	// the 0.7 parser reads the envelope during migration
+	tok, err := parser.ParseAndVerify(ctx, string(d.Raw))
+	require.NoError(t, err)
+	require.Len(t, tok.Attenuations, 1)
+	cap, ok := tok.Attenuations[0].Cap.(ucan.HierarchicalCapability)
+	require.True(t, ok)
+	assert.Equal(t, "/vault/sign", cap.Command())
+	assert.Equal(t, source.Issuer(), tok.Attenuations[0].Rsc.Value())
+
+	_, err = parser.ParseDelegation(ctx, append(d.Raw[:len(d.Raw)-1:len(d.Raw)-1], 0))
+	require.Error(t, err)
+}
@@ -2,11 +2,14 @@ package spec
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cosmos/cosmos-sdk/types/bech32"
 	"github.com/golang-jwt/jwt"
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/sonr-io/crypto/keys"
+	"github.com/sonr-io/crypto/mpc"
 	"github.com/sonr-io/crypto/ucan"
 )
 
@@ -28,7 +31,13 @@ var (
 	CapKey         = ucan.CapKey
 )
 
+const (
+	sonrDIDPrefix = "did:sonr:" // sonrDIDPrefix prefixes the bech32 address in the issuer DID of an enclave
+	addressHRP    = "idx"       // addressHRP is the bech32 prefix of enclave addresses
+)
+
 type ucanKeyshare struct {
+	enclave   mpc.Enclave
 	addr      string
 	issuerDID string
 }
@@ -41,7 +50,7 @@ func (k ucanKeyshare) NewAttenuatedToken(parent *Token, audienceDID string, att
 	if !parent.Attenuations.Contains(att) {
 		return nil, fmt.Errorf("scope of ucan attenuations must be less than it's parent")
 	}
-	return k.newToken(audienceDID, append(parent.Proofs, Proof(parent.Raw)), att, fct, nbf, exp)
+	return k.newToken(audienceDID, []Proof{Proof(parent.Raw)}, att, fct, nbf, exp)
 }
 
 func (k ucanKeyshare) newToken(audienceDID string, prf []Proof, att Attenuations, fct []Fact, nbf, exp time.Time) (*ucan.Token, error) {
@@ -94,9 +103,29 @@ func (k ucanKeyshare) newToken(audienceDID string, prf []Proof, att Attenuations
 }
 
 func GetIssuerDID(pk keys.PubKey) (string, string, error) {
-	addr, err := bech32.ConvertAndEncode("idx", pk.Bytes())
+	addr, err := bech32.ConvertAndEncode(addressHRP, pk.Bytes())
 	if err != nil {
 		return "", "", err
 	}
-	return fmt.Sprintf("did:sonr:%s", addr), addr, nil
+	return fmt.Sprintf("%s%s", sonrDIDPrefix, addr), addr, nil
+}
+
+// ParseIssuerDID returns the public key embedded in an issuer DID created by GetIssuerDID
+func ParseIssuerDID(didStr string) (keys.DID, error) {
+	addr, ok := strings.CutPrefix(didStr, sonrDIDPrefix)
+	if !ok {
+		return keys.DID{}, fmt.Errorf("decentralized identifier is not a 'sonr' type")
+	}
+	hrp, bz, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return keys.DID{}, fmt.Errorf("decoding address: %w", err)
+	}
+	if hrp != addressHRP {
+		return keys.DID{}, fmt.Errorf("unexpected address prefix %q", hrp)
+	}
+	pub, err := p2pcrypto.UnmarshalSecp256k1PublicKey(bz)
+	if err != nil {
+		return keys.DID{}, fmt.Errorf("failed to unmarshal Secp256k1 key: %w", err)
+	}
+	return keys.DID{PubKey: pub}, nil
 }
@@ -0,0 +1,310 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+const (
+	// SessionParam is the query parameter that carries the session id on HTTP polling requests.
+	SessionParam = "session"
+
+	// RoundParam is the query parameter that carries the round a polling request expects. Asking for a
+	// round acknowledges the envelopes of the rounds before it.
+	RoundParam = "round"
+)
+
+// HTTPServer exposes one mailbox per session over plain HTTP so that a party behind a
+// request/response API can take part in a protocol run.
+//
+// The remote party POSTs envelopes to the server and long-polls with GET ?session=<id>&round=<n>
+// for replies. An envelope stays available until a request for a later round acknowledges it, so
+// a poll whose response was lost can be repeated. Locally, the party obtains its end of the
+// exchange with Session.
+type HTTPServer struct {
+	// Authorize, if set, is called before a request reaches the mailbox of a session, and the request
+	// is refused with 401 Unauthorized if it returns an error. Servers reachable by parties other
+	// than the peer of each session must set it, e.g. to check a token bound to the session.
+	Authorize func(r *http.Request, session string) error
+
+	mu        sync.Mutex
+	mailboxes map[string]*httpMailbox
+}
+
+type httpMailbox struct {
+	inbox  chan *Envelope
+	outbox chan *Envelope
+	done   chan struct{}
+	once   sync.Once
+
+	mu   sync.Mutex
+	last *Envelope // last envelope taken from outbox, until the peer acknowledges it
+}
+
+// NewHTTPServer creates an HTTPServer with no open sessions.
+func NewHTTPServer() *HTTPServer {
+	return &HTTPServer{mailboxes: make(map[string]*httpMailbox)}
+}
+
+// Session returns the server side Transport for the given session id, creating it if needed.
+// Closing the returned transport discards the session.
+func (s *HTTPServer) Session(id string) Transport {
+	return &httpServerTransport{server: s, id: id, box: s.mailbox(id)}
+}
+
+func (s *HTTPServer) mailbox(id string) *httpMailbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	box, ok := s.mailboxes[id]
+	if !ok {
+		box = &httpMailbox{
+			inbox:  make(chan *Envelope, 1),
+			outbox: make(chan *Envelope, 1),
+			done:   make(chan struct{}),
+		}
+		s.mailboxes[id] = box
+	}
+	return box
+}
+
+func (s *HTTPServer) lookup(id string) (*httpMailbox, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	box, ok := s.mailboxes[id]
+	return box, ok
+}
+
+func (s *HTTPServer) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mailboxes, id)
+}
+
+// authorize runs the Authorize hook of the server, if any, and writes the refusal.
+func (s *HTTPServer) authorize(w http.ResponseWriter, r *http.Request, session string) bool {
+	if s.Authorize == nil {
+		return true
+	}
+	if err := s.Authorize(r, session); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// ServeHTTP implements http.Handler.
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		env := &Envelope{}
+		if err := json.NewDecoder(io.LimitReader(r.Body, MaxFrameSize)).Decode(env); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !s.authorize(w, r, env.SessionID) {
+			return
+		}
+		box, ok := s.lookup(env.SessionID)
+		if !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		select {
+		case box.inbox <- env:
+			w.WriteHeader(http.StatusAccepted)
+		case <-box.done:
+			http.Error(w, ErrClosed.Error(), http.StatusGone)
+		case <-r.Context().Done():
+		}
+
+	case http.MethodGet:
+		query := r.URL.Query()
+		session := query.Get(SessionParam)
+		if !s.authorize(w, r, session) {
+			return
+		}
+		box, ok := s.lookup(session)
+		if !ok {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		var round *uint32
+		if param := query.Get(RoundParam); param != "" {
+			n, err := strconv.ParseUint(param, 10, 32)
+			if err != nil {
+				http.Error(w, "invalid round", http.StatusBadRequest)
+				return
+			}
+			expected := uint32(n)
+			round = &expected
+		}
+		env, err := box.poll(r.Context(), round)
+		if err != nil {
+			if err == ErrClosed {
+				http.Error(w, err.Error(), http.StatusGone)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(env)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// poll returns the envelope for round, redelivering the last envelope if the peer did not receive it,
+// or waits for the next one. Without a round, the next envelope is returned.
+func (box *httpMailbox) poll(ctx context.Context, round *uint32) (*Envelope, error) {
+	box.mu.Lock()
+	defer box.mu.Unlock()
+	if round != nil && box.last != nil && box.last.Round == *round {
+		return box.last, nil
+	}
+	select {
+	case env := <-box.outbox:
+		box.last = env
+		return env, nil
+	case <-box.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// httpServerTransport is the local end of an HTTPServer session.
+type httpServerTransport struct {
+	server *HTTPServer
+	id     string
+	box    *httpMailbox
+}
+
+// Send implements Transport.
+func (t *httpServerTransport) Send(ctx context.Context, env *Envelope) error {
+	select {
+	case t.box.outbox <- env:
+		return nil
+	case <-t.box.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive implements Transport.
+func (t *httpServerTransport) Receive(ctx context.Context) (*Envelope, error) {
+	select {
+	case env := <-t.box.inbox:
+		return env, nil
+	case <-t.box.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close implements Transport.
+func (t *httpServerTransport) Close() error {
+	t.box.once.Do(func() { close(t.box.done) })
+	t.server.remove(t.id)
+	return nil
+}
+
+// httpClientTransport talks to an HTTPServer session.
+type httpClientTransport struct {
+	endpoint  string
+	sessionID string
+	client    *http.Client
+
+	mu    sync.Mutex
+	round uint32 // round of the next envelope to poll for
+}
+
+// NewHTTPClient returns a Transport that exchanges envelopes with the HTTPServer at endpoint.
+// If client is nil, http.DefaultClient is used.
+func NewHTTPClient(endpoint string, sessionID string, client *http.Client) Transport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpClientTransport{endpoint: endpoint, sessionID: sessionID, client: client}
+}
+
+// Send implements Transport.
+func (t *httpClientTransport) Send(ctx context.Context, env *Envelope) error {
+	bz, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkHTTPStatus(resp, http.StatusAccepted)
+}
+
+// Receive implements Transport.
+func (t *httpClientTransport) Receive(ctx context.Context) (*Envelope, error) {
+	u, err := url.Parse(t.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	q := u.Query()
+	q.Set(SessionParam, t.sessionID)
+	q.Set(RoundParam, strconv.FormatUint(uint64(t.round), 10))
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := checkHTTPStatus(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxFrameSize)).Decode(env); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	t.round = env.Round + 1
+	return env, nil
+}
+
+// Close implements Transport.
+func (t *httpClientTransport) Close() error {
+	return nil
+}
+
+func checkHTTPStatus(resp *http.Response, want int) error {
+	if resp.StatusCode == want {
+		return nil
+	}
+	if resp.StatusCode == http.StatusGone {
+		return ErrClosed
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s", ErrUnauthorized, bytes.TrimSpace(msg))
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("unexpected http status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,60 @@
+package transport
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryTransport is one end of an in-process channel pair.
+type memoryTransport struct {
+	in     <-chan *Envelope
+	out    chan<- *Envelope
+	done   chan struct{}
+	closer *sync.Once
+}
+
+// NewMemoryPair returns two connected in-memory transports. Envelopes sent on one are received on
+// the other. Closing either end closes both.
+func NewMemoryPair() (Transport, Transport) {
+	ab := make(chan *Envelope, 1)
+	ba := make(chan *Envelope, 1)
+	done := make(chan struct{})
+	once := &sync.Once{}
+	return &memoryTransport{in: ba, out: ab, done: done, closer: once},
+		&memoryTransport{in: ab, out: ba, done: done, closer: once}
+}
+
+// Send implements Transport.
+func (m *memoryTransport) Send(ctx context.Context, env *Envelope) error {
+	select {
+	case <-m.done:
+		return ErrClosed
+	default:
+	}
+	select {
+	case m.out <- env:
+		return nil
+	case <-m.done:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Receive implements Transport.
+func (m *memoryTransport) Receive(ctx context.Context) (*Envelope, error) {
+	select {
+	case env := <-m.in:
+		return env, nil
+	case <-m.done:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close implements Transport.
+func (m *memoryTransport) Close() error {
+	m.closer.Do(func() { close(m.done) })
+	return nil
+}
@@ -0,0 +1,143 @@
+package transport
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// MaxFrameSize bounds the size of a single envelope read from a stream. DKLs DKG messages carrying
+// the seed OT transcripts are the largest and stay well below this.
+const MaxFrameSize = 16 << 20
+
+// deadliner is implemented by net.Conn and most stream types that support per-operation deadlines.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// streamTransport frames envelopes as a 4-byte big-endian length followed by their JSON encoding.
+type streamTransport struct {
+	rw  io.ReadWriteCloser
+	rmu sync.Mutex
+	wmu sync.Mutex
+}
+
+// NewStreamTransport returns a Transport over an ordered byte stream such as a libp2p stream.
+// Context deadlines are honoured when the stream supports read and write deadlines, and a context
+// that is done interrupts a blocked Send or Receive: through a deadline in the past when the stream
+// supports deadlines, otherwise by closing the stream. An interrupted transport may have read or
+// written part of a frame, so it should be closed.
+func NewStreamTransport(rw io.ReadWriteCloser) Transport {
+	return &streamTransport{rw: rw}
+}
+
+// NewConnTransport returns a Transport over a net.Conn.
+func NewConnTransport(conn net.Conn) Transport {
+	return NewStreamTransport(conn)
+}
+
+// Send implements Transport.
+func (s *streamTransport) Send(ctx context.Context, env *Envelope) error {
+	bz, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	if len(bz) > MaxFrameSize {
+		return fmt.Errorf("envelope of %d bytes exceeds max frame size", len(bz))
+	}
+
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	if d, ok := s.rw.(deadliner); ok {
+		deadline, _ := ctx.Deadline()
+		if err := d.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	defer s.interruptOn(ctx, deadliner.SetWriteDeadline)()
+
+	frame := make([]byte, 4+len(bz))
+	binary.BigEndian.PutUint32(frame, uint32(len(bz)))
+	copy(frame[4:], bz)
+	if _, err := s.rw.Write(frame); err != nil {
+		return wrapStreamErr(ctx, err)
+	}
+	return nil
+}
+
+// Receive implements Transport.
+func (s *streamTransport) Receive(ctx context.Context) (*Envelope, error) {
+	s.rmu.Lock()
+	defer s.rmu.Unlock()
+	if d, ok := s.rw.(deadliner); ok {
+		deadline, _ := ctx.Deadline()
+		if err := d.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	defer s.interruptOn(ctx, deadliner.SetReadDeadline)()
+
+	var header [4]byte
+	if _, err := io.ReadFull(s.rw, header[:]); err != nil {
+		return nil, wrapStreamErr(ctx, err)
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds max frame size", size)
+	}
+	bz := make([]byte, size)
+	if _, err := io.ReadFull(s.rw, bz); err != nil {
+		return nil, wrapStreamErr(ctx, err)
+	}
+
+	env := &Envelope{}
+	if err := json.Unmarshal(bz, env); err != nil {
+		return nil, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	return env, nil
+}
+
+// Close implements Transport.
+func (s *streamTransport) Close() error {
+	return s.rw.Close()
+}
+
+// interruptOn unblocks the operation in progress once ctx is done, by setting its deadline in the
+// past with setDeadline, or by closing streams without deadlines. The returned function must be
+// called when the operation returns; it also waits for an interruption in progress, so that it does
+// not hit the next operation.
+func (s *streamTransport) interruptOn(ctx context.Context, setDeadline func(deadliner, time.Time) error) func() {
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(interrupted)
+		if d, ok := s.rw.(deadliner); ok {
+			_ = setDeadline(d, time.Unix(1, 0))
+			return
+		}
+		_ = s.rw.Close()
+	})
+	return func() {
+		if !stop() {
+			<-interrupted
+		}
+	}
+}
+
+// wrapStreamErr maps the error of an operation on the stream, reporting the error of ctx if it
+// interrupted the operation.
+func wrapStreamErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, net.ErrClosed) {
+		return ErrClosed
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+// Package transport carries DKLs protocol messages between two parties that do not share a process.
+//
+// The dklsv1 iterators only know how to consume and produce *protocol.Message values. A Transport
+// moves those messages between peers wrapped in an Envelope that adds a session id and a round
+// number, and Run drives a single protocol.Iterator against its remote counterpart.
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sonr-io/crypto/core/protocol"
+)
+
+var (
+	// ErrClosed is returned when sending or receiving on a transport that has been closed.
+	ErrClosed = errors.New("transport closed")
+
+	// ErrSessionMismatch is returned when an envelope for a different session is received.
+	ErrSessionMismatch = errors.New("session id mismatch")
+
+	// ErrRoundMismatch is returned when an envelope arrives out of order.
+	ErrRoundMismatch = errors.New("unexpected round number")
+
+	// ErrPeerAborted is returned when the remote party reports that it failed.
+	ErrPeerAborted = errors.New("peer aborted protocol")
+
+	// ErrUnauthorized is returned when the server refuses the requests of a party.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// DefaultTimeout is the amount of time Run waits for each message from the peer.
+const DefaultTimeout = 30 * time.Second
+
+// Envelope wraps a protocol.Message with the metadata needed to deliver it across a network.
+type Envelope struct {
+	SessionID string            `json:"session_id"`
+	Round     uint32            `json:"round"`
+	Message   *protocol.Message `json:"message,omitempty"`
+	Done      bool              `json:"done,omitempty"`  // Done is set when the sender has finished the protocol
+	Error     string            `json:"error,omitempty"` // Error is set when the sender aborted the protocol
+}
+
+// Transport is a bidirectional, ordered message channel between two protocol parties.
+type Transport interface {
+	// Send delivers an envelope to the peer.
+	Send(ctx context.Context, env *Envelope) error
+
+	// Receive blocks until an envelope from the peer is available or the context is done.
+	Receive(ctx context.Context) (*Envelope, error)
+
+	// Close releases any resources held by the transport.
+	Close() error
+}
+
+// Session identifies a single protocol run between two parties.
+type Session struct {
+	ID      string        // ID is shared by both parties and checked on every envelope
+	Timeout time.Duration // Timeout bounds the wait for each peer message, DefaultTimeout if zero
+}
+
+func (s Session) timeout() time.Duration {
+	if s.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return s.Timeout
+}
+
+// Run drives party to completion against a peer reachable through t.
+// The initiator is the party that produces the first message: Bob for DKG, Alice for sign and refresh.
+func Run(ctx context.Context, t Transport, session Session, party protocol.Iterator, initiator bool) error {
+	var (
+		input    *protocol.Message
+		sendNext uint32
+		recvNext uint32
+	)
+
+	receive := func() (*Envelope, error) {
+		rctx, cancel := context.WithTimeout(ctx, session.timeout())
+		defer cancel()
+		env, err := t.Receive(rctx)
+		if err != nil {
+			return nil, fmt.Errorf("round %d: %w", recvNext, err)
+		}
+		if env.SessionID != session.ID {
+			return nil, fmt.Errorf("%w: got %q, want %q", ErrSessionMismatch, env.SessionID, session.ID)
+		}
+		if env.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrPeerAborted, env.Error)
+		}
+		if env.Round != recvNext {
+			return nil, fmt.Errorf("%w: got %d, want %d", ErrRoundMismatch, env.Round, recvNext)
+		}
+		recvNext++
+		return env, nil
+	}
+
+	send := func(env *Envelope) error {
+		env.SessionID = session.ID
+		env.Round = sendNext
+		sctx, cancel := context.WithTimeout(ctx, session.timeout())
+		defer cancel()
+		if err := t.Send(sctx, env); err != nil {
+			return fmt.Errorf("round %d: %w", sendNext, err)
+		}
+		sendNext++
+		return nil
+	}
+
+	abort := func(cause error) error {
+		// Best effort: let the peer fail fast instead of waiting for its timeout.
+		_ = send(&Envelope{Error: cause.Error()})
+		return cause
+	}
+
+	if !initiator {
+		env, err := receive()
+		if err != nil {
+			return err
+		}
+		input = env.Message
+	}
+
+	for {
+		output, err := next(party, input)
+		if err == protocol.ErrProtocolFinished {
+			return send(&Envelope{Done: true})
+		}
+		if err != nil {
+			return abort(err)
+		}
+		if err := send(&Envelope{Message: output}); err != nil {
+			return err
+		}
+
+		env, err := receive()
+		if err != nil {
+			return err
+		}
+		if env.Done {
+			// The peer is done; we must be as well.
+			if _, err := next(party, nil); err != protocol.ErrProtocolFinished {
+				return errors.New("peer finished before local party completed")
+			}
+			return nil
+		}
+		input = env.Message
+	}
+}
+
+// next advances party by one step. The dklsv1 decoders assume well-formed input, so a panic caused
+// by a malformed peer message is turned into an error instead of taking down the process.
+func next(party protocol.Iterator, input *protocol.Message) (output *protocol.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			output, err = nil, fmt.Errorf("malformed peer message: %v", r)
+		}
+	}()
+	return party.Next(input)
+}
@@ -0,0 +1,256 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1"
+)
+
+type pairFactory func(t *testing.T, sessionID string) (alice Transport, bob Transport)
+
+func memoryPair(_ *testing.T, _ string) (Transport, Transport) {
+	return NewMemoryPair()
+}
+
+func connPair(_ *testing.T, _ string) (Transport, Transport) {
+	a, b := net.Pipe()
+	return NewConnTransport(a), NewConnTransport(b)
+}
+
+func httpPair(t *testing.T, sessionID string) (Transport, Transport) {
+	server := NewHTTPServer()
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return server.Session(sessionID), NewHTTPClient(ts.URL, sessionID, ts.Client())
+}
+
+var factories = map[string]pairFactory{
+	"memory": memoryPair,
+	"conn":   connPair,
+	"http":   httpPair,
+}
+
+// runPair runs both parties concurrently, each only able to reach the other through its transport.
+func runPair(t *testing.T, newPair pairFactory, sessionID string, alice, bob protocol.Iterator, aliceFirst bool) {
+	t.Helper()
+	aliceT, bobT := newPair(t, sessionID)
+	defer aliceT.Close()
+	defer bobT.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	session := Session{ID: sessionID, Timeout: 10 * time.Second}
+
+	errs := make(chan error, 2)
+	go func() { errs <- Run(ctx, aliceT, session, alice, aliceFirst) }()
+	go func() { errs <- Run(ctx, bobT, session, bob, !aliceFirst) }()
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+}
+
+func TestLoopbackDkgSignRefresh(t *testing.T) {
+	curve := curves.K256()
+	for name, newPair := range factories {
+		t.Run(name, func(t *testing.T) {
+			aliceDkg := dklsv1.NewAliceDkg(curve, protocol.Version1)
+			bobDkg := dklsv1.NewBobDkg(curve, protocol.Version1)
+			runPair(t, newPair, "dkg-"+name, aliceDkg, bobDkg, false)
+
+			aliceShare, err := aliceDkg.Result(protocol.Version1)
+			require.NoError(t, err)
+			bobShare, err := bobDkg.Result(protocol.Version1)
+			require.NoError(t, err)
+			aliceOut, err := dklsv1.DecodeAliceDkgResult(aliceShare)
+			require.NoError(t, err)
+			bobOut, err := dklsv1.DecodeBobDkgResult(bobShare)
+			require.NoError(t, err)
+			require.True(t, aliceOut.PublicKey.Equal(bobOut.PublicKey))
+
+			aliceRefresh, err := dklsv1.NewAliceRefresh(curve, aliceShare, protocol.Version1)
+			require.NoError(t, err)
+			bobRefresh, err := dklsv1.NewBobRefresh(curve, bobShare, protocol.Version1)
+			require.NoError(t, err)
+			runPair(t, newPair, "refresh-"+name, aliceRefresh, bobRefresh, true)
+
+			aliceShare, err = aliceRefresh.Result(protocol.Version1)
+			require.NoError(t, err)
+			bobShare, err = bobRefresh.Result(protocol.Version1)
+			require.NoError(t, err)
+
+			message := []byte("loopback " + name)
+			aliceSign, err := dklsv1.NewAliceSign(curve, sha3.New256(), message, aliceShare, protocol.Version1)
+			require.NoError(t, err)
+			bobSign, err := dklsv1.NewBobSign(curve, sha3.New256(), message, bobShare, protocol.Version1)
+			require.NoError(t, err)
+			runPair(t, newPair, "sign-"+name, aliceSign, bobSign, true)
+
+			sigMsg, err := bobSign.Result(protocol.Version1)
+			require.NoError(t, err)
+			sig, err := dklsv1.DecodeSignature(sigMsg)
+			require.NoError(t, err)
+
+			digest := sha3.Sum256(message)
+			ec, err := curve.ToEllipticCurve()
+			require.NoError(t, err)
+			raw := aliceOut.PublicKey.ToAffineUncompressed()
+			pub := &curves.EcPoint{Curve: ec, X: new(big.Int).SetBytes(raw[1:33]), Y: new(big.Int).SetBytes(raw[33:])}
+			require.True(t, curves.VerifyEcdsa(pub, digest[:], sig))
+		})
+	}
+}
+
+func TestRunRejectsWrongSession(t *testing.T) {
+	a, b := NewMemoryPair()
+	defer a.Close()
+
+	ctx := context.Background()
+	require.NoError(t, a.Send(ctx, &Envelope{SessionID: "other", Round: 0}))
+
+	alice := dklsv1.NewAliceDkg(curves.K256(), protocol.Version1)
+	err := Run(ctx, b, Session{ID: "expected", Timeout: time.Second}, alice, false)
+	require.ErrorIs(t, err, ErrSessionMismatch)
+}
+
+func TestRunTimesOut(t *testing.T) {
+	_, b := NewMemoryPair()
+	defer b.Close()
+
+	alice := dklsv1.NewAliceDkg(curves.K256(), protocol.Version1)
+	err := Run(context.Background(), b, Session{ID: "timeout", Timeout: 10 * time.Millisecond}, alice, false)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRunReportsPeerAbort(t *testing.T) {
+	a, b := NewMemoryPair()
+	defer a.Close()
+
+	session := Session{ID: "abort", Timeout: time.Second}
+	errs := make(chan error, 1)
+	go func() {
+		// A garbage first message makes Alice fail, which Bob should learn about immediately.
+		alice := dklsv1.NewAliceDkg(curves.K256(), protocol.Version1)
+		errs <- Run(context.Background(), a, session, alice, false)
+	}()
+	require.NoError(t, b.Send(context.Background(), &Envelope{SessionID: session.ID, Message: &protocol.Message{Version: protocol.Version1}}))
+	require.Error(t, <-errs)
+
+	env, err := b.Receive(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, env.Error)
+}
+
+// pipeStream is a stream without deadlines.
+type pipeStream struct {
+	*io.PipeReader
+	*io.PipeWriter
+}
+
+func (p pipeStream) Close() error {
+	_ = p.PipeReader.Close()
+	return p.PipeWriter.Close()
+}
+
+func TestStreamTransportInterruptedByContext(t *testing.T) {
+	conn, peer := net.Pipe()
+	defer peer.Close()
+	r, w := io.Pipe()
+	defer w.Close()
+	tests := map[string]Transport{
+		"conn":   NewConnTransport(conn),
+		"stream": NewStreamTransport(pipeStream{r, w}),
+	}
+	for name, tr := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer tr.Close()
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			_, err := tr.Receive(ctx)
+			require.ErrorIs(t, err, context.Canceled)
+		})
+	}
+
+	// a write nobody reads is interrupted too
+	a, b := net.Pipe()
+	defer b.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	require.ErrorIs(t, NewConnTransport(a).Send(ctx, &Envelope{SessionID: "cancel"}), context.Canceled)
+}
+
+// bearer adds the session id as a bearer token to requests.
+type bearer string
+
+func (b bearer) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+string(b))
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestHTTPServerAuthorize(t *testing.T) {
+	server := NewHTTPServer()
+	server.Authorize = func(r *http.Request, session string) error {
+		if r.Header.Get("Authorization") != "Bearer "+session {
+			return errors.New("invalid token")
+		}
+		return nil
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	local := server.Session("authorized")
+	defer local.Close()
+	ctx := context.Background()
+
+	intruder := NewHTTPClient(ts.URL, "authorized", ts.Client())
+	require.ErrorIs(t, intruder.Send(ctx, &Envelope{SessionID: "authorized"}), ErrUnauthorized)
+	_, err := intruder.Receive(ctx)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	peer := NewHTTPClient(ts.URL, "authorized", &http.Client{Transport: bearer("authorized")})
+	require.NoError(t, peer.Send(ctx, &Envelope{SessionID: "authorized"}))
+	env, err := local.Receive(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "authorized", env.SessionID)
+}
+
+func TestHTTPServerRedeliversUnacknowledged(t *testing.T) {
+	server := NewHTTPServer()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	local := server.Session("redeliver")
+	defer local.Close()
+	ctx := context.Background()
+
+	poll := func(round uint32) *Envelope {
+		resp, err := ts.Client().Get(fmt.Sprintf("%s?%s=redeliver&%s=%d", ts.URL, SessionParam, RoundParam, round))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		env := &Envelope{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(env))
+		return env
+	}
+
+	require.NoError(t, local.Send(ctx, &Envelope{SessionID: "redeliver", Round: 0}))
+	require.Equal(t, uint32(0), poll(0).Round)
+	// the response was lost, so the peer polls for the same round again
+	require.Equal(t, uint32(0), poll(0).Round)
+
+	// polling for the next round acknowledges the previous one
+	require.NoError(t, local.Send(ctx, &Envelope{SessionID: "redeliver", Round: 1}))
+	require.Equal(t, uint32(1), poll(1).Round)
+}
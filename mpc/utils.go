@@ -32,23 +32,18 @@ func GetHashKey(key []byte) []byte {
 	return hash.Sum(nil)[:32] // Use first 32 bytes of hash
 }
 
+// DecryptKeyshare decrypts a keyshare sealed by SealKeyshare or, for legacy blobs, by
+// EncryptKeyshare with the given nonce.
 func DecryptKeyshare(msg []byte, key []byte, nonce []byte) ([]byte, error) {
-	hashedKey := GetHashKey(key)
-	block, err := aes.NewCipher(hashedKey)
-	if err != nil {
-		return nil, err
-	}
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, err
-	}
-	plaintext, err := aesgcm.Open(nil, nonce, msg, nil)
-	if err != nil {
-		return nil, err
+	if IsEnvelope(msg) {
+		return Open(msg, key, nil)
 	}
-	return plaintext, nil
+	return openLegacy(msg, key, nonce)
 }
 
+// EncryptKeyshare encrypts a keyshare with a single-hash key and a caller supplied nonce.
+//
+// Deprecated: use SealKeyshare, which derives the key with a password KDF and draws a fresh nonce.
 func EncryptKeyshare(msg Message, key []byte, nonce []byte) ([]byte, error) {
 	hashedKey := GetHashKey(key)
 	msgBytes, err := protocol.EncodeMessage(msg)
@@ -67,6 +62,24 @@ func EncryptKeyshare(msg Message, key []byte, nonce []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
+// SealKeyshare encrypts a keyshare in a versioned envelope bound to the given public key.
+func SealKeyshare(msg Message, key []byte, pubKey []byte, opts ...SealOption) ([]byte, error) {
+	msgBytes, err := protocol.EncodeMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return Seal([]byte(msgBytes), key, pubKey, opts...)
+}
+
+// OpenKeyshare decrypts a keyshare sealed by SealKeyshare for the given public key.
+func OpenKeyshare(data []byte, key []byte, pubKey []byte) (Message, error) {
+	plaintext, err := Open(data, key, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.DecodeMessage(string(plaintext))
+}
+
 func GetAliceOut(msg *protocol.Message) (AliceOut, error) {
 	return dklsv1.DecodeAliceDkgResult(msg)
 }
@@ -91,16 +104,43 @@ func GetBobPubPoint(msg *protocol.Message) (Point, error) {
 	return out.PublicKey, nil
 }
 
-// GetECDSAPoint builds an elliptic curve point from a compressed byte slice
+// GetECDSAPoint builds a secp256k1 elliptic curve point from an uncompressed byte slice
 func GetECDSAPoint(pubKey []byte) (*curves.EcPoint, error) {
-	crv := curves.K256()
-	x := new(big.Int).SetBytes(pubKey[1:33])
-	y := new(big.Int).SetBytes(pubKey[33:])
+	return GetCurveECDSAPoint(K256Name, pubKey)
+}
+
+// GetCurveECDSAPoint builds an elliptic curve point on the named curve from a compressed or
+// uncompressed byte slice
+func GetCurveECDSAPoint(curveName CurveName, pubKey []byte) (*curves.EcPoint, error) {
+	crv, err := curveName.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	var point curves.Point
+	switch len(pubKey) {
+	case 33:
+		point, err = crv.NewIdentityPoint().FromAffineCompressed(pubKey)
+	case 65:
+		point, err = crv.NewIdentityPoint().FromAffineUncompressed(pubKey)
+	default:
+		return nil, fmt.Errorf("invalid public key length: %d", len(pubKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s public key: %w", curveName, err)
+	}
+	if !point.IsOnCurve() || point.IsIdentity() {
+		return nil, fmt.Errorf("invalid %s public key: point is not on curve", curveName)
+	}
+	uncompressed := point.ToAffineUncompressed()
 	ecCurve, err := crv.ToEllipticCurve()
 	if err != nil {
 		return nil, fmt.Errorf("error converting curve: %v", err)
 	}
-	return &curves.EcPoint{X: x, Y: y, Curve: ecCurve}, nil
+	return &curves.EcPoint{
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+		Curve: ecCurve,
+	}, nil
 }
 
 func SerializeSignature(sig *curves.EcdsaSignature) ([]byte, error) {
@@ -139,22 +179,106 @@ func DeserializeSignature(sigBytes []byte) (*curves.EcdsaSignature, error) {
 	}, nil
 }
 
-func GetAliceSignFunc(k *EnclaveData, bz []byte) (SignFunc, error) {
-	curve := k.Curve.Curve()
-	return dklsv1.NewAliceSign(curve, sha3.New256(), bz, k.ValShare, protocol.Version1)
+func GetAliceSignFunc(k *EnclaveData, bz []byte, opts ...SignOption) (SignFunc, error) {
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := newSignConfig(opts)
+	msg, err := cfg.message(bz)
+	if err != nil {
+		return nil, err
+	}
+	tweak, err := cfg.keyTweak(k.DeriveChild)
+	if err != nil {
+		return nil, err
+	}
+	signFunc, err := dklsv1.NewAliceSign(curve, cfg.newHash(), msg, k.ValShare, protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	signFunc.SetKeyTweak(tweak)
+	return signFunc, nil
+}
+
+func GetAliceBatchSignFunc(k *EnclaveData, data [][]byte, opts ...SignOption) (SignFunc, error) {
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := newSignConfig(opts)
+	msgs, err := cfg.messages(data)
+	if err != nil {
+		return nil, err
+	}
+	tweak, err := cfg.keyTweak(k.DeriveChild)
+	if err != nil {
+		return nil, err
+	}
+	signFunc, err := dklsv1.NewAliceBatchSign(curve, cfg.newHash, msgs, k.ValShare, protocol.Version2)
+	if err != nil {
+		return nil, err
+	}
+	signFunc.SetKeyTweak(tweak)
+	return signFunc, nil
 }
 
 func GetAliceRefreshFunc(k *EnclaveData) (RefreshFunc, error) {
-	curve := k.Curve.Curve()
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
 	return dklsv1.NewAliceRefresh(curve, k.ValShare, protocol.Version1)
 }
 
-func GetBobSignFunc(k *EnclaveData, bz []byte) (SignFunc, error) {
-	curve := curves.K256()
-	return dklsv1.NewBobSign(curve, sha3.New256(), bz, k.UserShare, protocol.Version1)
+func GetBobSignFunc(k *EnclaveData, bz []byte, opts ...SignOption) (SignFunc, error) {
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := newSignConfig(opts)
+	msg, err := cfg.message(bz)
+	if err != nil {
+		return nil, err
+	}
+	tweak, err := cfg.keyTweak(k.DeriveChild)
+	if err != nil {
+		return nil, err
+	}
+	signFunc, err := dklsv1.NewBobSign(curve, cfg.newHash(), msg, k.UserShare, protocol.Version1)
+	if err != nil {
+		return nil, err
+	}
+	signFunc.SetKeyTweak(tweak)
+	return signFunc, nil
+}
+
+func GetBobBatchSignFunc(k *EnclaveData, data [][]byte, opts ...SignOption) (SignFunc, error) {
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
+	cfg := newSignConfig(opts)
+	msgs, err := cfg.messages(data)
+	if err != nil {
+		return nil, err
+	}
+	tweak, err := cfg.keyTweak(k.DeriveChild)
+	if err != nil {
+		return nil, err
+	}
+	signFunc, err := dklsv1.NewBobBatchSign(curve, cfg.newHash, msgs, k.UserShare, protocol.Version2)
+	if err != nil {
+		return nil, err
+	}
+	signFunc.SetKeyTweak(tweak)
+	return signFunc, nil
 }
 
 func GetBobRefreshFunc(k *EnclaveData) (RefreshFunc, error) {
-	curve := curves.K256()
+	curve, err := k.Curve.ECDSACurve()
+	if err != nil {
+		return nil, err
+	}
 	return dklsv1.NewBobRefresh(curve, k.UserShare, protocol.Version1)
 }
@@ -2,16 +2,23 @@ package mpc
 
 import (
 	"crypto/ecdsa"
-
-	"golang.org/x/crypto/sha3"
+	"fmt"
 )
 
-func VerifyWithPubKey(pubKeyCompressed []byte, data []byte, sig []byte) (bool, error) {
+// VerifyWithPubKey verifies a signature against a secp256k1 public key. The options must
+// match the ones the message was signed with; SHA3-256 is used by default.
+func VerifyWithPubKey(pubKeyCompressed []byte, data []byte, sig []byte, opts ...SignOption) (bool, error) {
+	return VerifyWithCurve(K256Name, pubKeyCompressed, data, sig, opts...)
+}
+
+// VerifyWithCurve verifies a signature against a public key on the named curve. WithDerivationPath is
+// rejected, since the chain code is not known here.
+func VerifyWithCurve(curve CurveName, pubKey []byte, data []byte, sig []byte, opts ...SignOption) (bool, error) {
 	edSig, err := DeserializeSignature(sig)
 	if err != nil {
 		return false, err
 	}
-	ePub, err := GetECDSAPoint(pubKeyCompressed)
+	ePub, err := GetCurveECDSAPoint(curve, pubKey)
 	if err != nil {
 		return false, err
 	}
@@ -21,9 +28,13 @@ func VerifyWithPubKey(pubKeyCompressed []byte, data []byte, sig []byte) (bool, e
 		Y:     ePub.Y,
 	}
 
-	// Hash the message using SHA3-256
-	hash := sha3.New256()
-	hash.Write(data)
-	digest := hash.Sum(nil)
+	cfg := newSignConfig(opts)
+	if cfg.path != "" {
+		return false, fmt.Errorf("derivation path %q needs a chain code; verify against the child public key instead", cfg.path)
+	}
+	digest, err := cfg.digest(data)
+	if err != nil {
+		return false, err
+	}
 	return ecdsa.Verify(pk, digest, edSig.R, edSig.S), nil
 }
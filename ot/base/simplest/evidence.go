@@ -0,0 +1,167 @@
+package simplest
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+// openingsLabel is the label of the statement the sender attests in round 5.
+const openingsLabel = "Coinbase_DKLs_SeedOT round 5 openings"
+
+// OpeningEvidence shows that the sender's openings of round 5 failed the checks of Round6Verify for one OT of the
+// batch. The sender's proof of round 1 binds the evidence to the session id. The receiver reveals its secret and
+// choice bit for the OT, which is safe because the aborted OT is discarded; from them Verify recomputes Rho^w.
+// The sender attested its openings along with the masked choice and challenge of every OT of the batch, which the
+// evidence holds as the digests of the OTs it does not accuse.
+type OpeningEvidence struct {
+	// SessionId is the unique session id the OT ran under.
+	SessionId [DigestSize]byte
+
+	// SenderProof is the sender's message of round 1, the proof of knowledge of the discrete log of B.
+	SenderProof *schnorr.Proof
+
+	// Index is the OT of the batch that failed the checks.
+	Index int
+
+	// MaskedChoice is the receiver's message of round 2 for the OT, A = a . G + w . B.
+	MaskedChoice ReceiversMaskedChoices
+
+	// Secret is the receiver's a and Choice its choice bit w for the OT.
+	Secret curves.Scalar
+	Choice int
+
+	// Challenge is the sender's message of round 3 for the OT, xi in the paper.
+	Challenge OtChallenge
+
+	// Opening is the sender's message of round 5 for the OT, H(Rho^0) and H(Rho^1).
+	Opening ChallengeOpening
+
+	// Leaves are the digests of the masked choice, challenge and opening of every OT of the batch. The one at Index
+	// is recomputed from the evidence.
+	Leaves [][DigestSize]byte
+
+	// Attestation is the sender's attestation of its message of round 5.
+	Attestation *protocol.Attestation
+}
+
+// blame returns the abort of a failed check of the opening of the i-th OT, with evidence if the openings are attested.
+func (receiver *Receiver) blame(i int, round5Output *Round5Output, leaves [][DigestSize]byte, check string) error {
+	if receiver.senderKey == nil {
+		return &protocol.AbortError{Party: "sender", Check: check}
+	}
+	return &protocol.AbortError{
+		Party: "sender",
+		Check: check,
+		Evidence: &OpeningEvidence{
+			SessionId:    receiver.sessionId,
+			SenderProof:  receiver.senderProof,
+			Index:        i,
+			MaskedChoice: receiver.maskedChoices[i],
+			Secret:       receiver.maskedChoiceSecrets[i],
+			Choice:       receiver.Output.RandomChoiceBits[i],
+			Challenge:    receiver.senderChallenge[i],
+			Opening:      round5Output.Openings[i],
+			Leaves:       leaves,
+			Attestation:  round5Output.Attestation,
+		},
+	}
+}
+
+// openingLeaf is the digest of the masked choice, challenge and opening of the i-th OT of the batch.
+func openingLeaf(i int, maskedChoice ReceiversMaskedChoices, challenge OtChallenge, opening ChallengeOpening) [DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, uint32(len(maskedChoice))))
+	_, _ = hash.Write(maskedChoice)
+	_, _ = hash.Write(challenge[:])
+	_, _ = hash.Write(opening[0][:])
+	_, _ = hash.Write(opening[1][:])
+	leaf := [DigestSize]byte{}
+	copy(leaf[:], hash.Sum(nil))
+	return leaf
+}
+
+// openingLeaves returns the digest of every OT of the batch.
+func openingLeaves(maskedChoices []ReceiversMaskedChoices, challenges []OtChallenge, openings []ChallengeOpening) [][DigestSize]byte {
+	leaves := make([][DigestSize]byte, len(openings))
+	for i := range openings {
+		leaves[i] = openingLeaf(i, maskedChoices[i], challenges[i], openings[i])
+	}
+	return leaves
+}
+
+// openingsStatement returns the parts of the statement the sender attests in round 5.
+func openingsStatement(sessionId [DigestSize]byte, proof *schnorr.Proof, leaves [][DigestSize]byte) [][]byte {
+	parts := [][]byte{sessionId[:], proof.Statement.ToAffineCompressed(), proof.C.Bytes(), proof.S.Bytes()}
+	for i := range leaves {
+		parts = append(parts, leaves[i][:])
+	}
+	return parts
+}
+
+// Verify checks the sender's attestation and re-runs the checks of Round6Verify on the evidence. It returns nil if the
+// opening fails them.
+func (e *OpeningEvidence) Verify() error {
+	if e.SenderProof == nil || e.SenderProof.Statement == nil || e.SenderProof.C == nil || e.SenderProof.S == nil || e.Secret == nil {
+		return errors.New("incomplete evidence")
+	}
+	if e.Index < 0 || e.Index >= len(e.Leaves) {
+		return errors.New("the index is not in the batch")
+	}
+	if openingLeaf(e.Index, e.MaskedChoice, e.Challenge, e.Opening) != e.Leaves[e.Index] {
+		return errors.New("the messages of the OT are not the attested ones")
+	}
+	if err := e.Attestation.Verify(openingsLabel, openingsStatement(e.SessionId, e.SenderProof, e.Leaves)...); err != nil {
+		return errors.Wrap(err, "the openings are not attested by the sender")
+	}
+	if e.Choice != 0 && e.Choice != 1 {
+		return errors.New("the choice bit must be 0 or 1")
+	}
+	curve := curves.GetCurveByName(e.SenderProof.Statement.CurveName())
+	if curve == nil {
+		return errors.New("unknown curve")
+	}
+
+	// Replay the transcript of the receiver to bind the sender's proof and the salts to the session.
+	transcript := merlin.NewTranscript("Coinbase_DKLs_SeedOT")
+	transcript.AppendMessage([]byte("session_id"), e.SessionId[:])
+	proofSessionId := transcript.ExtractBytes([]byte("sender schnorr proof"), DigestSize)
+	if err := schnorr.Verify(e.SenderProof, curve, nil, proofSessionId); err != nil {
+		return errors.Wrap(err, "the sender's proof does not verify under the session id")
+	}
+	salt := transcript.ExtractBytes([]byte("random oracle salts"), DigestSize)
+
+	// A must commit to the revealed secret and choice bit, so that Rho^w is the key the receiver really obtained.
+	maskedChoice := curve.ScalarBaseMult(e.Secret)
+	if e.Choice == 1 {
+		maskedChoice = maskedChoice.Add(e.SenderProof.Statement)
+	}
+	if !bytes.Equal(maskedChoice.ToAffineCompressed(), e.MaskedChoice) {
+		return errors.New("the masked choice does not commit to the revealed secret and choice bit")
+	}
+	hash := sha3.New256()
+	hash.Write(salt)
+	hash.Write([]byte{byte(e.Index)})
+	hash.Write(e.SenderProof.Statement.Mul(e.Secret).ToAffineCompressed())
+	decryptionKey := hash.Sum(nil)
+
+	hashedDecryptionKey := sha3.Sum256(decryptionKey)
+	if subtle.ConstantTimeCompare(hashedDecryptionKey[:], e.Opening[e.Choice][:]) != 1 {
+		return nil
+	}
+	hashedKey0 := sha3.Sum256(e.Opening[0][:])
+	hashedKey1 := sha3.Sum256(e.Opening[1][:])
+	if xorBytes(hashedKey0, hashedKey1) != e.Challenge {
+		return nil
+	}
+	return errors.New("the opening passes the checks")
+}
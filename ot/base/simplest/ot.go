@@ -19,15 +19,16 @@
 package simplest
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/subtle"
-	"fmt"
 
 	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/zkp/schnorr"
 )
 
@@ -98,6 +99,14 @@ type Sender struct {
 	// batchSize is the number of parallel OTs.
 	batchSize int
 
+	// identityKey is the long-term key the sender attests its openings with, nil to run without attestation. The
+	// session id, proof, masked choices and challenges of the run are kept for the attestation.
+	identityKey   ed25519.PrivateKey
+	sessionId     [DigestSize]byte
+	proof         *schnorr.Proof
+	maskedChoices []ReceiversMaskedChoices
+	challenges    []OtChallenge
+
 	transcript *merlin.Transcript
 }
 
@@ -114,12 +123,32 @@ type Receiver struct {
 	// senderChallenge is "xi" in the protocol.
 	senderChallenge []OtChallenge
 
+	// sessionId, senderProof, maskedChoices and maskedChoiceSecrets, the "a" of the paper, are kept to build the
+	// evidence against a sender that fails the checks of Round6Verify.
+	sessionId           [DigestSize]byte
+	senderProof         *schnorr.Proof
+	maskedChoices       []ReceiversMaskedChoices
+	maskedChoiceSecrets []curves.Scalar
+
 	// batchSize is the number of parallel OTs.
 	batchSize int
 
+	// senderKey is the identity key of the sender, nil to run without attestation.
+	senderKey ed25519.PublicKey
+
 	transcript *merlin.Transcript
 }
 
+// Round5Output is the sender's message of round 5: its openings of the challenges, attested with its identity key.
+type Round5Output struct {
+	// Openings are H(Rho^0) and H(Rho^1) for every OT of the batch.
+	Openings []ChallengeOpening
+
+	// Attestation attests the openings along with the session id, the sender's proof of round 1 and the masked
+	// choices and challenges they answer to. It is nil if the sender runs without an identity key.
+	Attestation *protocol.Attestation
+}
+
 // NewSender creates a new "sender" object, ready to participate in a _random_ verified simplest OT in the role of the sender.
 // no messages are specified by the sender, because random ones will be sent (hence the random OT).
 // ultimately, the `Sender`'s `Output` field will be appropriately populated.
@@ -134,6 +163,7 @@ func NewSender(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize]b
 		Output:     &SenderOutput{},
 		curve:      curve,
 		batchSize:  batchSize,
+		sessionId:  uniqueSessionId,
 		transcript: transcript,
 	}, nil
 }
@@ -152,6 +182,7 @@ func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize
 	receiver := &Receiver{
 		Output:     &ReceiverOutput{},
 		curve:      curve,
+		sessionId:  uniqueSessionId,
 		batchSize:  batchSize,
 		transcript: transcript,
 	}
@@ -165,6 +196,18 @@ func NewReceiver(curve *curves.Curve, batchSize int, uniqueSessionId [DigestSize
 	return receiver, nil
 }
 
+// SetIdentityKey makes the sender attest its openings of round 5 with its long-term identity key, so that the receiver
+// can build evidence against it that a third party can verify.
+func (sender *Sender) SetIdentityKey(key ed25519.PrivateKey) {
+	sender.identityKey = key
+}
+
+// SetSenderKey makes the receiver require the openings of round 5 to be attested with key, the identity key of the
+// sender, and attach the attestation to the evidence of a failed check.
+func (receiver *Receiver) SetSenderKey(key ed25519.PublicKey) {
+	receiver.senderKey = key
+}
+
 // Round1ComputeAndZkpToPublicKey is the first phase of the protocol.
 // computes and stores public key and returns the schnorr proof. serialized / packed.
 // This implements step 1 of Protocol 7 of DKLs18, page 16.
@@ -182,6 +225,7 @@ func (sender *Sender) Round1ComputeAndZkpToPublicKey() (*schnorr.Proof, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "creating zkp proof for secret key in seed OT sender round 1")
 	}
+	sender.proof = proof
 	return proof, nil
 }
 
@@ -195,11 +239,16 @@ func (receiver *Receiver) Round2VerifySchnorrAndPadTransfer(proof *schnorr.Proof
 		return nil, errors.Wrap(err, "verifying schnorr proof in seed OT receiver round 2")
 	}
 
+	receiver.senderProof = proof
+
 	result := make([]ReceiversMaskedChoices, receiver.batchSize)
+	receiver.maskedChoices = result
+	receiver.maskedChoiceSecrets = make([]curves.Scalar, receiver.batchSize)
 	receiver.Output.OneTimePadDecryptionKey = make([]OneTimePadDecryptionKey, receiver.batchSize)
 	copy(uniqueSessionId[:], receiver.transcript.ExtractBytes([]byte("random oracle salts"), DigestSize))
 	for i := 0; i < receiver.batchSize; i++ {
 		a := receiver.curve.Scalar.Random(rand.Reader)
+		receiver.maskedChoiceSecrets[i] = a
 		// Computing `A := a . G + w . B` in constant time, by first computing option0 = a.G and option1 = a.G+B and then
 		// constant time choosing one of them by first assuming that the output is option0, and overwrite it if the choice bit is 1.
 
@@ -242,6 +291,8 @@ func (sender *Sender) Round3PadTransfer(compressedReceiversMaskedChoice []Receiv
 		}
 	}
 
+	sender.maskedChoices = compressedReceiversMaskedChoice
+	sender.challenges = challenge
 	baseEncryptionKeyMaterial := make([]curves.Point, keyCount)
 	var hashedKey [keyCount][DigestSize]byte
 	uniqueSessionId := [DigestSize]byte{}
@@ -302,8 +353,8 @@ func (receiver *Receiver) Round4RespondToChallenge(challenge []OtChallenge) ([]O
 // Round5Verify verifies the challenge response. If the verification passes, sender opens his challenges to the receiver.
 // See step 7 of page 16 of the paper.
 // Abort if Rho' != H(H(Rho^0)) in other words, if challengeResponse != H(H(encryption key 0)).
-// opening is H(encryption key)
-func (sender *Sender) Round5Verify(challengeResponses []OtChallengeResponse) ([]ChallengeOpening, error) {
+// opening is H(encryption key). The openings are attested if the sender has an identity key.
+func (sender *Sender) Round5Verify(challengeResponses []OtChallengeResponse) (*Round5Output, error) {
 	opening := make([]ChallengeOpening, sender.batchSize)
 	for i := 0; i < sender.batchSize; i++ {
 		for k := 0; k < keyCount; k++ {
@@ -316,7 +367,12 @@ func (sender *Sender) Round5Verify(challengeResponses []OtChallengeResponse) ([]
 			return nil, errors.New("receiver's challenge response didn't match H(H(rho^0))")
 		}
 	}
-	return opening, nil
+	output := &Round5Output{Openings: opening}
+	if sender.identityKey != nil {
+		leaves := openingLeaves(sender.maskedChoices, sender.challenges, opening)
+		output.Attestation = protocol.Attest(sender.identityKey, openingsLabel, openingsStatement(sender.sessionId, sender.proof, leaves)...)
+	}
+	return output, nil
 }
 
 // Round6Verify is the _last_ part of the "Verification" phase of OT; see p. 16 of https://eprint.iacr.org/2018/499.pdf.
@@ -329,18 +385,36 @@ func (sender *Sender) Round5Verify(challengeResponses []OtChallengeResponse) ([]
 //
 //	if opening_w != H(decryption key)  or
 //	if challenge != H(opening 0) XOR H(opening 0)
-func (receiver *Receiver) Round6Verify(challengeOpenings []ChallengeOpening) error {
+//
+// On abort, the error is a *protocol.AbortError that blames the sender. If the receiver knows the identity key of the
+// sender, the openings must be attested with it, and a failed check comes with an *OpeningEvidence.
+func (receiver *Receiver) Round6Verify(round5Output *Round5Output) error {
+	if round5Output == nil || len(round5Output.Openings) != receiver.batchSize {
+		return &protocol.AbortError{Party: "sender", Check: "the number of challenge openings"}
+	}
+	challengeOpenings := round5Output.Openings
+	var leaves [][DigestSize]byte
+	if receiver.senderKey != nil {
+		if len(receiver.senderChallenge) != receiver.batchSize {
+			return errors.New("the challenges of round 3 are missing")
+		}
+		leaves = openingLeaves(receiver.maskedChoices, receiver.senderChallenge, challengeOpenings)
+		statement := openingsStatement(receiver.sessionId, receiver.senderProof, leaves)
+		if err := round5Output.Attestation.VerifyFrom(receiver.senderKey, openingsLabel, statement...); err != nil {
+			return &protocol.AbortError{Party: "sender", Check: "the attestation of its openings", Err: err}
+		}
+	}
 	for i := 0; i < receiver.batchSize; i++ {
 		hashedDecryptionKey := sha3.Sum256(receiver.Output.OneTimePadDecryptionKey[i][:])
 		w := receiver.Output.RandomChoiceBits[i]
 		if subtle.ConstantTimeCompare(hashedDecryptionKey[:], challengeOpenings[i][w][:]) != 1 {
-			return fmt.Errorf("sender's supposed H(rho^omega) doesn't match our own")
+			return receiver.blame(i, round5Output, leaves, "the check of its opening of H(rho^omega)")
 		}
 		hashedKey0 := sha3.Sum256(challengeOpenings[i][0][:])
 		hashedKey1 := sha3.Sum256(challengeOpenings[i][1][:])
 		reconstructedChallenge := xorBytes(hashedKey0, hashedKey1)
 		if subtle.ConstantTimeCompare(reconstructedChallenge[:], receiver.senderChallenge[i][:]) != 1 {
-			return fmt.Errorf("sender's openings H(rho^0) and H(rho^1) didn't decommit to its prior message")
+			return receiver.blame(i, round5Output, leaves, "the check that its openings decommit to its challenge")
 		}
 	}
 	return nil
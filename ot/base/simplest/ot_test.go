@@ -7,14 +7,18 @@
 package simplest_test
 
 import (
+	"bytes"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/gob"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/ot/base/simplest"
 	"github.com/sonr-io/crypto/ot/ottest"
 )
@@ -83,3 +87,94 @@ func TestOTStreaming(t *testing.T) {
 		require.Equal(t, receiver.Output.OneTimePadDecryptionKey[i], sender.Output.OneTimePadEncryptionKeys[i][receiver.Output.RandomChoiceBits[i]])
 	}
 }
+
+// runToRound5 runs the OT up to the sender's openings of round 5, with the sender attesting them with senderKey if
+// it is not nil, after tamper changed its encryption keys.
+func runToRound5(t *testing.T, senderKey ed25519.PrivateKey, tamper func(*simplest.SenderOutput)) (*simplest.Receiver, *simplest.Round5Output) {
+	t.Helper()
+	curve := curves.K256()
+	batchSize := 16
+	sessionId := [simplest.DigestSize]byte{}
+	_, err := rand.Read(sessionId[:])
+	require.NoError(t, err)
+	sender, err := simplest.NewSender(curve, batchSize, sessionId)
+	require.NoError(t, err)
+	receiver, err := simplest.NewReceiver(curve, batchSize, sessionId)
+	require.NoError(t, err)
+	if senderKey != nil {
+		sender.SetIdentityKey(senderKey)
+		receiver.SetSenderKey(senderKey.Public().(ed25519.PublicKey))
+	}
+	proof, err := sender.Round1ComputeAndZkpToPublicKey()
+	require.NoError(t, err)
+	maskedChoices, err := receiver.Round2VerifySchnorrAndPadTransfer(proof)
+	require.NoError(t, err)
+	challenges, err := sender.Round3PadTransfer(maskedChoices)
+	require.NoError(t, err)
+	responses, err := receiver.Round4RespondToChallenge(challenges)
+	require.NoError(t, err)
+	tamper(sender.Output)
+	openings, err := sender.Round5Verify(responses)
+	require.NoError(t, err)
+	return receiver, openings
+}
+
+func TestRound6VerifyBlamesSender(t *testing.T) {
+	curve := curves.K256()
+	_, senderKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// An honest sender passes.
+	receiver, openings := runToRound5(t, senderKey, func(*simplest.SenderOutput) {})
+	require.NotNil(t, openings.Attestation)
+	require.NoError(t, receiver.Round6Verify(openings))
+
+	// A sender that attests an opening of rho^1 that is not the hash of its key fails a check, whichever the choice
+	// bit of the receiver, and the receiver gets evidence that a third party can verify.
+	receiver, openings = runToRound5(t, senderKey, func(output *simplest.SenderOutput) {
+		output.OneTimePadEncryptionKeys[3][1][0] ^= 1
+	})
+	err = receiver.Round6Verify(openings)
+	var abort *protocol.AbortError
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "sender", abort.Party)
+
+	// a third party decodes the evidence and re-runs the check
+	var buf bytes.Buffer
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	require.NoError(t, gob.NewEncoder(&buf).Encode(abort.Evidence))
+	evidence := &simplest.OpeningEvidence{}
+	require.NoError(t, gob.NewDecoder(&buf).Decode(evidence))
+	require.Equal(t, 3, evidence.Index)
+	require.True(t, evidence.Attestation.PublicKey.Equal(senderKey.Public()))
+	require.NoError(t, evidence.Verify())
+
+	// the evidence does not hold with an opening the sender did not attest, with another secret, or without the
+	// attestation
+	forged := *evidence
+	forged.Opening[1][0] ^= 1
+	require.Error(t, forged.Verify())
+	forged = *evidence
+	forged.Secret = curve.Scalar.Random(rand.Reader)
+	require.Error(t, forged.Verify())
+	forged = *evidence
+	forged.Attestation = nil
+	require.Error(t, forged.Verify())
+
+	// Openings changed after the sender attested them fail the attestation, which comes without evidence.
+	receiver, openings = runToRound5(t, senderKey, func(*simplest.SenderOutput) {})
+	openings.Openings[3][0][0] ^= 1
+	require.ErrorAs(t, receiver.Round6Verify(openings), &abort)
+	require.Equal(t, "the attestation of its openings", abort.Check)
+	require.Nil(t, abort.Evidence)
+
+	// Without identity keys the sender is blamed without evidence.
+	receiver, openings = runToRound5(t, nil, func(output *simplest.SenderOutput) {
+		output.OneTimePadEncryptionKeys[3][1][0] ^= 1
+	})
+	require.Nil(t, openings.Attestation)
+	require.ErrorAs(t, receiver.Round6Verify(openings), &abort)
+	require.Equal(t, "sender", abort.Party)
+	require.Nil(t, abort.Evidence)
+}
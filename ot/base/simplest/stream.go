@@ -47,8 +47,8 @@ func ReceiverStreamOTRun(receiver *Receiver, rw io.ReadWriter) error {
 	if err = enc.Encode(challengeResponse); err != nil {
 		return errors.Wrap(err, "error encoding challenge response in receiver stream OT")
 	}
-	var openings []ChallengeOpening
-	err = dec.Decode(&openings)
+	openings := &Round5Output{}
+	err = dec.Decode(openings)
 	if err != nil {
 		return errors.Wrap(err, "error decoding challenge openings in receiver stream OT")
 	}
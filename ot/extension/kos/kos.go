@@ -32,46 +32,55 @@ const (
 	// KappaBytes is same as Kappa // 8, but avoids cpu division.
 	KappaBytes = Kappa >> 3
 
-	// L is the batch size used in the cOT functionality.
+	// L is the default batch size used in the cOT functionality, the number of cOTs a multiplication takes.
 	L = 2*Kappa + 2*s
 
 	// COtBlockSizeBytes is same as L // 8, but avoids cpu division.
 	COtBlockSizeBytes = L >> 3
 
+	// MaxBatchSize is the largest batch size of the cOT functionality. The index of every extended cOT is hashed as
+	// 2 bytes, which bounds the batch size plus kappaOT.
+	MaxBatchSize = 1<<16 - kappaOT
+
 	// OtWidth is the number of scalars processed per "slot" of the cOT. by definition of this parameter,
 	// for each of the receiver's choice bits, the sender will provide `OTWidth` scalars.
 	// in turn, both the sender and receiver will obtain `OTWidth` shares _per_ slot / bit of the cOT.
 	// by definition of the cOT, these "vectors of" scalars will add (componentwise) to the sender's original scalars.
 	OtWidth = 2
 
-	s                         = 80 // statistical security parameter.
-	kappaOT                   = Kappa + s
-	lPrime                    = L + kappaOT // length of pseudorandom seed expansion, used within cOT protocol
-	cOtExtendedBlockSizeBytes = lPrime >> 3
+	s            = 80 // statistical security parameter.
+	kappaOT      = Kappa + s
+	kappaOTBytes = kappaOT >> 3
 )
 
 type Receiver struct {
-	// OutputAdditiveShares are the ultimate output received. basically just the "pads".
-	OutputAdditiveShares [L][OtWidth]curves.Scalar
+	// OutputAdditiveShares are the ultimate output received. basically just the "pads". There is one per cOT of the
+	// batch.
+	OutputAdditiveShares [][OtWidth]curves.Scalar
 
 	// seedOtResults are the results that this party has received by playing the sender role in a base OT protocol.
 	seedOtResults *simplest.SenderOutput
 
+	batchSize int // the number of cOTs, L unless set with NewCOtBatchReceiver
+
 	// extendedPackedChoices is storage for "choice vector || gamma^{ext}" in a packed format.
-	extendedPackedChoices [cOtExtendedBlockSizeBytes]byte
-	psi                   [lPrime][KappaBytes]byte // transpose of v^0. gets retained between messages
+	extendedPackedChoices []byte
+	psi                   [][KappaBytes]byte // transpose of v^0. gets retained between messages
 
 	curve           *curves.Curve
 	uniqueSessionId [simplest.DigestSize]byte // store this between rounds
 }
 
 type Sender struct {
-	// OutputAdditiveShares are the ultimate output received. basically just the "pads".
-	OutputAdditiveShares [L][OtWidth]curves.Scalar
+	// OutputAdditiveShares are the ultimate output received. basically just the "pads". There is one per cOT of the
+	// batch.
+	OutputAdditiveShares [][OtWidth]curves.Scalar
 
 	// seedOtResults are the results that this party has received by playing the receiver role in a base OT protocol.
 	seedOtResults *simplest.ReceiverOutput
 
+	batchSize int // the number of cOTs, L unless set with NewCOtBatchSender
+
 	curve *curves.Curve
 }
 
@@ -135,31 +144,79 @@ func binaryFieldMul(A []byte, B []byte) []byte {
 func NewCOtReceiver(seedOTResults *simplest.SenderOutput, curve *curves.Curve) *Receiver {
 	return &Receiver{
 		seedOtResults: seedOTResults,
+		batchSize:     L,
 		curve:         curve,
 	}
 }
 
+// NewCOtBatchReceiver is NewCOtReceiver for an extension of batchSize cOTs instead of L. batchSize must be a positive
+// multiple of 8 no larger than MaxBatchSize. One extension of n . L cOTs costs less than n extensions of L cOTs, since
+// the kappaOT extra cOTs and the consistency check of the extension are paid for once.
+func NewCOtBatchReceiver(seedOTResults *simplest.SenderOutput, curve *curves.Curve, batchSize int) (*Receiver, error) {
+	if err := checkBatchSize(batchSize); err != nil {
+		return nil, err
+	}
+	return &Receiver{
+		seedOtResults: seedOTResults,
+		batchSize:     batchSize,
+		curve:         curve,
+	}, nil
+}
+
 // NewCOtSender creates a `Sender` instance, ready for use as the sender in the KOS cOT protocol.
 // you must supply the output gotten by running an instance of seed OT as the _receiver_ (note the reversal of roles)
 func NewCOtSender(seedOTResults *simplest.ReceiverOutput, curve *curves.Curve) *Sender {
 	return &Sender{
 		seedOtResults: seedOTResults,
+		batchSize:     L,
 		curve:         curve,
 	}
 }
 
+// NewCOtBatchSender is NewCOtSender for an extension of batchSize cOTs instead of L. See NewCOtBatchReceiver.
+func NewCOtBatchSender(seedOTResults *simplest.ReceiverOutput, curve *curves.Curve, batchSize int) (*Sender, error) {
+	if err := checkBatchSize(batchSize); err != nil {
+		return nil, err
+	}
+	return &Sender{
+		seedOtResults: seedOTResults,
+		batchSize:     batchSize,
+		curve:         curve,
+	}, nil
+}
+
+// checkBatchSize checks that the cOT can be run for batchSize choice bits.
+func checkBatchSize(batchSize int) error {
+	if batchSize <= 0 || batchSize%8 != 0 || batchSize > MaxBatchSize {
+		return fmt.Errorf("the cOT batch size must be a positive multiple of 8 up to %d, got %d", MaxBatchSize, batchSize)
+	}
+	return nil
+}
+
+// BatchSize returns the number of cOTs the receiver runs.
+func (receiver *Receiver) BatchSize() int {
+	return receiver.batchSize
+}
+
+// BatchSize returns the number of cOTs the sender runs.
+func (sender *Sender) BatchSize() int {
+	return sender.batchSize
+}
+
 // Round1Output is Bob's first message to Alice during cOT extension;
 // these outputs are described in step 4) of Protocol 9) https://eprint.iacr.org/2018/499.pdf
+// Every row of U holds (batch size + kappaOT) / 8 bytes.
 type Round1Output struct {
-	U      [Kappa][cOtExtendedBlockSizeBytes]byte
+	U      [Kappa][]byte
 	WPrime [simplest.DigestSize]byte
 	VPrime [simplest.DigestSize]byte
 }
 
 // Round2Output this is Alice's response to Bob in cOT extension;
 // the values `tau` are specified in Alice's step 6) of Protocol 9) https://eprint.iacr.org/2018/499.pdf
+// There is one row of Tau per cOT of the batch.
 type Round2Output struct {
-	Tau [L][OtWidth]curves.Scalar
+	Tau [][OtWidth]curves.Scalar
 }
 
 // convertBitToBitmask converts a "bit"---i.e., a `byte` which is _assumed to be_ either 0 or 1---into a bitmask,
@@ -169,16 +226,17 @@ func convertBitToBitmask(bit byte) byte {
 }
 
 // the below code takes as input a `kappa` by `lPrime` _boolean_ matrix, whose rows are actually "compacted" as bytes.
-// so in actuality, it's a `kappa` by `lPrime >> 3 == cOtExtendedBlockSizeBytes` matrix of _bytes_.
+// here `lPrime` is the batch size plus kappaOT, so in actuality, it's a `kappa` by `lPrime >> 3` matrix of _bytes_.
 // its output is the same boolean matrix, but transposed, so it has dimensions `lPrime` by `kappa`.
 // but likewise we want to compact the output matrix as bytes, again _row-wise_.
 // so the output matrix's dimensions are lPrime by `kappa >> 3 == KappaBytes`, as a _byte_ matrix.
 // the technique is fairly straightforward, but involves some bitwise operations.
-func transposeBooleanMatrix(input [Kappa][cOtExtendedBlockSizeBytes]byte) [lPrime][KappaBytes]byte {
-	output := [lPrime][KappaBytes]byte{}
+func transposeBooleanMatrix(input [Kappa][]byte) [][KappaBytes]byte {
+	columnBytes := len(input[0])
+	output := make([][KappaBytes]byte, columnBytes<<3)
 	for rowByte := 0; rowByte < KappaBytes; rowByte++ {
 		for rowBitWithinByte := 0; rowBitWithinByte < 8; rowBitWithinByte++ {
-			for columnByte := 0; columnByte < cOtExtendedBlockSizeBytes; columnByte++ {
+			for columnByte := 0; columnByte < columnBytes; columnByte++ {
 				for columnBitWithinByte := 0; columnBitWithinByte < 8; columnBitWithinByte++ {
 					rowBit := rowByte<<3 + rowBitWithinByte
 					columnBit := columnByte<<3 + columnBitWithinByte
@@ -199,26 +257,37 @@ func transposeBooleanMatrix(input [Kappa][cOtExtendedBlockSizeBytes]byte) [lPrim
 }
 
 // Round1Initialize initializes the OT Extension. see page 17, steps 1), 2), 3) and 4) of Protocol 9 of the paper.
-// The input `choice` vector is "packed" (i.e., the underlying abstract vector of `L` bits is represented as a `cOTBlockSizeBytes` bytes).
-func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]byte, choice [COtBlockSizeBytes]byte) (*Round1Output, error) {
+// The input `choice` vector is "packed" (i.e., the underlying abstract vector of batch size bits is represented as
+// batch size / 8 bytes, `COtBlockSizeBytes` for the default batch size).
+func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]byte, choice []byte) (*Round1Output, error) {
+	blockSizeBytes := receiver.batchSize >> 3
+	if len(choice) != blockSizeBytes {
+		return nil, fmt.Errorf("expected %d bytes of choice bits, got %d", blockSizeBytes, len(choice))
+	}
+	extendedBlockSizeBytes := blockSizeBytes + kappaOTBytes
+	lPrime := extendedBlockSizeBytes << 3 // length of pseudorandom seed expansion
+
 	// salt the transcript with the OT-extension session ID
 	receiver.uniqueSessionId = uniqueSessionId
 
 	// write the input choice vector into our local data. Since `otBatchSize` is the number of bits, we are working with
 	// bytes, we first need to calculate how many bytes are needed to store that many bits.
-	copy(receiver.extendedPackedChoices[0:COtBlockSizeBytes], choice[:])
+	receiver.extendedPackedChoices = make([]byte, extendedBlockSizeBytes)
+	copy(receiver.extendedPackedChoices[0:blockSizeBytes], choice)
 
 	// Fill the rest of the extended choice vector with random values. These random values correspond to `gamma^{ext}`.
-	if _, err := rand.Read(receiver.extendedPackedChoices[COtBlockSizeBytes:]); err != nil {
+	if _, err := rand.Read(receiver.extendedPackedChoices[blockSizeBytes:]); err != nil {
 		return nil, errors.Wrap(err, "sampling random coins for gamma^{ext}")
 	}
 
-	v := [2][Kappa][cOtExtendedBlockSizeBytes]byte{} // kappa * L array of _bits_, in "dense" form. contains _both_ v_0 and v_1.
+	v := [2][Kappa][]byte{} // kappa * lPrime array of _bits_, in "dense" form. contains _both_ v_0 and v_1.
 	result := &Round1Output{}
 
 	hash := sha3.New256() // basically this will contain a hash of the matrix U.
 	for i := 0; i < Kappa; i++ {
+		result.U[i] = make([]byte, extendedBlockSizeBytes)
 		for j := 0; j < 2; j++ {
+			v[j][i] = make([]byte, extendedBlockSizeBytes)
 			shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
 			if _, err := shake.Write(receiver.seedOtResults.OneTimePadEncryptionKeys[i][j][:]); err != nil {
 				return nil, errors.Wrap(err, "writing seed OT into shake in cOT receiver round 1")
@@ -230,7 +299,7 @@ func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]
 				return nil, errors.Wrap(err, "reading from shake to compute v^j in cOT receiver round 1")
 			}
 		}
-		for j := 0; j < cOtExtendedBlockSizeBytes; j++ {
+		for j := 0; j < extendedBlockSizeBytes; j++ {
 			result.U[i][j] = v[0][i][j] ^ v[1][i][j] ^ receiver.extendedPackedChoices[j]
 			// U := v_i^0 ^ v_i^1 ^ w. note: in step 4) of Prot. 9, i think `w` should be bolded?
 		}
@@ -266,12 +335,23 @@ func (receiver *Receiver) Round1Initialize(uniqueSessionId [simplest.DigestSize]
 // `message` contains the message the receiver ("Bob") sent us. this itself contains Bob's values WPrime, VPrime, and U
 // the output is just the values `Tau` we send back to Bob.
 // as a side effect of this function, our (i.e., the sender's) outputs tA_j from the cOT will be populated.
-func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte, input [L][OtWidth]curves.Scalar, round1Output *Round1Output) (*Round2Output, error) {
-	z := [Kappa][cOtExtendedBlockSizeBytes]byte{}
+// There must be one row of `input` per cOT of the batch.
+func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte, input [][OtWidth]curves.Scalar, round1Output *Round1Output) (*Round2Output, error) {
+	if len(input) != sender.batchSize {
+		return nil, fmt.Errorf("expected %d rows of input, got %d", sender.batchSize, len(input))
+	}
+	extendedBlockSizeBytes := sender.batchSize>>3 + kappaOTBytes
+	lPrime := extendedBlockSizeBytes << 3 // length of pseudorandom seed expansion
+	for i := 0; i < Kappa; i++ {
+		if len(round1Output.U[i]) != extendedBlockSizeBytes {
+			return nil, fmt.Errorf("row %d of the cOT receiver's matrix U has %d bytes instead of %d", i, len(round1Output.U[i]), extendedBlockSizeBytes)
+		}
+	}
+	z := [Kappa][]byte{}
 	hash := sha3.New256() // basically this will contain a hash of the matrix U.
 
 	for i := 0; i < Kappa; i++ {
-		v := make([]byte, cOtExtendedBlockSizeBytes) // will contain alice's expanded PRG output for the row i, namely v_i^{\Nabla_i}.
+		v := make([]byte, extendedBlockSizeBytes) // will contain alice's expanded PRG output for the row i, namely v_i^{\Nabla_i}.
 		shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
 		if _, err := shake.Write(sender.seedOtResults.OneTimePadDecryptionKey[i][:]); err != nil {
 			return nil, errors.Wrap(err, "sender writing seed OT decryption key into shake in sender round 2 transfer")
@@ -281,7 +361,8 @@ func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte,
 		}
 		// use the idExt as the domain separator, and the _secret_ seed rho as the input!
 		mask := convertBitToBitmask(byte(sender.seedOtResults.RandomChoiceBits[i]))
-		for j := 0; j < cOtExtendedBlockSizeBytes; j++ {
+		z[i] = make([]byte, extendedBlockSizeBytes)
+		for j := 0; j < extendedBlockSizeBytes; j++ {
 			z[i][j] = v[j] ^ mask&round1Output.U[i][j]
 		}
 		if _, err := hash.Write(round1Output.U[i][:]); err != nil {
@@ -315,8 +396,9 @@ func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte,
 	if subtle.ConstantTimeCompare(zPrime[:], rhs[:]) != 1 {
 		return nil, fmt.Errorf("cOT receiver's consistency check failed; this may be an attempted attack; do NOT re-run the protocol")
 	}
-	result := &Round2Output{}
-	for j := 0; j < L; j++ {
+	sender.OutputAdditiveShares = make([][OtWidth]curves.Scalar, sender.batchSize)
+	result := &Round2Output{Tau: make([][OtWidth]curves.Scalar, sender.batchSize)}
+	for j := 0; j < sender.batchSize; j++ {
 		column := make([]byte, OtWidth*simplest.DigestSize)
 		shake := sha3.NewCShake256(uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
 		jBytes := [2]byte{}
@@ -366,7 +448,11 @@ func (sender *Sender) Round2Transfer(uniqueSessionId [simplest.DigestSize]byte,
 
 // Round3Transfer does the receiver (Bob)'s step 7) of Protocol 9, namely the computation of the outputs tB.
 func (receiver *Receiver) Round3Transfer(round2Output *Round2Output) error {
-	for j := 0; j < L; j++ {
+	if len(round2Output.Tau) != receiver.batchSize {
+		return fmt.Errorf("expected %d rows of tau, got %d", receiver.batchSize, len(round2Output.Tau))
+	}
+	receiver.OutputAdditiveShares = make([][OtWidth]curves.Scalar, receiver.batchSize)
+	for j := 0; j < receiver.batchSize; j++ {
 		column := make([]byte, OtWidth*simplest.DigestSize)
 		shake := sha3.NewCShake256(receiver.uniqueSessionId[:], []byte("Coinbase_DKLs_cOT"))
 		jBytes := [2]byte{}
@@ -383,6 +469,9 @@ func (receiver *Receiver) Round3Transfer(round2Output *Round2Output) error {
 		bit := int(simplest.ExtractBitFromByteVector(receiver.extendedPackedChoices[:], j))
 		var err error
 		for k := 0; k < OtWidth; k++ {
+			if round2Output.Tau[j][k] == nil {
+				return fmt.Errorf("tau %d is missing", j)
+			}
 			receiver.OutputAdditiveShares[j][k], err = receiver.curve.Scalar.SetBytes(column[k*simplest.DigestSize : (k+1)*simplest.DigestSize])
 			if err != nil {
 				return errors.Wrap(err, "scalar output additive shares from bytes")
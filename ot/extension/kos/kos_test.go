@@ -45,38 +45,67 @@ func TestCOTExtension(t *testing.T) {
 			require.Equal(t, baseOtReceiverOutput.OneTimePadDecryptionKey[i], baseOtSenderOutput.OneTimePadEncryptionKeys[i][baseOtReceiverOutput.RandomChoiceBits[i]])
 		}
 
-		sender := NewCOtSender(baseOtReceiverOutput, curve)
-		receiver := NewCOtReceiver(baseOtSenderOutput, curve)
-		choice := [COtBlockSizeBytes]byte{} // receiver's input, namely choice vector. just random
-		_, err = rand.Read(choice[:])
-		require.NoError(t, err)
-		input := [L][OtWidth]curves.Scalar{} // sender's input, namely integer "sums" in case w_j == 1.
-		for i := 0; i < L; i++ {
-			for j := 0; j < OtWidth; j++ {
-				input[i][j] = curve.Scalar.Random(rand.Reader)
-				require.NoError(t, err)
+		for _, batchSize := range []int{L, 3 * L} {
+			sender, err := NewCOtBatchSender(baseOtReceiverOutput, curve, batchSize)
+			require.NoError(t, err)
+			receiver, err := NewCOtBatchReceiver(baseOtSenderOutput, curve, batchSize)
+			require.NoError(t, err)
+			choice := make([]byte, batchSize>>3) // receiver's input, namely choice vector. just random
+			_, err = rand.Read(choice)
+			require.NoError(t, err)
+			input := make([][OtWidth]curves.Scalar, batchSize) // sender's input, namely integer "sums" in case w_j == 1.
+			for i := range input {
+				for j := 0; j < OtWidth; j++ {
+					input[i][j] = curve.Scalar.Random(rand.Reader)
+				}
 			}
-		}
-		firstMessage, err := receiver.Round1Initialize(uniqueSessionId, choice)
-		require.NoError(t, err)
-		responseTau, err := sender.Round2Transfer(uniqueSessionId, input, firstMessage)
-		require.NoError(t, err)
-		err = receiver.Round3Transfer(responseTau)
-		require.NoError(t, err)
-		for j := 0; j < L; j++ {
-			bit := simplest.ExtractBitFromByteVector(choice[:], j) == 1
-			for k := 0; k < OtWidth; k++ {
-				temp := sender.OutputAdditiveShares[j][k].Add(receiver.OutputAdditiveShares[j][k])
-				if bit {
-					require.Equal(t, temp, input[j][k])
-				} else {
-					require.Equal(t, temp, curve.Scalar.Zero())
+			firstMessage, err := receiver.Round1Initialize(uniqueSessionId, choice)
+			require.NoError(t, err)
+			responseTau, err := sender.Round2Transfer(uniqueSessionId, input, firstMessage)
+			require.NoError(t, err)
+			err = receiver.Round3Transfer(responseTau)
+			require.NoError(t, err)
+			for j := 0; j < batchSize; j++ {
+				bit := simplest.ExtractBitFromByteVector(choice, j) == 1
+				for k := 0; k < OtWidth; k++ {
+					temp := sender.OutputAdditiveShares[j][k].Add(receiver.OutputAdditiveShares[j][k])
+					if bit {
+						require.Equal(t, temp, input[j][k])
+					} else {
+						require.Equal(t, temp, curve.Scalar.Zero())
+					}
 				}
 			}
 		}
 	}
 }
 
+func TestCOTExtensionBatchSize(t *testing.T) {
+	curve := curves.K256()
+	uniqueSessionId := [simplest.DigestSize]byte{}
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, Kappa, uniqueSessionId)
+	require.NoError(t, err)
+	for _, batchSize := range []int{0, 12, MaxBatchSize + 8} {
+		_, err = NewCOtBatchSender(baseOtReceiverOutput, curve, batchSize)
+		require.Error(t, err)
+		_, err = NewCOtBatchReceiver(baseOtSenderOutput, curve, batchSize)
+		require.Error(t, err)
+	}
+
+	// the parties must agree on the batch size
+	sender, err := NewCOtBatchSender(baseOtReceiverOutput, curve, 2*L)
+	require.NoError(t, err)
+	receiver := NewCOtReceiver(baseOtSenderOutput, curve)
+	_, err = receiver.Round1Initialize(uniqueSessionId, make([]byte, 2*COtBlockSizeBytes))
+	require.Error(t, err)
+	firstMessage, err := receiver.Round1Initialize(uniqueSessionId, make([]byte, COtBlockSizeBytes))
+	require.NoError(t, err)
+	input := make([][OtWidth]curves.Scalar, 2*L)
+	_, err = sender.Round2Transfer(uniqueSessionId, input, firstMessage)
+	require.Error(t, err)
+	require.Error(t, receiver.Round3Transfer(&Round2Output{Tau: input}))
+}
+
 func TestCOTExtensionStreaming(t *testing.T) {
 	curve := curves.K256()
 	hashKeySeed := [simplest.DigestSize]byte{}
@@ -106,10 +135,10 @@ func TestCOTExtensionStreaming(t *testing.T) {
 	}
 
 	// begin test of cOT extension. first populate both parties' inputs randomly
-	choice := [COtBlockSizeBytes]byte{} // receiver's input, namely choice vector. just random
-	_, err = rand.Read(choice[:])
+	choice := make([]byte, COtBlockSizeBytes) // receiver's input, namely choice vector. just random
+	_, err = rand.Read(choice)
 	require.NoError(t, err)
-	input := [L][OtWidth]curves.Scalar{} // sender's input, namely integer "sums" in case w_j == 1. random for the test
+	input := make([][OtWidth]curves.Scalar, L) // sender's input, namely integer "sums" in case w_j == 1. random for the test
 	for i := 0; i < L; i++ {
 		for j := 0; j < OtWidth; j++ {
 			input[i][j] = curve.Scalar.Random(rand.Reader)
@@ -128,7 +157,7 @@ func TestCOTExtensionStreaming(t *testing.T) {
 		require.Nil(t, <-errorsChannel)
 	}
 	for j := 0; j < L; j++ {
-		bit := simplest.ExtractBitFromByteVector(choice[:], j) == 1
+		bit := simplest.ExtractBitFromByteVector(choice, j) == 1
 		for k := 0; k < OtWidth; k++ {
 			temp := sender.OutputAdditiveShares[j][k].Add(receiver.OutputAdditiveShares[j][k])
 			if bit {
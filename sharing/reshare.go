@@ -0,0 +1,122 @@
+package sharing
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// Resharing moves a secret shared among old shareholders to a new committee with other sharing parameters, without
+// ever reconstructing it. Each dealer of a qualified set of old shareholders weights its share s_i with its Lagrange
+// coefficient l_i for the set, and deals l_i * s_i with Feldman to the new committee. Since the constant terms of the
+// sub-sharings add up to the secret, each new member adds up its sub-shares into its share of the secret.
+
+// Reshare deals a sharing of share, weighted by its Lagrange coefficient among dealers, with the threshold and limit
+// of f. old is the sharing share belongs to. dealers are the ids of the qualified set of old shareholders taking part
+// in the resharing, and must include the id of share.
+func (f Feldman) Reshare(old *Feldman, share *ShamirShare, dealers []uint32, reader io.Reader) (*FeldmanVerifier, []*ShamirShare, error) {
+	if err := share.Validate(f.Curve); err != nil {
+		return nil, nil, err
+	}
+	lagrange, err := f.dealerCoeffs(old, dealers)
+	if err != nil {
+		return nil, nil, err
+	}
+	weight, ok := lagrange[share.Id]
+	if !ok {
+		return nil, nil, fmt.Errorf("share %d is not one of the dealers", share.Id)
+	}
+	value, err := f.Curve.Scalar.SetBytes(share.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f.Split(value.Mul(weight), reader)
+}
+
+// CombineReshares verifies the sub-shares that the new shareholder id received from the dealers of a resharing, and
+// combines them into its share of the secret. old is the sharing of the dealers. subShares and verifiers are keyed by
+// dealer, and publicShares holds the public share s_i * G of each dealer, which the constant term of its commitments
+// is checked against.
+// It also returns the commitments of the new sharing. The caller must check that their constant term is the public
+// key of the secret, which proves that the dealers formed a qualified set.
+func (f Feldman) CombineReshares(old *Feldman, id uint32, subShares map[uint32]*ShamirShare, verifiers map[uint32]*FeldmanVerifier, publicShares map[uint32]curves.Point) (*ShamirShare, *FeldmanVerifier, error) {
+	if len(subShares) != len(verifiers) {
+		return nil, nil, fmt.Errorf("expected a sub-share and commitments from each dealer")
+	}
+	dealers := make([]uint32, 0, len(verifiers))
+	for dealer := range verifiers {
+		dealers = append(dealers, dealer)
+	}
+	lagrange, err := f.dealerCoeffs(old, dealers)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := f.Curve.Scalar.Zero()
+	commitments := make([]curves.Point, f.Threshold)
+	for i := range commitments {
+		commitments[i] = f.Curve.NewIdentityPoint()
+	}
+	for dealer, verifier := range verifiers {
+		subShare, ok := subShares[dealer]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing sub-share of dealer %d", dealer)
+		}
+		publicShare, ok := publicShares[dealer]
+		if !ok {
+			return nil, nil, fmt.Errorf("missing public share of dealer %d", dealer)
+		}
+		if verifier == nil || uint32(len(verifier.Commitments)) != f.Threshold {
+			return nil, nil, fmt.Errorf("invalid commitments of dealer %d", dealer)
+		}
+		for _, commitment := range verifier.Commitments {
+			if commitment == nil || !commitment.IsOnCurve() || commitment.CurveName() != f.Curve.Name {
+				return nil, nil, fmt.Errorf("invalid commitments of dealer %d", dealer)
+			}
+		}
+		if !verifier.Commitments[0].Equal(publicShare.Mul(lagrange[dealer])) {
+			return nil, nil, fmt.Errorf("dealer %d did not deal its share", dealer)
+		}
+		if subShare.Id != id {
+			return nil, nil, fmt.Errorf("dealer %d dealt the sub-share of another shareholder", dealer)
+		}
+		if err = verifier.Verify(subShare); err != nil {
+			return nil, nil, fmt.Errorf("feldman verify fails for dealer %d", dealer)
+		}
+		sc, err := f.Curve.Scalar.SetBytes(subShare.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		value = value.Add(sc)
+		for i, commitment := range verifier.Commitments {
+			commitments[i] = commitments[i].Add(commitment)
+		}
+	}
+	return &ShamirShare{Id: id, Value: value.Bytes()}, &FeldmanVerifier{Commitments: commitments}, nil
+}
+
+// dealerCoeffs returns the Lagrange coefficients at 0 of the dealers of a resharing from the old sharing.
+func (f Feldman) dealerCoeffs(old *Feldman, dealers []uint32) (map[uint32]curves.Scalar, error) {
+	if old == nil || old.Curve == nil || old.Curve.Name != f.Curve.Name {
+		return nil, fmt.Errorf("invalid old sharing")
+	}
+	if uint32(len(dealers)) < old.Threshold {
+		return nil, fmt.Errorf("at least %d dealers are needed", old.Threshold)
+	}
+	seen := make(map[uint32]bool, len(dealers))
+	for _, dealer := range dealers {
+		if dealer == 0 || dealer > old.Limit {
+			return nil, fmt.Errorf("invalid dealer identifier")
+		}
+		if seen[dealer] {
+			return nil, fmt.Errorf("duplicate dealer")
+		}
+		seen[dealer] = true
+	}
+	shamir := &Shamir{
+		threshold: old.Threshold,
+		limit:     old.Limit,
+		curve:     old.Curve,
+	}
+	return shamir.LagrangeCoeffs(dealers)
+}
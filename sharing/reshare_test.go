@@ -0,0 +1,123 @@
+package sharing
+
+import (
+	crand "crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// dealResharing splits a random secret with old, and has the shareholders dealers reshare it with next. It returns
+// the secret, the public shares of the old sharing, and the dealings keyed by dealer.
+func dealResharing(t *testing.T, old, next *Feldman, dealers []uint32) (curves.Scalar, map[uint32]curves.Point, map[uint32]*FeldmanVerifier, map[uint32][]*ShamirShare) {
+	t.Helper()
+	secret := old.Curve.Scalar.Random(crand.Reader)
+	_, shares, err := old.Split(secret, crand.Reader)
+	require.NoError(t, err)
+	publicShares := make(map[uint32]curves.Point, len(shares))
+	for _, share := range shares {
+		value, err := old.Curve.Scalar.SetBytes(share.Value)
+		require.NoError(t, err)
+		publicShares[share.Id] = old.Curve.ScalarBaseMult(value)
+	}
+	verifiers := make(map[uint32]*FeldmanVerifier, len(dealers))
+	subShares := make(map[uint32][]*ShamirShare, len(dealers))
+	for _, dealer := range dealers {
+		verifiers[dealer], subShares[dealer], err = next.Reshare(old, shares[dealer-1], dealers, crand.Reader)
+		require.NoError(t, err)
+	}
+	return secret, publicShares, verifiers, subShares
+}
+
+// received returns the sub-shares new shareholder id received, keyed by dealer.
+func received(subShares map[uint32][]*ShamirShare, id uint32) map[uint32]*ShamirShare {
+	out := make(map[uint32]*ShamirShare, len(subShares))
+	for dealer, shares := range subShares {
+		out[dealer] = shares[id-1]
+	}
+	return out
+}
+
+func TestFeldmanReshare(t *testing.T) {
+	tests := []struct {
+		curve                    *curves.Curve
+		threshold, limit         uint32
+		dealers                  []uint32
+		nextThreshold, nextLimit uint32
+	}{
+		{curves.ED25519(), 2, 3, []uint32{1, 3}, 3, 5},
+		{curves.K256(), 2, 3, []uint32{1, 2, 3}, 3, 5},
+		{curves.P256(), 3, 5, []uint32{2, 4, 5}, 2, 3},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d of %d to %d of %d for curve %s", test.threshold, test.limit, test.nextThreshold, test.nextLimit, test.curve.Name), func(t *testing.T) {
+			old, err := NewFeldman(test.threshold, test.limit, test.curve)
+			require.NoError(t, err)
+			next, err := NewFeldman(test.nextThreshold, test.nextLimit, test.curve)
+			require.NoError(t, err)
+			secret, publicShares, verifiers, subShares := dealResharing(t, old, next, test.dealers)
+
+			shares := make([]*ShamirShare, 0, test.nextLimit)
+			for id := uint32(1); id <= test.nextLimit; id++ {
+				share, verifier, err := next.CombineReshares(old, id, received(subShares, id), verifiers, publicShares)
+				require.NoError(t, err)
+				require.True(t, verifier.Commitments[0].Equal(test.curve.ScalarBaseMult(secret)))
+				require.NoError(t, verifier.Verify(share))
+				shares = append(shares, share)
+			}
+			combined, err := next.Combine(shares[len(shares)-int(test.nextThreshold):]...)
+			require.NoError(t, err)
+			require.Equal(t, secret.Bytes(), combined.Bytes())
+			_, err = next.Combine(shares[:test.nextThreshold-1]...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFeldmanCombineResharesRejectsBadDealings(t *testing.T) {
+	curve := curves.K256()
+	old, err := NewFeldman(2, 3, curve)
+	require.NoError(t, err)
+	next, err := NewFeldman(3, 5, curve)
+	require.NoError(t, err)
+	dealers := []uint32{1, 2}
+	_, publicShares, verifiers, subShares := dealResharing(t, old, next, dealers)
+
+	// a sub-share off the committed polynomial
+	tampered := received(subShares, 1)
+	tampered[2] = &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}
+	_, _, err = next.CombineReshares(old, 1, tampered, verifiers, publicShares)
+	require.Error(t, err)
+
+	// the sub-share of another shareholder
+	_, _, err = next.CombineReshares(old, 1, received(subShares, 2), verifiers, publicShares)
+	require.Error(t, err)
+
+	// a consistent sharing of something else than the weighted share of the dealer
+	verifier, shares, err := next.Split(curve.Scalar.Random(crand.Reader), crand.Reader)
+	require.NoError(t, err)
+	forged := received(subShares, 1)
+	forged[2] = shares[0]
+	_, _, err = next.CombineReshares(old, 1, forged, map[uint32]*FeldmanVerifier{1: verifiers[1], 2: verifier}, publicShares)
+	require.Error(t, err)
+
+	// a missing dealing
+	delete(forged, 2)
+	_, _, err = next.CombineReshares(old, 1, forged, verifiers, publicShares)
+	require.Error(t, err)
+
+	// a dealer that is not part of the dealers
+	_, _, err = next.Reshare(old, &ShamirShare{Id: 3, Value: curve.Scalar.Random(crand.Reader).Bytes()}, dealers, crand.Reader)
+	require.Error(t, err)
+	_, _, err = next.Reshare(old, &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}, []uint32{1, 1}, crand.Reader)
+	require.Error(t, err)
+
+	// the dealers are checked against the old sharing, not the new one
+	_, _, err = next.Reshare(old, &ShamirShare{Id: 4, Value: curve.Scalar.Random(crand.Reader).Bytes()}, []uint32{1, 4}, crand.Reader)
+	require.Error(t, err)
+	_, _, err = old.Reshare(next, &ShamirShare{Id: 1, Value: curve.Scalar.Random(crand.Reader).Bytes()}, []uint32{1, 2}, crand.Reader)
+	require.Error(t, err)
+}
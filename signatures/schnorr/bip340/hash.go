@@ -0,0 +1,47 @@
+package bip340
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// Tags of the BIP-340 and BIP-341 tagged hashes
+const (
+	TagAux       = "BIP0340/aux"
+	TagNonce     = "BIP0340/nonce"
+	TagChallenge = "BIP0340/challenge"
+	TagTapTweak  = "TapTweak"
+	TagTapLeaf   = "TapLeaf"
+	TagTapBranch = "TapBranch"
+)
+
+// TaggedHash returns sha256(sha256(tag) || sha256(tag) || msgs...)
+func TaggedHash(tag string, msgs ...[]byte) [32]byte {
+	t := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(t[:])
+	h.Write(t[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	var out [32]byte
+	h.Sum(out[:0])
+	return out
+}
+
+// Challenge returns the challenge e = int(hash_BIP0340/challenge(r || P || msg)) mod n of the x-only
+// nonce r and public key P. Threshold signers compute it over the aggregate nonce and key.
+func Challenge(r, pub, msg []byte) curves.Scalar {
+	h := TaggedHash(TagChallenge, r, pub, msg)
+	return hashToScalar(h)
+}
+
+// hashToScalar returns a hash reduced modulo the group order
+func hashToScalar(h [32]byte) curves.Scalar {
+	v := new(big.Int).SetBytes(h[:])
+	v.Mod(v, curves.K256Curve().Params().N)
+	s, _ := curves.K256().Scalar.SetBigInt(v)
+	return s
+}
@@ -0,0 +1,154 @@
+// Package bip340 implements BIP-340 Schnorr signatures over secp256k1 and the BIP-341 Taproot key
+// tweaks, on top of curves.K256().
+package bip340
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+const (
+	// PublicKeySize is the size, in bytes, of x-only public keys
+	PublicKeySize = 32
+	// SecretKeySize is the size, in bytes, of secret keys
+	SecretKeySize = 32
+	// SignatureSize is the size, in bytes, of signatures
+	SignatureSize = 64
+)
+
+var (
+	// ErrInvalidPublicKey is returned for x-only keys that are not the x coordinate of a point
+	ErrInvalidPublicKey = errors.New("invalid bip340 public key")
+	// ErrInvalidSecretKey is returned for secret keys outside of [1, n-1]
+	ErrInvalidSecretKey = errors.New("invalid bip340 secret key")
+)
+
+// PublicKey is an x-only public key, the point with an even Y coordinate and the given X coordinate
+type PublicKey struct {
+	point curves.Point
+}
+
+// NewPublicKey returns the x-only public key of a secp256k1 point
+func NewPublicKey(p curves.Point) (*PublicKey, error) {
+	if p.CurveName() != curves.K256Name {
+		return nil, fmt.Errorf("%w: %s point", ErrInvalidPublicKey, p.CurveName())
+	}
+	if p.IsIdentity() {
+		return nil, fmt.Errorf("%w: the identity", ErrInvalidPublicKey)
+	}
+	return &PublicKey{point: evenY(p)}, nil
+}
+
+// ParsePublicKey returns the public key of a 32 byte x coordinate, lift_x(x)
+func ParsePublicKey(b []byte) (*PublicKey, error) {
+	p, err := liftX(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	return &PublicKey{point: p}, nil
+}
+
+// Bytes returns the x-only encoding of the public key
+func (pk *PublicKey) Bytes() []byte {
+	return pk.point.ToAffineCompressed()[1:]
+}
+
+// Point returns the point of the public key, which has an even Y coordinate
+func (pk *PublicKey) Point() curves.Point {
+	return pk.point
+}
+
+// Equal reports whether two public keys are the same
+func (pk *PublicKey) Equal(other *PublicKey) bool {
+	return pk.point.Equal(other.point)
+}
+
+// SecretKey is a secp256k1 secret key. Its public key may have an odd Y coordinate, signing
+// negates the key when it does.
+type SecretKey struct {
+	scalar curves.Scalar
+}
+
+// NewSecretKey returns the secret key of a non-zero secp256k1 scalar
+func NewSecretKey(s curves.Scalar) (*SecretKey, error) {
+	if s.IsZero() {
+		return nil, ErrInvalidSecretKey
+	}
+	if _, ok := s.(*curves.ScalarK256); !ok {
+		return nil, fmt.Errorf("%w: not a secp256k1 scalar", ErrInvalidSecretKey)
+	}
+	return &SecretKey{scalar: s}, nil
+}
+
+// ParseSecretKey returns the secret key of a 32 byte big-endian scalar
+func ParseSecretKey(b []byte) (*SecretKey, error) {
+	if len(b) != SecretKeySize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidSecretKey, len(b))
+	}
+	s, err := curves.K256().Scalar.SetBytes(b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecretKey, err)
+	}
+	return NewSecretKey(s)
+}
+
+// GenerateKey returns a random secret key
+func GenerateKey(reader io.Reader) (*SecretKey, error) {
+	for {
+		s := curves.K256().Scalar.Random(reader)
+		if s == nil {
+			return nil, errors.New("failed to read randomness")
+		}
+		if !s.IsZero() {
+			return &SecretKey{scalar: s}, nil
+		}
+	}
+}
+
+// Bytes returns the big-endian encoding of the secret key
+func (sk *SecretKey) Bytes() []byte {
+	return sk.scalar.Bytes()
+}
+
+// Scalar returns the scalar of the secret key
+func (sk *SecretKey) Scalar() curves.Scalar {
+	return sk.scalar
+}
+
+// PublicKey returns the x-only public key of the secret key
+func (sk *SecretKey) PublicKey() *PublicKey {
+	return &PublicKey{point: evenY(curves.K256().ScalarBaseMult(sk.scalar))}
+}
+
+// evenScalar returns the secret key negated when its point has an odd Y coordinate, the key of the
+// x-only public key
+func (sk *SecretKey) evenScalar() curves.Scalar {
+	if hasOddY(curves.K256().ScalarBaseMult(sk.scalar)) {
+		return sk.scalar.Neg()
+	}
+	return sk.scalar
+}
+
+// hasOddY reports whether the Y coordinate of a point is odd
+func hasOddY(p curves.Point) bool {
+	return p.ToAffineCompressed()[0] == 0x03
+}
+
+// evenY returns p or -p, whichever has an even Y coordinate
+func evenY(p curves.Point) curves.Point {
+	if hasOddY(p) {
+		return p.Neg()
+	}
+	return p
+}
+
+// liftX returns the point with an even Y coordinate and the X coordinate x
+func liftX(x []byte) (curves.Point, error) {
+	if len(x) != PublicKeySize {
+		return nil, fmt.Errorf("x coordinate of %d bytes", len(x))
+	}
+	return curves.K256().Point.FromAffineCompressed(append([]byte{0x02}, x...))
+}
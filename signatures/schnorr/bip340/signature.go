@@ -0,0 +1,122 @@
+package bip340
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// ErrInvalidSignature is returned for malformed signatures
+var ErrInvalidSignature = errors.New("invalid bip340 signature")
+
+// Signature is a BIP-340 signature, the nonce point R with an even Y coordinate and the scalar s
+type Signature struct {
+	R curves.Point
+	S curves.Scalar
+}
+
+// MarshalBinary returns the 64 byte encoding of the signature, the x-only nonce and s
+func (sig Signature) MarshalBinary() ([]byte, error) {
+	out := make([]byte, 0, SignatureSize)
+	out = append(out, sig.R.ToAffineCompressed()[1:]...)
+	return append(out, sig.S.Bytes()...), nil
+}
+
+// UnmarshalBinary parses the 64 byte encoding of a signature. It fails for nonces that are not the
+// x coordinate of a point and scalars that are not below the group order.
+func (sig *Signature) UnmarshalBinary(input []byte) error {
+	if len(input) != SignatureSize {
+		return fmt.Errorf("%w: %d bytes", ErrInvalidSignature, len(input))
+	}
+	r, err := liftX(input[:32])
+	if err != nil {
+		return fmt.Errorf("%w: nonce: %v", ErrInvalidSignature, err)
+	}
+	s, err := curves.K256().Scalar.SetBytes(input[32:])
+	if err != nil {
+		return fmt.Errorf("%w: s: %v", ErrInvalidSignature, err)
+	}
+	sig.R, sig.S = r, s
+	return nil
+}
+
+// Sign signs a message of any length. The 32 bytes of auxiliary randomness are mixed into the nonce;
+// they are read from crypto/rand when aux is nil.
+func Sign(sk *SecretKey, msg, aux []byte) (*Signature, error) {
+	if aux == nil {
+		aux = make([]byte, 32)
+		if _, err := rand.Read(aux); err != nil {
+			return nil, err
+		}
+	}
+	if len(aux) != 32 {
+		return nil, fmt.Errorf("auxiliary randomness of %d bytes", len(aux))
+	}
+	d := sk.evenScalar()
+	pub := sk.PublicKey().Bytes()
+
+	t := TaggedHash(TagAux, aux)
+	for i, b := range d.Bytes() {
+		t[i] ^= b
+	}
+	k := hashToScalar(TaggedHash(TagNonce, t[:], pub, msg))
+	if k.IsZero() {
+		return nil, errors.New("derived nonce is zero")
+	}
+	r := curves.K256().ScalarBaseMult(k)
+	if hasOddY(r) {
+		k, r = k.Neg(), r.Neg()
+	}
+	e := Challenge(r.ToAffineCompressed()[1:], pub, msg)
+	sig := &Signature{R: r, S: k.Add(e.Mul(d))}
+	if !Verify(sk.PublicKey(), msg, sig) {
+		return nil, errors.New("created signature does not verify")
+	}
+	return sig, nil
+}
+
+// Verify reports whether sig is a signature of msg by the public key
+func Verify(pk *PublicKey, msg []byte, sig *Signature) bool {
+	if sig == nil || sig.R == nil || sig.S == nil || sig.R.IsIdentity() {
+		return false
+	}
+	e := Challenge(sig.R.ToAffineCompressed()[1:], pk.Bytes(), msg)
+	// R' = sG - eP
+	r := curves.K256().ScalarBaseMult(sig.S).Sub(pk.point.Mul(e))
+	return !r.IsIdentity() && !hasOddY(r) && r.Equal(sig.R)
+}
+
+// BatchVerify reports whether all the signatures are valid, checking with random weights a_i that
+// (s_1 + a_2s_2 + ... + a_us_u)G = R_1 + a_2R_2 + ... + a_uR_u + e_1P_1 + a_2e_2P_2 + ... + a_ue_uP_u
+func BatchVerify(pks []*PublicKey, msgs [][]byte, sigs []*Signature) (bool, error) {
+	if len(pks) != len(msgs) || len(pks) != len(sigs) {
+		return false, fmt.Errorf("%d public keys, %d messages and %d signatures", len(pks), len(msgs), len(sigs))
+	}
+	if len(sigs) == 0 {
+		return false, errors.New("no signatures to verify")
+	}
+	curve := curves.K256()
+	points := make([]curves.Point, 0, 2*len(sigs))
+	scalars := make([]curves.Scalar, 0, 2*len(sigs))
+	s := curve.Scalar.Zero()
+	for i, sig := range sigs {
+		if sig == nil || sig.R == nil || sig.S == nil || sig.R.IsIdentity() || hasOddY(sig.R) {
+			return false, nil
+		}
+		a := curve.Scalar.One()
+		if i > 0 {
+			a = curve.Scalar.Random(rand.Reader)
+		}
+		e := Challenge(sig.R.ToAffineCompressed()[1:], pks[i].Bytes(), msgs[i])
+		s = s.Add(a.Mul(sig.S))
+		points = append(points, sig.R, pks[i].point)
+		scalars = append(scalars, a, a.Mul(e))
+	}
+	rhs := curve.Point.SumOfProducts(points, scalars)
+	if rhs == nil {
+		return false, errors.New("failed to compute the batch multi-scalar multiplication")
+	}
+	return curve.ScalarBaseMult(s).Equal(rhs), nil
+}
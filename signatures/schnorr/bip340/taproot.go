@@ -0,0 +1,92 @@
+package bip340
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/sonr-io/crypto/core/curves"
+)
+
+// LeafVersionTapScript is the leaf version of BIP-342 tapscripts
+const LeafVersionTapScript = 0xc0
+
+// TapTweak returns the BIP-341 tweak t = int(hash_TapTweak(P || merkleRoot)) of an internal key.
+// The merkle root is empty for outputs without a script path.
+func TapTweak(internal *PublicKey, merkleRoot []byte) (curves.Scalar, error) {
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return nil, fmt.Errorf("merkle root of %d bytes", len(merkleRoot))
+	}
+	t, err := curves.K256().Scalar.SetBytes(tapTweakHash(internal, merkleRoot))
+	if err != nil {
+		return nil, errors.New("taproot tweak is not below the group order")
+	}
+	return t, nil
+}
+
+func tapTweakHash(internal *PublicKey, merkleRoot []byte) []byte {
+	h := TaggedHash(TagTapTweak, internal.Bytes(), merkleRoot)
+	return h[:]
+}
+
+// TweakPublicKey returns the x-only Taproot output key Q = P + tG of an internal key, and whether Q
+// has an odd Y coordinate, the parity bit of script path control blocks
+func TweakPublicKey(internal *PublicKey, merkleRoot []byte) (*PublicKey, bool, error) {
+	t, err := TapTweak(internal, merkleRoot)
+	if err != nil {
+		return nil, false, err
+	}
+	q := internal.point.Add(curves.K256().ScalarBaseMult(t))
+	if q.IsIdentity() {
+		return nil, false, errors.New("taproot output key is the identity")
+	}
+	return &PublicKey{point: evenY(q)}, hasOddY(q), nil
+}
+
+// TweakSecretKey returns the secret key of the Taproot output key of the secret key's x-only public
+// key, the key that signs key path spends
+func TweakSecretKey(sk *SecretKey, merkleRoot []byte) (*SecretKey, error) {
+	t, err := TapTweak(sk.PublicKey(), merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	d := sk.evenScalar().Add(t)
+	if d.IsZero() {
+		return nil, errors.New("tweaked secret key is zero")
+	}
+	return &SecretKey{scalar: d}, nil
+}
+
+// TapLeafHash returns the BIP-341 leaf hash hash_TapLeaf(version || compact_size(script) || script)
+func TapLeafHash(version byte, script []byte) [32]byte {
+	return TaggedHash(TagTapLeaf, []byte{version}, compactSize(uint64(len(script))), script)
+}
+
+// TapBranchHash returns the BIP-341 branch hash of two child hashes, hashed in lexicographic order
+func TapBranchHash(a, b []byte) [32]byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return TaggedHash(TagTapBranch, a, b)
+}
+
+// compactSize returns the bitcoin variable length encoding of n
+func compactSize(n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return []byte{byte(n)}
+	case n <= 0xffff:
+		b := []byte{0xfd, 0, 0}
+		binary.LittleEndian.PutUint16(b[1:], uint16(n))
+		return b
+	case n <= 0xffffffff:
+		b := []byte{0xfe, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(b[1:], uint32(n))
+		return b
+	default:
+		b := []byte{0xff, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint64(b[1:], n)
+		return b
+	}
+}
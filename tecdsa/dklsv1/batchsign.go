@@ -0,0 +1,162 @@
+package dklsv1
+
+import (
+	"crypto/ed25519"
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1/sign"
+)
+
+// Batch signing signs many messages in one run of the sign protocol. Every message is signed in a sub-session of its
+// own, with its own seeds and instance key, but the messages of all the sub-sessions travel together, so a batch takes
+// as many round trips as a single signature, and the multiplications of up to sign.SessionsPerExtension sub-sessions
+// run in one KOS extension, so the cost of the extension is amortized over the batch. See sign.AliceBatch. Batched
+// messages use the payloads of protocol.Version2.
+
+// AliceBatchSign DKLS batch sign implementation that satisfies the protocol iterator interface.
+type AliceBatchSign struct {
+	protoStepper
+	batch *sign.AliceBatch
+}
+
+// BobBatchSign DKLS batch sign implementation that satisfies the protocol iterator interface.
+type BobBatchSign struct {
+	protoStepper
+	batch *sign.BobBatch
+
+	// Signatures are the signatures of the messages, in order, once the protocol completed.
+	Signatures []*curves.EcdsaSignature
+}
+
+var (
+	// Static type assertions
+	_ protocol.Iterator = &AliceBatchSign{}
+	_ protocol.Iterator = &BobBatchSign{}
+)
+
+// checkBatch validates the messages and version of a batch sign protocol.
+func checkBatch(messages [][]byte, version uint) error {
+	if version != protocol.Version2 {
+		return errors.New("batch signing requires version 2")
+	}
+	if len(messages) == 0 {
+		return errors.New("no messages to sign")
+	}
+	return nil
+}
+
+// NewAliceBatchSign creates a new protocol that can compute the signatures of messages as Alice, hashing each one with
+// a hash from newHash. Requires dkg state that was produced at the end of DKG.Output().
+func NewAliceBatchSign(curve *curves.Curve, newHash func() hash.Hash, messages [][]byte, dkgResultMessage *protocol.Message, version uint) (*AliceBatchSign, error) {
+	if err := checkBatch(messages, version); err != nil {
+		return nil, err
+	}
+	dkgResult, err := DecodeAliceDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	a := &AliceBatchSign{batch: sign.NewAliceBatch(curve, newHash, dkgResult, len(messages))}
+	a.steps = []func(message *protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			seeds, err := a.batch.Round1GenerateRandomSeeds()
+			if err != nil {
+				return nil, err
+			}
+			return encodeBatch(seeds, "1", version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Output := &sign.BatchSignRound2Output{}
+			if err := decodeBatchRound(input, round2Output); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round3Output, err := a.batch.Round3Sign(messages, round2Output)
+			if err != nil {
+				return nil, err
+			}
+			return encodeBatchRound(round3Output, "3", version)
+		},
+	}
+	return a, nil
+}
+
+// NewBobBatchSign creates a new protocol that can compute the signatures of messages as Bob. See NewAliceBatchSign.
+func NewBobBatchSign(curve *curves.Curve, newHash func() hash.Hash, messages [][]byte, dkgResultMessage *protocol.Message, version uint) (*BobBatchSign, error) {
+	if err := checkBatch(messages, version); err != nil {
+		return nil, err
+	}
+	dkgResult, err := DecodeBobDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	b := &BobBatchSign{batch: sign.NewBobBatch(curve, newHash, dkgResult, len(messages))}
+	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
+		func(input *protocol.Message) (*protocol.Message, error) {
+			seeds, err := decodeBatch[[32]byte](input, len(messages))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round2Output, err := b.batch.Round2Initialize(seeds)
+			if err != nil {
+				return nil, err
+			}
+			return encodeBatchRound(round2Output, "2", version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round3Output := &sign.BatchSignRound3Output{}
+			if err := decodeBatchRound(input, round3Output); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if err := b.batch.Round4Final(messages, round3Output); err != nil {
+				return nil, err
+			}
+			b.Signatures = b.batch.Signatures
+			return nil, nil
+		},
+	}
+	return b, nil
+}
+
+// SetIdentityKeys makes Alice attest her round 3 messages with her identity key. See sign.AliceBatch.SetIdentityKeys.
+func (a *AliceBatchSign) SetIdentityKeys(key ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	a.batch.SetIdentityKeys(key, peerKey)
+}
+
+// SetIdentityKeys makes Bob require Alice's round 3 messages to be attested with peerKey. See
+// sign.BobBatch.SetIdentityKeys.
+func (b *BobBatchSign) SetIdentityKeys(key ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	b.batch.SetIdentityKeys(key, peerKey)
+}
+
+// SetKeyTweak makes Alice sign every message for the key sk + tweak. See sign.AliceBatch.SetKeyTweak.
+func (a *AliceBatchSign) SetKeyTweak(tweak curves.Scalar) {
+	a.batch.SetKeyTweak(tweak)
+}
+
+// SetKeyTweak makes Bob sign every message for, and verify against, the key sk + tweak. See sign.BobBatch.SetKeyTweak.
+func (b *BobBatchSign) SetKeyTweak(tweak curves.Scalar) {
+	b.batch.SetKeyTweak(tweak)
+}
+
+// Result always returns an error.
+// Alice does not compute a signature in the DKLS protocol; only Bob computes the signature.
+func (a *AliceBatchSign) Result(_ uint) (*protocol.Message, error) {
+	return nil, errors.New("dkls.Alice does not produce a signature")
+}
+
+// Result returns the signatures that Bob computed, in the order of the messages, if the signing protocol completed
+// successfully. Decode them with DecodeSignatures.
+func (b *BobBatchSign) Result(version uint) (*protocol.Message, error) {
+	// We can't produce the signatures until the protocol completes
+	if !b.complete() {
+		return nil, nil
+	}
+	if b.batch == nil {
+		// Object wasn't created with NewBobBatchSign()
+		return nil, protocol.ErrNotInitialized
+	}
+	return encodeBatch(b.Signatures, "signatures", version)
+}
@@ -36,6 +36,24 @@ type BobSign struct {
 	*sign.Bob
 }
 
+// AlicePresign DKLS presign implementation that satisfies the protocol iterator interface.
+type AlicePresign struct {
+	protoStepper
+	*sign.Alice
+
+	// Presignature is Alice's presignature, once the protocol completed.
+	Presignature *sign.AlicePresignature
+}
+
+// BobPresign DKLS presign implementation that satisfies the protocol iterator interface.
+type BobPresign struct {
+	protoStepper
+	*sign.Bob
+
+	// Presignature is Bob's presignature, once the protocol completed.
+	Presignature *sign.BobPresignature
+}
+
 // AliceRefresh DKLS refresh implementation that satisfies the protocol iterator interface.
 type AliceRefresh struct {
 	protoStepper
@@ -54,6 +72,8 @@ var (
 	_ protocol.Iterator = &BobDkg{}
 	_ protocol.Iterator = &AliceSign{}
 	_ protocol.Iterator = &BobSign{}
+	_ protocol.Iterator = &AlicePresign{}
+	_ protocol.Iterator = &BobPresign{}
 	_ protocol.Iterator = &AliceRefresh{}
 	_ protocol.Iterator = &BobRefresh{}
 )
@@ -292,6 +312,119 @@ func (b *BobSign) Result(version uint) (*protocol.Message, error) {
 	return encodeSignature(b.Bob.Signature, version)
 }
 
+// NewAlicePresign creates a new protocol that can compute a presignature as Alice, i.e. run the rounds of signing that
+// do not depend on the message ahead of time. The protocol exchanges the same messages as signing, except that Alice's
+// round 3 message has no EtaSig. Requires dkg state that was produced at the end of DKG.Output().
+func NewAlicePresign(curve *curves.Curve, hash hash.Hash, dkgResultMessage *protocol.Message, version uint) (*AlicePresign, error) {
+	dkgResult, err := DecodeAliceDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	a := &AlicePresign{Alice: sign.NewAlice(curve, hash, dkgResult)}
+	a.steps = []func(message *protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			aliceCommitment, err := a.Round1GenerateRandomSeed()
+			if err != nil {
+				return nil, err
+			}
+			return encodeSignRound1Output(aliceCommitment, version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round2Output, err := decodeSignRound3Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round3Output, presignature, err := a.Round3Presign(round2Output)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			a.Presignature = presignature
+			return encodeSignRound3Output(round3Output, version)
+		},
+	}
+	return a, nil
+}
+
+// NewBobPresign creates a new protocol that can compute a presignature as Bob. See NewAlicePresign.
+func NewBobPresign(curve *curves.Curve, hash hash.Hash, dkgResultMessage *protocol.Message, version uint) (*BobPresign, error) {
+	dkgResult, err := DecodeBobDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	b := &BobPresign{Bob: sign.NewBob(curve, hash, dkgResult)}
+	b.steps = []func(message *protocol.Message) (*protocol.Message, error){
+		func(input *protocol.Message) (*protocol.Message, error) {
+			commitment, err := decodeSignRound2Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round2Output, err := b.Round2Initialize(commitment)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return encodeSignRound2Output(round2Output, version)
+		},
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input, err := decodeSignRound4Input(input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if b.Presignature, err = b.Round4Presign(round4Input); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return nil, nil
+		},
+	}
+	return b, nil
+}
+
+// Result always returns an error.
+// Presignatures only live in memory and are never serialized; use Presignature instead.
+func (a *AlicePresign) Result(_ uint) (*protocol.Message, error) {
+	return nil, errors.New("presignatures are not serialized")
+}
+
+// Result always returns an error.
+// Presignatures only live in memory and are never serialized; use Presignature instead.
+func (b *BobPresign) Result(_ uint) (*protocol.Message, error) {
+	return nil, errors.New("presignatures are not serialized")
+}
+
+// SignWithAlicePresignature uses up Alice's presignature to sign the message, and returns her only message of the
+// online phase, which Bob passes to SignWithBobPresignature.
+func SignWithAlicePresignature(presignature *sign.AlicePresignature, message []byte, version uint) (*protocol.Message, error) {
+	output, err := presignature.Sign(message)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return encodePresignedSignOutput(output, version)
+}
+
+// SignWithBobPresignature takes the presignature Alice signed with from Bob's pool, uses it up to complete the
+// signature of the message, and returns the signature encoded as BobSign.Result does. If signing fails without using
+// up the presignature, it goes back to the pool.
+func SignWithBobPresignature(pool *sign.PresignaturePool[*sign.BobPresignature], message []byte, aliceMessage *protocol.Message, version uint) (*protocol.Message, error) {
+	aliceOutput, err := decodePresignedSignInput(aliceMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	presignature, ok := pool.Take(aliceOutput.PresignatureId)
+	if !ok {
+		return nil, errors.New("unknown or used presignature")
+	}
+	signature, err := presignature.Sign(message, aliceOutput)
+	if err != nil {
+		// A malformed message from Alice leaves the presignature unused, so it can still complete her signature
+		if !presignature.Used() {
+			if putErr := pool.Put(presignature); putErr != nil {
+				return nil, errors.Wrapf(err, "cannot return the presignature to the pool: %v", putErr)
+			}
+		}
+		return nil, errors.WithStack(err)
+	}
+	return encodeSignature(signature, version)
+}
+
 // NewAliceRefresh creates a new protocol that can compute a key refresh as Alice
 func NewAliceRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, version uint) (*AliceRefresh, error) {
 	dkgResult, err := DecodeAliceDkgResult(dkgResultMessage)
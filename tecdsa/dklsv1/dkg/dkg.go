@@ -10,17 +10,22 @@
 package dkg
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 
 	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/ot/base/simplest"
 	"github.com/sonr-io/crypto/ot/extension/kos"
 	"github.com/sonr-io/crypto/zkp/schnorr"
 )
 
+// ChainCodeSize is the length in bytes of the BIP32 chain code produced by DKG.
+const ChainCodeSize = 32
+
 // AliceOutput is the result of running DKG for Alice. It contains both the public and secret values that are needed
 // for signing.
 type AliceOutput struct {
@@ -36,6 +41,11 @@ type AliceOutput struct {
 	// This output must be kept secret. Although, if it is lost the users can run another OT protocol and obtain
 	// new values to replace it.
 	SeedOtResult *simplest.ReceiverOutput
+
+	// ChainCode is a 32-byte value that both parties extract from the joint DKG transcript. Together with the
+	// public key it forms a BIP32 extended public key, from which non-hardened child keys can be derived.
+	// It is not secret on its own, but should not be published alongside a child key.
+	ChainCode []byte
 }
 
 // BobOutput is the result of running DKG for Bob. It contains both the public and secret values that are needed
@@ -53,6 +63,11 @@ type BobOutput struct {
 	// This output must be kept secret. Although, if it is lost the users can run another OT protocol and obtain
 	// new values to replace it.
 	SeedOtResult *simplest.SenderOutput
+
+	// ChainCode is a 32-byte value that both parties extract from the joint DKG transcript. Together with the
+	// public key it forms a BIP32 extended public key, from which non-hardened child keys can be derived.
+	// It is not secret on its own, but should not be published alongside a child key.
+	ChainCode []byte
 }
 
 // Alice struct encoding Alice's state during one execution of the overall signing algorithm.
@@ -73,6 +88,13 @@ type Alice struct {
 	// publicKey is the joint public key of Alice and Bob.
 	publicKey curves.Point
 
+	// chainCode is the BIP32 chain code agreed on through the DKG transcript.
+	chainCode []byte
+
+	// peerKey is Bob's identity key, which his openings of the seed OT must be attested with, nil to run without
+	// attestation.
+	peerKey ed25519.PublicKey
+
 	curve *curves.Curve
 
 	transcript *merlin.Transcript
@@ -98,6 +120,12 @@ type Bob struct {
 	// 32-byte transcript salt which will be used for Alice's schnorr proof
 	aliceSalt [simplest.DigestSize]byte
 
+	// chainCode is the BIP32 chain code agreed on through the DKG transcript.
+	chainCode []byte
+
+	// identityKey is the long-term key Bob attests his openings of the seed OT with, nil to run without attestation.
+	identityKey ed25519.PrivateKey
+
 	curve *curves.Curve
 
 	transcript *merlin.Transcript
@@ -129,6 +157,19 @@ func NewBob(curve *curves.Curve) *Bob {
 	}
 }
 
+// SetIdentityKeys sets the long-term identity key of Alice and the identity key of Bob, so that a failed check of
+// Bob's openings of the seed OT comes with evidence that a third party can verify. Bob is the seed OT sender, so
+// Alice only needs his key; both parties take both keys so that they are set up the same way.
+func (alice *Alice) SetIdentityKeys(_ ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	alice.peerKey = peerKey
+}
+
+// SetIdentityKeys sets the long-term identity key of Bob, which he attests his openings of the seed OT with, and the
+// identity key of Alice. See Alice.SetIdentityKeys.
+func (bob *Bob) SetIdentityKeys(key ed25519.PrivateKey, _ ed25519.PublicKey) {
+	bob.identityKey = key
+}
+
 // Round1GenerateRandomSeed Bob flips random coins, and sends these to Alice
 // in this round, Bob flips 32 random bytes and sends them to Alice.
 // note that this is not _explicitly_ given as part of the protocol in https://eprint.iacr.org/2018/499.pdf, Protocol 1).
@@ -159,6 +200,7 @@ func (alice *Alice) Round2CommitToProof(bobSeed [simplest.DigestSize]byte) (*Rou
 	if err != nil {
 		return nil, errors.Wrap(err, "alice constructing new seed OT receiver in Alice DKG round 1")
 	}
+	alice.receiver.SetSenderKey(alice.peerKey)
 
 	alice.secretKeyShare = alice.curve.Scalar.Random(rand.Reader)
 	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("salt for alice schnorr"), simplest.DigestSize))
@@ -188,10 +230,14 @@ func (bob *Bob) Round3SchnorrProve(round2Output *Round2Output) (*schnorr.Proof,
 	if err != nil {
 		return nil, errors.Wrap(err, "bob constructing new OT sender in DKG round 2")
 	}
+	bob.sender.SetIdentityKey(bob.identityKey)
 	// extract alice's salt in the right order; we won't use this until she reveals her proof and we verify it below
 	copy(bob.aliceSalt[:], bob.transcript.ExtractBytes([]byte("salt for alice schnorr"), simplest.DigestSize))
 	bob.secretKeyShare = bob.curve.Scalar.Random(rand.Reader)
 	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("salt for bob schnorr"), simplest.DigestSize))
+	// both transcripts have absorbed both seeds and performed the same extractions at this point, so the chain code
+	// is identical for alice and bob, and uniformly random as long as either party is honest.
+	bob.chainCode = bob.transcript.ExtractBytes([]byte("chain code"), ChainCodeSize)
 	bob.prover = schnorr.NewProver(bob.curve, nil, uniqueSessionId[:])
 	proof, err := bob.prover.Prove(bob.secretKeyShare)
 	if err != nil {
@@ -205,6 +251,7 @@ func (alice *Alice) Round4VerifyAndReveal(proof *schnorr.Proof) (*schnorr.Proof,
 	var err error
 	uniqueSessionId := [simplest.DigestSize]byte{}
 	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("salt for bob schnorr"), simplest.DigestSize))
+	alice.chainCode = alice.transcript.ExtractBytes([]byte("chain code"), ChainCodeSize)
 	if err = schnorr.Verify(proof, alice.curve, nil, uniqueSessionId[:]); err != nil {
 		return nil, errors.Wrap(err, "alice's verification of Bob's schnorr proof failed in DKG round 3")
 	}
@@ -242,13 +289,14 @@ func (alice *Alice) Round8DkgRound4Ot(challenge []simplest.OtChallenge) ([]simpl
 }
 
 // Round9DkgRound5Ot is a thin wrapper around the 5th round of seed OT protocol.
-func (bob *Bob) Round9DkgRound5Ot(challengeResponses []simplest.OtChallengeResponse) ([]simplest.ChallengeOpening, error) {
+func (bob *Bob) Round9DkgRound5Ot(challengeResponses []simplest.OtChallengeResponse) (*simplest.Round5Output, error) {
 	return bob.sender.Round5Verify(challengeResponses)
 }
 
 // Round10DkgRound6Ot is a thin wrapper around the 6th round of seed OT protocol.
-func (alice *Alice) Round10DkgRound6Ot(challengeOpenings []simplest.ChallengeOpening) error {
-	return alice.receiver.Round6Verify(challengeOpenings)
+func (alice *Alice) Round10DkgRound6Ot(challengeOpenings *simplest.Round5Output) error {
+	// Bob is the seed OT sender, so a failed check is his.
+	return protocol.Reattribute(alice.receiver.Round6Verify(challengeOpenings), "bob")
 }
 
 // Output returns the output of the DKG operation. Must be called after step 9. Calling it before that step
@@ -258,6 +306,7 @@ func (alice *Alice) Output() *AliceOutput {
 		PublicKey:      alice.publicKey,
 		SecretKeyShare: alice.secretKeyShare,
 		SeedOtResult:   alice.receiver.Output,
+		ChainCode:      alice.chainCode,
 	}
 }
 
@@ -268,5 +317,6 @@ func (bob *Bob) Output() *BobOutput {
 		PublicKey:      bob.publicKey,
 		SecretKeyShare: bob.secretKeyShare,
 		SeedOtResult:   bob.sender.Output,
+		ChainCode:      bob.chainCode,
 	}
 }
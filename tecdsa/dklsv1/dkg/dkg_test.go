@@ -7,12 +7,16 @@
 package dkg
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
 	"github.com/sonr-io/crypto/ot/extension/kos"
 )
 
@@ -66,10 +70,54 @@ func TestDkg(t *testing.T) {
 			computedPublicKeyB := pkB.Mul(alice.Output().SecretKeyShare)
 			require.True(tt, computedPublicKeyB.Equal(alice.Output().PublicKey))
 			require.True(tt, computedPublicKeyB.Equal(bob.Output().PublicKey))
+
+			require.Len(tt, alice.Output().ChainCode, ChainCodeSize)
+			require.Equal(tt, alice.Output().ChainCode, bob.Output().ChainCode)
 		})
 	}
 }
 
+func TestDkgBlamesBobForBadOtOpening(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	alice := NewAlice(curve)
+	bob := NewBob(curve)
+	alicePublic, aliceKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	bobPublic, bobKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	alice.SetIdentityKeys(aliceKey, bobPublic)
+	bob.SetIdentityKeys(bobKey, alicePublic)
+
+	seed, err := bob.Round1GenerateRandomSeed()
+	require.NoError(t, err)
+	round3Output, err := alice.Round2CommitToProof(seed)
+	require.NoError(t, err)
+	proof, err := bob.Round3SchnorrProve(round3Output)
+	require.NoError(t, err)
+	proof, err = alice.Round4VerifyAndReveal(proof)
+	require.NoError(t, err)
+	proof, err = bob.Round5DecommitmentAndStartOt(proof)
+	require.NoError(t, err)
+	compressedReceiversMaskedChoice, err := alice.Round6DkgRound2Ot(proof)
+	require.NoError(t, err)
+	challenge, err := bob.Round7DkgRound3Ot(compressedReceiversMaskedChoice)
+	require.NoError(t, err)
+	challengeResponse, err := alice.Round8DkgRound4Ot(challenge)
+	require.NoError(t, err)
+	// Bob opens a key of the first OT that is not the one he derived, and attests the opening.
+	bob.sender.Output.OneTimePadEncryptionKeys[0][1][0] ^= 1
+	challengeOpenings, err := bob.Round9DkgRound5Ot(challengeResponse)
+	require.NoError(t, err)
+
+	var abort *protocol.AbortError
+	require.ErrorAs(t, alice.Round10DkgRound6Ot(challengeOpenings), &abort)
+	require.Equal(t, "bob", abort.Party)
+	require.NotNil(t, abort.Evidence)
+	require.NoError(t, abort.Evidence.Verify())
+	require.True(t, abort.Evidence.(*simplest.OpeningEvidence).Attestation.PublicKey.Equal(bobPublic))
+}
+
 func BenchmarkDkg(b *testing.B) {
 	if testing.Short() {
 		b.SkipNow()
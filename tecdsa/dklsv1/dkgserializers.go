@@ -232,7 +232,7 @@ func decodeDkgRound9Input(m *protocol.Message) ([]simplest.OtChallengeResponse,
 	return decoded, nil
 }
 
-func encodeDkgRound9Output(opening []simplest.ChallengeOpening, version uint) (*protocol.Message, error) {
+func encodeDkgRound9Output(opening *simplest.Round5Output, version uint) (*protocol.Message, error) {
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
@@ -244,14 +244,14 @@ func encodeDkgRound9Output(opening []simplest.ChallengeOpening, version uint) (*
 	return newDkgProtocolMessage(buf.Bytes(), "9", version), nil
 }
 
-func decodeDkgRound10Input(m *protocol.Message) ([]simplest.ChallengeOpening, error) {
+func decodeDkgRound10Input(m *protocol.Message) (*simplest.Round5Output, error) {
 	if m.Version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
 	}
 	buf := bytes.NewBuffer(m.Payloads[payloadKey])
 	dec := gob.NewDecoder(buf)
-	decoded := []simplest.ChallengeOpening{}
-	if err := dec.Decode(&decoded); err != nil {
+	decoded := &simplest.Round5Output{}
+	if err := dec.Decode(decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
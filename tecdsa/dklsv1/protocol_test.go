@@ -17,6 +17,7 @@ import (
 	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/ot/extension/kos"
 	"github.com/sonr-io/crypto/tecdsa/dklsv1/dkg"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1/sign"
 )
 
 // For DKG bob starts first. For refresh and sign, Alice starts first.
@@ -204,6 +205,117 @@ func TestDkgSignProto(t *testing.T) {
 	})
 }
 
+// DKG > Presign > Sign with presignature > Output
+func TestDkgPresignProto(t *testing.T) {
+	curve := curves.K256()
+	aliceDkg := NewAliceDkg(curve, protocol.Version1)
+	bobDkg := NewBobDkg(curve, protocol.Version1)
+	aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+	aliceDkgResultMessage, err := aliceDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+	bobDkgResultMessage, err := bobDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+
+	// Offline phase
+	alicePool := sign.NewPresignaturePool[*sign.AlicePresignature]()
+	bobPool := sign.NewPresignaturePool[*sign.BobPresignature]()
+	for i := 0; i < 2; i++ {
+		alicePresign, err := NewAlicePresign(curve, sha3.New256(), aliceDkgResultMessage, protocol.Version1)
+		require.NoError(t, err)
+		bobPresign, err := NewBobPresign(curve, sha3.New256(), bobDkgResultMessage, protocol.Version1)
+		require.NoError(t, err)
+		aErr, bErr = runIteratedProtocol(alicePresign, bobPresign)
+		require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+		require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+		_, err = alicePresign.Result(protocol.Version1)
+		require.Error(t, err)
+		require.NoError(t, alicePool.Put(alicePresign.Presignature))
+		require.NoError(t, bobPool.Put(bobPresign.Presignature))
+	}
+
+	// a malformed message from Alice does not use up Bob's presignature
+	presignature, ok := alicePool.TakeAny()
+	require.True(t, ok)
+	malformed, err := encodePresignedSignOutput(&sign.PresignedSignOutput{PresignatureId: presignature.ID()}, protocol.Version1)
+	require.NoError(t, err)
+	_, err = SignWithBobPresignature(bobPool, []byte("first"), malformed, protocol.Version1)
+	require.Error(t, err)
+	require.Equal(t, 2, bobPool.Len())
+	require.NoError(t, alicePool.Put(presignature))
+
+	// Online phase
+	for _, msg := range [][]byte{[]byte("first"), []byte("second")} {
+		presignature, ok := alicePool.TakeAny()
+		require.True(t, ok)
+		aliceMessage, err := SignWithAlicePresignature(presignature, msg, protocol.Version1)
+		require.NoError(t, err)
+		resultMessage, err := SignWithBobPresignature(bobPool, msg, aliceMessage, protocol.Version1)
+		require.NoError(t, err)
+		result, err := DecodeSignature(resultMessage)
+		require.NoError(t, err)
+		digest := sha3.Sum256(msg)
+		recovered, err := curves.RecoverEcdsaPublicKey(curve, digest[:], result)
+		require.NoError(t, err)
+		require.True(t, recovered.Equal(aliceDkg.Output().PublicKey))
+
+		// neither party can use its presignature again
+		_, err = SignWithAlicePresignature(presignature, msg, protocol.Version1)
+		require.ErrorIs(t, err, sign.ErrPresignatureUsed)
+		_, err = SignWithBobPresignature(bobPool, msg, aliceMessage, protocol.Version1)
+		require.Error(t, err)
+	}
+}
+
+// DKG > Batch sign > Output
+func TestDkgBatchSignProto(t *testing.T) {
+	curve := curves.P256()
+	aliceDkg := NewAliceDkg(curve, protocol.Version1)
+	bobDkg := NewBobDkg(curve, protocol.Version1)
+	aErr, bErr := runIteratedProtocol(bobDkg, aliceDkg)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+	aliceDkgResultMessage, err := aliceDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+	bobDkgResultMessage, err := bobDkg.Result(protocol.Version1)
+	require.NoError(t, err)
+
+	msgs := [][]byte{[]byte("first"), []byte("second"), []byte("third")}
+	_, err = NewAliceBatchSign(curve, sha3.New256, msgs, aliceDkgResultMessage, protocol.Version1)
+	require.Error(t, err)
+	_, err = NewBobBatchSign(curve, sha3.New256, nil, bobDkgResultMessage, protocol.Version2)
+	require.Error(t, err)
+
+	aliceSign, err := NewAliceBatchSign(curve, sha3.New256, msgs, aliceDkgResultMessage, protocol.Version2)
+	require.NoError(t, err)
+	bobSign, err := NewBobBatchSign(curve, sha3.New256, msgs, bobDkgResultMessage, protocol.Version2)
+	require.NoError(t, err)
+	aErr, bErr = runIteratedProtocol(aliceSign, bobSign)
+	require.ErrorIs(t, aErr, protocol.ErrProtocolFinished)
+	require.ErrorIs(t, bErr, protocol.ErrProtocolFinished)
+
+	resultMessage, err := bobSign.Result(protocol.Version2)
+	require.NoError(t, err)
+	signatures, err := DecodeSignatures(resultMessage)
+	require.NoError(t, err)
+	require.Len(t, signatures, len(msgs))
+	for i, msg := range msgs {
+		digest := sha3.Sum256(msg)
+		recovered, err := curves.RecoverEcdsaPublicKey(curve, digest[:], signatures[i])
+		require.NoError(t, err)
+		require.True(t, recovered.Equal(aliceDkg.Output().PublicKey))
+	}
+
+	// a batch message of another size is rejected
+	message, err := encodeBatch(make([][32]byte, 2), "1", protocol.Version2)
+	require.NoError(t, err)
+	bobSign, err = NewBobBatchSign(curve, sha3.New256, msgs, bobDkgResultMessage, protocol.Version2)
+	require.NoError(t, err)
+	_, err = bobSign.Next(message)
+	require.Error(t, err)
+}
+
 //
 // // Decode > NewDklsSign > Sign > Output
 // // NOTE: this cold-start test ensures backwards compatibility with durable,
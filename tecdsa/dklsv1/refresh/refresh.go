@@ -14,12 +14,14 @@
 package refresh
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
 
 	"github.com/gtank/merlin"
 	"github.com/pkg/errors"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/ot/base/simplest"
 	"github.com/sonr-io/crypto/ot/extension/kos"
 	"github.com/sonr-io/crypto/tecdsa/dklsv1/dkg"
@@ -38,6 +40,13 @@ type Alice struct {
 	// publicKey is the joint public key of Alice and Bob.
 	publicKey curves.Point
 
+	// chainCode is the BIP32 chain code from DKG. Refresh does not change it.
+	chainCode []byte
+
+	// peerKey is Bob's identity key, which his openings of the seed OT must be attested with, nil to run without
+	// attestation.
+	peerKey ed25519.PublicKey
+
 	curve *curves.Curve
 
 	transcript *merlin.Transcript
@@ -55,6 +64,12 @@ type Bob struct {
 	// publicKey is the joint public key of Alice and Bob.
 	publicKey curves.Point
 
+	// chainCode is the BIP32 chain code from DKG. Refresh does not change it.
+	chainCode []byte
+
+	// identityKey is the long-term key Bob attests his openings of the seed OT with, nil to run without attestation.
+	identityKey ed25519.PrivateKey
+
 	curve *curves.Curve
 
 	transcript *merlin.Transcript
@@ -71,6 +86,7 @@ func NewAlice(curve *curves.Curve, dkgOutput *dkg.AliceOutput) *Alice {
 		curve:          curve,
 		secretKeyShare: dkgOutput.SecretKeyShare,
 		publicKey:      dkgOutput.PublicKey,
+		chainCode:      dkgOutput.ChainCode,
 		transcript:     merlin.NewTranscript("Coinbase_DKLs_Refresh"),
 	}
 }
@@ -81,10 +97,24 @@ func NewBob(curve *curves.Curve, dkgOutput *dkg.BobOutput) *Bob {
 		curve:          curve,
 		secretKeyShare: dkgOutput.SecretKeyShare,
 		publicKey:      dkgOutput.PublicKey,
+		chainCode:      dkgOutput.ChainCode,
 		transcript:     merlin.NewTranscript("Coinbase_DKLs_Refresh"),
 	}
 }
 
+// SetIdentityKeys sets the long-term identity key of Alice and the identity key of Bob, so that a failed check of
+// Bob's openings of the seed OT comes with evidence that a third party can verify. Bob is the seed OT sender, so
+// Alice only needs his key; both parties take both keys so that they are set up the same way.
+func (alice *Alice) SetIdentityKeys(_ ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	alice.peerKey = peerKey
+}
+
+// SetIdentityKeys sets the long-term identity key of Bob, which he attests his openings of the seed OT with, and the
+// identity key of Alice. See Alice.SetIdentityKeys.
+func (bob *Bob) SetIdentityKeys(key ed25519.PrivateKey, _ ed25519.PublicKey) {
+	bob.identityKey = key
+}
+
 func (alice *Alice) Round1RefreshGenerateSeed() curves.Scalar {
 	refreshSeed := alice.curve.Scalar.Random(rand.Reader)
 	alice.transcript.AppendMessage([]byte("alice refresh seed"), refreshSeed.Bytes())
@@ -109,6 +139,7 @@ func (bob *Bob) Round2RefreshProduceSeedAndMultiplyAndStartOT(aliceSeed curves.S
 	if err != nil {
 		return nil, errors.Wrap(err, "bob constructing new OT sender in refresh round 2")
 	}
+	bob.sender.SetIdentityKey(bob.identityKey)
 	seedOTRound1Output, err := bob.sender.Round1ComputeAndZkpToPublicKey()
 	if err != nil {
 		return nil, errors.Wrap(err, "bob computing round 1 of seed OT within refresh round 2")
@@ -140,6 +171,7 @@ func (alice *Alice) Round3RefreshMultiplyRound2Ot(input *RefreshRound2Output) ([
 	if err != nil {
 		return nil, errors.Wrap(err, "couldn't construct OT receiver")
 	}
+	alice.receiver.SetSenderKey(alice.peerKey)
 
 	return alice.receiver.Round2VerifySchnorrAndPadTransfer(input.SeedOTRound1Output)
 }
@@ -152,12 +184,13 @@ func (alice *Alice) Round5RefreshRound4Ot(challenge []simplest.OtChallenge) ([]s
 	return alice.receiver.Round4RespondToChallenge(challenge)
 }
 
-func (bob *Bob) Round6RefreshRound5Ot(challengeResponses []simplest.OtChallengeResponse) ([]simplest.ChallengeOpening, error) {
+func (bob *Bob) Round6RefreshRound5Ot(challengeResponses []simplest.OtChallengeResponse) (*simplest.Round5Output, error) {
 	return bob.sender.Round5Verify(challengeResponses)
 }
 
-func (alice *Alice) Round7DkgRound6Ot(challengeOpenings []simplest.ChallengeOpening) error {
-	return alice.receiver.Round6Verify(challengeOpenings)
+func (alice *Alice) Round7DkgRound6Ot(challengeOpenings *simplest.Round5Output) error {
+	// Bob is the seed OT sender, so a failed check is his.
+	return protocol.Reattribute(alice.receiver.Round6Verify(challengeOpenings), "bob")
 }
 
 func (alice *Alice) Output() *dkg.AliceOutput {
@@ -165,6 +198,7 @@ func (alice *Alice) Output() *dkg.AliceOutput {
 		PublicKey:      alice.publicKey,
 		SecretKeyShare: alice.secretKeyShare,
 		SeedOtResult:   alice.receiver.Output,
+		ChainCode:      alice.chainCode,
 	}
 }
 
@@ -173,5 +207,6 @@ func (bob *Bob) Output() *dkg.BobOutput {
 		PublicKey:      bob.publicKey,
 		SecretKeyShare: bob.secretKeyShare,
 		SeedOtResult:   bob.sender.Output,
+		ChainCode:      bob.chainCode,
 	}
 }
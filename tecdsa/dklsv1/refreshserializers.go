@@ -155,7 +155,7 @@ func decodeRefreshRound6Input(m *protocol.Message) ([]simplest.OtChallengeRespon
 	return decoded, nil
 }
 
-func encodeRefreshRound6Output(opening []simplest.ChallengeOpening, version uint) (*protocol.Message, error) {
+func encodeRefreshRound6Output(opening *simplest.Round5Output, version uint) (*protocol.Message, error) {
 	if err := versionIsSupported(version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
@@ -167,14 +167,14 @@ func encodeRefreshRound6Output(opening []simplest.ChallengeOpening, version uint
 	return newRefreshProtocolMessage(buf.Bytes(), "6", version), nil
 }
 
-func decodeRefreshRound7Input(m *protocol.Message) ([]simplest.ChallengeOpening, error) {
+func decodeRefreshRound7Input(m *protocol.Message) (*simplest.Round5Output, error) {
 	if err := versionIsSupported(m.Version); err != nil {
 		return nil, errors.Wrap(err, "version error")
 	}
 	buf := bytes.NewBuffer(m.Payloads[payloadKey])
 	dec := gob.NewDecoder(buf)
-	decoded := []simplest.ChallengeOpening{}
-	if err := dec.Decode(&decoded); err != nil {
+	decoded := &simplest.Round5Output{}
+	if err := dec.Decode(decoded); err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return decoded, nil
@@ -0,0 +1,293 @@
+package sign
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"hash"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1/dkg"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+// Batch signing signs many messages in one run of the protocol. Every message is signed in a session of its own, with
+// its own seeds, instance key and multiplication inputs, but the multiplications of the sessions run together in one
+// OT extension of kos.L cOTs per multiplication, so that the kappaOT extra cOTs and the consistency check of the
+// extension are paid for once per batch instead of twice per signature. The session id of the extension binds the
+// multiplication ids of all the sessions it serves. An extension holds at most kos.MaxBatchSize cOTs, so batches of
+// more than SessionsPerExtension messages run one extension per chunk of SessionsPerExtension sessions.
+
+// SessionsPerExtension is the number of sessions of a batch whose multiplications share an OT extension.
+const SessionsPerExtension = kos.MaxBatchSize / (kos.L * multiplicationCount)
+
+// BatchSignRound2Output is Bob's round 2 message of a batch. Seeds and DB hold the values of SignRound2Output of
+// every session, in order, and KosRound1Outputs start the OT extensions of the multiplications, one per chunk of
+// SessionsPerExtension sessions.
+type BatchSignRound2Output struct {
+	Seeds            [][simplest.DigestSize]byte
+	DB               []curves.Point
+	KosRound1Outputs []*kos.Round1Output
+}
+
+// BatchSessionRound3Output is Alice's round 3 message for one session of a batch, SignRound3Output without the
+// multiplications.
+type BatchSessionRound3Output struct {
+	RSchnorrProof *schnorr.Proof
+	RPrime        curves.Point
+	EtaPhi        curves.Scalar
+	EtaSig        curves.Scalar
+	Attestation   *protocol.Attestation
+}
+
+// BatchSignRound3Output is Alice's round 3 message of a batch. Sessions hold the messages of every session, in order,
+// and MultiplyRound2Outputs respond to the OT extensions of the multiplications, one per chunk of
+// SessionsPerExtension sessions.
+type BatchSignRound3Output struct {
+	Sessions              []*BatchSessionRound3Output
+	MultiplyRound2Outputs []*MultiplyRound2Output
+}
+
+// AliceBatch is Alice's state during one batch signing run.
+type AliceBatch struct {
+	sessions []*Alice
+}
+
+// BobBatch is Bob's state during one batch signing run. At the end of the joint computation, Bob obtains the
+// signatures.
+type BobBatch struct {
+	// Signatures are the signatures of the messages, in order, and the output of this protocol.
+	Signatures []*curves.EcdsaSignature
+
+	sessions          []*Bob
+	multiplyReceivers []*MultiplyReceiver // one per chunk of SessionsPerExtension sessions
+}
+
+// NewAliceBatch creates a party that can sign count messages in a batch in the role of Alice, hashing each one with a
+// hash from newHash.
+func NewAliceBatch(curve *curves.Curve, newHash func() hash.Hash, dkgOutput *dkg.AliceOutput, count int) *AliceBatch {
+	a := &AliceBatch{sessions: make([]*Alice, count)}
+	for i := range a.sessions {
+		a.sessions[i] = NewAlice(curve, newHash(), dkgOutput)
+	}
+	return a
+}
+
+// NewBobBatch creates a party that can sign count messages in a batch in the role of Bob. See NewAliceBatch.
+func NewBobBatch(curve *curves.Curve, newHash func() hash.Hash, dkgOutput *dkg.BobOutput, count int) *BobBatch {
+	b := &BobBatch{sessions: make([]*Bob, count)}
+	for i := range b.sessions {
+		b.sessions[i] = NewBob(curve, newHash(), dkgOutput)
+	}
+	return b
+}
+
+// SetIdentityKeys makes Alice attest her round 3 message of every session. See Alice.SetIdentityKeys.
+func (a *AliceBatch) SetIdentityKeys(key ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	for _, alice := range a.sessions {
+		alice.SetIdentityKeys(key, peerKey)
+	}
+}
+
+// SetIdentityKeys makes Bob require Alice's round 3 message of every session to be attested. See Bob.SetIdentityKeys.
+func (b *BobBatch) SetIdentityKeys(key ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	for _, bob := range b.sessions {
+		bob.SetIdentityKeys(key, peerKey)
+	}
+}
+
+// SetKeyTweak makes Alice sign every message for the key sk + tweak. See Alice.SetKeyTweak.
+func (a *AliceBatch) SetKeyTweak(tweak curves.Scalar) {
+	for _, alice := range a.sessions {
+		alice.SetKeyTweak(tweak)
+	}
+}
+
+// SetKeyTweak makes Bob sign every message for, and verify against, the key sk + tweak. See Bob.SetKeyTweak.
+func (b *BobBatch) SetKeyTweak(tweak curves.Scalar) {
+	for _, bob := range b.sessions {
+		bob.SetKeyTweak(tweak)
+	}
+}
+
+// extensionCount returns the number of OT extensions a batch of count sessions runs.
+func extensionCount(count int) int {
+	return (count + SessionsPerExtension - 1) / SessionsPerExtension
+}
+
+// extensionSessions returns the bounds of the sessions whose multiplications run in the c-th OT extension.
+func extensionSessions(c, count int) (int, int) {
+	return c * SessionsPerExtension, min((c+1)*SessionsPerExtension, count)
+}
+
+// extensionSessionId derives the session id of an OT extension from the multiplication ids of the sessions it serves.
+func extensionSessionId(ids [][multiplicationCount][simplest.DigestSize]byte) [simplest.DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write([]byte("Coinbase_DKLs_Sign batch multiply"))
+	for _, sessionIds := range ids {
+		for _, id := range sessionIds {
+			_, _ = hash.Write(id[:])
+		}
+	}
+	sessionId := [simplest.DigestSize]byte{}
+	copy(sessionId[:], hash.Sum(nil))
+	return sessionId
+}
+
+// Round1GenerateRandomSeeds is Round1GenerateRandomSeed for every session of the batch.
+func (a *AliceBatch) Round1GenerateRandomSeeds() ([][simplest.DigestSize]byte, error) {
+	seeds := make([][simplest.DigestSize]byte, len(a.sessions))
+	for i, alice := range a.sessions {
+		var err error
+		if seeds[i], err = alice.Round1GenerateRandomSeed(); err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+	}
+	return seeds, nil
+}
+
+// Round2Initialize is Round2Initialize for every session of the batch, with the multiplications of every chunk of
+// sessions started in one OT extension.
+func (b *BobBatch) Round2Initialize(aliceSeeds [][simplest.DigestSize]byte) (*BatchSignRound2Output, error) {
+	if len(aliceSeeds) != len(b.sessions) {
+		return nil, &protocol.AbortError{Party: "alice", Check: "the well-formedness of her round 1 message"}
+	}
+	round2Output := &BatchSignRound2Output{
+		Seeds:            make([][simplest.DigestSize]byte, len(b.sessions)),
+		DB:               make([]curves.Point, len(b.sessions)),
+		KosRound1Outputs: make([]*kos.Round1Output, extensionCount(len(b.sessions))),
+	}
+	betas := make([]curves.Scalar, 0, multiplicationCount*len(b.sessions))
+	ids := make([][multiplicationCount][simplest.DigestSize]byte, len(b.sessions))
+	for i, bob := range b.sessions {
+		sessionOutput, sessionBetas, err := bob.initialize(aliceSeeds[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+		round2Output.Seeds[i], round2Output.DB[i] = sessionOutput.Seed, sessionOutput.DB
+		betas = append(betas, sessionBetas[:]...)
+		ids[i] = bob.multiplyIds
+	}
+	b.multiplyReceivers = make([]*MultiplyReceiver, len(round2Output.KosRound1Outputs))
+	for c := range b.multiplyReceivers {
+		start, end := extensionSessions(c, len(b.sessions))
+		receiver, err := NewBatchMultiplyReceiver(b.sessions[0].seedOtResults, b.sessions[0].curve, extensionSessionId(ids[start:end]), multiplicationCount*(end-start))
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating the multiply receiver of extension %d", c)
+		}
+		if round2Output.KosRound1Outputs[c], err = receiver.Round1InitializeBatch(betas[multiplicationCount*start : multiplicationCount*end]); err != nil {
+			return nil, errors.Wrapf(err, "starting the multiplications of extension %d", c)
+		}
+		b.multiplyReceivers[c] = receiver
+	}
+	return round2Output, nil
+}
+
+// Round3Sign is Round3Sign for every session of the batch, with the multiplications of every chunk of sessions run in
+// one OT extension.
+func (a *AliceBatch) Round3Sign(messages [][]byte, round2Output *BatchSignRound2Output) (*BatchSignRound3Output, error) {
+	if len(messages) != len(a.sessions) {
+		return nil, errors.Errorf("expected %d messages, got %d", len(a.sessions), len(messages))
+	}
+	if round2Output == nil || len(round2Output.Seeds) != len(a.sessions) || len(round2Output.DB) != len(a.sessions) ||
+		len(round2Output.KosRound1Outputs) != extensionCount(len(a.sessions)) {
+		return nil, &protocol.AbortError{Party: "bob", Check: "the well-formedness of his round 2 message"}
+	}
+	round3Outputs := make([]*SignRound3Output, len(a.sessions))
+	alphas := make([]curves.Scalar, 0, multiplicationCount*len(a.sessions))
+	ids := make([][multiplicationCount][simplest.DigestSize]byte, len(a.sessions))
+	for i, alice := range a.sessions {
+		sessionOutput, sessionAlphas, err := alice.initialize(&SignRound2Output{DB: round2Output.DB[i], Seed: round2Output.Seeds[i]})
+		if err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+		round3Outputs[i] = sessionOutput
+		alphas = append(alphas, sessionAlphas[:]...)
+		ids[i] = alice.multiplyIds
+	}
+	result := &BatchSignRound3Output{
+		Sessions:              make([]*BatchSessionRound3Output, len(a.sessions)),
+		MultiplyRound2Outputs: make([]*MultiplyRound2Output, len(round2Output.KosRound1Outputs)),
+	}
+	tA := make([]curves.Scalar, 0, len(alphas))
+	for c := range result.MultiplyRound2Outputs {
+		start, end := extensionSessions(c, len(a.sessions))
+		sender, err := NewBatchMultiplySender(a.sessions[0].seedOtResults, a.sessions[0].curve, extensionSessionId(ids[start:end]), multiplicationCount*(end-start))
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating the multiply sender of extension %d", c)
+		}
+		if result.MultiplyRound2Outputs[c], err = sender.Round2MultiplyBatch(alphas[multiplicationCount*start:multiplicationCount*end], round2Output.KosRound1Outputs[c]); err != nil {
+			return nil, errors.Wrapf(err, "running the multiplications of extension %d", c)
+		}
+		tA = append(tA, sender.OutputAdditiveShares()...)
+	}
+	for i, alice := range a.sessions {
+		state, err := alice.finish(round3Outputs[i], [multiplicationCount]curves.Scalar{tA[multiplicationCount*i], tA[multiplicationCount*i+1]})
+		if err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+		if round3Outputs[i].EtaSig, err = state.etaSig(messages[i], alice.tweak); err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+		result.Sessions[i] = &BatchSessionRound3Output{
+			RSchnorrProof: round3Outputs[i].RSchnorrProof,
+			RPrime:        round3Outputs[i].RPrime,
+			EtaPhi:        round3Outputs[i].EtaPhi,
+			EtaSig:        round3Outputs[i].EtaSig,
+			Attestation:   round3Outputs[i].Attestation,
+		}
+	}
+	return result, nil
+}
+
+// Round4Final is Round4Final for every session of the batch. It completes the multiplications of every OT extension,
+// then the signatures, which it stores in Signatures.
+func (b *BobBatch) Round4Final(messages [][]byte, round3Output *BatchSignRound3Output) error {
+	if len(messages) != len(b.sessions) {
+		return errors.Errorf("expected %d messages, got %d", len(b.sessions), len(messages))
+	}
+	if round3Output == nil || len(round3Output.Sessions) != len(b.sessions) || len(round3Output.MultiplyRound2Outputs) != len(b.multiplyReceivers) {
+		return &protocol.AbortError{Party: "alice", Check: "the well-formedness of her round 3 message"}
+	}
+	round3Outputs := make([]*SignRound3Output, len(b.sessions))
+	for i, session := range round3Output.Sessions {
+		if session == nil || session.EtaSig == nil {
+			return errors.Wrapf(&protocol.AbortError{Party: "alice", Check: "the well-formedness of her round 3 message"}, "signature %d", i)
+		}
+		round3Outputs[i] = &SignRound3Output{
+			RSchnorrProof: session.RSchnorrProof,
+			RPrime:        session.RPrime,
+			EtaPhi:        session.EtaPhi,
+			EtaSig:        session.EtaSig,
+			Attestation:   session.Attestation,
+		}
+		if err := checkRound3Output(round3Outputs[i]); err != nil {
+			return errors.Wrapf(err, "signature %d", i)
+		}
+	}
+	// As in Round4Final, a failed multiplication identifies Alice to Bob but comes without evidence.
+	tB := make([]curves.Scalar, 0, multiplicationCount*len(b.sessions))
+	for c, receiver := range b.multiplyReceivers {
+		if err := receiver.Round3Multiply(round3Output.MultiplyRound2Outputs[c]); err != nil {
+			return &protocol.AbortError{Party: "alice", Check: fmt.Sprintf("the consistency check of the multiplications of extension %d", c), Err: err}
+		}
+		tB = append(tB, receiver.OutputAdditiveShares()...)
+	}
+	signatures := make([]*curves.EcdsaSignature, len(b.sessions))
+	for i, bob := range b.sessions {
+		state, err := bob.finish(round3Outputs[i], [multiplicationCount]curves.Scalar{tB[multiplicationCount*i], tB[multiplicationCount*i+1]})
+		if err != nil {
+			return errors.Wrapf(err, "signature %d", i)
+		}
+		if signatures[i], err = state.signature(messages[i], round3Outputs[i].EtaSig, bob.tweak); err != nil {
+			return errors.Wrapf(err, "signature %d", i)
+		}
+	}
+	b.Signatures = signatures
+	return nil
+}
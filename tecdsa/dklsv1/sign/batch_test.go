@@ -0,0 +1,112 @@
+package sign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/ot/ottest"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1/dkg"
+)
+
+func newTestBatch(t *testing.T, curve *curves.Curve, count int) (*AliceBatch, *BobBatch, curves.Point) {
+	t.Helper()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+	secretKeyShareA := curve.Scalar.Random(rand.Reader)
+	secretKeyShareB := curve.Scalar.Random(rand.Reader)
+	publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+	alice := NewAliceBatch(curve, sha3.New256, &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey}, count)
+	bob := NewBobBatch(curve, sha3.New256, &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey}, count)
+	aliceKey, alicePrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	alice.SetIdentityKeys(alicePrivateKey, nil)
+	bob.SetIdentityKeys(nil, aliceKey)
+	return alice, bob, publicKey
+}
+
+func newTestMessages(count int) [][]byte {
+	messages := make([][]byte, count)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("message %d", i))
+	}
+	return messages
+}
+
+func TestSignBatch(t *testing.T) {
+	curve := curves.K256()
+	// a batch of more than SessionsPerExtension sessions runs a second extension for the last ones
+	for _, count := range []int{1, 3, SessionsPerExtension + 2} {
+		t.Run(fmt.Sprint(count), func(t *testing.T) {
+			alice, bob, publicKey := newTestBatch(t, curve, count)
+			messages := newTestMessages(count)
+
+			seeds, err := alice.Round1GenerateRandomSeeds()
+			require.NoError(t, err)
+			round2Output, err := bob.Round2Initialize(seeds)
+			require.NoError(t, err)
+			require.Len(t, round2Output.KosRound1Outputs, (count+SessionsPerExtension-1)/SessionsPerExtension)
+			round3Output, err := alice.Round3Sign(messages, round2Output)
+			require.NoError(t, err)
+			require.NoError(t, bob.Round4Final(messages, round3Output))
+
+			require.Len(t, bob.Signatures, count)
+			for i, signature := range bob.Signatures {
+				digest := sha3.Sum256(messages[i])
+				recovered, err := curves.RecoverEcdsaPublicKey(curve, digest[:], signature)
+				require.NoError(t, err)
+				require.True(t, recovered.Equal(publicKey), "signature %d", i)
+			}
+		})
+	}
+}
+
+func TestSignBatchBlamesAlice(t *testing.T) {
+	curve := curves.K256()
+	tests := []struct {
+		name   string
+		tamper func(*BatchSignRound3Output)
+	}{
+		{"multiplication", func(o *BatchSignRound3Output) {
+			o.MultiplyRound2Outputs[0].U[3] = o.MultiplyRound2Outputs[0].U[3].Add(curve.Scalar.One())
+		}},
+		{"schnorr proof", func(o *BatchSignRound3Output) {
+			o.Sessions[1].RSchnorrProof.S = o.Sessions[1].RSchnorrProof.S.Add(curve.Scalar.One())
+		}},
+		{"attestation", func(o *BatchSignRound3Output) { o.Sessions[1].Attestation = nil }},
+		{"signature", func(o *BatchSignRound3Output) { o.Sessions[2].EtaSig = o.Sessions[2].EtaSig.Add(curve.Scalar.One()) }},
+		{"missing session", func(o *BatchSignRound3Output) { o.Sessions[0] = nil }},
+		{"missing extension", func(o *BatchSignRound3Output) { o.MultiplyRound2Outputs = nil }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const count = 3
+			alice, bob, _ := newTestBatch(t, curve, count)
+			messages := newTestMessages(count)
+
+			seeds, err := alice.Round1GenerateRandomSeeds()
+			require.NoError(t, err)
+			round2Output, err := bob.Round2Initialize(seeds)
+			require.NoError(t, err)
+			round3Output, err := alice.Round3Sign(messages, round2Output)
+			require.NoError(t, err)
+			test.tamper(round3Output)
+
+			var abort *protocol.AbortError
+			require.ErrorAs(t, bob.Round4Final(messages, round3Output), &abort)
+			require.Equal(t, "alice", abort.Party)
+			require.Nil(t, bob.Signatures)
+		})
+	}
+}
@@ -26,26 +26,30 @@ import (
 // two parties---the "sender" and "receiver", let's say---each input a scalar modulo q.
 // the functionality multiplies their two scalars modulo q, and then randomly additively shares the product mod q.
 // it then returns the two respective additive shares to the two parties.
+// A batch of multiplications runs in a single cOT extension of kos.L cOTs per multiplication, and the multiplications
+// share the challenges chi of the consistency check.
 
 // MultiplySender is the party that plays the role of Sender in the multiplication protocol (protocol 5 of the paper).
 type MultiplySender struct {
-	cOtSender           *kos.Sender   // underlying cOT sender struct, used by mult.
-	outputAdditiveShare curves.Scalar // ultimate output share of mult.
-	gadget              [kos.L]curves.Scalar
-	curve               *curves.Curve
-	transcript          *merlin.Transcript
-	uniqueSessionId     [simplest.DigestSize]byte
+	cOtSender            *kos.Sender     // underlying cOT sender struct, used by mult.
+	outputAdditiveShares []curves.Scalar // ultimate output shares of mult, one per multiplication.
+	count                int             // the number of multiplications.
+	gadget               [kos.L]curves.Scalar
+	curve                *curves.Curve
+	transcript           *merlin.Transcript
+	uniqueSessionId      [simplest.DigestSize]byte
 }
 
 // MultiplyReceiver is the party that plays the role of Sender in the multiplication protocol (protocol 5 of the paper).
 type MultiplyReceiver struct {
-	cOtReceiver         *kos.Receiver               // underlying cOT receiver struct, used by mult.
-	outputAdditiveShare curves.Scalar               // ultimate output share of mult.
-	omega               [kos.COtBlockSizeBytes]byte // this is used as an intermediate result during the course of mult.
-	gadget              [kos.L]curves.Scalar
-	curve               *curves.Curve
-	transcript          *merlin.Transcript
-	uniqueSessionId     [simplest.DigestSize]byte
+	cOtReceiver          *kos.Receiver   // underlying cOT receiver struct, used by mult.
+	outputAdditiveShares []curves.Scalar // ultimate output shares of mult, one per multiplication.
+	count                int             // the number of multiplications.
+	omega                []byte          // this is used as an intermediate result during the course of mult.
+	gadget               [kos.L]curves.Scalar
+	curve                *curves.Curve
+	transcript           *merlin.Transcript
+	uniqueSessionId      [simplest.DigestSize]byte
 }
 
 func generateGadgetVector(curve *curves.Curve) ([kos.L]curves.Scalar, error) {
@@ -76,7 +80,19 @@ func generateGadgetVector(curve *curves.Curve) ([kos.L]curves.Scalar, error) {
 // You must supply it the _output_ of a seed OT, from the receiver's point of view, as well as params and a unique ID.
 // That is, the mult sender must run the base OT as the receiver; note the (apparent) reversal of roles.
 func NewMultiplySender(seedOtResults *simplest.ReceiverOutput, curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte) (*MultiplySender, error) {
-	sender := kos.NewCOtSender(seedOtResults, curve)
+	return NewBatchMultiplySender(seedOtResults, curve, uniqueSessionId, 1)
+}
+
+// NewBatchMultiplySender is NewMultiplySender for a batch of count multiplications, which run in a single cOT
+// extension. See Round2MultiplyBatch.
+func NewBatchMultiplySender(seedOtResults *simplest.ReceiverOutput, curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte, count int) (*MultiplySender, error) {
+	if count <= 0 || count > kos.MaxBatchSize/kos.L {
+		return nil, fmt.Errorf("a batch must have between 1 and %d multiplications, got %d", kos.MaxBatchSize/kos.L, count)
+	}
+	sender, err := kos.NewCOtBatchSender(seedOtResults, curve, count*kos.L)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cOT sender in new multiply sender")
+	}
 	gadget, err := generateGadgetVector(curve)
 	if err != nil {
 		return nil, errors.Wrap(err, "error generating gadget vector in new multiply sender")
@@ -86,6 +102,7 @@ func NewMultiplySender(seedOtResults *simplest.ReceiverOutput, curve *curves.Cur
 	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
 	return &MultiplySender{
 		cOtSender:       sender,
+		count:           count,
 		curve:           curve,
 		transcript:      transcript,
 		uniqueSessionId: uniqueSessionId,
@@ -97,7 +114,19 @@ func NewMultiplySender(seedOtResults *simplest.ReceiverOutput, curve *curves.Cur
 // You must supply it the _output_ of a seed OT, from the sender's point of view, as well as params and a unique ID.
 // That is, the mult sender must run the base OT as the sender; note the (apparent) reversal of roles.
 func NewMultiplyReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte) (*MultiplyReceiver, error) {
-	receiver := kos.NewCOtReceiver(seedOtResults, curve)
+	return NewBatchMultiplyReceiver(seedOtResults, curve, uniqueSessionId, 1)
+}
+
+// NewBatchMultiplyReceiver is NewMultiplyReceiver for a batch of count multiplications, which run in a single cOT
+// extension. See Round1InitializeBatch.
+func NewBatchMultiplyReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Curve, uniqueSessionId [simplest.DigestSize]byte, count int) (*MultiplyReceiver, error) {
+	if count <= 0 || count > kos.MaxBatchSize/kos.L {
+		return nil, fmt.Errorf("a batch must have between 1 and %d multiplications, got %d", kos.MaxBatchSize/kos.L, count)
+	}
+	receiver, err := kos.NewCOtBatchReceiver(seedOtResults, curve, count*kos.L)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cOT receiver in new multiply receiver")
+	}
 	gadget, err := generateGadgetVector(curve)
 	if err != nil {
 		return nil, errors.Wrap(err, "error generating gadget vector in new multiply receiver")
@@ -106,6 +135,7 @@ func NewMultiplyReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Cur
 	transcript.AppendMessage([]byte("session_id"), uniqueSessionId[:])
 	return &MultiplyReceiver{
 		cOtReceiver:     receiver,
+		count:           count,
 		curve:           curve,
 		transcript:      transcript,
 		uniqueSessionId: uniqueSessionId,
@@ -113,11 +143,42 @@ func NewMultiplyReceiver(seedOtResults *simplest.SenderOutput, curve *curves.Cur
 	}, nil
 }
 
-// MultiplyRound2Output is the output of the second round of the multiplication protocol.
+// OutputAdditiveShare returns the sender's additive share of the product, the first one of a batch. It is only set
+// once Round2Multiply ran.
+func (sender *MultiplySender) OutputAdditiveShare() curves.Scalar {
+	if len(sender.outputAdditiveShares) == 0 {
+		return nil
+	}
+	return sender.outputAdditiveShares[0]
+}
+
+// OutputAdditiveShares returns the sender's additive shares of the products of a batch, in order. They are only set
+// once Round2MultiplyBatch ran.
+func (sender *MultiplySender) OutputAdditiveShares() []curves.Scalar {
+	return sender.outputAdditiveShares
+}
+
+// OutputAdditiveShare returns the receiver's additive share of the product, the first one of a batch. It is only set
+// once Round3Multiply succeeded.
+func (receiver *MultiplyReceiver) OutputAdditiveShare() curves.Scalar {
+	if len(receiver.outputAdditiveShares) == 0 {
+		return nil
+	}
+	return receiver.outputAdditiveShares[0]
+}
+
+// OutputAdditiveShares returns the receiver's additive shares of the products of a batch, in order. They are only
+// set once Round3Multiply succeeded.
+func (receiver *MultiplyReceiver) OutputAdditiveShares() []curves.Scalar {
+	return receiver.outputAdditiveShares
+}
+
+// MultiplyRound2Output is the output of the second round of the multiplication protocol. R holds kos.L values and U
+// one value per multiplication of the batch.
 type MultiplyRound2Output struct {
 	COTRound2Output *kos.Round2Output
-	R               [kos.L]curves.Scalar
-	U               curves.Scalar
+	R               []curves.Scalar
+	U               []curves.Scalar
 }
 
 // Algorithm 5. in DKLs. "Encodes" Bob's secret input scalars `beta` in the right way, using the opts.
@@ -150,9 +211,21 @@ func (receiver *MultiplyReceiver) encode(beta curves.Scalar) ([kos.COtBlockSizeB
 
 // Round1Initialize Protocol 5., Multiplication, 3). Bob (receiver) encodes beta and initiates the cOT extension
 func (receiver *MultiplyReceiver) Round1Initialize(beta curves.Scalar) (*kos.Round1Output, error) {
-	var err error
-	if receiver.omega, err = receiver.encode(beta); err != nil {
-		return nil, errors.Wrap(err, "encoding input beta in receiver round 1 initialize")
+	return receiver.Round1InitializeBatch([]curves.Scalar{beta})
+}
+
+// Round1InitializeBatch is Round1Initialize for a batch, with one input beta per multiplication.
+func (receiver *MultiplyReceiver) Round1InitializeBatch(betas []curves.Scalar) (*kos.Round1Output, error) {
+	if len(betas) != receiver.count {
+		return nil, fmt.Errorf("expected %d inputs to multiply, got %d", receiver.count, len(betas))
+	}
+	receiver.omega = make([]byte, 0, receiver.count*kos.COtBlockSizeBytes)
+	for i, beta := range betas {
+		encoding, err := receiver.encode(beta)
+		if err != nil {
+			return nil, errors.Wrapf(err, "encoding input beta %d in receiver round 1 initialize", i)
+		}
+		receiver.omega = append(receiver.omega, encoding[:]...)
 	}
 	cOtRound1Output, err := receiver.cOtReceiver.Round1Initialize(receiver.uniqueSessionId, receiver.omega)
 	if err != nil {
@@ -173,12 +246,26 @@ func (receiver *MultiplyReceiver) Round1Initialize(beta curves.Scalar) (*kos.Rou
 // specifically, Alice can then do step 5) (compute the outputs of the multiplication protocol), also stashes this.
 // Finishes by taking care of 7), after that, Alice is totally done with multiplication and has stashed the outputs.
 func (sender *MultiplySender) Round2Multiply(alpha curves.Scalar, round1Output *kos.Round1Output) (*MultiplyRound2Output, error) {
+	return sender.Round2MultiplyBatch([]curves.Scalar{alpha}, round1Output)
+}
+
+// Round2MultiplyBatch is Round2Multiply for a batch, with one input alpha per multiplication.
+func (sender *MultiplySender) Round2MultiplyBatch(alphas []curves.Scalar, round1Output *kos.Round1Output) (*MultiplyRound2Output, error) {
+	if len(alphas) != sender.count {
+		return nil, fmt.Errorf("expected %d inputs to multiply, got %d", sender.count, len(alphas))
+	}
+	if round1Output == nil {
+		return nil, errors.New("missing cOT message in round 2 multiply")
+	}
 	var err error
-	alphaHat := sender.curve.Scalar.Random(rand.Reader)
-	input := [kos.L][2]curves.Scalar{} // sender's input, namely integer "sums" in case w_j == 1.
-	for j := 0; j < kos.L; j++ {
-		input[j][0] = alpha
-		input[j][1] = alphaHat
+	alphaHats := make([]curves.Scalar, sender.count)
+	input := make([][kos.OtWidth]curves.Scalar, sender.count*kos.L) // sender's input, namely integer "sums" in case w_j == 1.
+	for m, alpha := range alphas {
+		alphaHats[m] = sender.curve.Scalar.Random(rand.Reader)
+		for j := m * kos.L; j < (m+1)*kos.L; j++ {
+			input[j][0] = alpha
+			input[j][1] = alphaHats[m]
+		}
 	}
 	round2Output := &MultiplyRound2Output{}
 	round2Output.COTRound2Output, err = sender.cOtSender.Round2Transfer(sender.uniqueSessionId, input, round1Output)
@@ -194,12 +281,7 @@ func (sender *MultiplySender) Round2Multiply(alpha curves.Scalar, round1Output *
 	sender.transcript.AppendMessage([]byte("vPrime"), round1Output.VPrime[:])
 	// write our own output of the second round to the transcript
 	chiWidth := 2
-	for i := 0; i < kos.Kappa; i++ {
-		for k := 0; k < chiWidth; k++ {
-			label := []byte(fmt.Sprintf("row %d of Tau", i))
-			sender.transcript.AppendMessage(label, round2Output.COTRound2Output.Tau[i][k].Bytes())
-		}
-	}
+	appendTau(sender.transcript, round2Output.COTRound2Output, sender.count)
 	chi := make([]curves.Scalar, chiWidth)
 	for k := 0; k < 2; k++ {
 		label := []byte(fmt.Sprintf("draw challenge chi %d", k))
@@ -209,30 +291,46 @@ func (sender *MultiplySender) Round2Multiply(alpha curves.Scalar, round1Output *
 			return nil, errors.Wrap(err, "setting chi scalar from bytes")
 		}
 	}
-	sender.outputAdditiveShare = sender.curve.Scalar.Zero()
-	for j := 0; j < kos.L; j++ {
-		round2Output.R[j] = sender.curve.Scalar.Zero()
-		for k := 0; k < chiWidth; k++ {
-			round2Output.R[j] = round2Output.R[j].Add(chi[k].Mul(sender.cOtSender.OutputAdditiveShares[j][k]))
+	sender.outputAdditiveShares = make([]curves.Scalar, sender.count)
+	round2Output.R = make([]curves.Scalar, sender.count*kos.L)
+	round2Output.U = make([]curves.Scalar, sender.count)
+	for m := range alphas {
+		sender.outputAdditiveShares[m] = sender.curve.Scalar.Zero()
+		for j := 0; j < kos.L; j++ {
+			share := sender.cOtSender.OutputAdditiveShares[m*kos.L+j]
+			round2Output.R[m*kos.L+j] = sender.curve.Scalar.Zero()
+			for k := 0; k < chiWidth; k++ {
+				round2Output.R[m*kos.L+j] = round2Output.R[m*kos.L+j].Add(chi[k].Mul(share[k]))
+			}
+			sender.outputAdditiveShares[m] = sender.outputAdditiveShares[m].Add(sender.gadget[j].Mul(share[0]))
 		}
-		sender.outputAdditiveShare = sender.outputAdditiveShare.Add(sender.gadget[j].Mul(sender.cOtSender.OutputAdditiveShares[j][0]))
+		round2Output.U[m] = chi[0].Mul(alphas[m]).Add(chi[1].Mul(alphaHats[m]))
 	}
-	round2Output.U = chi[0].Mul(alpha).Add(chi[1].Mul(alphaHat))
 	return round2Output, nil
 }
 
+// appendTau writes the first kos.Kappa rows of Tau of every multiplication of a batch of count to the transcript.
+func appendTau(transcript *merlin.Transcript, cOtRound2Output *kos.Round2Output, count int) {
+	for m := 0; m < count; m++ {
+		for i := 0; i < kos.Kappa; i++ {
+			for k := 0; k < kos.OtWidth; k++ {
+				label := []byte(fmt.Sprintf("row %d of Tau", i))
+				transcript.AppendMessage(label, cOtRound2Output.Tau[m*kos.L+i][k].Bytes())
+			}
+		}
+	}
+}
+
 // Round3Multiply Protocol 5., Multiplication, 3) and 6). Bob finalizes the cOT extension.
 // using that and Alice's multiplication message, Bob completes the multiplication protocol, including checks.
 // At the end, Bob's values tB_j are populated.
 func (receiver *MultiplyReceiver) Round3Multiply(round2Output *MultiplyRound2Output) error {
+	if err := receiver.checkRound2Output(round2Output); err != nil {
+		return err
+	}
 	chiWidth := 2
 	// write the output of the second round to the transcript
-	for i := 0; i < kos.Kappa; i++ {
-		for k := 0; k < chiWidth; k++ {
-			label := []byte(fmt.Sprintf("row %d of Tau", i))
-			receiver.transcript.AppendMessage(label, round2Output.COTRound2Output.Tau[i][k].Bytes())
-		}
-	}
+	appendTau(receiver.transcript, round2Output.COTRound2Output, receiver.count)
 	if err := receiver.cOtReceiver.Round3Transfer(round2Output.COTRound2Output); err != nil {
 		return errors.Wrap(err, "error within cOT round 3 transfer within round 3 multiply")
 	}
@@ -247,21 +345,53 @@ func (receiver *MultiplyReceiver) Round3Multiply(round2Output *MultiplyRound2Out
 		}
 	}
 
-	receiver.outputAdditiveShare = receiver.curve.Scalar.Zero()
-	for j := 0; j < kos.L; j++ {
-		// compute the LHS of bob's step 6) for j. note that we're "adding r_j" to both sides"; so this LHS includes r_j.
-		// the reason to do this is so that the constant-time (i.e., independent of w_j) calculation of w_j * u can proceed more cleanly.
-		leftHandSideOfCheck := round2Output.R[j]
-		for k := 0; k < chiWidth; k++ {
-			leftHandSideOfCheck = leftHandSideOfCheck.Add(chi[k].Mul(receiver.cOtReceiver.OutputAdditiveShares[j][k]))
+	outputAdditiveShares := make([]curves.Scalar, receiver.count)
+	for m := range outputAdditiveShares {
+		outputAdditiveShares[m] = receiver.curve.Scalar.Zero()
+		for j := m * kos.L; j < (m+1)*kos.L; j++ {
+			// compute the LHS of bob's step 6) for j. note that we're "adding r_j" to both sides"; so this LHS includes r_j.
+			// the reason to do this is so that the constant-time (i.e., independent of w_j) calculation of w_j * u can proceed more cleanly.
+			leftHandSideOfCheck := round2Output.R[j]
+			for k := 0; k < chiWidth; k++ {
+				leftHandSideOfCheck = leftHandSideOfCheck.Add(chi[k].Mul(receiver.cOtReceiver.OutputAdditiveShares[j][k]))
+			}
+			rightHandSideOfCheck := [simplest.DigestSize]byte{}
+			jthBitOfOmega := simplest.ExtractBitFromByteVector(receiver.omega, j)
+			subtle.ConstantTimeCopy(int(jthBitOfOmega), rightHandSideOfCheck[:], round2Output.U[m].Bytes())
+			if subtle.ConstantTimeCompare(rightHandSideOfCheck[:], leftHandSideOfCheck.Bytes()) != 1 {
+				return fmt.Errorf("alice's values R and U failed to check in round 3 multiply")
+			}
+			outputAdditiveShares[m] = outputAdditiveShares[m].Add(receiver.gadget[j-m*kos.L].Mul(receiver.cOtReceiver.OutputAdditiveShares[j][0]))
 		}
-		rightHandSideOfCheck := [simplest.DigestSize]byte{}
-		jthBitOfOmega := simplest.ExtractBitFromByteVector(receiver.omega[:], j)
-		subtle.ConstantTimeCopy(int(jthBitOfOmega), rightHandSideOfCheck[:], round2Output.U.Bytes())
-		if subtle.ConstantTimeCompare(rightHandSideOfCheck[:], leftHandSideOfCheck.Bytes()) != 1 {
-			return fmt.Errorf("alice's values R and U failed to check in round 3 multiply")
+	}
+	receiver.outputAdditiveShares = outputAdditiveShares
+	return nil
+}
+
+// checkRound2Output checks that the sender's message has the shape of a batch of the receiver's size.
+func (receiver *MultiplyReceiver) checkRound2Output(round2Output *MultiplyRound2Output) error {
+	if round2Output == nil || round2Output.COTRound2Output == nil {
+		return errors.New("missing multiplication message in round 3 multiply")
+	}
+	if len(round2Output.R) != receiver.count*kos.L || len(round2Output.U) != receiver.count || len(round2Output.COTRound2Output.Tau) != receiver.count*kos.L {
+		return fmt.Errorf("the multiplication message is not sized for %d multiplications", receiver.count)
+	}
+	for _, r := range round2Output.R {
+		if r == nil {
+			return errors.New("the multiplication message is missing values of R")
+		}
+	}
+	for _, u := range round2Output.U {
+		if u == nil {
+			return errors.New("the multiplication message is missing values of U")
+		}
+	}
+	for _, tau := range round2Output.COTRound2Output.Tau {
+		for _, t := range tau {
+			if t == nil {
+				return errors.New("the multiplication message is missing values of Tau")
+			}
 		}
-		receiver.outputAdditiveShare = receiver.outputAdditiveShare.Add(receiver.gadget[j].Mul(receiver.cOtReceiver.OutputAdditiveShares[j][0]))
 	}
 	return nil
 }
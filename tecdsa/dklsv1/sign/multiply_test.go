@@ -43,6 +43,46 @@ func TestMultiply(t *testing.T) {
 	require.Nil(t, err)
 
 	product := alpha.Mul(beta)
-	sum := sender.outputAdditiveShare.Add(receiver.outputAdditiveShare)
+	sum := sender.OutputAdditiveShare().Add(receiver.OutputAdditiveShare())
 	require.Equal(t, product, sum)
 }
+
+func TestMultiplyBatch(t *testing.T) {
+	curve := curves.K256()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+
+	const count = 5
+	sender, err := NewBatchMultiplySender(baseOtReceiverOutput, curve, hashKeySeed, count)
+	require.NoError(t, err)
+	receiver, err := NewBatchMultiplyReceiver(baseOtSenderOutput, curve, hashKeySeed, count)
+	require.NoError(t, err)
+
+	alphas := make([]curves.Scalar, count)
+	betas := make([]curves.Scalar, count)
+	for i := range alphas {
+		alphas[i] = curve.Scalar.Random(rand.Reader)
+		betas[i] = curve.Scalar.Random(rand.Reader)
+	}
+
+	round1Output, err := receiver.Round1InitializeBatch(betas)
+	require.NoError(t, err)
+	round2Output, err := sender.Round2MultiplyBatch(alphas, round1Output)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Round3Multiply(round2Output))
+
+	for i := range alphas {
+		sum := sender.OutputAdditiveShares()[i].Add(receiver.OutputAdditiveShares()[i])
+		require.Equal(t, alphas[i].Mul(betas[i]), sum)
+	}
+
+	// the batch size is bounded by the size of one extension
+	_, err = NewBatchMultiplySender(baseOtReceiverOutput, curve, hashKeySeed, 0)
+	require.Error(t, err)
+	_, err = NewBatchMultiplyReceiver(baseOtSenderOutput, curve, hashKeySeed, kos.MaxBatchSize/kos.L+1)
+	require.Error(t, err)
+}
@@ -0,0 +1,182 @@
+package sign
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+)
+
+// Presigning splits the signing protocol into an offline phase, that runs rounds 1 to 3 before the message is known,
+// and an online phase, in which Alice sends EtaSig, the only part of her round 3 message that depends on the message,
+// and Bob completes the signature. The offline phase leaves each party with a presignature that must be used at most
+// once: signing two messages with the same presignature reveals the secret key.
+//
+// Presignatures do not support key tweaks. Letting the signer pick the tweak after the instance key R is known enables
+// related-key attacks on ECDSA, so SetKeyTweak has no effect on presignatures.
+//
+// Presignatures only live in memory and cannot be serialized, since a copy restored from storage could be used twice.
+
+// ErrPresignatureUsed is returned when signing with a presignature that was already used.
+var ErrPresignatureUsed = errors.New("the presignature was already used")
+
+// PresignatureId identifies a presignature. Alice and Bob derive the same id from the transcript of the offline phase.
+type PresignatureId [simplest.DigestSize]byte
+
+// AlicePresignature is Alice's output of the offline phase. It is safe for concurrent use.
+type AlicePresignature struct {
+	id    PresignatureId
+	mu    sync.Mutex
+	state *alicePresignState // nil once used
+}
+
+// BobPresignature is Bob's output of the offline phase. It is safe for concurrent use.
+type BobPresignature struct {
+	id    PresignatureId
+	mu    sync.Mutex
+	state *bobPresignState // nil once used
+}
+
+// PresignedSignOutput is Alice's only message of the online phase.
+type PresignedSignOutput struct {
+	// PresignatureId identifies the presignature Alice signed with.
+	PresignatureId PresignatureId
+
+	// EtaSig is the Eta_{Sig} from the paper.
+	EtaSig curves.Scalar
+}
+
+// Round3Presign is Round3Sign without the message. It returns Alice's round 3 message, with no EtaSig, along with her
+// presignature.
+func (alice *Alice) Round3Presign(round2Output *SignRound2Output) (*SignRound3Output, *AlicePresignature, error) {
+	round3Output, state, err := alice.presign(round2Output)
+	if err != nil {
+		return nil, nil, err
+	}
+	presignature := &AlicePresignature{state: state}
+	copy(presignature.id[:], alice.transcript.ExtractBytes([]byte("presignature id"), simplest.DigestSize))
+	return round3Output, presignature, nil
+}
+
+// Round4Presign is Round4Final without the message. Bob verifies Alice's round 3 message, whose EtaSig is ignored,
+// and returns his presignature.
+func (bob *Bob) Round4Presign(round3Output *SignRound3Output) (*BobPresignature, error) {
+	state, err := bob.presign(round3Output)
+	if err != nil {
+		return nil, err
+	}
+	presignature := &BobPresignature{state: state}
+	copy(presignature.id[:], bob.transcript.ExtractBytes([]byte("presignature id"), simplest.DigestSize))
+	return presignature, nil
+}
+
+// ID returns the id of the presignature.
+func (p *AlicePresignature) ID() PresignatureId { return p.id }
+
+// Used reports whether the presignature was used.
+func (p *AlicePresignature) Used() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state == nil
+}
+
+// Sign uses the presignature to sign the message, and returns Alice's message to Bob. The presignature is used up
+// even if Sign fails.
+func (p *AlicePresignature) Sign(message []byte) (*PresignedSignOutput, error) {
+	p.mu.Lock()
+	state := p.state
+	p.state = nil
+	p.mu.Unlock()
+	if state == nil {
+		return nil, ErrPresignatureUsed
+	}
+	etaSig, err := state.etaSig(message, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PresignedSignOutput{PresignatureId: p.id, EtaSig: etaSig}, nil
+}
+
+// ID returns the id of the presignature.
+func (p *BobPresignature) ID() PresignatureId { return p.id }
+
+// Used reports whether the presignature was used.
+func (p *BobPresignature) Used() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state == nil
+}
+
+// Sign uses the presignature to complete the signature of the message from Alice's message, and verifies it. Alice
+// must have signed with the matching presignature and the same message. The presignature is used up even if Sign
+// fails, unless Alice's message is for another presignature or is malformed.
+func (p *BobPresignature) Sign(message []byte, aliceOutput *PresignedSignOutput) (*curves.EcdsaSignature, error) {
+	if aliceOutput.PresignatureId != p.id {
+		return nil, errors.New("alice signed with another presignature")
+	}
+	if aliceOutput.EtaSig == nil {
+		return nil, &protocol.AbortError{Party: "alice", Check: "the well-formedness of her presigned sign message"}
+	}
+	p.mu.Lock()
+	state := p.state
+	p.state = nil
+	p.mu.Unlock()
+	if state == nil {
+		return nil, ErrPresignatureUsed
+	}
+	return state.signature(message, aliceOutput.EtaSig, nil)
+}
+
+// PresignaturePool stores presignatures of one party by id until they are used. Taking a presignature removes it from
+// the pool, so that concurrent signers never get the same one. It is safe for concurrent use.
+type PresignaturePool[P interface{ ID() PresignatureId }] struct {
+	mu            sync.Mutex
+	presignatures map[PresignatureId]P
+}
+
+// NewPresignaturePool creates an empty pool.
+func NewPresignaturePool[P interface{ ID() PresignatureId }]() *PresignaturePool[P] {
+	return &PresignaturePool[P]{presignatures: make(map[PresignatureId]P)}
+}
+
+// Put adds a presignature to the pool. It fails if the pool already holds a presignature with the same id.
+func (pool *PresignaturePool[P]) Put(presignature P) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if _, ok := pool.presignatures[presignature.ID()]; ok {
+		return errors.New("the pool already holds the presignature")
+	}
+	pool.presignatures[presignature.ID()] = presignature
+	return nil
+}
+
+// Take removes the presignature with the given id from the pool and returns it, if the pool holds it.
+func (pool *PresignaturePool[P]) Take(id PresignatureId) (P, bool) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	presignature, ok := pool.presignatures[id]
+	delete(pool.presignatures, id)
+	return presignature, ok
+}
+
+// TakeAny removes any presignature from the pool and returns it, if the pool is not empty.
+func (pool *PresignaturePool[P]) TakeAny() (P, bool) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	for id, presignature := range pool.presignatures {
+		delete(pool.presignatures, id)
+		return presignature, true
+	}
+	var none P
+	return none, false
+}
+
+// Len returns the number of presignatures in the pool.
+func (pool *PresignaturePool[P]) Len() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return len(pool.presignatures)
+}
@@ -0,0 +1,121 @@
+package sign
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/ot/ottest"
+	"github.com/sonr-io/crypto/tecdsa/dklsv1/dkg"
+)
+
+// presign runs the offline phase count times on the same key shares.
+func presign(t *testing.T, curve *curves.Curve, count int) (curves.Point, []*AlicePresignature, []*BobPresignature) {
+	t.Helper()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+	secretKeyShareA := curve.Scalar.Random(rand.Reader)
+	secretKeyShareB := curve.Scalar.Random(rand.Reader)
+	publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+	aliceOutput := &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey}
+	bobOutput := &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey}
+
+	alicePresignatures := make([]*AlicePresignature, count)
+	bobPresignatures := make([]*BobPresignature, count)
+	for i := 0; i < count; i++ {
+		alice := NewAlice(curve, sha3.New256(), aliceOutput)
+		bob := NewBob(curve, sha3.New256(), bobOutput)
+		seed, err := alice.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		round2Output, err := bob.Round2Initialize(seed)
+		require.NoError(t, err)
+		round3Output, alicePresignature, err := alice.Round3Presign(round2Output)
+		require.NoError(t, err)
+		require.Nil(t, round3Output.EtaSig)
+		bobPresignature, err := bob.Round4Presign(round3Output)
+		require.NoError(t, err)
+		require.Equal(t, alicePresignature.ID(), bobPresignature.ID())
+		alicePresignatures[i], bobPresignatures[i] = alicePresignature, bobPresignature
+	}
+	return publicKey, alicePresignatures, bobPresignatures
+}
+
+func TestPresign(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		publicKey, alicePresignatures, bobPresignatures := presign(t, curve, 3)
+		alicePool := NewPresignaturePool[*AlicePresignature]()
+		bobPool := NewPresignaturePool[*BobPresignature]()
+		for i := range alicePresignatures {
+			require.NoError(t, alicePool.Put(alicePresignatures[i]))
+			require.NoError(t, bobPool.Put(bobPresignatures[i]))
+		}
+		require.Error(t, bobPool.Put(bobPresignatures[0]))
+
+		for _, message := range [][]byte{[]byte("first"), []byte("second"), []byte("third")} {
+			alicePresignature, ok := alicePool.TakeAny()
+			require.True(t, ok)
+			aliceOutput, err := alicePresignature.Sign(message)
+			require.NoError(t, err)
+			bobPresignature, ok := bobPool.Take(aliceOutput.PresignatureId)
+			require.True(t, ok)
+			signature, err := bobPresignature.Sign(message, aliceOutput)
+			require.NoError(t, err, "curve: %s", curve.Name)
+
+			digest := sha3.Sum256(message)
+			recovered, err := curves.RecoverEcdsaPublicKey(curve, digest[:], signature)
+			require.NoError(t, err)
+			require.True(t, recovered.Equal(publicKey), "curve: %s", curve.Name)
+		}
+		require.Zero(t, alicePool.Len())
+		require.Zero(t, bobPool.Len())
+		_, ok := alicePool.TakeAny()
+		require.False(t, ok)
+	}
+}
+
+func TestPresignatureIsSingleUse(t *testing.T) {
+	curve := curves.K256()
+	_, alicePresignatures, bobPresignatures := presign(t, curve, 1)
+	alicePresignature, bobPresignature := alicePresignatures[0], bobPresignatures[0]
+
+	aliceOutput, err := alicePresignature.Sign([]byte("message"))
+	require.NoError(t, err)
+	require.True(t, alicePresignature.Used())
+	_, err = alicePresignature.Sign([]byte("another message"))
+	require.ErrorIs(t, err, ErrPresignatureUsed)
+
+	_, err = bobPresignature.Sign([]byte("message"), aliceOutput)
+	require.NoError(t, err)
+	require.True(t, bobPresignature.Used())
+	_, err = bobPresignature.Sign([]byte("message"), aliceOutput)
+	require.ErrorIs(t, err, ErrPresignatureUsed)
+}
+
+func TestPresignatureBlamesAlice(t *testing.T) {
+	curve := curves.K256()
+	_, alicePresignatures, bobPresignatures := presign(t, curve, 2)
+
+	aliceOutput, err := alicePresignatures[0].Sign([]byte("message"))
+	require.NoError(t, err)
+
+	// a message for another presignature leaves the presignature unused
+	_, err = bobPresignatures[1].Sign([]byte("message"), aliceOutput)
+	require.Error(t, err)
+	require.False(t, bobPresignatures[1].Used())
+
+	// Alice signed another message than Bob
+	_, err = bobPresignatures[0].Sign([]byte("another message"), aliceOutput)
+	var abort *protocol.AbortError
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "alice", abort.Party)
+	require.True(t, bobPresignatures[0].Used())
+}
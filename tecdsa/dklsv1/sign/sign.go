@@ -10,7 +10,7 @@
 package sign
 
 import (
-	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"fmt"
 	"hash"
@@ -21,6 +21,7 @@ import (
 	"golang.org/x/crypto/sha3"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/ot/base/simplest"
 	"github.com/sonr-io/crypto/ot/extension/kos"
 	"github.com/sonr-io/crypto/tecdsa/dklsv1/dkg"
@@ -36,8 +37,16 @@ type Alice struct {
 	seedOtResults  *simplest.ReceiverOutput
 	secretKeyShare curves.Scalar // the witness
 	publicKey      curves.Point
+	tweak          curves.Scalar // additive tweak of the joint secret key, nil to sign with the joint key itself
 	curve          *curves.Curve
 	transcript     *merlin.Transcript
+	// multiplyIds are the session ids of the two multiplications, kA and phi are kept between the start and the end
+	// of the multiplications.
+	multiplyIds [multiplicationCount][simplest.DigestSize]byte
+	kA, phi     curves.Scalar
+	// aliceSeed is kept for the attestation of her round 3 message, which she makes with identityKey if it is set.
+	aliceSeed   [simplest.DigestSize]byte
+	identityKey ed25519.PrivateKey
 }
 
 // Bob struct encoding Bob's state during one execution of the overall signing algorithm.
@@ -50,14 +59,21 @@ type Bob struct {
 	seedOtResults  *simplest.SenderOutput
 	secretKeyShare curves.Scalar
 	publicKey      curves.Point
+	tweak          curves.Scalar // additive tweak of the joint secret key, nil to sign with the joint key itself
 	transcript     *merlin.Transcript
 	// multiplyReceivers are 2 receivers that are used to perform the two multiplications needed:
 	// 1. (phi + 1/kA) * (1/kB)
 	// 2. skA/KA * skB/kB
 	multiplyReceivers [multiplicationCount]*MultiplyReceiver
+	multiplyIds       [multiplicationCount][simplest.DigestSize]byte
 	kB                curves.Scalar
 	dB                curves.Point
 	curve             *curves.Curve
+	// aliceSeed and bobSeed are kept to bind the evidence against Alice to the session, and aliceKey, if it is set, is
+	// the identity key she must attest her round 3 message with.
+	aliceSeed [simplest.DigestSize]byte
+	bobSeed   [simplest.DigestSize]byte
+	aliceKey  ed25519.PublicKey
 }
 
 // NewAlice creates a party that can participate in protocol runs of DKLs sign, in the role of Alice.
@@ -85,6 +101,40 @@ func NewBob(curve *curves.Curve, hash hash.Hash, dkgOutput *dkg.BobOutput) *Bob
 	}
 }
 
+// SetKeyTweak makes Alice sign for the key sk + tweak, whose public key is publicKey + tweak . G, instead of the
+// joint key sk. Since s = (h + r . sk) / k, the tweak is folded into the digest as h + r . tweak, so the shares and
+// the consistency checks of the protocol are unchanged. Bob must be given the same tweak.
+// This is how BIP32 non-hardened child keys are signed for without touching the keyshares.
+func (alice *Alice) SetKeyTweak(tweak curves.Scalar) {
+	alice.tweak = tweak
+}
+
+// SetKeyTweak makes Bob sign for, and verify against, the key sk + tweak. See Alice.SetKeyTweak.
+func (bob *Bob) SetKeyTweak(tweak curves.Scalar) {
+	bob.tweak = tweak
+}
+
+// SetIdentityKeys makes Alice attest her round 3 message with her long-term identity key, so that Bob can hand a
+// failed check of her proof for R to a third party. The peer key is unused; both parties take both keys so that they
+// are set up the same way.
+func (alice *Alice) SetIdentityKeys(key ed25519.PrivateKey, _ ed25519.PublicKey) {
+	alice.identityKey = key
+}
+
+// SetIdentityKeys makes Bob require Alice's round 3 message to be attested with peerKey, her identity key, and attach
+// evidence to a failed check of her proof for R. See Alice.SetIdentityKeys.
+func (bob *Bob) SetIdentityKeys(_ ed25519.PrivateKey, peerKey ed25519.PublicKey) {
+	bob.aliceKey = peerKey
+}
+
+// tweakedDigest returns h + r . tweak, or h if no tweak is set.
+func tweakedDigest(h, rX, tweak curves.Scalar) curves.Scalar {
+	if tweak == nil {
+		return h
+	}
+	return h.Add(rX.Mul(tweak))
+}
+
 // SignRound2Output is the output of the 3rd round of the protocol.
 type SignRound2Output struct {
 	// KosRound1Outputs is the output of the first round of OT Extension, stored for future rounds.
@@ -113,6 +163,9 @@ type SignRound3Output struct {
 
 	// EtaSig is the Eta_{Sig} from the paper.
 	EtaSig curves.Scalar
+
+	// Attestation is Alice's attestation of RPrime and RSchnorrProof, nil if she runs without an identity key.
+	Attestation *protocol.Attestation
 }
 
 // Round1GenerateRandomSeed first step of the generation of the shared random salt `idExt`
@@ -127,6 +180,7 @@ func (alice *Alice) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error
 		return [simplest.DigestSize]byte{}, errors.Wrap(err, "generating random bytes in alice round 1 generate")
 	}
 	alice.transcript.AppendMessage([]byte("session_id_alice"), aliceSeed[:])
+	alice.aliceSeed = aliceSeed
 	return aliceSeed, nil
 }
 
@@ -137,25 +191,32 @@ func (alice *Alice) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error
 // This latter step in turn amounts to sending the initial message in a new cOT extension.
 // All the resulting data gets packaged and sent to Alice.
 func (bob *Bob) Round2Initialize(aliceSeed [simplest.DigestSize]byte) (*SignRound2Output, error) {
+	round2Output, betas, err := bob.initialize(aliceSeed)
+	if err != nil {
+		return nil, err
+	}
+	for k := range bob.multiplyReceivers {
+		if bob.multiplyReceivers[k], err = NewMultiplyReceiver(bob.seedOtResults, bob.curve, bob.multiplyIds[k]); err != nil {
+			return nil, errors.Wrapf(err, "error creating multiply receiver %d in Bob sign round 3", k)
+		}
+		if round2Output.KosRound1Outputs[k], err = bob.multiplyReceivers[k].Round1Initialize(betas[k]); err != nil {
+			return nil, errors.Wrapf(err, "error in multiply round 1 initialize %d within Bob sign round 3 initialize", k)
+		}
+	}
+	return round2Output, nil
+}
+
+// initialize runs Bob's steps of Round2Initialize but the multiplications, and returns their inputs.
+func (bob *Bob) initialize(aliceSeed [simplest.DigestSize]byte) (*SignRound2Output, [multiplicationCount]curves.Scalar, error) {
 	bobSeed := [simplest.DigestSize]byte{}
 	if _, err := rand.Read(bobSeed[:]); err != nil {
-		return nil, errors.Wrap(err, "flipping random coins in bob round 2 initialize")
+		return nil, [multiplicationCount]curves.Scalar{}, errors.Wrap(err, "flipping random coins in bob round 2 initialize")
 	}
 	bob.transcript.AppendMessage([]byte("session_id_alice"), aliceSeed[:])
 	bob.transcript.AppendMessage([]byte("session_id_bob"), bobSeed[:])
+	bob.aliceSeed, bob.bobSeed = aliceSeed, bobSeed
+	bob.multiplyIds = multiplyIds(bob.transcript)
 
-	var err error
-	uniqueSessionId := [simplest.DigestSize]byte{} // will use and _re-use_ this throughout, for sub-session IDs
-	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("multiply receiver id 0"), simplest.DigestSize))
-	bob.multiplyReceivers[0], err = NewMultiplyReceiver(bob.seedOtResults, bob.curve, uniqueSessionId)
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating multiply receiver 0 in Bob sign round 3")
-	}
-	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("multiply receiver id 1"), simplest.DigestSize))
-	bob.multiplyReceivers[1], err = NewMultiplyReceiver(bob.seedOtResults, bob.curve, uniqueSessionId)
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating multiply receiver 1 in Bob sign round 3")
-	}
 	round2Output := &SignRound2Output{
 		Seed: bobSeed,
 	}
@@ -163,16 +224,15 @@ func (bob *Bob) Round2Initialize(aliceSeed [simplest.DigestSize]byte) (*SignRoun
 	bob.dB = bob.curve.ScalarBaseMult(bob.kB)
 	round2Output.DB = bob.dB
 	kBInv := bob.curve.Scalar.One().Div(bob.kB)
+	return round2Output, [multiplicationCount]curves.Scalar{kBInv, bob.secretKeyShare.Mul(kBInv)}, nil
+}
 
-	round2Output.KosRound1Outputs[0], err = bob.multiplyReceivers[0].Round1Initialize(kBInv)
-	if err != nil {
-		return nil, errors.Wrap(err, "error in multiply round 1 initialize 0 within Bob sign round 3 initialize")
-	}
-	round2Output.KosRound1Outputs[1], err = bob.multiplyReceivers[1].Round1Initialize(bob.secretKeyShare.Mul(kBInv))
-	if err != nil {
-		return nil, errors.Wrap(err, "error in multiply round 1 initialize 1 within Bob sign round 3 initialize")
-	}
-	return round2Output, nil
+// multiplyIds extracts the session ids of the two multiplications from the transcript.
+func multiplyIds(transcript *merlin.Transcript) [multiplicationCount][simplest.DigestSize]byte {
+	ids := [multiplicationCount][simplest.DigestSize]byte{}
+	copy(ids[0][:], transcript.ExtractBytes([]byte("multiply receiver id 0"), simplest.DigestSize))
+	copy(ids[1][:], transcript.ExtractBytes([]byte("multiply receiver id 1"), simplest.DigestSize))
+	return ids
 }
 
 // Round3Sign Alice's first message. Alice is the _responder_; she is responding to Bob's initial message.
@@ -182,50 +242,83 @@ func (bob *Bob) Round2Initialize(aliceSeed [simplest.DigestSize]byte) (*SignRoun
 // then to use the _output_ of the multiplication (which she already possesses as of the end of her computation),
 // and use that to compute some final values which will help Bob compute the final signature.
 func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (*SignRound3Output, error) {
-	alice.transcript.AppendMessage([]byte("session_id_bob"), round2Output.Seed[:])
+	round3Output, state, err := alice.presign(round2Output)
+	if err != nil {
+		return nil, err
+	}
+	if round3Output.EtaSig, err = state.etaSig(message, alice.tweak); err != nil {
+		return nil, err
+	}
+	return round3Output, nil
+}
 
-	multiplySenders := [multiplicationCount]*MultiplySender{}
-	var err error
-	uniqueSessionId := [simplest.DigestSize]byte{} // will use and _re-use_ this throughout, for sub-session IDs
-	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("multiply receiver id 0"), simplest.DigestSize))
-	if multiplySenders[0], err = NewMultiplySender(alice.seedOtResults, alice.curve, uniqueSessionId); err != nil {
-		return nil, errors.Wrap(err, "creating multiply sender 0 in Alice round 4 sign")
+// presign runs Alice's steps of Round3Sign that do not depend on the message, i.e. all of them but the computation of
+// EtaSig, which it leaves to the returned state.
+func (alice *Alice) presign(round2Output *SignRound2Output) (*SignRound3Output, *alicePresignState, error) {
+	round3Output, alphas, err := alice.initialize(round2Output)
+	if err != nil {
+		return nil, nil, err
+	}
+	tA := [multiplicationCount]curves.Scalar{}
+	for k := range alphas {
+		sender, err := NewMultiplySender(alice.seedOtResults, alice.curve, alice.multiplyIds[k])
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "creating multiply sender %d in Alice round 4 sign", k)
+		}
+		if round3Output.MultiplyRound2Outputs[k], err = sender.Round2Multiply(alphas[k], round2Output.KosRound1Outputs[k]); err != nil {
+			return nil, nil, errors.Wrapf(err, "error in round 2 multiply %d within alice round 4 sign", k)
+		}
+		tA[k] = sender.OutputAdditiveShare()
+	}
+	state, err := alice.finish(round3Output, tA)
+	if err != nil {
+		return nil, nil, err
 	}
-	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("multiply receiver id 1"), simplest.DigestSize))
-	if multiplySenders[1], err = NewMultiplySender(alice.seedOtResults, alice.curve, uniqueSessionId); err != nil {
-		return nil, errors.Wrap(err, "creating multiply sender 1 in Alice round 4 sign")
+	return round3Output, state, nil
+}
+
+// initialize runs Alice's steps of Round3Sign up to the multiplications, and returns their inputs.
+func (alice *Alice) initialize(round2Output *SignRound2Output) (*SignRound3Output, [multiplicationCount]curves.Scalar, error) {
+	if round2Output.DB == nil {
+		return nil, [multiplicationCount]curves.Scalar{}, &protocol.AbortError{Party: "bob", Check: "the well-formedness of his round 2 message"}
 	}
+	alice.transcript.AppendMessage([]byte("session_id_bob"), round2Output.Seed[:])
+	alice.multiplyIds = multiplyIds(alice.transcript)
+
 	round3Output := &SignRound3Output{}
 	kPrimeA := alice.curve.Scalar.Random(rand.Reader)
 	round3Output.RPrime = round2Output.DB.Mul(kPrimeA)
 	hashRPrimeBytes := sha3.Sum256(round3Output.RPrime.ToAffineCompressed())
 	hashRPrime, err := alice.curve.Scalar.SetBytes(hashRPrimeBytes[:])
 	if err != nil {
-		return nil, errors.Wrap(err, "setting hashRPrime scalar from bytes")
+		return nil, [multiplicationCount]curves.Scalar{}, errors.Wrap(err, "setting hashRPrime scalar from bytes")
 	}
-	kA := hashRPrime.Add(kPrimeA)
+	alice.kA = hashRPrime.Add(kPrimeA)
+	uniqueSessionId := [simplest.DigestSize]byte{}
 	copy(uniqueSessionId[:], alice.transcript.ExtractBytes([]byte("schnorr proof for R"), simplest.DigestSize))
 	rSchnorrProver := schnorr.NewProver(alice.curve, round2Output.DB, uniqueSessionId[:])
-	round3Output.RSchnorrProof, err = rSchnorrProver.Prove(kA)
+	round3Output.RSchnorrProof, err = rSchnorrProver.Prove(alice.kA)
 	if err != nil {
-		return nil, errors.Wrap(err, "generating schnorr proof for R = kA * DB in alice round 4 sign")
+		return nil, [multiplicationCount]curves.Scalar{}, errors.Wrap(err, "generating schnorr proof for R = kA * DB in alice round 4 sign")
 	}
+	round3Output.Attestation = protocol.Attest(alice.identityKey, instanceKeyLabel,
+		instanceKeyStatement(alice.aliceSeed, round2Output.Seed, round2Output.DB, round3Output.RPrime, round3Output.RSchnorrProof)...)
+	alice.phi = alice.curve.Scalar.Random(rand.Reader)
+	kAInv := alice.curve.Scalar.One().Div(alice.kA)
+	return round3Output, [multiplicationCount]curves.Scalar{alice.phi.Add(kAInv), alice.secretKeyShare.Mul(kAInv)}, nil
+}
+
+// finish runs Alice's steps of Round3Sign after the multiplications, in which she got the shares tA of the products,
+// but the computation of EtaSig, which it leaves to the returned state.
+func (alice *Alice) finish(round3Output *SignRound3Output, tA [multiplicationCount]curves.Scalar) (*alicePresignState, error) {
 	// reassign / stash the below value here just for notational clarity.
 	// this is _the_ key public point R in the ECDSA signature. we'll use its coordinate X in various places.
 	r := round3Output.RSchnorrProof.Statement
-	phi := alice.curve.Scalar.Random(rand.Reader)
-	kAInv := alice.curve.Scalar.One().Div(kA)
-
-	if round3Output.MultiplyRound2Outputs[0], err = multiplySenders[0].Round2Multiply(phi.Add(kAInv), round2Output.KosRound1Outputs[0]); err != nil {
-		return nil, errors.Wrap(err, "error in round 2 multiply 0 within alice round 4 sign")
-	}
-	if round3Output.MultiplyRound2Outputs[1], err = multiplySenders[1].Round2Multiply(alice.secretKeyShare.Mul(kAInv), round2Output.KosRound1Outputs[1]); err != nil {
-		return nil, errors.Wrap(err, "error in round 2 multiply 1 within alice round 4 sign")
-	}
+	kA, phi := alice.kA, alice.phi
 
 	one := alice.curve.Scalar.One()
 	gamma1 := alice.curve.ScalarBaseMult(kA.Mul(phi).Add(one))
-	other := r.Mul(multiplySenders[0].outputAdditiveShare.Neg())
+	other := r.Mul(tA[0].Neg())
 	gamma1 = gamma1.Add(other)
 	hashGamma1Bytes := sha3.Sum256(gamma1.ToAffineCompressed())
 	hashGamma1, err := alice.curve.Scalar.SetBytes(hashGamma1Bytes[:])
@@ -233,35 +326,57 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 		return nil, errors.Wrap(err, "setting hashGamma1 scalar from bytes")
 	}
 	round3Output.EtaPhi = hashGamma1.Add(phi)
-	if _, err = alice.hash.Write(message); err != nil {
-		return nil, errors.Wrap(err, "writing message to hash in alice round 4 sign")
-	}
-	digest := alice.hash.Sum(nil)
-	hOfMAsInteger, err := alice.curve.Scalar.SetBytes(digest)
-	if err != nil {
-		return nil, errors.Wrap(err, "setting hOfMAsInteger scalar from bytes")
-	}
 	affineCompressedForm := r.ToAffineCompressed()
 	if len(affineCompressedForm) != 33 {
 		return nil, errors.New("the compressed form must be exactly 33 bytes")
 	}
 	// Discard the leading byte and parse the rest as the X coordinate.
-	rX, err := alice.curve.Scalar.SetBytes(affineCompressedForm[1:])
+	rX, err := alice.curve.Scalar.SetBigInt(new(big.Int).SetBytes(affineCompressedForm[1:]))
 	if err != nil {
-		return nil, errors.Wrap(err, "setting rX scalar from bytes")
+		return nil, errors.Wrap(err, "setting rX scalar from big int")
 	}
-
-	sigA := hOfMAsInteger.Mul(multiplySenders[0].outputAdditiveShare).Add(rX.Mul(multiplySenders[1].outputAdditiveShare))
-	gamma2 := alice.publicKey.Mul(multiplySenders[0].outputAdditiveShare)
-	other = alice.curve.ScalarBaseMult(multiplySenders[1].outputAdditiveShare.Neg())
+	gamma2 := alice.publicKey.Mul(tA[0])
+	other = alice.curve.ScalarBaseMult(tA[1].Neg())
 	gamma2 = gamma2.Add(other)
 	hashGamma2Bytes := sha3.Sum256(gamma2.ToAffineCompressed())
 	hashGamma2, err := alice.curve.Scalar.SetBytes(hashGamma2Bytes[:])
 	if err != nil {
 		return nil, errors.Wrap(err, "setting hashGamma2 scalar from bytes")
 	}
-	round3Output.EtaSig = hashGamma2.Add(sigA)
-	return round3Output, nil
+	return &alicePresignState{
+		hash:       alice.hash,
+		curve:      alice.curve,
+		rX:         rX,
+		tA0:        tA[0],
+		tA1:        tA[1],
+		hashGamma2: hashGamma2,
+	}, nil
+}
+
+// alicePresignState is what Alice needs of a run of the protocol to compute EtaSig once the message is known.
+type alicePresignState struct {
+	hash       hash.Hash
+	curve      *curves.Curve
+	rX         curves.Scalar
+	tA0, tA1   curves.Scalar // Alice's shares of the products of the two multiplications
+	hashGamma2 curves.Scalar
+}
+
+// etaSig computes EtaSig for the message, signing for the key tweaked by tweak if it is not nil.
+func (state *alicePresignState) etaSig(message []byte, tweak curves.Scalar) (curves.Scalar, error) {
+	if _, err := state.hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash in alice round 4 sign")
+	}
+	digest := state.hash.Sum(nil)
+	// The digest is interpreted as an integer and reduced mod q, exactly as ECDSA verification does.
+	hOfMAsInteger, err := state.curve.Scalar.SetBigInt(new(big.Int).SetBytes(digest))
+	if err != nil {
+		return nil, errors.Wrap(err, "setting hOfMAsInteger scalar from big int")
+	}
+	hOfMAsInteger = tweakedDigest(hOfMAsInteger, state.rX, tweak)
+
+	sigA := hOfMAsInteger.Mul(state.tA0).Add(state.rX.Mul(state.tA1))
+	return state.hashGamma2.Add(sigA), nil
 }
 
 // Round4Final this is Bob's last portion of the signature computation, and ultimately results in the complete signature
@@ -270,89 +385,212 @@ func (alice *Alice) Round3Sign(message []byte, round2Output *SignRound2Output) (
 // Bob then move's onto the remainder of Alice's message, which contains extraneous data used to finish the signature.
 // Using this data, Bob completes the signature, which gets stored in `Bob.Sig`. Bob also verifies it.
 func (bob *Bob) Round4Final(message []byte, round3Output *SignRound3Output) error {
-	if err := bob.multiplyReceivers[0].Round3Multiply(round3Output.MultiplyRound2Outputs[0]); err != nil {
-		return errors.Wrap(err, "error in round 3 multiply 0 within sign round 5")
+	if round3Output.EtaSig == nil {
+		return &protocol.AbortError{Party: "alice", Check: "the well-formedness of her round 3 message"}
+	}
+	state, err := bob.presign(round3Output)
+	if err != nil {
+		return err
 	}
-	if err := bob.multiplyReceivers[1].Round3Multiply(round3Output.MultiplyRound2Outputs[1]); err != nil {
-		return errors.Wrap(err, "error in round 3 multiply 1 within sign round 5")
+	bob.Signature, err = state.signature(message, round3Output.EtaSig, bob.tweak)
+	return err
+}
+
+// presign runs Bob's steps of Round4Final that do not depend on the message or on EtaSig, i.e. all of them but the
+// computation and verification of the signature, which it leaves to the returned state.
+func (bob *Bob) presign(round3Output *SignRound3Output) (*bobPresignState, error) {
+	if err := checkRound3Output(round3Output); err != nil {
+		return nil, err
+	}
+	// The multiplications and the final verification run on Bob's seed OT and key shares, so a failure identifies
+	// Alice to Bob but comes without evidence.
+	tB := [multiplicationCount]curves.Scalar{}
+	for k, receiver := range bob.multiplyReceivers {
+		if err := receiver.Round3Multiply(round3Output.MultiplyRound2Outputs[k]); err != nil {
+			return nil, &protocol.AbortError{Party: "alice", Check: fmt.Sprintf("the consistency check of multiplication %d", k), Err: err}
+		}
+		tB[k] = receiver.OutputAdditiveShare()
+	}
+	return bob.finish(round3Output, tB)
+}
+
+// checkRound3Output checks that Alice's round 3 message has the values every run of the protocol needs.
+func checkRound3Output(round3Output *SignRound3Output) error {
+	if round3Output == nil || round3Output.RSchnorrProof == nil || round3Output.RSchnorrProof.C == nil ||
+		round3Output.RSchnorrProof.S == nil || round3Output.RPrime == nil || round3Output.EtaPhi == nil {
+		return &protocol.AbortError{Party: "alice", Check: "the well-formedness of her round 3 message"}
 	}
-	rPrimeHashedBytes := sha3.Sum256(round3Output.RPrime.ToAffineCompressed())
-	rPrimeHashed, err := bob.curve.Scalar.SetBytes(rPrimeHashedBytes[:])
+	return nil
+}
+
+// finish runs Bob's steps of Round4Final after the multiplications, in which he got the shares tB of the products,
+// but the computation and verification of the signature, which it leaves to the returned state.
+func (bob *Bob) finish(round3Output *SignRound3Output, tB [multiplicationCount]curves.Scalar) (*bobPresignState, error) {
+	r, err := instanceKey(bob.curve, bob.dB, round3Output.RPrime)
 	if err != nil {
-		return errors.Wrap(err, "setting rPrimeHashed scalar from bytes")
+		return nil, err
+	}
+	// Evidence needs Alice's attestation, so there is none if Bob runs without her identity key.
+	var evidence protocol.Evidence
+	if bob.aliceKey != nil {
+		statement := instanceKeyStatement(bob.aliceSeed, bob.bobSeed, bob.dB, round3Output.RPrime, round3Output.RSchnorrProof)
+		if err = round3Output.Attestation.VerifyFrom(bob.aliceKey, instanceKeyLabel, statement...); err != nil {
+			return nil, &protocol.AbortError{Party: "alice", Check: "the attestation of her round 3 message", Err: err}
+		}
+		evidence = &InstanceKeyEvidence{
+			AliceSeed:     bob.aliceSeed,
+			BobSeed:       bob.bobSeed,
+			DB:            bob.dB,
+			RPrime:        round3Output.RPrime,
+			RSchnorrProof: &schnorr.Proof{C: round3Output.RSchnorrProof.C, S: round3Output.RSchnorrProof.S},
+			Attestation:   round3Output.Attestation,
+		}
 	}
-	r := bob.dB.Mul(rPrimeHashed)
-	r = r.Add(round3Output.RPrime)
 	// To ensure that the correct public statement is used, we use the public statement that we have calculated
 	// instead of the open Alice sent us.
 	round3Output.RSchnorrProof.Statement = r
 	uniqueSessionId := [simplest.DigestSize]byte{}
 	copy(uniqueSessionId[:], bob.transcript.ExtractBytes([]byte("schnorr proof for R"), simplest.DigestSize))
 	if err = schnorr.Verify(round3Output.RSchnorrProof, bob.curve, bob.dB, uniqueSessionId[:]); err != nil {
-		return errors.Wrap(err, "bob's verification of alice's schnorr proof re: r failed")
-	}
-	zero := bob.curve.Scalar.Zero()
-	affineCompressedForm := r.ToAffineCompressed()
-	if len(affineCompressedForm) != 33 {
-		return errors.New("the compressed form must be exactly 33 bytes")
+		return nil, &protocol.AbortError{Party: "alice", Check: "the verification of her schnorr proof re: r", Evidence: evidence, Err: err}
 	}
-	rY := affineCompressedForm[0] & 0x1 // this is bit(0) of Y coordinate
-	rX, err := bob.curve.Scalar.SetBytes(affineCompressedForm[1:])
+	rX, _, err := curves.EcdsaR(bob.curve, r)
 	if err != nil {
-		return errors.Wrap(err, "setting rX scalar from bytes")
+		return nil, err
 	}
-	bob.Signature = &curves.EcdsaSignature{
-		R: rX.Add(zero).BigInt(), // slight trick here; add it to 0 just to mod it by q (now it's mod p!)
-		V: int(rY),
-	}
-	gamma1 := r.Mul(bob.multiplyReceivers[0].outputAdditiveShare)
+	gamma1 := r.Mul(tB[0])
 	gamma1HashedBytes := sha3.Sum256(gamma1.ToAffineCompressed())
 	gamma1Hashed, err := bob.curve.Scalar.SetBytes(gamma1HashedBytes[:])
 	if err != nil {
-		return errors.Wrap(err, "setting gamma1Hashed scalar from bytes")
+		return nil, errors.Wrap(err, "setting gamma1Hashed scalar from bytes")
 	}
 	phi := round3Output.EtaPhi.Sub(gamma1Hashed)
-	theta := bob.multiplyReceivers[0].outputAdditiveShare.Sub(phi.Div(bob.kB))
-	if _, err = bob.hash.Write(message); err != nil {
-		return errors.Wrap(err, "writing message to hash in Bob sign round 5 final")
-	}
-	digestBytes := bob.hash.Sum(nil)
-	digest, err := bob.curve.Scalar.SetBytes(digestBytes)
-	if err != nil {
-		return errors.Wrap(err, "setting digest scalar from bytes")
-	}
-	capitalR, err := bob.curve.Scalar.SetBigInt(bob.Signature.R)
-	if err != nil {
-		return errors.Wrap(err, "setting capitalR scalar from big int")
-	}
-	sigB := digest.Mul(theta).Add(capitalR.Mul(bob.multiplyReceivers[1].outputAdditiveShare))
-	gamma2 := bob.curve.ScalarBaseMult(bob.multiplyReceivers[1].outputAdditiveShare)
+	theta := tB[0].Sub(phi.Div(bob.kB))
+	gamma2 := bob.curve.ScalarBaseMult(tB[1])
 	other := bob.publicKey.Mul(theta.Neg())
 	gamma2 = gamma2.Add(other)
 	gamma2HashedBytes := sha3.Sum256(gamma2.ToAffineCompressed())
 	gamma2Hashed, err := bob.curve.Scalar.SetBytes(gamma2HashedBytes[:])
 	if err != nil {
-		return errors.Wrap(err, "setting gamma2Hashed scalar from bytes")
-	}
-	scalarS := sigB.Add(round3Output.EtaSig.Sub(gamma2Hashed))
-	bob.Signature.S = scalarS.BigInt()
-	if bob.Signature.S.Bit(255) == 1 {
-		bob.Signature.S = scalarS.Neg().BigInt()
-		bob.Signature.V ^= 1
-	}
-	// now verify the signature
-	unCompressedAffinePublicKey := bob.publicKey.ToAffineUncompressed()
-	if len(unCompressedAffinePublicKey) != 65 {
-		return errors.New("the uncompressed form must have exactly 65 bytes")
-	}
-	x := new(big.Int).SetBytes(unCompressedAffinePublicKey[1:33])
-	y := new(big.Int).SetBytes(unCompressedAffinePublicKey[33:])
-	ellipticCurve, err := bob.curve.ToEllipticCurve()
+		return nil, errors.Wrap(err, "setting gamma2Hashed scalar from bytes")
+	}
+	return &bobPresignState{
+		hash:         bob.hash,
+		curve:        bob.curve,
+		publicKey:    bob.publicKey,
+		bigR:         r,
+		rX:           rX,
+		theta:        theta,
+		tB1:          tB[1],
+		gamma2Hashed: gamma2Hashed,
+	}, nil
+}
+
+// bobPresignState is what Bob needs of a run of the protocol to complete the signature once the message and EtaSig
+// are known.
+type bobPresignState struct {
+	hash         hash.Hash
+	curve        *curves.Curve
+	publicKey    curves.Point
+	rX           curves.Scalar
+	bigR         curves.Point
+	theta        curves.Scalar
+	tB1          curves.Scalar // Bob's share of the product of the second multiplication
+	gamma2Hashed curves.Scalar
+}
+
+// signature completes and verifies the signature of the message from Alice's EtaSig, signing for the key tweaked by
+// tweak if it is not nil.
+func (state *bobPresignState) signature(message []byte, etaSig, tweak curves.Scalar) (*curves.EcdsaSignature, error) {
+	if _, err := state.hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash in Bob sign round 5 final")
+	}
+	digestBytes := state.hash.Sum(nil)
+	digest, err := state.curve.Scalar.SetBigInt(new(big.Int).SetBytes(digestBytes))
 	if err != nil {
-		return errors.Wrap(err, "invalid curve")
+		return nil, errors.Wrap(err, "setting digest scalar from big int")
+	}
+	digest = tweakedDigest(digest, state.rX, tweak)
+	sigB := digest.Mul(state.theta).Add(state.rX.Mul(state.tB1))
+	scalarS := sigB.Add(etaSig.Sub(state.gamma2Hashed))
+	// now verify the signature, against the tweaked key if there is one
+	verificationKey := state.publicKey
+	if tweak != nil {
+		verificationKey = verificationKey.Add(state.curve.ScalarBaseMult(tweak))
+	}
+	signature, err := curves.FinalizeEcdsaSignature(state.curve, state.bigR, scalarS, verificationKey, digestBytes)
+	if errors.Is(err, curves.ErrInvalidEcdsaSignature) {
+		return nil, &protocol.AbortError{Party: "alice", Check: "the verification of the final signature"}
+	}
+	return signature, err
+}
+
+// instanceKey computes R = H(R') . D_B + R', the instance key Alice proves knowledge of the discrete log of to base D_B.
+func instanceKey(curve *curves.Curve, dB, rPrime curves.Point) (curves.Point, error) {
+	rPrimeHashedBytes := sha3.Sum256(rPrime.ToAffineCompressed())
+	rPrimeHashed, err := curve.Scalar.SetBytes(rPrimeHashedBytes[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "setting rPrimeHashed scalar from bytes")
+	}
+	return dB.Mul(rPrimeHashed).Add(rPrime), nil
+}
+
+// instanceKeyLabel is the label of the statement Alice attests her round 3 message with.
+const instanceKeyLabel = "Coinbase_DKLs_Sign round 3"
+
+// instanceKeyStatement returns the parts of the statement Alice attests her round 3 message with: the seeds and D_B,
+// which bind it to the session, then R' and the proof for R.
+func instanceKeyStatement(aliceSeed, bobSeed [simplest.DigestSize]byte, dB, rPrime curves.Point, proof *schnorr.Proof) [][]byte {
+	return [][]byte{aliceSeed[:], bobSeed[:], dB.ToAffineCompressed(), rPrime.ToAffineCompressed(), proof.C.Bytes(), proof.S.Bytes()}
+}
+
+// InstanceKeyEvidence shows that Alice's proof of knowledge of k_A, for R = k_A . D_B, failed Bob's verification in
+// Round4Final. The seeds of round 1 and 2 bind the proof to the session, and Alice's attestation shows that she sent
+// it, in answer to D_B.
+type InstanceKeyEvidence struct {
+	// AliceSeed and BobSeed are the seeds of the session, from Alice's round 1 and Bob's round 2 messages.
+	AliceSeed [simplest.DigestSize]byte
+	BobSeed   [simplest.DigestSize]byte
+
+	// DB is D_{B} = k_{B} . G, from Bob's round 2 message.
+	DB curves.Point
+
+	// RPrime and RSchnorrProof are R' and the proof for R, from Alice's round 3 message.
+	RPrime        curves.Point
+	RSchnorrProof *schnorr.Proof
+
+	// Attestation is Alice's attestation of her round 3 message.
+	Attestation *protocol.Attestation
+}
+
+// Verify checks Alice's attestation and re-runs Bob's verification of the proof. It returns nil if the proof fails
+// it.
+func (e *InstanceKeyEvidence) Verify() error {
+	if e.DB == nil || e.RPrime == nil || e.RSchnorrProof == nil || e.RSchnorrProof.C == nil || e.RSchnorrProof.S == nil {
+		return errors.New("incomplete evidence")
 	}
-	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: ellipticCurve, X: x, Y: y}, digestBytes, bob.Signature.R, bob.Signature.S) {
-		return fmt.Errorf("final signature failed to verify")
+	statement := instanceKeyStatement(e.AliceSeed, e.BobSeed, e.DB, e.RPrime, e.RSchnorrProof)
+	if err := e.Attestation.Verify(instanceKeyLabel, statement...); err != nil {
+		return errors.Wrap(err, "the proof is not attested by alice")
+	}
+	curve := curves.GetCurveByName(e.DB.CurveName())
+	if curve == nil {
+		return errors.New("unknown curve")
+	}
+	r, err := instanceKey(curve, e.DB, e.RPrime)
+	if err != nil {
+		return err
+	}
+	// Replay the transcript of the session up to the proof.
+	transcript := merlin.NewTranscript("Coinbase_DKLs_Sign")
+	transcript.AppendMessage([]byte("session_id_alice"), e.AliceSeed[:])
+	transcript.AppendMessage([]byte("session_id_bob"), e.BobSeed[:])
+	transcript.ExtractBytes([]byte("multiply receiver id 0"), simplest.DigestSize)
+	transcript.ExtractBytes([]byte("multiply receiver id 1"), simplest.DigestSize)
+	uniqueSessionId := transcript.ExtractBytes([]byte("schnorr proof for R"), simplest.DigestSize)
+	proof := &schnorr.Proof{C: e.RSchnorrProof.C, S: e.RSchnorrProof.S, Statement: r}
+	if schnorr.Verify(proof, curve, e.DB, uniqueSessionId) == nil {
+		return errors.New("the proof verifies")
 	}
 	return nil
 }
@@ -7,13 +7,16 @@
 package sign
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/sha3"
 
 	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
 	"github.com/sonr-io/crypto/ot/base/simplest"
 	"github.com/sonr-io/crypto/ot/extension/kos"
 	"github.com/sonr-io/crypto/ot/ottest"
@@ -49,6 +52,152 @@ func TestSign(t *testing.T) {
 		require.NoError(t, err)
 		err = bob.Round4Final(message, round4Output)
 		require.NoError(t, err, "curve: %s", curve.Name)
+
+		// The signature is low-S and carries a recovery id that yields the joint public key
+		ec, err := curve.ToEllipticCurve()
+		require.NoError(t, err)
+		halfOrder := new(big.Int).Rsh(ec.Params().N, 1)
+		require.True(t, bob.Signature.S.Cmp(halfOrder) <= 0)
+		digest := sha3.Sum256(message)
+		recovered, err := curves.RecoverEcdsaPublicKey(curve, digest[:], bob.Signature)
+		require.NoError(t, err)
+		require.True(t, recovered.Equal(publicKey), "curve: %s", curve.Name)
+	}
+}
+
+func TestSignWithKeyTweak(t *testing.T) {
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		hashKeySeed := [simplest.DigestSize]byte{}
+		_, err := rand.Read(hashKeySeed[:])
+		require.NoError(t, err)
+
+		baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+		require.NoError(t, err)
+
+		secretKeyShareA := curve.Scalar.Random(rand.Reader)
+		secretKeyShareB := curve.Scalar.Random(rand.Reader)
+		publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+		alice := NewAlice(curve, sha3.New256(), &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey})
+		bob := NewBob(curve, sha3.New256(), &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey})
+
+		tweak := curve.Scalar.Random(rand.Reader)
+		alice.SetKeyTweak(tweak)
+		bob.SetKeyTweak(tweak)
+
+		message := []byte("A message.")
+		seed, err := alice.Round1GenerateRandomSeed()
+		require.NoError(t, err)
+		round3Output, err := bob.Round2Initialize(seed)
+		require.NoError(t, err)
+		round4Output, err := alice.Round3Sign(message, round3Output)
+		require.NoError(t, err)
+		require.NoError(t, bob.Round4Final(message, round4Output), "curve: %s", curve.Name)
+
+		// The signature is for the tweaked key, not the joint key
+		digest := sha3.Sum256(message)
+		recovered, err := curves.RecoverEcdsaPublicKey(curve, digest[:], bob.Signature)
+		require.NoError(t, err)
+		require.True(t, recovered.Equal(publicKey.Add(curve.ScalarBaseMult(tweak))), "curve: %s", curve.Name)
+		require.False(t, recovered.Equal(publicKey))
+	}
+}
+
+func TestSignWithMismatchedKeyTweak(t *testing.T) {
+	curve := curves.K256()
+	hashKeySeed := [simplest.DigestSize]byte{}
+	_, err := rand.Read(hashKeySeed[:])
+	require.NoError(t, err)
+
+	baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+	require.NoError(t, err)
+
+	secretKeyShareA := curve.Scalar.Random(rand.Reader)
+	secretKeyShareB := curve.Scalar.Random(rand.Reader)
+	publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+	alice := NewAlice(curve, sha3.New256(), &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey})
+	bob := NewBob(curve, sha3.New256(), &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey})
+	bob.SetKeyTweak(curve.Scalar.Random(rand.Reader))
+
+	message := []byte("A message.")
+	seed, err := alice.Round1GenerateRandomSeed()
+	require.NoError(t, err)
+	round3Output, err := bob.Round2Initialize(seed)
+	require.NoError(t, err)
+	round4Output, err := alice.Round3Sign(message, round3Output)
+	require.NoError(t, err)
+	require.Error(t, bob.Round4Final(message, round4Output))
+}
+
+func TestSignBlamesAlice(t *testing.T) {
+	curve := curves.K256()
+	tests := []struct {
+		name        string
+		tamper      func(*SignRound3Output)
+		hasEvidence bool
+	}{
+		{"multiplication", func(o *SignRound3Output) {
+			o.MultiplyRound2Outputs[0].U[0] = o.MultiplyRound2Outputs[0].U[0].Add(curve.Scalar.One())
+		}, false},
+		{"schnorr proof", func(o *SignRound3Output) { o.RSchnorrProof.S = o.RSchnorrProof.S.Add(curve.Scalar.One()) }, true},
+		{"attestation", func(o *SignRound3Output) { o.Attestation = nil }, false},
+		{"signature", func(o *SignRound3Output) { o.EtaSig = o.EtaSig.Add(curve.Scalar.One()) }, false},
+		{"malformed", func(o *SignRound3Output) { o.RPrime = nil }, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hashKeySeed := [simplest.DigestSize]byte{}
+			_, err := rand.Read(hashKeySeed[:])
+			require.NoError(t, err)
+			baseOtSenderOutput, baseOtReceiverOutput, err := ottest.RunSimplestOT(curve, kos.Kappa, hashKeySeed)
+			require.NoError(t, err)
+			secretKeyShareA := curve.Scalar.Random(rand.Reader)
+			secretKeyShareB := curve.Scalar.Random(rand.Reader)
+			publicKey := curve.ScalarBaseMult(secretKeyShareA.Mul(secretKeyShareB))
+			alice := NewAlice(curve, sha3.New256(), &dkg.AliceOutput{SeedOtResult: baseOtReceiverOutput, SecretKeyShare: secretKeyShareA, PublicKey: publicKey})
+			bob := NewBob(curve, sha3.New256(), &dkg.BobOutput{SeedOtResult: baseOtSenderOutput, SecretKeyShare: secretKeyShareB, PublicKey: publicKey})
+			aliceKey, alicePrivateKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+			bobKey, bobPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+			alice.SetIdentityKeys(alicePrivateKey, bobKey)
+			bob.SetIdentityKeys(bobPrivateKey, aliceKey)
+
+			message := []byte("A message.")
+			seed, err := alice.Round1GenerateRandomSeed()
+			require.NoError(t, err)
+			round3Output, err := bob.Round2Initialize(seed)
+			require.NoError(t, err)
+			round4Output, err := alice.Round3Sign(message, round3Output)
+			require.NoError(t, err)
+			honestProof := *round4Output.RSchnorrProof
+			test.tamper(round4Output)
+			if test.hasEvidence {
+				// a cheating Alice attests the message she tampered with
+				statement := instanceKeyStatement(seed, round3Output.Seed, round3Output.DB, round4Output.RPrime, round4Output.RSchnorrProof)
+				round4Output.Attestation = protocol.Attest(alicePrivateKey, instanceKeyLabel, statement...)
+			}
+
+			var abort *protocol.AbortError
+			require.ErrorAs(t, bob.Round4Final(message, round4Output), &abort)
+			require.Equal(t, "alice", abort.Party)
+			if !test.hasEvidence {
+				require.Nil(t, abort.Evidence)
+				return
+			}
+			evidence, ok := abort.Evidence.(*InstanceKeyEvidence)
+			require.True(t, ok)
+			require.NoError(t, evidence.Verify())
+			require.True(t, evidence.Attestation.PublicKey.Equal(aliceKey))
+
+			// the evidence does not hold against the honest proof, which Alice did not attest, nor without the
+			// attestation
+			honest := *evidence
+			honest.RSchnorrProof = &honestProof
+			require.Error(t, honest.Verify())
+			unattested := *evidence
+			unattested.Attestation = nil
+			require.Error(t, unattested.Verify())
+		})
 	}
 }
 
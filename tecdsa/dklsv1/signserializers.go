@@ -95,6 +95,31 @@ func decodeSignRound4Input(m *protocol.Message) (*sign.SignRound3Output, error)
 	return decoded, nil
 }
 
+func encodePresignedSignOutput(output *sign.PresignedSignOutput, version uint) (*protocol.Message, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	buf := bytes.NewBuffer([]byte{})
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(output); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newSignProtocolMessage(buf.Bytes(), "presigned", version), nil
+}
+
+func decodePresignedSignInput(m *protocol.Message) (*sign.PresignedSignOutput, error) {
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	buf := bytes.NewBuffer(m.Payloads[payloadKey])
+	dec := gob.NewDecoder(buf)
+	decoded := &sign.PresignedSignOutput{}
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return decoded, nil
+}
+
 func encodeSignature(signature *curves.EcdsaSignature, version uint) (*protocol.Message, error) {
 	if version != protocol.Version1 {
 		return nil, errors.New("only version 1 is supported")
@@ -120,3 +145,61 @@ func DecodeSignature(m *protocol.Message) (*curves.EcdsaSignature, error) {
 	}
 	return decoded, nil
 }
+
+// encodeBatch encodes the outputs of a round of batch signing, one per signature, in a version 2 payload.
+func encodeBatch[T any](outputs []T, round string, version uint) (*protocol.Message, error) {
+	if version != protocol.Version2 {
+		return nil, errors.New("only version 2 is supported for batches")
+	}
+	buf := bytes.NewBuffer([]byte{})
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(outputs); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newSignProtocolMessage(buf.Bytes(), round, version), nil
+}
+
+// decodeBatch decodes the inputs of a round of batch signing, and checks that there is one per signature.
+func decodeBatch[T any](m *protocol.Message, count int) ([]T, error) {
+	if m.Version != protocol.Version2 {
+		return nil, errors.New("only version 2 is supported for batches")
+	}
+	buf := bytes.NewBuffer(m.Payloads[payloadKey])
+	dec := gob.NewDecoder(buf)
+	var decoded []T
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if count >= 0 && len(decoded) != count {
+		return nil, errors.Errorf("expected a batch of %d, got %d", count, len(decoded))
+	}
+	return decoded, nil
+}
+
+// encodeBatchRound encodes the output of a round of batch signing that covers the whole batch in a version 2 payload.
+func encodeBatchRound(output any, round string, version uint) (*protocol.Message, error) {
+	if version != protocol.Version2 {
+		return nil, errors.New("only version 2 is supported for batches")
+	}
+	buf := bytes.NewBuffer([]byte{})
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(output); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newSignProtocolMessage(buf.Bytes(), round, version), nil
+}
+
+// decodeBatchRound decodes the input of a round of batch signing that covers the whole batch into decoded.
+func decodeBatchRound(m *protocol.Message, decoded any) error {
+	if m.Version != protocol.Version2 {
+		return errors.New("only version 2 is supported for batches")
+	}
+	buf := bytes.NewBuffer(m.Payloads[payloadKey])
+	dec := gob.NewDecoder(buf)
+	return errors.WithStack(dec.Decode(decoded))
+}
+
+// DecodeSignatures deserializes the signatures of a batch, in the order of the messages.
+func DecodeSignatures(m *protocol.Message) ([]*curves.EcdsaSignature, error) {
+	return decodeBatch[*curves.EcdsaSignature](m, -1)
+}
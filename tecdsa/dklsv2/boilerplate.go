@@ -0,0 +1,208 @@
+package dklsv2
+
+import (
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/refresh"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/sign"
+)
+
+// Dkg DKLs23 DKG implementation that satisfies the protocol iterator interface.
+type Dkg struct {
+	protoStepper
+	*dkg.Participant
+}
+
+// Sign DKLs23 sign implementation that satisfies the protocol iterator interface.
+type Sign struct {
+	protoStepper
+	*sign.Participant
+}
+
+// Refresh DKLs23 refresh implementation that satisfies the protocol iterator interface.
+type Refresh struct {
+	protoStepper
+	*refresh.Participant
+}
+
+var (
+	// Static type assertions
+	_ protocol.Iterator = &Dkg{}
+	_ protocol.Iterator = &Sign{}
+	_ protocol.Iterator = &Refresh{}
+)
+
+// peerStep is a protocol step that decodes the payloads received from each peer, runs a round on them and encodes
+// the payloads the round addressed to each peer.
+func peerStep[I, O any](name, round string, version uint, run func(map[uint32]I) (map[uint32]O, error)) func(*protocol.Message) (*protocol.Message, error) {
+	return func(input *protocol.Message) (*protocol.Message, error) {
+		decoded, err := decodePeerPayloads[I](input)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		output, err := run(decoded)
+		if err != nil {
+			return nil, err
+		}
+		return encodePeerPayloads(name, output, round, version)
+	}
+}
+
+// finalStep is the last protocol step, that decodes the payloads received from each peer and runs a round on them.
+func finalStep[I any](run func(map[uint32]I) error) func(*protocol.Message) (*protocol.Message, error) {
+	return func(input *protocol.Message) (*protocol.Message, error) {
+		decoded, err := decodePeerPayloads[I](input)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return nil, run(decoded)
+	}
+}
+
+// NewDkg creates a new protocol that can compute a t-of-n DKG as party id, where ids run from 1 to n.
+func NewDkg(curve *curves.Curve, id, threshold, limit uint32, version uint) (*Dkg, error) {
+	participant, err := dkg.NewParticipant(curve, id, threshold, limit)
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]uint32, 0, limit-1)
+	for peer := uint32(1); peer <= limit; peer++ {
+		if peer != id {
+			peers = append(peers, peer)
+		}
+	}
+	d := &Dkg{Participant: participant}
+	d.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			seedCommitment, err := d.Round1CommitToSeed()
+			if err != nil {
+				return nil, err
+			}
+			return encodeBroadcast(protocol.Dkls23Dkg, seedCommitment, peers, "1", version)
+		},
+		peerStep(protocol.Dkls23Dkg, "2", version, d.Round2DealShares),
+		peerStep(protocol.Dkls23Dkg, "3", version, d.Round3VerifySharesAndStartOt),
+		peerStep(protocol.Dkls23Dkg, "4", version, d.Round4DkgRound2Ot),
+		peerStep(protocol.Dkls23Dkg, "5", version, d.Round5DkgRound3Ot),
+		peerStep(protocol.Dkls23Dkg, "6", version, d.Round6DkgRound4Ot),
+		peerStep(protocol.Dkls23Dkg, "7", version, d.Round7DkgRound5Ot),
+		finalStep(d.Round8DkgRound6Ot),
+	}
+	return d, nil
+}
+
+// Result returns an encoded version of the party's DKG output that can be used to initialize a Sign or Refresh
+// protocol.
+func (d *Dkg) Result(version uint) (*protocol.Message, error) {
+	// Sanity check
+	if !d.complete() {
+		return nil, nil
+	}
+	if d.Participant == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeDkgOutput(d.Output(), version)
+}
+
+// NewSign creates a new protocol that can compute a signature as one of the signers, which must include the party
+// and at least threshold parties of the DKG. Requires dkg state that was produced at the end of DKG.Output().
+func NewSign(curve *curves.Curve, hash hash.Hash, message []byte, dkgResultMessage *protocol.Message, signers []uint32, version uint) (*Sign, error) {
+	dkgResult, err := DecodeDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	participant, err := sign.NewParticipant(curve, hash, dkgResult, signers)
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]uint32, 0, len(signers)-1)
+	for _, signer := range signers {
+		if signer != dkgResult.ID {
+			peers = append(peers, signer)
+		}
+	}
+	s := &Sign{Participant: participant}
+	s.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			seed, err := s.Round1GenerateRandomSeed()
+			if err != nil {
+				return nil, err
+			}
+			return encodeBroadcast(protocol.Dkls23Sign, seed, peers, "1", version)
+		},
+		peerStep(protocol.Dkls23Sign, "2", version, s.Round2Initialize),
+		peerStep(protocol.Dkls23Sign, "3", version, s.Round3Multiply),
+		func(input *protocol.Message) (*protocol.Message, error) {
+			round4Input, err := decodePeerPayloads[*sign.Round3Output](input)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			round4Output, err := s.Round4Combine(message, round4Input)
+			if err != nil {
+				return nil, err
+			}
+			return encodeBroadcast(protocol.Dkls23Sign, round4Output, peers, "4", version)
+		},
+		finalStep(s.Round5Final),
+	}
+	return s, nil
+}
+
+// Result returns the signature as a *curves.EcdsaSignature if the signing protocol completed successfully. Every
+// signer computes the signature.
+func (s *Sign) Result(version uint) (*protocol.Message, error) {
+	// We can't produce a signature until the protocol completes
+	if !s.complete() {
+		return nil, nil
+	}
+	if s.Participant == nil {
+		// Object wasn't created with NewSign()
+		return nil, protocol.ErrNotInitialized
+	}
+	return encodeSignature(s.Signature, version)
+}
+
+// NewRefresh creates a new protocol that can compute a key refresh. All the parties of the DKG must take part.
+func NewRefresh(curve *curves.Curve, dkgResultMessage *protocol.Message, version uint) (*Refresh, error) {
+	dkgResult, err := DecodeDkgResult(dkgResultMessage)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	peers := dkgResult.Peers()
+	r := &Refresh{Participant: refresh.NewParticipant(curve, dkgResult)}
+	r.steps = []func(*protocol.Message) (*protocol.Message, error){
+		func(*protocol.Message) (*protocol.Message, error) {
+			seed, err := r.Round1RefreshGenerateSeed()
+			if err != nil {
+				return nil, err
+			}
+			return encodeBroadcast(protocol.Dkls23Refresh, seed, peers, "1", version)
+		},
+		peerStep(protocol.Dkls23Refresh, "2", version, r.Round2RefreshDealZeroShares),
+		peerStep(protocol.Dkls23Refresh, "3", version, r.Round3RefreshUpdateAndStartOt),
+		peerStep(protocol.Dkls23Refresh, "4", version, r.Round4RefreshRound2Ot),
+		peerStep(protocol.Dkls23Refresh, "5", version, r.Round5RefreshRound3Ot),
+		peerStep(protocol.Dkls23Refresh, "6", version, r.Round6RefreshRound4Ot),
+		peerStep(protocol.Dkls23Refresh, "7", version, r.Round7RefreshRound5Ot),
+		finalStep(r.Round8RefreshRound6Ot),
+	}
+	return r, nil
+}
+
+// Result returns an encoded version of the party's refreshed DKG output that can be used to initialize a Sign or
+// Refresh protocol.
+func (r *Refresh) Result(version uint) (*protocol.Message, error) {
+	// Sanity check
+	if !r.complete() {
+		return nil, nil
+	}
+	if r.Participant == nil {
+		return nil, protocol.ErrNotInitialized
+	}
+	return EncodeDkgOutput(r.Output(), version)
+}
@@ -0,0 +1,129 @@
+package dkg
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/sharing"
+)
+
+// Blame returns the error that aborts a run because the party failed check. The party is named by its decimal
+// identifier. evidence may be nil.
+func Blame(party uint32, check string, evidence protocol.Evidence, err error) error {
+	return &protocol.AbortError{Party: partyName(party), Check: check, Evidence: evidence, Err: err}
+}
+
+func partyName(party uint32) string {
+	return strconv.FormatUint(uint64(party), 10)
+}
+
+// ShareEvidence shows that a share a party dealt is not on the polynomial it committed to. The recipient reveals the
+// share, which is safe because the aborted run is discarded. The dealer attested the commitments and share under the
+// session id of the run.
+type ShareEvidence struct {
+	// SessionId is the session id of the run, and Dealer the identifier of the party that dealt the share.
+	SessionId [simplest.DigestSize]byte
+	Dealer    uint32
+
+	// Commitments are the Feldman commitments the dealer sent.
+	Commitments []curves.Point
+
+	// ZeroConstant is set when the commitments omit the one to a zero constant term, as in refresh.
+	ZeroConstant bool
+
+	// Share is the share the dealer sent.
+	Share *sharing.ShamirShare
+
+	// Attestation is the dealer's attestation of the commitments and share.
+	Attestation *protocol.Attestation
+}
+
+// AttestShare returns the attestation of the commitments and share party dealer deals in round 2 of a run, of DKG or,
+// if zeroConstant is set, of refresh. It is nil if key is nil.
+func AttestShare(key ed25519.PrivateKey, sessionId [simplest.DigestSize]byte, dealer uint32, zeroConstant bool, commitments []curves.Point, share *sharing.ShamirShare) *protocol.Attestation {
+	return protocol.Attest(key, shareLabel(zeroConstant), shareStatement(sessionId, dealer, commitments, share)...)
+}
+
+// shareLabel is the label of the statement a dealer attests its commitments and share with.
+func shareLabel(zeroConstant bool) string {
+	if zeroConstant {
+		return "DKLs23 refresh round 2 sub-share"
+	}
+	return "DKLs23 DKG round 2 share"
+}
+
+// wellFormed reports whether the share and every commitment are set.
+func wellFormed(commitments []curves.Point, share *sharing.ShamirShare) bool {
+	for _, commitment := range commitments {
+		if commitment == nil {
+			return false
+		}
+	}
+	return share != nil
+}
+
+// shareStatement returns the parts of the statement a dealer attests its commitments and share with.
+func shareStatement(sessionId [simplest.DigestSize]byte, dealer uint32, commitments []curves.Point, share *sharing.ShamirShare) [][]byte {
+	parts := [][]byte{
+		sessionId[:],
+		binary.BigEndian.AppendUint32(nil, dealer),
+		binary.BigEndian.AppendUint32(nil, share.Id),
+		share.Value,
+	}
+	for _, commitment := range commitments {
+		parts = append(parts, commitment.ToAffineCompressed())
+	}
+	return parts
+}
+
+// CheckShareAttestation checks the attestation of the commitments and share party dealer dealt in round 2 of a run,
+// if key, the identity key of the dealer, is not nil, and returns the evidence to attach to a failed check of the
+// share. The evidence is nil if key is nil.
+func CheckShareAttestation(key ed25519.PublicKey, attestation *protocol.Attestation, sessionId [simplest.DigestSize]byte, dealer uint32, zeroConstant bool, commitments []curves.Point, share *sharing.ShamirShare) (protocol.Evidence, error) {
+	if key == nil {
+		return nil, nil
+	}
+	if !wellFormed(commitments, share) {
+		return nil, Blame(dealer, "the well-formedness of its round 2 message", nil, nil)
+	}
+	if err := attestation.VerifyFrom(key, shareLabel(zeroConstant), shareStatement(sessionId, dealer, commitments, share)...); err != nil {
+		return nil, Blame(dealer, "the attestation of its round 2 message", nil, err)
+	}
+	return &ShareEvidence{
+		SessionId:    sessionId,
+		Dealer:       dealer,
+		Commitments:  commitments,
+		ZeroConstant: zeroConstant,
+		Share:        share,
+		Attestation:  attestation,
+	}, nil
+}
+
+// Verify checks the dealer's attestation and re-runs the Feldman verification of the share. It returns nil if the
+// share fails it.
+func (e *ShareEvidence) Verify() error {
+	if len(e.Commitments) == 0 || !wellFormed(e.Commitments, e.Share) {
+		return errors.New("incomplete evidence")
+	}
+	if err := e.Attestation.Verify(shareLabel(e.ZeroConstant), shareStatement(e.SessionId, e.Dealer, e.Commitments, e.Share)...); err != nil {
+		return errors.Wrap(err, "the share is not attested by the dealer")
+	}
+	commitments := e.Commitments
+	if e.ZeroConstant {
+		curve := curves.GetCurveByName(commitments[0].CurveName())
+		if curve == nil {
+			return errors.New("unknown curve")
+		}
+		commitments = append([]curves.Point{curve.NewIdentityPoint()}, commitments...)
+	}
+	if (sharing.FeldmanVerifier{Commitments: commitments}).Verify(e.Share) == nil {
+		return errors.New("the share is on the committed polynomial")
+	}
+	return nil
+}
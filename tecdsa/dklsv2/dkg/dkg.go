@@ -0,0 +1,395 @@
+// Package dkg implements the n-party distributed key generation of the t-of-n threshold ECDSA of
+// [DKLs23](https://eprint.iacr.org/2023/765.pdf). Every party deals a Feldman sharing of a random secret and proves
+// knowledge of it with a schnorr proof; the joint secret key is the sum of the secrets, and each party's Shamir share
+// of it is the sum of the shares it was dealt. The parties echo digests of the commitments and proofs they received,
+// so that a party that sends different ones to different peers is caught. Every pair of parties then runs the
+// verified simplest OT in both directions, whose outputs seed the OT extensions of the pairwise multiplications in
+// signing.
+package dkg
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/sharing"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+// ChainCodeSize is the length in bytes of the BIP32 chain code produced by DKG.
+const ChainCodeSize = 32
+
+// Output is the result of running DKG for one party. It contains both the public and secret values that are needed
+// for signing with any threshold of the parties.
+type Output struct {
+	// ID is the Shamir identifier of the party, in [1, n].
+	ID uint32
+
+	// Threshold is the number of parties needed to sign.
+	Threshold uint32
+
+	// PublicKey is the joint public key of the parties.
+	// This value is public.
+	PublicKey curves.Point
+
+	// SecretKeyShare is the party's Shamir share of the joint secret key.
+	// This output must be kept secret. If more than n - t parties lose it, the parties cannot create signatures.
+	SecretKeyShare curves.Scalar
+
+	// PublicShares are the points x_j . G of the Shamir shares of all the parties, by identifier. Signers check the
+	// key shares their peers multiply with against them.
+	// This value is public.
+	PublicShares map[uint32]curves.Point
+
+	// SeedOtSenders are the outputs of the seed OTs in which the party was the sender, by peer. With these peers the
+	// party is the multiplication receiver.
+	// This output must be kept secret. If it is lost, refresh runs new seed OTs.
+	SeedOtSenders map[uint32]*simplest.SenderOutput
+
+	// SeedOtReceivers are the outputs of the seed OTs in which the party was the receiver, by peer. With these peers
+	// the party is the multiplication sender.
+	// This output must be kept secret. If it is lost, refresh runs new seed OTs.
+	SeedOtReceivers map[uint32]*simplest.ReceiverOutput
+
+	// ChainCode is a 32-byte value that all the parties extract from the joint DKG transcript, after the seeds they
+	// committed to in round 1 are revealed. Together with the public key it forms a BIP32 extended public key.
+	ChainCode []byte
+}
+
+// Peers returns the identifiers of all the other parties, in increasing order.
+func (o *Output) Peers() []uint32 {
+	peers := make([]uint32, 0, len(o.PublicShares)-1)
+	for id := range o.PublicShares {
+		if id != o.ID {
+			peers = append(peers, id)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+	return peers
+}
+
+// Participant is the state of one party during one execution of DKG.
+type Participant struct {
+	id    uint32
+	peers []uint32
+
+	feldman *sharing.Feldman
+
+	// shares are the Feldman shares of the party's secret, dealt to all the parties.
+	shares []*sharing.ShamirShare
+
+	// commitments are the Feldman commitments to the party's sharing polynomial.
+	commitments []curves.Point
+
+	// proof is the schnorr proof of knowledge of the party's secret.
+	proof *schnorr.Proof
+
+	seed                [simplest.DigestSize]byte
+	seedCommitment      [simplest.DigestSize]byte
+	peerSeedCommitments map[uint32][simplest.DigestSize]byte
+	sessionId           [simplest.DigestSize]byte
+
+	// echo holds the digests of the round 2 broadcasts of all the parties.
+	echo Echo
+
+	secretKeyShare curves.Scalar
+	publicKey      curves.Point
+	publicShares   map[uint32]curves.Point
+	chainCode      []byte
+
+	seedOt *SeedOt
+
+	// identityKey is the long-term key the party attests its messages with and peerKeys are the identity keys of the
+	// peers, by identifier. Both are nil to run without attestation.
+	identityKey ed25519.PrivateKey
+	peerKeys    map[uint32]ed25519.PublicKey
+
+	curve *curves.Curve
+
+	transcript *merlin.Transcript
+}
+
+// Round2Output is the output of the 2nd round of DKG, sent by a party to one of its peers.
+type Round2Output struct {
+	// Seed opens the commitment to the sender's seed of round 1. It is the same for all peers.
+	Seed [simplest.DigestSize]byte
+
+	// Commitments are the Feldman commitments to the sender's sharing polynomial. They are the same for all peers.
+	Commitments []curves.Point
+
+	// Proof is the schnorr proof of knowledge of the sender's secret, the discrete log of Commitments[0]. It is the
+	// same for all peers.
+	Proof *schnorr.Proof
+
+	// Share is the Feldman share of the sender's secret dealt to the recipient.
+	Share *sharing.ShamirShare
+
+	// Attestation is the sender's attestation of Commitments and Share, nil if it runs without an identity key.
+	Attestation *protocol.Attestation
+}
+
+// NewParticipant creates party id of a t-of-n DKG, where ids run from 1 to n.
+func NewParticipant(curve *curves.Curve, id, threshold, limit uint32) (*Participant, error) {
+	feldman, err := sharing.NewFeldman(threshold, limit, curve)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating feldman sharing for DKG")
+	}
+	if id == 0 || id > limit {
+		return nil, fmt.Errorf("party id %d is not in [1, %d]", id, limit)
+	}
+	peers := make([]uint32, 0, limit-1)
+	for peer := uint32(1); peer <= limit; peer++ {
+		if peer != id {
+			peers = append(peers, peer)
+		}
+	}
+	return &Participant{
+		id:         id,
+		peers:      peers,
+		feldman:    feldman,
+		curve:      curve,
+		transcript: merlin.NewTranscript("DKLs23_DKG"),
+	}, nil
+}
+
+// SetIdentityKeys makes the party attest the shares it deals and its openings of the seed OTs with its long-term
+// identity key, and require the ones of every peer with a key in peerKeys to be attested with it, so that a failed
+// check of them comes with evidence that a third party can verify. It must be called before round 2.
+func (p *Participant) SetIdentityKeys(key ed25519.PrivateKey, peerKeys map[uint32]ed25519.PublicKey) {
+	p.identityKey = key
+	p.peerKeys = peerKeys
+}
+
+// Round1CommitToSeed flips 32 random bytes and returns the commitment to them, which the party broadcasts. The
+// commitments of all the parties make up the unique session id used by the schnorr proofs and the seed OTs. The
+// seeds are revealed in round 2 and make up the chain code, which no party can bias since it committed to its seed
+// before seeing the others.
+func (p *Participant) Round1CommitToSeed() ([simplest.DigestSize]byte, error) {
+	if _, err := rand.Read(p.seed[:]); err != nil {
+		return [simplest.DigestSize]byte{}, errors.Wrap(err, "generating random bytes in DKG round 1")
+	}
+	p.seedCommitment = SeedCommitment(p.id, p.seed)
+	return p.seedCommitment, nil
+}
+
+// Round2DealShares derives the session id from the seed commitments of the peers, then deals a Feldman sharing of a
+// random secret and proves knowledge of it. It returns the message for each peer, which also reveals the seed.
+func (p *Participant) Round2DealShares(seedCommitments map[uint32][simplest.DigestSize]byte) (map[uint32]*Round2Output, error) {
+	if err := CheckInputs(p.peers, seedCommitments); err != nil {
+		return nil, errors.Wrap(err, "DKG round 2")
+	}
+	p.peerSeedCommitments = seedCommitments
+	p.sessionId = AppendSeeds(p.transcript, p.id, p.seedCommitment, seedCommitments)
+
+	secret := p.curve.Scalar.Random(rand.Reader)
+	verifier, shares, err := p.feldman.Split(secret, rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "splitting secret in DKG round 2")
+	}
+	p.shares = shares
+	p.commitments = verifier.Commitments
+	p.proof, err = schnorr.NewProver(p.curve, nil, PartySessionId(p.sessionId, p.id)).Prove(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "proving knowledge of secret in DKG round 2")
+	}
+	out := make(map[uint32]*Round2Output, len(p.peers))
+	for _, peer := range p.peers {
+		out[peer] = &Round2Output{
+			Seed:        p.seed,
+			Commitments: p.commitments,
+			Proof:       p.proof,
+			Share:       p.shares[peer-1],
+			Attestation: AttestShare(p.identityKey, p.sessionId, p.id, false, p.commitments, p.shares[peer-1]),
+		}
+	}
+	return out, nil
+}
+
+// Round3VerifySharesAndStartOt verifies the seeds, proofs and shares the peers revealed and dealt, sums the shares into
+// the party's key share, and starts the seed OTs with every peer. The messages for the peers carry the echo of the
+// round 2 broadcasts.
+func (p *Participant) Round3VerifySharesAndStartOt(inputs map[uint32]*Round2Output) (map[uint32]*Round3Output, error) {
+	if err := CheckInputs(p.peers, inputs); err != nil {
+		return nil, errors.Wrap(err, "DKG round 3")
+	}
+	ownShare, err := p.curve.Scalar.SetBytes(p.shares[p.id-1].Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting own share in DKG round 3")
+	}
+	p.secretKeyShare = ownShare
+	p.publicKey = p.commitments[0]
+	allCommitments := [][]curves.Point{p.commitments}
+	p.echo = Echo{p.id: round2Digest(p.sessionId, p.id, p.seed, p.commitments, p.proof)}
+	peerSeeds := make(map[uint32][simplest.DigestSize]byte, len(p.peers))
+	for _, peer := range p.peers {
+		input := inputs[peer]
+		if input == nil || input.Proof == nil || input.Share == nil || len(input.Commitments) != int(p.feldman.Threshold) {
+			return nil, Blame(peer, "the well-formedness of its DKG round 2 message", nil, nil)
+		}
+		if SeedCommitment(peer, input.Seed) != p.peerSeedCommitments[peer] {
+			return nil, Blame(peer, "the opening of its commitment to its seed", nil, nil)
+		}
+		if err = schnorr.Verify(input.Proof, p.curve, nil, PartySessionId(p.sessionId, peer)); err != nil {
+			return nil, Blame(peer, "the verification of its schnorr proof in DKG round 3", nil, err)
+		}
+		if !input.Proof.Statement.Equal(input.Commitments[0]) {
+			return nil, Blame(peer, "the check that its schnorr proof is for its secret", nil, nil)
+		}
+		if input.Share.Id != p.id {
+			return nil, Blame(peer, fmt.Sprintf("the check that it dealt the share of party %d", p.id), nil, nil)
+		}
+		evidence, err := CheckShareAttestation(p.peerKeys[peer], input.Attestation, p.sessionId, peer, false, input.Commitments, input.Share)
+		if err != nil {
+			return nil, err
+		}
+		if err = (sharing.FeldmanVerifier{Commitments: input.Commitments}).Verify(input.Share); err != nil {
+			return nil, Blame(peer, "the verification of the share it dealt in DKG round 3", evidence, err)
+		}
+		share, err := p.curve.Scalar.SetBytes(input.Share.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting share of party %d in DKG round 3", peer)
+		}
+		p.secretKeyShare = p.secretKeyShare.Add(share)
+		p.publicKey = p.publicKey.Add(input.Commitments[0])
+		allCommitments = append(allCommitments, input.Commitments)
+		p.echo[peer] = round2Digest(p.sessionId, peer, input.Seed, input.Commitments, input.Proof)
+		peerSeeds[peer] = input.Seed
+	}
+	if p.publicKey.IsIdentity() {
+		return nil, errors.New("joint public key is the identity")
+	}
+	p.publicShares = make(map[uint32]curves.Point, len(p.peers)+1)
+	for id := uint32(1); id <= p.feldman.Limit; id++ {
+		p.publicShares[id] = p.curve.NewIdentityPoint()
+		for _, commitments := range allCommitments {
+			p.publicShares[id] = p.publicShares[id].Add(EvaluateCommitments(commitments, id))
+		}
+	}
+	if !p.publicShares[p.id].Equal(p.curve.ScalarBaseMult(p.secretKeyShare)) {
+		return nil, errors.New("key share does not match its public share")
+	}
+	// The chain code is extracted after the revealed seeds, which no party could choose after seeing the others.
+	AppendSeeds(p.transcript, p.id, p.seed, peerSeeds)
+	p.chainCode = p.transcript.ExtractBytes([]byte("chain code"), ChainCodeSize)
+
+	if p.seedOt, err = NewSeedOt(p.curve, p.id, p.peers, p.sessionId); err != nil {
+		return nil, err
+	}
+	p.seedOt.SetIdentityKeys(p.identityKey, p.peerKeys)
+	proofs, err := p.seedOt.Round1ComputeAndZkpToPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return WithEcho(p.echo, proofs), nil
+}
+
+// Round4DkgRound2Ot checks the echoes of the peers, which aborts the run if a party sent different commitments,
+// proofs or seeds to different peers, then runs the 2nd round of the seed OTs.
+func (p *Participant) Round4DkgRound2Ot(inputs map[uint32]*Round3Output) (map[uint32][]simplest.ReceiversMaskedChoices, error) {
+	proofs, err := CheckRound3(p.id, p.peers, p.echo, inputs, "DKG round 2 broadcast")
+	if err != nil {
+		return nil, errors.Wrap(err, "DKG round 4")
+	}
+	return p.seedOt.Round2VerifySchnorrAndPadTransfer(proofs)
+}
+
+// Round5DkgRound3Ot is a thin wrapper around the 3rd round of the seed OTs.
+func (p *Participant) Round5DkgRound3Ot(choices map[uint32][]simplest.ReceiversMaskedChoices) (map[uint32][]simplest.OtChallenge, error) {
+	return p.seedOt.Round3PadTransfer(choices)
+}
+
+// Round6DkgRound4Ot is a thin wrapper around the 4th round of the seed OTs.
+func (p *Participant) Round6DkgRound4Ot(challenges map[uint32][]simplest.OtChallenge) (map[uint32][]simplest.OtChallengeResponse, error) {
+	return p.seedOt.Round4RespondToChallenge(challenges)
+}
+
+// Round7DkgRound5Ot is a thin wrapper around the 5th round of the seed OTs.
+func (p *Participant) Round7DkgRound5Ot(responses map[uint32][]simplest.OtChallengeResponse) (map[uint32]*simplest.Round5Output, error) {
+	return p.seedOt.Round5Verify(responses)
+}
+
+// Round8DkgRound6Ot is a thin wrapper around the 6th round of the seed OTs.
+func (p *Participant) Round8DkgRound6Ot(openings map[uint32]*simplest.Round5Output) error {
+	return p.seedOt.Round6Verify(openings)
+}
+
+// Output returns the output of the DKG operation. Must be called after step 8. Calling it before that step
+// has undefined behaviour.
+func (p *Participant) Output() *Output {
+	return &Output{
+		ID:              p.id,
+		Threshold:       p.feldman.Threshold,
+		PublicKey:       p.publicKey,
+		SecretKeyShare:  p.secretKeyShare,
+		PublicShares:    p.publicShares,
+		SeedOtSenders:   p.seedOt.SenderOutputs(),
+		SeedOtReceivers: p.seedOt.ReceiverOutputs(),
+		ChainCode:       p.chainCode,
+	}
+}
+
+// AppendSeeds writes the seed of party id and the seeds of its peers to the transcript, in increasing order of
+// identifier, and extracts the session id of the run from it.
+func AppendSeeds(transcript *merlin.Transcript, id uint32, seed [simplest.DigestSize]byte, peerSeeds map[uint32][simplest.DigestSize]byte) [simplest.DigestSize]byte {
+	seeds := map[uint32][simplest.DigestSize]byte{id: seed}
+	ids := []uint32{id}
+	for peer, peerSeed := range peerSeeds {
+		seeds[peer] = peerSeed
+		ids = append(ids, peer)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, party := range ids {
+		partySeed := seeds[party]
+		transcript.AppendMessage([]byte(fmt.Sprintf("session_id_%d", party)), partySeed[:])
+	}
+	sessionId := [simplest.DigestSize]byte{}
+	copy(sessionId[:], transcript.ExtractBytes([]byte("session id"), simplest.DigestSize))
+	return sessionId
+}
+
+// SeedCommitment is the commitment of party id to its seed.
+func SeedCommitment(id uint32, seed [simplest.DigestSize]byte) [simplest.DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write([]byte("DKLs23 seed commitment"))
+	_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, id))
+	_, _ = hash.Write(seed[:])
+	commitment := [simplest.DigestSize]byte{}
+	copy(commitment[:], hash.Sum(nil))
+	return commitment
+}
+
+// round2Digest is the digest of the broadcast part of the DKG round 2 message of party from.
+func round2Digest(sessionId [simplest.DigestSize]byte, from uint32, seed [simplest.DigestSize]byte, commitments []curves.Point, proof *schnorr.Proof) [simplest.DigestSize]byte {
+	parts := [][]byte{seed[:], proof.C.Bytes(), proof.S.Bytes(), proof.Statement.ToAffineCompressed()}
+	for _, commitment := range commitments {
+		parts = append(parts, commitment.ToAffineCompressed())
+	}
+	return BroadcastDigest(sessionId, "DKG round 2", from, parts...)
+}
+
+// EvaluateCommitments returns the public share of party id of a Feldman sharing, C_0 + id . C_1 + id^2 . C_2 + ...
+func EvaluateCommitments(commitments []curves.Point, id uint32) curves.Point {
+	curve := curves.GetCurveByName(commitments[0].CurveName())
+	x := curve.Scalar.New(int(id))
+	power := curve.Scalar.One()
+	result := commitments[0]
+	for _, commitment := range commitments[1:] {
+		power = power.Mul(x)
+		result = result.Add(commitment.Mul(power))
+	}
+	return result
+}
+
+// PartySessionId binds the schnorr proof of a party to the session and to its identifier.
+func PartySessionId(sessionId [simplest.DigestSize]byte, id uint32) []byte {
+	return binary.BigEndian.AppendUint32(append([]byte{}, sessionId[:]...), id)
+}
@@ -0,0 +1,202 @@
+package dkg_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/sharing"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/internal/dklstest"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+func TestDkg(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		curve            *curves.Curve
+		threshold, limit uint32
+	}{
+		{curves.K256(), 2, 3},
+		{curves.K256(), 3, 5},
+		{curves.P256(), 2, 3},
+	}
+	for _, test := range tests {
+		boundCurve, threshold, limit := test.curve, test.threshold, test.limit
+		t.Run(fmt.Sprintf("%d of %d dkg for curve %s", threshold, limit, boundCurve.Name), func(tt *testing.T) {
+			tt.Parallel()
+			outputs := dklstest.RunDkg(tt, boundCurve, threshold, limit)
+			publicKey := outputs[1].PublicKey
+			for id, output := range outputs {
+				require.True(tt, publicKey.Equal(output.PublicKey))
+				require.Equal(tt, outputs[1].ChainCode, output.ChainCode)
+				require.Len(tt, output.ChainCode, dkg.ChainCodeSize)
+				for j, publicShare := range outputs[1].PublicShares {
+					require.True(tt, publicShare.Equal(output.PublicShares[j]))
+				}
+				require.True(tt, output.PublicShares[id].Equal(boundCurve.ScalarBaseMult(output.SecretKeyShare)))
+				require.Len(tt, output.Peers(), int(limit-1))
+			}
+
+			// any threshold of the shares recombines to the secret key of the public key
+			feldman, err := sharing.NewFeldman(threshold, limit, boundCurve)
+			require.NoError(tt, err)
+			for first := uint32(1); first+threshold-1 <= limit; first++ {
+				shares := make([]*sharing.ShamirShare, 0, threshold)
+				for id := first; id < first+threshold; id++ {
+					shares = append(shares, &sharing.ShamirShare{Id: id, Value: outputs[id].SecretKeyShare.Bytes()})
+				}
+				secret, err := feldman.Combine(shares...)
+				require.NoError(tt, err)
+				require.True(tt, publicKey.Equal(boundCurve.ScalarBaseMult(secret)))
+			}
+
+			// every pair ran a correct OT in both directions
+			for i, output := range outputs {
+				for _, j := range output.Peers() {
+					sender := output.SeedOtSenders[j]
+					receiver := outputs[j].SeedOtReceivers[i]
+					for k := 0; k < kos.Kappa; k++ {
+						require.Equal(tt, sender.OneTimePadEncryptionKeys[k][receiver.RandomChoiceBits[k]], receiver.OneTimePadDecryptionKey[k])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDkgRejectsBadShare(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	parties := dklstest.NewDkgParticipants(t, curve, 2, 3)
+	keys := dklstest.SetIdentityKeys(t, parties)
+	inputs := dklstest.DealShares(t, parties)
+	sent := inputs[1][2]
+
+	// party 2 deals and attests to party 1 a share that is not on its committed polynomial
+	tampered := *sent.Share
+	tampered.Value = curve.Scalar.Random(rand.Reader).Bytes()
+	attestation := dkg.AttestShare(keys[2], parties[2].SessionId(), 2, false, sent.Commitments, &tampered)
+	inputs[1][2] = &dkg.Round2Output{Seed: sent.Seed, Commitments: sent.Commitments, Proof: sent.Proof, Share: &tampered, Attestation: attestation}
+	_, err := parties[1].Round3VerifySharesAndStartOt(inputs[1])
+	var abort *protocol.AbortError
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "2", abort.Party)
+	require.NoError(t, abort.Evidence.Verify())
+	evidence, ok := abort.Evidence.(*dkg.ShareEvidence)
+	require.True(t, ok)
+	require.True(t, evidence.Attestation.PublicKey.Equal(keys[2].Public()))
+
+	// the evidence does not hold against the share of an honest dealer, nor with a share the dealer did not attest
+	honest := &dkg.ShareEvidence{
+		SessionId:   evidence.SessionId,
+		Dealer:      3,
+		Commitments: inputs[1][3].Commitments,
+		Share:       inputs[1][3].Share,
+		Attestation: inputs[1][3].Attestation,
+	}
+	require.Error(t, honest.Verify())
+	forged := *evidence
+	forged.Share = &sharing.ShamirShare{Id: tampered.Id, Value: curve.Scalar.Random(rand.Reader).Bytes()}
+	require.Error(t, forged.Verify())
+	forged.Share, forged.Attestation = evidence.Share, nil
+	require.Error(t, forged.Verify())
+
+	// a share that is not attested aborts without evidence
+	inputs[1][2] = &dkg.Round2Output{Seed: sent.Seed, Commitments: sent.Commitments, Proof: sent.Proof, Share: &tampered}
+	_, err = parties[1].Round3VerifySharesAndStartOt(inputs[1])
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "2", abort.Party)
+	require.Nil(t, abort.Evidence)
+
+	// party 3 replays the proof of party 2
+	inputs[1][2] = sent
+	inputs[1][3] = &dkg.Round2Output{Seed: inputs[1][3].Seed, Commitments: inputs[1][3].Commitments, Proof: inputs[1][2].Proof, Share: inputs[1][3].Share, Attestation: inputs[1][3].Attestation}
+	_, err = parties[1].Round3VerifySharesAndStartOt(inputs[1])
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "3", abort.Party)
+
+	// party 2 reveals another seed than the one it committed to
+	inputs = dklstest.DealShares(t, parties)
+	otherSeed := inputs[1][2].Seed
+	otherSeed[0] ^= 1
+	inputs[1][2] = &dkg.Round2Output{Seed: otherSeed, Commitments: inputs[1][2].Commitments, Proof: inputs[1][2].Proof, Share: inputs[1][2].Share, Attestation: inputs[1][2].Attestation}
+	_, err = parties[1].Round3VerifySharesAndStartOt(inputs[1])
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "2", abort.Party)
+
+	// a missing peer
+	inputs[1][2] = sent
+	delete(inputs[1], 3)
+	_, err = parties[1].Round3VerifySharesAndStartOt(inputs[1])
+	require.Error(t, err)
+}
+
+func TestDkgCatchesEquivocation(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	parties := dklstest.NewDkgParticipants(t, curve, 2, 3)
+	inputs := dklstest.DealShares(t, parties)
+
+	// party 2 deals party 3 a consistent sharing of another secret, so party 3 alone cannot tell
+	feldman, err := sharing.NewFeldman(2, 3, curve)
+	require.NoError(t, err)
+	secret := curve.Scalar.Random(rand.Reader)
+	verifier, shares, err := feldman.Split(secret, rand.Reader)
+	require.NoError(t, err)
+	proof, err := schnorr.NewProver(curve, nil, dkg.PartySessionId(parties[2].SessionId(), 2)).Prove(secret)
+	require.NoError(t, err)
+	inputs[3][2] = &dkg.Round2Output{Seed: inputs[3][2].Seed, Commitments: verifier.Commitments, Proof: proof, Share: shares[2]}
+	round3 := dklstest.Route(dklstest.RunRound(t, parties, inputs, (*dkg.Participant).Round3VerifySharesAndStartOt))
+
+	var abort *protocol.AbortError
+	for _, id := range []uint32{1, 3} {
+		_, err = parties[id].Round4DkgRound2Ot(round3[id])
+		require.ErrorAs(t, err, &abort, "party %d", id)
+		require.Equal(t, "2", abort.Party, "party %d", id)
+	}
+}
+
+func TestDkgBlamesFalseEcho(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	parties := dklstest.NewDkgParticipants(t, curve, 2, 3)
+	round3 := dklstest.Route(dklstest.RunRound(t, parties, dklstest.DealShares(t, parties), (*dkg.Participant).Round3VerifySharesAndStartOt))
+
+	// party 3 echoes another digest of the broadcast of party 1 than the one party 1 sent
+	echo := make(dkg.Echo, len(round3[1][3].Echo))
+	for id, digest := range round3[1][3].Echo {
+		echo[id] = digest
+	}
+	digest := echo[1]
+	digest[0] ^= 1
+	echo[1] = digest
+	round3[1][3] = &dkg.Round3Output{Echo: echo, SeedOtProof: round3[1][3].SeedOtProof}
+	_, err := parties[1].Round4DkgRound2Ot(round3[1])
+	var abort *protocol.AbortError
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "3", abort.Party)
+
+	// party 3 leaves out the digest of a broadcast
+	delete(echo, 2)
+	_, err = parties[1].Round4DkgRound2Ot(round3[1])
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "3", abort.Party)
+}
+
+func TestNewParticipantValidatesIds(t *testing.T) {
+	t.Parallel()
+	_, err := dkg.NewParticipant(curves.K256(), 0, 2, 3)
+	require.Error(t, err)
+	_, err = dkg.NewParticipant(curves.K256(), 4, 2, 3)
+	require.Error(t, err)
+	_, err = dkg.NewParticipant(curves.K256(), 1, 4, 3)
+	require.Error(t, err)
+	_, err = dkg.NewParticipant(curves.K256(), 1, 2, 3)
+	require.NoError(t, err)
+}
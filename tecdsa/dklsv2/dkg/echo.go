@@ -0,0 +1,107 @@
+package dkg
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+// The values a party broadcasts, like its Feldman commitments, travel over the same point-to-point channels as the
+// rest of its messages, so a party could send different values to different peers. To catch this, every party echoes
+// a digest of the broadcast it got from each party, its own included, and checks the echoes of its peers against its
+// own digests before it relies on the broadcasts.
+//
+// Echoes are not attested (see SetIdentityKeys), so a mismatch cannot tell a party that equivocated from a peer that lies about what it
+// received. CheckEchoes blames the sender of the broadcast and names the peer that echoed another digest in the
+// check, unless the mismatch is about the broadcast of the party itself or of that peer, in which case the peer is to
+// blame.
+
+// Echo holds the digests of the broadcasts a party received in one round, by sender.
+type Echo map[uint32][simplest.DigestSize]byte
+
+// Round3Output is the output of the 3rd round of DKG and of refresh, sent by a party to one of its peers.
+type Round3Output struct {
+	// Echo is the echo of the round 2 broadcasts. It is the same for all peers.
+	Echo Echo
+
+	// SeedOtProof is the 1st message of the seed OT in which the sender is the OT sender.
+	SeedOtProof *schnorr.Proof
+}
+
+// BroadcastDigest hashes the broadcast of party from in the round named label of the run with the given session id.
+func BroadcastDigest(sessionId [simplest.DigestSize]byte, label string, from uint32, parts ...[]byte) [simplest.DigestSize]byte {
+	hash := sha3.New256()
+	_, _ = hash.Write(sessionId[:])
+	_, _ = hash.Write([]byte(label))
+	_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, from))
+	for _, part := range parts {
+		_, _ = hash.Write(binary.BigEndian.AppendUint32(nil, uint32(len(part))))
+		_, _ = hash.Write(part)
+	}
+	digest := [simplest.DigestSize]byte{}
+	copy(digest[:], hash.Sum(nil))
+	return digest
+}
+
+// CheckEchoes checks that the echo of every peer of party id matches own, the echo of the party. broadcast names the
+// echoed broadcast in the abort.
+func CheckEchoes(id uint32, peers []uint32, own Echo, echoes map[uint32]Echo, broadcast string) error {
+	if err := CheckInputs(peers, echoes); err != nil {
+		return err
+	}
+	senders := append([]uint32{id}, peers...)
+	for _, peer := range peers {
+		echo := echoes[peer]
+		if len(echo) != len(senders) {
+			return Blame(peer, "the well-formedness of its echo of the "+broadcast, nil, nil)
+		}
+		for _, sender := range senders {
+			digest, ok := echo[sender]
+			if !ok {
+				return Blame(peer, "the well-formedness of its echo of the "+broadcast, nil, nil)
+			}
+			if digest == own[sender] {
+				continue
+			}
+			if sender == id || sender == peer {
+				return Blame(peer, fmt.Sprintf("the check that its echo of the %s of party %d is right", broadcast, sender), nil, nil)
+			}
+			return Blame(sender, fmt.Sprintf("the check that it sent the same %s to party %d and party %d", broadcast, id, peer), nil, nil)
+		}
+	}
+	return nil
+}
+
+// CheckRound3 checks the echoes in the round 3 messages of the peers of party id against own, and returns the seed OT
+// messages they carry.
+func CheckRound3(id uint32, peers []uint32, own Echo, inputs map[uint32]*Round3Output, broadcast string) (map[uint32]*schnorr.Proof, error) {
+	if err := CheckInputs(peers, inputs); err != nil {
+		return nil, err
+	}
+	echoes := make(map[uint32]Echo, len(inputs))
+	proofs := make(map[uint32]*schnorr.Proof, len(inputs))
+	for _, peer := range peers {
+		if inputs[peer] == nil || inputs[peer].SeedOtProof == nil {
+			return nil, Blame(peer, "the well-formedness of its round 3 message", nil, nil)
+		}
+		echoes[peer] = inputs[peer].Echo
+		proofs[peer] = inputs[peer].SeedOtProof
+	}
+	if err := CheckEchoes(id, peers, own, echoes, broadcast); err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
+
+// WithEcho attaches the echo to the seed OT messages of round 3.
+func WithEcho(echo Echo, proofs map[uint32]*schnorr.Proof) map[uint32]*Round3Output {
+	out := make(map[uint32]*Round3Output, len(proofs))
+	for peer, proof := range proofs {
+		out[peer] = &Round3Output{Echo: echo, SeedOtProof: proof}
+	}
+	return out
+}
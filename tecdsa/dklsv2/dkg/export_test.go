@@ -0,0 +1,6 @@
+package dkg
+
+import "github.com/sonr-io/crypto/ot/base/simplest"
+
+// SessionId returns the session id of the run, for the tests of package dkg_test.
+func (p *Participant) SessionId() [simplest.DigestSize]byte { return p.sessionId }
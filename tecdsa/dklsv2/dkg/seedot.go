@@ -0,0 +1,196 @@
+package dkg
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+// SeedOt runs the verified simplest OT of a party with every one of its peers, in both directions: with each peer the
+// party is the OT sender of one run and the OT receiver of the other. The sender outputs seed the OT extensions in
+// which the party is the multiplication receiver, and the receiver outputs the ones in which it is the multiplication
+// sender. All the runs advance together, every round takes and returns the messages of all the peers by identifier.
+type SeedOt struct {
+	peers     []uint32
+	senders   map[uint32]*simplest.Sender
+	receivers map[uint32]*simplest.Receiver
+}
+
+// PairSessionId derives the unique session id of a two-party sub-protocol between sender and receiver from the
+// session id of a multi-party run. Both parties derive the same value; distinct labels separate sub-protocols.
+func PairSessionId(sessionId [simplest.DigestSize]byte, label string, sender, receiver uint32) [simplest.DigestSize]byte {
+	var ids [8]byte
+	binary.BigEndian.PutUint32(ids[:4], sender)
+	binary.BigEndian.PutUint32(ids[4:], receiver)
+	hash := sha3.New256()
+	_, _ = hash.Write(sessionId[:])
+	_, _ = hash.Write([]byte(label))
+	_, _ = hash.Write(ids[:])
+	pairSessionId := [simplest.DigestSize]byte{}
+	copy(pairSessionId[:], hash.Sum(nil))
+	return pairSessionId
+}
+
+// NewSeedOt creates the OT senders and receivers of party id with each of its peers.
+func NewSeedOt(curve *curves.Curve, id uint32, peers []uint32, sessionId [simplest.DigestSize]byte) (*SeedOt, error) {
+	s := &SeedOt{
+		peers:     peers,
+		senders:   make(map[uint32]*simplest.Sender, len(peers)),
+		receivers: make(map[uint32]*simplest.Receiver, len(peers)),
+	}
+	var err error
+	for _, peer := range peers {
+		s.senders[peer], err = simplest.NewSender(curve, kos.Kappa, PairSessionId(sessionId, "seed ot", id, peer))
+		if err != nil {
+			return nil, errors.Wrapf(err, "constructing seed OT sender for party %d", peer)
+		}
+		s.receivers[peer], err = simplest.NewReceiver(curve, kos.Kappa, PairSessionId(sessionId, "seed ot", peer, id))
+		if err != nil {
+			return nil, errors.Wrapf(err, "constructing seed OT receiver for party %d", peer)
+		}
+	}
+	return s, nil
+}
+
+// SetIdentityKeys makes the party attest its openings as the sender of every peer with its long-term identity key,
+// and require the openings of every peer to be attested with the identity key of the peer in peerKeys, so that a
+// failed check comes with evidence that a third party can verify.
+func (s *SeedOt) SetIdentityKeys(key ed25519.PrivateKey, peerKeys map[uint32]ed25519.PublicKey) {
+	for _, sender := range s.senders {
+		sender.SetIdentityKey(key)
+	}
+	for peer, receiver := range s.receivers {
+		receiver.SetSenderKey(peerKeys[peer])
+	}
+}
+
+// Round1ComputeAndZkpToPublicKey runs the 1st round of seed OT as the sender of every peer.
+func (s *SeedOt) Round1ComputeAndZkpToPublicKey() (map[uint32]*schnorr.Proof, error) {
+	out := make(map[uint32]*schnorr.Proof, len(s.senders))
+	for peer, sender := range s.senders {
+		proof, err := sender.Round1ComputeAndZkpToPublicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 1 with party %d", peer)
+		}
+		out[peer] = proof
+	}
+	return out, nil
+}
+
+// Round2VerifySchnorrAndPadTransfer runs the 2nd round of seed OT as the receiver of every peer.
+func (s *SeedOt) Round2VerifySchnorrAndPadTransfer(proofs map[uint32]*schnorr.Proof) (map[uint32][]simplest.ReceiversMaskedChoices, error) {
+	if err := CheckInputs(s.peers, proofs); err != nil {
+		return nil, err
+	}
+	out := make(map[uint32][]simplest.ReceiversMaskedChoices, len(s.receivers))
+	for peer, receiver := range s.receivers {
+		choices, err := receiver.Round2VerifySchnorrAndPadTransfer(proofs[peer])
+		if err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 2 with party %d", peer)
+		}
+		out[peer] = choices
+	}
+	return out, nil
+}
+
+// Round3PadTransfer runs the 3rd round of seed OT as the sender of every peer.
+func (s *SeedOt) Round3PadTransfer(choices map[uint32][]simplest.ReceiversMaskedChoices) (map[uint32][]simplest.OtChallenge, error) {
+	if err := CheckInputs(s.peers, choices); err != nil {
+		return nil, err
+	}
+	out := make(map[uint32][]simplest.OtChallenge, len(s.senders))
+	for peer, sender := range s.senders {
+		challenge, err := sender.Round3PadTransfer(choices[peer])
+		if err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 3 with party %d", peer)
+		}
+		out[peer] = challenge
+	}
+	return out, nil
+}
+
+// Round4RespondToChallenge runs the 4th round of seed OT as the receiver of every peer.
+func (s *SeedOt) Round4RespondToChallenge(challenges map[uint32][]simplest.OtChallenge) (map[uint32][]simplest.OtChallengeResponse, error) {
+	if err := CheckInputs(s.peers, challenges); err != nil {
+		return nil, err
+	}
+	out := make(map[uint32][]simplest.OtChallengeResponse, len(s.receivers))
+	for peer, receiver := range s.receivers {
+		responses, err := receiver.Round4RespondToChallenge(challenges[peer])
+		if err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 4 with party %d", peer)
+		}
+		out[peer] = responses
+	}
+	return out, nil
+}
+
+// Round5Verify runs the 5th round of seed OT as the sender of every peer.
+func (s *SeedOt) Round5Verify(responses map[uint32][]simplest.OtChallengeResponse) (map[uint32]*simplest.Round5Output, error) {
+	if err := CheckInputs(s.peers, responses); err != nil {
+		return nil, err
+	}
+	out := make(map[uint32]*simplest.Round5Output, len(s.senders))
+	for peer, sender := range s.senders {
+		openings, err := sender.Round5Verify(responses[peer])
+		if err != nil {
+			return nil, errors.Wrapf(err, "seed OT round 5 with party %d", peer)
+		}
+		out[peer] = openings
+	}
+	return out, nil
+}
+
+// Round6Verify runs the 6th and last round of seed OT as the receiver of every peer.
+func (s *SeedOt) Round6Verify(openings map[uint32]*simplest.Round5Output) error {
+	if err := CheckInputs(s.peers, openings); err != nil {
+		return err
+	}
+	for peer, receiver := range s.receivers {
+		if err := receiver.Round6Verify(openings[peer]); err != nil {
+			// The peer is the OT sender, so a failed check is its.
+			return protocol.Reattribute(errors.Wrapf(err, "seed OT round 6 with party %d", peer), partyName(peer))
+		}
+	}
+	return nil
+}
+
+// SenderOutputs returns the outputs of the runs in which the party was the OT sender, by peer.
+func (s *SeedOt) SenderOutputs() map[uint32]*simplest.SenderOutput {
+	out := make(map[uint32]*simplest.SenderOutput, len(s.senders))
+	for peer, sender := range s.senders {
+		out[peer] = sender.Output
+	}
+	return out
+}
+
+// ReceiverOutputs returns the outputs of the runs in which the party was the OT receiver, by peer.
+func (s *SeedOt) ReceiverOutputs() map[uint32]*simplest.ReceiverOutput {
+	out := make(map[uint32]*simplest.ReceiverOutput, len(s.receivers))
+	for peer, receiver := range s.receivers {
+		out[peer] = receiver.Output
+	}
+	return out
+}
+
+// CheckInputs checks there is exactly one message from every peer in the inputs of a round.
+func CheckInputs[I any](peers []uint32, inputs map[uint32]I) error {
+	if len(inputs) != len(peers) {
+		return fmt.Errorf("expected messages from %d parties, got %d", len(peers), len(inputs))
+	}
+	for _, peer := range peers {
+		if _, ok := inputs[peer]; !ok {
+			return fmt.Errorf("missing message from party %d", peer)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package dklstest holds the helpers that the tests of the dklsv2 protocols share to run the rounds of many parties
+// side by side.
+package dklstest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+)
+
+// Route turns the messages each party addressed to its peers into the messages each party received, by sender.
+func Route[T any](outputs map[uint32]map[uint32]T) map[uint32]map[uint32]T {
+	inputs := make(map[uint32]map[uint32]T, len(outputs))
+	for from, messages := range outputs {
+		for to, message := range messages {
+			if inputs[to] == nil {
+				inputs[to] = make(map[uint32]T)
+			}
+			inputs[to][from] = message
+		}
+	}
+	return inputs
+}
+
+// Broadcast addresses the message of each party to all the other parties.
+func Broadcast[T any](outputs map[uint32]T) map[uint32]map[uint32]T {
+	addressed := make(map[uint32]map[uint32]T, len(outputs))
+	for from, message := range outputs {
+		addressed[from] = make(map[uint32]T, len(outputs)-1)
+		for to := range outputs {
+			if to != from {
+				addressed[from][to] = message
+			}
+		}
+	}
+	return Route(addressed)
+}
+
+// RunRound runs one round of every party on the messages it received.
+func RunRound[P, I, O any](t *testing.T, parties map[uint32]P, inputs map[uint32]I, round func(P, I) (O, error)) map[uint32]O {
+	t.Helper()
+	outputs := make(map[uint32]O, len(parties))
+	for id, party := range parties {
+		out, err := round(party, inputs[id])
+		require.NoError(t, err, "party %d", id)
+		outputs[id] = out
+	}
+	return outputs
+}
+
+// NewDkgParticipants creates the parties of a t-of-n DKG.
+func NewDkgParticipants(t *testing.T, curve *curves.Curve, threshold, limit uint32) map[uint32]*dkg.Participant {
+	t.Helper()
+	parties := make(map[uint32]*dkg.Participant, limit)
+	for id := uint32(1); id <= limit; id++ {
+		party, err := dkg.NewParticipant(curve, id, threshold, limit)
+		require.NoError(t, err)
+		parties[id] = party
+	}
+	return parties
+}
+
+// SetIdentityKeys gives every party a fresh identity key and the identity keys of its peers, and returns the private
+// keys by party.
+func SetIdentityKeys[P interface {
+	SetIdentityKeys(ed25519.PrivateKey, map[uint32]ed25519.PublicKey)
+}](t *testing.T, parties map[uint32]P) map[uint32]ed25519.PrivateKey {
+	t.Helper()
+	keys := make(map[uint32]ed25519.PrivateKey, len(parties))
+	publicKeys := make(map[uint32]ed25519.PublicKey, len(parties))
+	for id := range parties {
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		keys[id], publicKeys[id] = private, public
+	}
+	for id, party := range parties {
+		peerKeys := make(map[uint32]ed25519.PublicKey, len(parties)-1)
+		for peer, key := range publicKeys {
+			if peer != id {
+				peerKeys[peer] = key
+			}
+		}
+		party.SetIdentityKeys(keys[id], peerKeys)
+	}
+	return keys
+}
+
+// DealShares runs the first two rounds of DKG, and returns the round 2 messages each party received.
+func DealShares(t *testing.T, parties map[uint32]*dkg.Participant) map[uint32]map[uint32]*dkg.Round2Output {
+	t.Helper()
+	seedCommitments := RunRound(t, parties, map[uint32]struct{}{}, func(p *dkg.Participant, _ struct{}) ([simplest.DigestSize]byte, error) {
+		return p.Round1CommitToSeed()
+	})
+	return Route(RunRound(t, parties, Broadcast(seedCommitments), (*dkg.Participant).Round2DealShares))
+}
+
+// RunDkg runs a t-of-n DKG with identity keys to completion and returns the outputs of the parties.
+func RunDkg(t *testing.T, curve *curves.Curve, threshold, limit uint32) map[uint32]*dkg.Output {
+	t.Helper()
+	parties := NewDkgParticipants(t, curve, threshold, limit)
+	SetIdentityKeys(t, parties)
+	FinishDkg(t, parties, DealShares(t, parties))
+	outputs := make(map[uint32]*dkg.Output, limit)
+	for id, party := range parties {
+		outputs[id] = party.Output()
+	}
+	return outputs
+}
+
+// FinishDkg runs DKG from round 3 to completion on the round 2 messages each party received.
+func FinishDkg(t *testing.T, parties map[uint32]*dkg.Participant, round2 map[uint32]map[uint32]*dkg.Round2Output) {
+	t.Helper()
+	round3 := RunRound(t, parties, round2, (*dkg.Participant).Round3VerifySharesAndStartOt)
+	round4 := RunRound(t, parties, Route(round3), (*dkg.Participant).Round4DkgRound2Ot)
+	round5 := RunRound(t, parties, Route(round4), (*dkg.Participant).Round5DkgRound3Ot)
+	round6 := RunRound(t, parties, Route(round5), (*dkg.Participant).Round6DkgRound4Ot)
+	round7 := RunRound(t, parties, Route(round6), (*dkg.Participant).Round7DkgRound5Ot)
+	RunRound(t, parties, Route(round7), func(p *dkg.Participant, input map[uint32]*simplest.Round5Output) (struct{}, error) {
+		return struct{}{}, p.Round8DkgRound6Ot(input)
+	})
+}
@@ -0,0 +1,75 @@
+// Package dklsv2 provides a wrapper around the t-of-n [DKLs23](https://eprint.iacr.org/2023/765.pdf) dkg, sign and
+// refresh, and provides serialization and versioning for the serialized data, like dklsv1 does for the two-party
+// protocol.
+//
+// Every round of a party takes the messages of all its peers and returns one message per peer. In a protocol.Message
+// the payloads are keyed by party identifier: an output message holds the payload addressed to each peer, and an
+// input message holds the payload received from each peer. Route turns the outputs of a round into the inputs of the
+// next one.
+package dklsv2
+
+import (
+	"strconv"
+
+	"github.com/sonr-io/crypto/core/protocol"
+)
+
+// Basic protocol interface implementation that calls the next step func in a pre-defined list
+type protoStepper struct {
+	steps []func(input *protocol.Message) (*protocol.Message, error)
+	step  int
+}
+
+// Next runs the next step in the protocol and reports errors or increments the step index
+func (p *protoStepper) Next(input *protocol.Message) (*protocol.Message, error) {
+	if p.complete() {
+		return nil, protocol.ErrProtocolFinished
+	}
+
+	// Run the current protocol step and report any errors
+	output, err := p.steps[p.step](input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Increment the step index and report success
+	p.step++
+	return output, nil
+}
+
+// Reports true if the step index exceeds the number of steps
+func (p *protoStepper) complete() bool { return p.step >= len(p.steps) }
+
+// Route turns the messages the parties output in a round, by party identifier, into the message each party takes as
+// input in the next round, whose payloads are keyed by sender. Parties with a nil output are skipped.
+func Route(outputs map[uint32]*protocol.Message) map[uint32]*protocol.Message {
+	inputs := make(map[uint32]*protocol.Message, len(outputs))
+	for from, output := range outputs {
+		if output == nil {
+			continue
+		}
+		for to, payload := range output.Payloads {
+			id, err := strconv.ParseUint(to, 10, 32)
+			if err != nil {
+				continue
+			}
+			input, ok := inputs[uint32(id)]
+			if !ok {
+				input = &protocol.Message{
+					Protocol: output.Protocol,
+					Version:  output.Version,
+					Payloads: make(map[string][]byte, len(output.Payloads)),
+					Metadata: output.Metadata,
+				}
+				inputs[uint32(id)] = input
+			}
+			input.Payloads[partyKey(from)] = payload
+		}
+	}
+	return inputs
+}
+
+// partyKey is the payload key of a party identifier.
+func partyKey(id uint32) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
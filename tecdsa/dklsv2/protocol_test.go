@@ -0,0 +1,150 @@
+package dklsv2
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+)
+
+// runIteratedProtocol cranks every party forward one round at a time, routing the outputs of each round to the
+// parties they are addressed to, until all of them finished.
+func runIteratedProtocol(parties map[uint32]protocol.Iterator) error {
+	inputs := make(map[uint32]*protocol.Message, len(parties))
+	for {
+		outputs := make(map[uint32]*protocol.Message, len(parties))
+		finished := 0
+		for id, party := range parties {
+			output, err := party.Next(inputs[id])
+			if err == protocol.ErrProtocolFinished {
+				finished++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("party %d: %w", id, err)
+			}
+			outputs[id] = output
+		}
+		if finished == len(parties) {
+			return nil
+		}
+		inputs = Route(outputs)
+	}
+}
+
+func runDkgProto(t *testing.T, curve *curves.Curve, threshold, limit uint32) map[uint32]*protocol.Message {
+	t.Helper()
+	parties := make(map[uint32]*Dkg, limit)
+	iterators := make(map[uint32]protocol.Iterator, limit)
+	for id := uint32(1); id <= limit; id++ {
+		party, err := NewDkg(curve, id, threshold, limit, protocol.Version1)
+		require.NoError(t, err)
+		parties[id], iterators[id] = party, party
+	}
+	require.NoError(t, runIteratedProtocol(iterators))
+	results := make(map[uint32]*protocol.Message, limit)
+	for id, party := range parties {
+		result, err := party.Result(protocol.Version1)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		results[id] = result
+	}
+	return results
+}
+
+func runSignProto(t *testing.T, curve *curves.Curve, message []byte, results map[uint32]*protocol.Message, signers []uint32) *curves.EcdsaSignature {
+	t.Helper()
+	parties := make(map[uint32]*Sign, len(signers))
+	iterators := make(map[uint32]protocol.Iterator, len(signers))
+	for _, id := range signers {
+		party, err := NewSign(curve, sha3.New256(), message, results[id], signers, protocol.Version1)
+		require.NoError(t, err)
+		parties[id], iterators[id] = party, party
+	}
+	require.NoError(t, runIteratedProtocol(iterators))
+	var signature *curves.EcdsaSignature
+	for _, party := range parties {
+		result, err := party.Result(protocol.Version1)
+		require.NoError(t, err)
+		decoded, err := DecodeSignature(result)
+		require.NoError(t, err)
+		if signature != nil {
+			require.Equal(t, signature, decoded)
+		}
+		signature = decoded
+	}
+	return signature
+}
+
+func verifySignature(t *testing.T, curve *curves.Curve, publicKey curves.Point, message []byte, signature *curves.EcdsaSignature) {
+	t.Helper()
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	uncompressed := publicKey.ToAffineUncompressed()
+	pk := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	digest := sha3.Sum256(message)
+	require.True(t, ecdsa.Verify(pk, digest[:], signature.R, signature.S))
+}
+
+func TestDkgSignRefreshProto(t *testing.T) {
+	t.Parallel()
+	for _, curve := range []*curves.Curve{curves.K256(), curves.P256()} {
+		boundCurve := curve
+		t.Run(boundCurve.Name, func(t *testing.T) {
+			t.Parallel()
+			results := runDkgProto(t, boundCurve, 2, 3)
+			output, err := DecodeDkgResult(results[1])
+			require.NoError(t, err)
+			publicKey := output.PublicKey
+
+			message := []byte("DKLs23 t-of-n signing")
+			signature := runSignProto(t, boundCurve, message, results, []uint32{1, 3})
+			verifySignature(t, boundCurve, publicKey, message, signature)
+
+			// every party of the DKG refreshes its share
+			parties := make(map[uint32]*Refresh, len(results))
+			iterators := make(map[uint32]protocol.Iterator, len(results))
+			for id, result := range results {
+				party, err := NewRefresh(boundCurve, result, protocol.Version1)
+				require.NoError(t, err)
+				parties[id], iterators[id] = party, party
+			}
+			require.NoError(t, runIteratedProtocol(iterators))
+			refreshed := make(map[uint32]*protocol.Message, len(results))
+			for id, party := range parties {
+				result, err := party.Result(protocol.Version1)
+				require.NoError(t, err)
+				refreshedOutput, err := DecodeDkgResult(result)
+				require.NoError(t, err)
+				require.True(t, publicKey.Equal(refreshedOutput.PublicKey))
+				before, err := DecodeDkgResult(results[id])
+				require.NoError(t, err)
+				require.NotEqual(t, before.SecretKeyShare.Bytes(), refreshedOutput.SecretKeyShare.Bytes())
+				refreshed[id] = result
+			}
+
+			// a different subset signs with the refreshed shares
+			signature = runSignProto(t, boundCurve, message, refreshed, []uint32{2, 3})
+			verifySignature(t, boundCurve, publicKey, message, signature)
+		})
+	}
+}
+
+func TestSignProtoRejectsForeignDkgResult(t *testing.T) {
+	t.Parallel()
+	_, err := NewSign(curves.K256(), sha3.New256(), []byte("message"), &protocol.Message{Version: 2}, []uint32{1, 2}, protocol.Version1)
+	require.Error(t, err)
+	_, err = EncodeDkgOutput(&dkg.Output{}, 2)
+	require.Error(t, err)
+}
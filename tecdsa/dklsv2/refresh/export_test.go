@@ -0,0 +1,6 @@
+package refresh
+
+import "github.com/sonr-io/crypto/ot/base/simplest"
+
+// SessionId returns the session id of the run, for the tests of package refresh_test.
+func (p *Participant) SessionId() [simplest.DigestSize]byte { return p.sessionId }
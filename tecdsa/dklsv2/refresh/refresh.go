@@ -0,0 +1,252 @@
+// Package refresh implements the proactive refresh of the t-of-n threshold ECDSA keys produced by dklsv2/dkg.
+// The refresh protocol is defined as follows:
+//  1. Seeds: every party broadcasts 32 random bytes, from which all derive the session id.
+//  2. Zero sharing: every party deals a Feldman sharing of zero, a random polynomial of degree t - 1 whose constant
+//     term is zero, with the commitments to its non-constant coefficients.
+//  3. Update: every party verifies the sub-shares it was dealt against the commitments, completed with the identity
+//     as the commitment to the zero constant term, and adds the sub-shares to its key share. The joint key is unchanged, the public shares move with the key shares.
+//  4. Echo: every party echoes digests of the commitments it received, and aborts if a party sent different ones to
+//     different peers, see dkg.CheckEchoes.
+//  5. OT: every pair of parties redoes the seed OTs, as it is done in the DKG.
+//
+// All n parties must take part. Key shares from before the refresh cannot be combined with shares from after it.
+package refresh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/sharing"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+)
+
+// Participant is the state of one party during one execution of refresh.
+type Participant struct {
+	id    uint32
+	peers []uint32
+
+	threshold uint32
+
+	// polynomial is the party's sharing of zero.
+	polynomial *sharing.Polynomial
+
+	// commitments are the Feldman commitments to polynomial.
+	commitments []curves.Point
+
+	seed      [simplest.DigestSize]byte
+	sessionId [simplest.DigestSize]byte
+
+	// echo holds the digests of the round 2 broadcasts of all the parties.
+	echo dkg.Echo
+
+	secretKeyShare curves.Scalar
+	publicKey      curves.Point
+	publicShares   map[uint32]curves.Point
+
+	// chainCode is the BIP32 chain code from DKG. Refresh does not change it.
+	chainCode []byte
+
+	seedOt *dkg.SeedOt
+
+	// identityKey is the long-term key the party attests its messages with and peerKeys are the identity keys of the
+	// peers, by identifier. Both are nil to run without attestation.
+	identityKey ed25519.PrivateKey
+	peerKeys    map[uint32]ed25519.PublicKey
+
+	curve *curves.Curve
+
+	transcript *merlin.Transcript
+}
+
+// Round2Output is the output of the 2nd round of refresh, sent by a party to one of its peers.
+type Round2Output struct {
+	// Commitments are the Feldman commitments to the non-constant coefficients of the sender's sharing of zero. The
+	// commitment to the constant term is always the identity, so it is not sent. They are the same for all peers.
+	Commitments []curves.Point
+
+	// Share is the sub-share of zero dealt to the recipient.
+	Share *sharing.ShamirShare
+
+	// Attestation is the sender's attestation of Commitments and Share, nil if it runs without an identity key.
+	Attestation *protocol.Attestation
+}
+
+// NewParticipant creates a party that can participate in t-of-n key refresh.
+func NewParticipant(curve *curves.Curve, dkgOutput *dkg.Output) *Participant {
+	publicShares := make(map[uint32]curves.Point, len(dkgOutput.PublicShares))
+	for id, share := range dkgOutput.PublicShares {
+		publicShares[id] = share
+	}
+	return &Participant{
+		id:             dkgOutput.ID,
+		peers:          dkgOutput.Peers(),
+		threshold:      dkgOutput.Threshold,
+		secretKeyShare: dkgOutput.SecretKeyShare,
+		publicKey:      dkgOutput.PublicKey,
+		publicShares:   publicShares,
+		chainCode:      dkgOutput.ChainCode,
+		curve:          curve,
+		transcript:     merlin.NewTranscript("DKLs23_Refresh"),
+	}
+}
+
+// SetIdentityKeys makes the party attest the sub-shares it deals and its openings of the seed OTs with its long-term
+// identity key, and require the ones of every peer with a key in peerKeys to be attested with it. See
+// dkg.Participant.SetIdentityKeys.
+func (p *Participant) SetIdentityKeys(key ed25519.PrivateKey, peerKeys map[uint32]ed25519.PublicKey) {
+	p.identityKey = key
+	p.peerKeys = peerKeys
+}
+
+// Round1RefreshGenerateSeed flips the 32 random bytes the party broadcasts.
+func (p *Participant) Round1RefreshGenerateSeed() ([simplest.DigestSize]byte, error) {
+	if _, err := rand.Read(p.seed[:]); err != nil {
+		return [simplest.DigestSize]byte{}, errors.Wrap(err, "generating random bytes in refresh round 1")
+	}
+	return p.seed, nil
+}
+
+// Round2RefreshDealZeroShares derives the session id and deals a Feldman sharing of zero. It returns the message for
+// each peer.
+func (p *Participant) Round2RefreshDealZeroShares(seeds map[uint32][simplest.DigestSize]byte) (map[uint32]*Round2Output, error) {
+	if err := dkg.CheckInputs(p.peers, seeds); err != nil {
+		return nil, errors.Wrap(err, "refresh round 2")
+	}
+	p.sessionId = dkg.AppendSeeds(p.transcript, p.id, p.seed, seeds)
+
+	// sharing.Feldman refuses to split zero, so the polynomial is built directly and committed to the same way.
+	p.polynomial = new(sharing.Polynomial).Init(p.curve.Scalar.Zero(), p.threshold, rand.Reader)
+	p.commitments = make([]curves.Point, p.threshold)
+	for i, coefficient := range p.polynomial.Coefficients {
+		p.commitments[i] = p.curve.ScalarBaseMult(coefficient)
+	}
+	out := make(map[uint32]*Round2Output, len(p.peers))
+	for _, peer := range p.peers {
+		share := &sharing.ShamirShare{
+			Id:    peer,
+			Value: p.polynomial.Evaluate(p.curve.Scalar.New(int(peer))).Bytes(),
+		}
+		out[peer] = &Round2Output{
+			Commitments: p.commitments[1:],
+			Share:       share,
+			Attestation: dkg.AttestShare(p.identityKey, p.sessionId, p.id, true, p.commitments[1:], share),
+		}
+	}
+	return out, nil
+}
+
+// Round3RefreshUpdateAndStartOt verifies the sub-shares the peers dealt, adds them to the party's key share and to
+// the public shares, and starts new seed OTs with every peer. The messages for the peers carry the echo of the round 2
+// broadcasts.
+func (p *Participant) Round3RefreshUpdateAndStartOt(inputs map[uint32]*Round2Output) (map[uint32]*dkg.Round3Output, error) {
+	if err := dkg.CheckInputs(p.peers, inputs); err != nil {
+		return nil, errors.Wrap(err, "refresh round 3")
+	}
+	p.secretKeyShare = p.secretKeyShare.Add(p.polynomial.Evaluate(p.curve.Scalar.New(int(p.id))))
+	allCommitments := [][]curves.Point{p.commitments}
+	p.echo = dkg.Echo{p.id: round2Digest(p.sessionId, p.id, p.commitments[1:])}
+	for _, peer := range p.peers {
+		input := inputs[peer]
+		if input == nil || input.Share == nil || len(input.Commitments) != int(p.threshold)-1 {
+			return nil, dkg.Blame(peer, "the well-formedness of its refresh round 2 message", nil, nil)
+		}
+		if input.Share.Id != p.id {
+			return nil, dkg.Blame(peer, fmt.Sprintf("the check that it dealt the sub-share of party %d", p.id), nil, nil)
+		}
+		evidence, err := dkg.CheckShareAttestation(p.peerKeys[peer], input.Attestation, p.sessionId, peer, true, input.Commitments, input.Share)
+		if err != nil {
+			return nil, err
+		}
+		commitments := append([]curves.Point{p.curve.NewIdentityPoint()}, input.Commitments...)
+		if err := (sharing.FeldmanVerifier{Commitments: commitments}).Verify(input.Share); err != nil {
+			return nil, dkg.Blame(peer, "the verification of the sub-share it dealt in refresh round 3", evidence, err)
+		}
+		share, err := p.curve.Scalar.SetBytes(input.Share.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting sub-share of party %d in refresh round 3", peer)
+		}
+		p.secretKeyShare = p.secretKeyShare.Add(share)
+		allCommitments = append(allCommitments, commitments)
+		p.echo[peer] = round2Digest(p.sessionId, peer, input.Commitments)
+	}
+	for id := range p.publicShares {
+		for _, commitments := range allCommitments {
+			p.publicShares[id] = p.publicShares[id].Add(dkg.EvaluateCommitments(commitments, id))
+		}
+	}
+	if !p.publicShares[p.id].Equal(p.curve.ScalarBaseMult(p.secretKeyShare)) {
+		return nil, errors.New("refreshed key share does not match its public share")
+	}
+
+	var err error
+	if p.seedOt, err = dkg.NewSeedOt(p.curve, p.id, p.peers, p.sessionId); err != nil {
+		return nil, err
+	}
+	p.seedOt.SetIdentityKeys(p.identityKey, p.peerKeys)
+	proofs, err := p.seedOt.Round1ComputeAndZkpToPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return dkg.WithEcho(p.echo, proofs), nil
+}
+
+// Round4RefreshRound2Ot checks the echoes of the peers, which aborts the run if a party sent different commitments to
+// different peers, then runs the 2nd round of the seed OTs.
+func (p *Participant) Round4RefreshRound2Ot(inputs map[uint32]*dkg.Round3Output) (map[uint32][]simplest.ReceiversMaskedChoices, error) {
+	proofs, err := dkg.CheckRound3(p.id, p.peers, p.echo, inputs, "refresh round 2 broadcast")
+	if err != nil {
+		return nil, errors.Wrap(err, "refresh round 4")
+	}
+	return p.seedOt.Round2VerifySchnorrAndPadTransfer(proofs)
+}
+
+// Round5RefreshRound3Ot is a thin wrapper around the 3rd round of the seed OTs.
+func (p *Participant) Round5RefreshRound3Ot(choices map[uint32][]simplest.ReceiversMaskedChoices) (map[uint32][]simplest.OtChallenge, error) {
+	return p.seedOt.Round3PadTransfer(choices)
+}
+
+// Round6RefreshRound4Ot is a thin wrapper around the 4th round of the seed OTs.
+func (p *Participant) Round6RefreshRound4Ot(challenges map[uint32][]simplest.OtChallenge) (map[uint32][]simplest.OtChallengeResponse, error) {
+	return p.seedOt.Round4RespondToChallenge(challenges)
+}
+
+// Round7RefreshRound5Ot is a thin wrapper around the 5th round of the seed OTs.
+func (p *Participant) Round7RefreshRound5Ot(responses map[uint32][]simplest.OtChallengeResponse) (map[uint32]*simplest.Round5Output, error) {
+	return p.seedOt.Round5Verify(responses)
+}
+
+// Round8RefreshRound6Ot is a thin wrapper around the 6th round of the seed OTs.
+func (p *Participant) Round8RefreshRound6Ot(openings map[uint32]*simplest.Round5Output) error {
+	return p.seedOt.Round6Verify(openings)
+}
+
+// Output returns the refreshed DKG output. Must be called after step 8. Calling it before that step has undefined
+// behaviour.
+func (p *Participant) Output() *dkg.Output {
+	return &dkg.Output{
+		ID:              p.id,
+		Threshold:       p.threshold,
+		PublicKey:       p.publicKey,
+		SecretKeyShare:  p.secretKeyShare,
+		PublicShares:    p.publicShares,
+		SeedOtSenders:   p.seedOt.SenderOutputs(),
+		SeedOtReceivers: p.seedOt.ReceiverOutputs(),
+		ChainCode:       p.chainCode,
+	}
+}
+
+// round2Digest is the digest of the commitments in the refresh round 2 message of party from.
+func round2Digest(sessionId [simplest.DigestSize]byte, from uint32, commitments []curves.Point) [simplest.DigestSize]byte {
+	parts := make([][]byte, len(commitments))
+	for i, commitment := range commitments {
+		parts[i] = commitment.ToAffineCompressed()
+	}
+	return dkg.BroadcastDigest(sessionId, "refresh round 2", from, parts...)
+}
@@ -0,0 +1,166 @@
+package refresh_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/sharing"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/internal/dklstest"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/refresh"
+)
+
+func newParties(curve *curves.Curve, outputs map[uint32]*dkg.Output) map[uint32]*refresh.Participant {
+	parties := make(map[uint32]*refresh.Participant, len(outputs))
+	for id, output := range outputs {
+		parties[id] = refresh.NewParticipant(curve, output)
+	}
+	return parties
+}
+
+func dealZeroShares(t *testing.T, parties map[uint32]*refresh.Participant) map[uint32]map[uint32]*refresh.Round2Output {
+	t.Helper()
+	seeds := dklstest.RunRound(t, parties, map[uint32]struct{}{}, func(p *refresh.Participant, _ struct{}) ([simplest.DigestSize]byte, error) {
+		return p.Round1RefreshGenerateSeed()
+	})
+	return dklstest.Route(dklstest.RunRound(t, parties, dklstest.Broadcast(seeds), (*refresh.Participant).Round2RefreshDealZeroShares))
+}
+
+func TestRefresh(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		curve            *curves.Curve
+		threshold, limit uint32
+	}{
+		{curves.K256(), 2, 3},
+		{curves.P256(), 3, 4},
+	}
+	for _, test := range tests {
+		boundCurve, threshold, limit := test.curve, test.threshold, test.limit
+		t.Run(fmt.Sprintf("%d of %d refresh for curve %s", threshold, limit, boundCurve.Name), func(tt *testing.T) {
+			tt.Parallel()
+			outputs := dklstest.RunDkg(tt, boundCurve, threshold, limit)
+			parties := newParties(boundCurve, outputs)
+			round3 := dklstest.RunRound(tt, parties, dealZeroShares(tt, parties), (*refresh.Participant).Round3RefreshUpdateAndStartOt)
+			round4 := dklstest.RunRound(tt, parties, dklstest.Route(round3), (*refresh.Participant).Round4RefreshRound2Ot)
+			round5 := dklstest.RunRound(tt, parties, dklstest.Route(round4), (*refresh.Participant).Round5RefreshRound3Ot)
+			round6 := dklstest.RunRound(tt, parties, dklstest.Route(round5), (*refresh.Participant).Round6RefreshRound4Ot)
+			round7 := dklstest.RunRound(tt, parties, dklstest.Route(round6), (*refresh.Participant).Round7RefreshRound5Ot)
+			dklstest.RunRound(tt, parties, dklstest.Route(round7), func(p *refresh.Participant, input map[uint32]*simplest.Round5Output) (struct{}, error) {
+				return struct{}{}, p.Round8RefreshRound6Ot(input)
+			})
+
+			refreshed := make(map[uint32]*dkg.Output, limit)
+			for id, party := range parties {
+				refreshed[id] = party.Output()
+				require.True(tt, outputs[id].PublicKey.Equal(refreshed[id].PublicKey))
+				require.Equal(tt, outputs[id].ChainCode, refreshed[id].ChainCode)
+				require.NotEqual(tt, outputs[id].SecretKeyShare.Bytes(), refreshed[id].SecretKeyShare.Bytes())
+				require.True(tt, refreshed[id].PublicShares[id].Equal(boundCurve.ScalarBaseMult(refreshed[id].SecretKeyShare)))
+			}
+			for _, output := range refreshed {
+				for j, publicShare := range refreshed[1].PublicShares {
+					require.True(tt, publicShare.Equal(output.PublicShares[j]))
+				}
+			}
+
+			// the refreshed shares still recombine to the secret key
+			feldman, err := sharing.NewFeldman(threshold, limit, boundCurve)
+			require.NoError(tt, err)
+			shares := make([]*sharing.ShamirShare, 0, threshold)
+			for id := limit - threshold + 1; id <= limit; id++ {
+				shares = append(shares, &sharing.ShamirShare{Id: id, Value: refreshed[id].SecretKeyShare.Bytes()})
+			}
+			secret, err := feldman.Combine(shares...)
+			require.NoError(tt, err)
+			require.True(tt, outputs[1].PublicKey.Equal(boundCurve.ScalarBaseMult(secret)))
+
+			// every pair ran a new, correct OT in both directions
+			for i, output := range refreshed {
+				for _, j := range output.Peers() {
+					sender := output.SeedOtSenders[j]
+					receiver := refreshed[j].SeedOtReceivers[i]
+					require.NotEqual(tt, outputs[i].SeedOtSenders[j].OneTimePadEncryptionKeys, sender.OneTimePadEncryptionKeys)
+					for k := 0; k < kos.Kappa; k++ {
+						require.Equal(tt, sender.OneTimePadEncryptionKeys[k][receiver.RandomChoiceBits[k]], receiver.OneTimePadDecryptionKey[k])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRefreshRejectsBadSubShare(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	outputs := dklstest.RunDkg(t, curve, 2, 3)
+	parties := newParties(curve, outputs)
+	keys := dklstest.SetIdentityKeys(t, parties)
+	inputs := dealZeroShares(t, parties)
+	sent := inputs[1][2]
+
+	// party 2 deals and attests to party 1 a sub-share that is not on its committed polynomial
+	tampered := *sent.Share
+	tampered.Value = curve.Scalar.Random(rand.Reader).Bytes()
+	attestation := dkg.AttestShare(keys[2], parties[2].SessionId(), 2, true, sent.Commitments, &tampered)
+	inputs[1][2] = &refresh.Round2Output{Commitments: sent.Commitments, Share: &tampered, Attestation: attestation}
+	_, err := parties[1].Round3RefreshUpdateAndStartOt(inputs[1])
+	var abort *protocol.AbortError
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "2", abort.Party)
+	require.NoError(t, abort.Evidence.Verify())
+
+	// the evidence does not hold against the sub-share of an honest dealer
+	evidence, ok := abort.Evidence.(*dkg.ShareEvidence)
+	require.True(t, ok)
+	honest := &dkg.ShareEvidence{
+		SessionId:    evidence.SessionId,
+		Dealer:       3,
+		Commitments:  inputs[1][3].Commitments,
+		ZeroConstant: true,
+		Share:        inputs[1][3].Share,
+		Attestation:  inputs[1][3].Attestation,
+	}
+	require.Error(t, honest.Verify())
+
+	// a sub-share attested as a DKG share is not accepted
+	inputs[1][2] = &refresh.Round2Output{
+		Commitments: sent.Commitments,
+		Share:       &tampered,
+		Attestation: dkg.AttestShare(keys[2], parties[2].SessionId(), 2, false, sent.Commitments, &tampered),
+	}
+	_, err = parties[1].Round3RefreshUpdateAndStartOt(inputs[1])
+	require.ErrorAs(t, err, &abort)
+	require.Equal(t, "2", abort.Party)
+	require.Nil(t, abort.Evidence)
+}
+
+func TestRefreshCatchesEquivocation(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	outputs := dklstest.RunDkg(t, curve, 2, 3)
+	parties := newParties(curve, outputs)
+	inputs := dealZeroShares(t, parties)
+
+	// party 2 deals party 3 a consistent sub-share of another sharing of zero, so party 3 alone cannot tell
+	polynomial := new(sharing.Polynomial).Init(curve.Scalar.Zero(), 2, rand.Reader)
+	inputs[3][2] = &refresh.Round2Output{
+		Commitments: []curves.Point{curve.ScalarBaseMult(polynomial.Coefficients[1])},
+		Share:       &sharing.ShamirShare{Id: 3, Value: polynomial.Evaluate(curve.Scalar.New(3)).Bytes()},
+	}
+	round3 := dklstest.Route(dklstest.RunRound(t, parties, inputs, (*refresh.Participant).Round3RefreshUpdateAndStartOt))
+
+	var abort *protocol.AbortError
+	for _, id := range []uint32{1, 3} {
+		_, err := parties[id].Round4RefreshRound2Ot(round3[id])
+		require.ErrorAs(t, err, &abort, "party %d", id)
+		require.Equal(t, "2", abort.Party, "party %d", id)
+	}
+}
@@ -0,0 +1,142 @@
+package dklsv2
+
+import (
+	"bytes"
+	"encoding/gob"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+)
+
+const payloadKey = "direct"
+
+func newProtocolMessage(name string, payloads map[string][]byte, round string, version uint) *protocol.Message {
+	return &protocol.Message{
+		Protocol: name,
+		Version:  version,
+		Payloads: payloads,
+		Metadata: map[string]string{"round": round},
+	}
+}
+
+func registerTypes() {
+	gob.Register(&curves.ScalarK256{})
+	gob.Register(&curves.PointK256{})
+	gob.Register(&curves.ScalarP256{})
+	gob.Register(&curves.PointP256{})
+}
+
+func encodePayload(payload any) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	enc := gob.NewEncoder(buf)
+	if err := enc.Encode(payload); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodePeerPayloads serializes the payload addressed to each peer of a round output.
+func encodePeerPayloads[T any](name string, payloads map[uint32]T, round string, version uint) (*protocol.Message, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	registerTypes()
+	encoded := make(map[string][]byte, len(payloads))
+	for peer, payload := range payloads {
+		bz, err := encodePayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		encoded[partyKey(peer)] = bz
+	}
+	return newProtocolMessage(name, encoded, round, version), nil
+}
+
+// encodeBroadcast serializes a round output that is the same for every peer.
+func encodeBroadcast[T any](name string, payload T, peers []uint32, round string, version uint) (*protocol.Message, error) {
+	payloads := make(map[uint32]T, len(peers))
+	for _, peer := range peers {
+		payloads[peer] = payload
+	}
+	return encodePeerPayloads(name, payloads, round, version)
+}
+
+// decodePeerPayloads deserializes the payload received from each peer of a round input.
+func decodePeerPayloads[T any](m *protocol.Message) (map[uint32]T, error) {
+	if m == nil {
+		return nil, errors.New("missing round input")
+	}
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	registerTypes()
+	decoded := make(map[uint32]T, len(m.Payloads))
+	for key, payload := range m.Payloads {
+		peer, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "payload key %q is not a party identifier", key)
+		}
+		var value T
+		dec := gob.NewDecoder(bytes.NewBuffer(payload))
+		if err := dec.Decode(&value); err != nil {
+			return nil, errors.Wrapf(err, "decoding payload of party %d", peer)
+		}
+		decoded[uint32(peer)] = value
+	}
+	return decoded, nil
+}
+
+// EncodeDkgOutput serializes the DKG output of a party based on the protocol version.
+func EncodeDkgOutput(result *dkg.Output, version uint) (*protocol.Message, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	registerTypes()
+	bz, err := encodePayload(result)
+	if err != nil {
+		return nil, err
+	}
+	return newProtocolMessage(protocol.Dkls23Dkg, map[string][]byte{payloadKey: bz}, "output", version), nil
+}
+
+// DecodeDkgResult deserializes the DKG output of a party.
+func DecodeDkgResult(m *protocol.Message) (*dkg.Output, error) {
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	registerTypes()
+	dec := gob.NewDecoder(bytes.NewBuffer(m.Payloads[payloadKey]))
+	decoded := new(dkg.Output)
+	if err := dec.Decode(decoded); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return decoded, nil
+}
+
+func encodeSignature(signature *curves.EcdsaSignature, version uint) (*protocol.Message, error) {
+	if version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	bz, err := encodePayload(signature)
+	if err != nil {
+		return nil, err
+	}
+	return newProtocolMessage(protocol.Dkls23Sign, map[string][]byte{payloadKey: bz}, "signature", version), nil
+}
+
+// DecodeSignature deserializes the signature.
+func DecodeSignature(m *protocol.Message) (*curves.EcdsaSignature, error) {
+	if m.Version != protocol.Version1 {
+		return nil, errors.New("only version 1 is supported")
+	}
+	dec := gob.NewDecoder(bytes.NewBuffer(m.Payloads[payloadKey]))
+	decoded := &curves.EcdsaSignature{}
+	if err := dec.Decode(decoded); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return decoded, nil
+}
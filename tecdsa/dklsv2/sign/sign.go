@@ -0,0 +1,383 @@
+// Package sign implements the t-of-n threshold signature protocol of [DKLs23](https://eprint.iacr.org/2023/765.pdf),
+// "Protocol 3.6". Any t parties of a dklsv2 DKG can sign. Each signer i holds an additive share sk_i = λ_i . x_i of
+// the joint key, and samples an instance key share r_i and an inversion mask φ_i. Every ordered pair of signers runs
+// two multiplications of dklsv1 (protocol 5 of DKLs18) to share r_i . φ_j and sk_i . φ_j, so that the signers hold
+// additive shares u_i of r . φ and v_i of sk . φ. They then reveal u_i and w_i = H(m) . φ_i + r_x . v_i, and
+// s = Σ w_i / Σ u_i = (H(m) + r_x . sk) / r.
+//
+// A signer's instance key R_i is committed to before any multiplication, and the consistency checks of step 3 tie the
+// inputs of each multiplication to R_i and to the signer's public key share. The signers echo digests of the
+// commitments they received, and check the echoes before revealing u_i and w_i, so that a signer that commits to
+// different instance keys towards different peers is caught.
+package sign
+
+import (
+	"crypto/rand"
+	"fmt"
+	"hash"
+	"math/big"
+	"sort"
+
+	"github.com/gtank/merlin"
+	"github.com/pkg/errors"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/ot/extension/kos"
+	"github.com/sonr-io/crypto/sharing"
+	multiply "github.com/sonr-io/crypto/tecdsa/dklsv1/sign"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+// multiplicationCount is the number of multiplications per ordered pair of signers: r_i . φ_j and sk_i . φ_j.
+const multiplicationCount = 2
+
+// Participant is the state of one signer during one execution of the signing protocol.
+// At the end of the joint computation, every signer obtains the signature.
+type Participant struct {
+	// Signature is the resulting digital signature and is the output of this protocol.
+	Signature *curves.EcdsaSignature
+
+	id    uint32
+	peers []uint32
+
+	hash hash.Hash
+
+	// secretKeyShare is the additive share λ_i . x_i of the joint secret key.
+	secretKeyShare curves.Scalar
+	publicKey      curves.Point
+
+	// publicKeyShares are the points λ_j . x_j . G of the additive key shares of the peers.
+	publicKeyShares map[uint32]curves.Point
+
+	seedOtSenders   map[uint32]*simplest.SenderOutput
+	seedOtReceivers map[uint32]*simplest.ReceiverOutput
+
+	seed      [simplest.DigestSize]byte
+	sessionId [simplest.DigestSize]byte
+
+	// r is the instance key share and phi the inversion mask of the signer.
+	r   curves.Scalar
+	phi curves.Scalar
+
+	// rProof is the committed schnorr proof of R_i = r_i . G, and rCommitment the commitment to it.
+	rProof      *schnorr.Proof
+	rCommitment schnorr.Commitment
+
+	// echo holds the digests of the commitments to the instance key shares of all the signers.
+	echo dkg.Echo
+
+	peerCommitments   map[uint32]schnorr.Commitment
+	multiplySenders   map[uint32][multiplicationCount]*multiply.MultiplySender
+	multiplyReceivers map[uint32][multiplicationCount]*multiply.MultiplyReceiver
+
+	// u and w are the signer's shares of r . φ and (H(m) + r_x . sk) . φ.
+	u curves.Scalar
+	w curves.Scalar
+
+	bigR   curves.Point
+	digest []byte
+
+	curve *curves.Curve
+
+	transcript *merlin.Transcript
+}
+
+// Round2Output is the output of the 2nd round of the protocol, sent by a signer to one of its peers.
+type Round2Output struct {
+	// Commitment is the commitment to the schnorr proof of the sender's instance key share R_i.
+	// It is the same for all peers.
+	Commitment schnorr.Commitment
+
+	// KosRound1Outputs are the first messages of the multiplications in which the sender is the receiver, with its
+	// mask φ_i as input.
+	KosRound1Outputs [multiplicationCount]*kos.Round1Output
+}
+
+// Round3Output is the output of the 3rd round of the protocol, sent by a signer to one of its peers.
+type Round3Output struct {
+	// MultiplyRound2Outputs are the responses of the sender to the multiplications, with r_i and sk_i as inputs.
+	MultiplyRound2Outputs [multiplicationCount]*multiply.MultiplyRound2Output
+
+	// RSchnorrProof opens the commitment of round 2. Its statement is R_i = r_i . G.
+	RSchnorrProof *schnorr.Proof
+
+	// GammaU and GammaV are the sender's shares of the two products, times G, checked by the recipient.
+	GammaU curves.Point
+	GammaV curves.Point
+
+	// Echo is the echo of the commitments of round 2. It is the same for all peers.
+	Echo dkg.Echo
+}
+
+// Round4Output is the broadcast of the 4th round of the protocol.
+type Round4Output struct {
+	// U is the sender's additive share of r . φ.
+	U curves.Scalar
+
+	// W is the sender's additive share of (H(m) + r_x . sk) . φ.
+	W curves.Scalar
+}
+
+// NewParticipant creates a signer for the signers in signers, which must include the party and at least threshold
+// parties of the DKG.
+func NewParticipant(curve *curves.Curve, hash hash.Hash, dkgOutput *dkg.Output, signers []uint32) (*Participant, error) {
+	if len(signers) < int(dkgOutput.Threshold) {
+		return nil, fmt.Errorf("%d signers is below the threshold of %d", len(signers), dkgOutput.Threshold)
+	}
+	feldman, err := sharing.NewFeldman(dkgOutput.Threshold, uint32(len(dkgOutput.PublicShares)), curve)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating feldman sharing for signing")
+	}
+	identities := make(map[uint32]*sharing.ShamirShare, len(signers))
+	peers := make([]uint32, 0, len(signers)-1)
+	for _, signer := range signers {
+		if _, ok := dkgOutput.PublicShares[signer]; !ok {
+			return nil, fmt.Errorf("signer %d is not a party of the DKG", signer)
+		}
+		if _, ok := identities[signer]; ok {
+			return nil, fmt.Errorf("signer %d is listed twice", signer)
+		}
+		identities[signer] = &sharing.ShamirShare{Id: signer}
+		if signer != dkgOutput.ID {
+			peers = append(peers, signer)
+		}
+	}
+	if _, ok := identities[dkgOutput.ID]; !ok {
+		return nil, fmt.Errorf("party %d is not one of the signers", dkgOutput.ID)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+	lagrange, err := feldman.LagrangeCoeffs(identities)
+	if err != nil {
+		return nil, errors.Wrap(err, "computing lagrange coefficients of the signers")
+	}
+	publicKeyShares := make(map[uint32]curves.Point, len(peers))
+	for _, peer := range peers {
+		publicKeyShares[peer] = dkgOutput.PublicShares[peer].Mul(lagrange[peer])
+	}
+	return &Participant{
+		id:              dkgOutput.ID,
+		peers:           peers,
+		hash:            hash,
+		secretKeyShare:  dkgOutput.SecretKeyShare.Mul(lagrange[dkgOutput.ID]),
+		publicKey:       dkgOutput.PublicKey,
+		publicKeyShares: publicKeyShares,
+		seedOtSenders:   dkgOutput.SeedOtSenders,
+		seedOtReceivers: dkgOutput.SeedOtReceivers,
+		curve:           curve,
+		transcript:      merlin.NewTranscript("DKLs23_Sign"),
+	}, nil
+}
+
+// Round1GenerateRandomSeed flips the 32 random bytes the signer broadcasts. The seeds of all the signers make up the
+// session id of the run.
+func (p *Participant) Round1GenerateRandomSeed() ([simplest.DigestSize]byte, error) {
+	if _, err := rand.Read(p.seed[:]); err != nil {
+		return [simplest.DigestSize]byte{}, errors.Wrap(err, "generating random bytes in sign round 1")
+	}
+	return p.seed, nil
+}
+
+// Round2Initialize samples the instance key share and the mask, commits to R_i, and starts the multiplications in
+// which the signer is the receiver, one pair per peer. Protocol 3.6, step 1.
+func (p *Participant) Round2Initialize(seeds map[uint32][simplest.DigestSize]byte) (map[uint32]*Round2Output, error) {
+	if err := dkg.CheckInputs(p.peers, seeds); err != nil {
+		return nil, errors.Wrap(err, "sign round 2")
+	}
+	p.sessionId = dkg.AppendSeeds(p.transcript, p.id, p.seed, seeds)
+
+	p.r = p.curve.Scalar.Random(rand.Reader)
+	p.phi = p.curve.Scalar.Random(rand.Reader)
+	var err error
+	p.rProof, p.rCommitment, err = schnorr.NewProver(p.curve, nil, dkg.PartySessionId(p.sessionId, p.id)).ProveCommit(p.r)
+	if err != nil {
+		return nil, errors.Wrap(err, "committing to R in sign round 2")
+	}
+
+	p.multiplyReceivers = make(map[uint32][multiplicationCount]*multiply.MultiplyReceiver, len(p.peers))
+	out := make(map[uint32]*Round2Output, len(p.peers))
+	for _, peer := range p.peers {
+		seedOt, ok := p.seedOtSenders[peer]
+		if !ok {
+			return nil, fmt.Errorf("no seed OT with party %d", peer)
+		}
+		receivers := [multiplicationCount]*multiply.MultiplyReceiver{}
+		round2Output := &Round2Output{Commitment: p.rCommitment}
+		for k := range receivers {
+			label := fmt.Sprintf("multiply %d", k)
+			receivers[k], err = multiply.NewMultiplyReceiver(seedOt, p.curve, dkg.PairSessionId(p.sessionId, label, peer, p.id))
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating multiply receiver %d for party %d in sign round 2", k, peer)
+			}
+			round2Output.KosRound1Outputs[k], err = receivers[k].Round1Initialize(p.phi)
+			if err != nil {
+				return nil, errors.Wrapf(err, "multiply round 1 initialize %d with party %d in sign round 2", k, peer)
+			}
+		}
+		p.multiplyReceivers[peer] = receivers
+		out[peer] = round2Output
+	}
+	return out, nil
+}
+
+// Round3Multiply responds to the multiplications the peers started, with r_i and sk_i as inputs, opens the commitment
+// to R_i, and sends the shares of the products times G for the consistency checks, along with the echo of the
+// commitments. Protocol 3.6, step 2.
+func (p *Participant) Round3Multiply(inputs map[uint32]*Round2Output) (map[uint32]*Round3Output, error) {
+	if err := dkg.CheckInputs(p.peers, inputs); err != nil {
+		return nil, errors.Wrap(err, "sign round 3")
+	}
+	p.peerCommitments = make(map[uint32]schnorr.Commitment, len(p.peers))
+	p.echo = dkg.Echo{p.id: commitmentDigest(p.sessionId, p.id, p.rCommitment)}
+	for _, peer := range p.peers {
+		if inputs[peer] == nil {
+			return nil, dkg.Blame(peer, "the well-formedness of its sign round 2 message", nil, nil)
+		}
+		p.peerCommitments[peer] = inputs[peer].Commitment
+		p.echo[peer] = commitmentDigest(p.sessionId, peer, inputs[peer].Commitment)
+	}
+	p.multiplySenders = make(map[uint32][multiplicationCount]*multiply.MultiplySender, len(p.peers))
+	out := make(map[uint32]*Round3Output, len(p.peers))
+	for _, peer := range p.peers {
+		input := inputs[peer]
+		seedOt, ok := p.seedOtReceivers[peer]
+		if !ok {
+			return nil, fmt.Errorf("no seed OT with party %d", peer)
+		}
+		senders := [multiplicationCount]*multiply.MultiplySender{}
+		round3Output := &Round3Output{RSchnorrProof: p.rProof, Echo: p.echo}
+		alphas := [multiplicationCount]curves.Scalar{p.r, p.secretKeyShare}
+		var err error
+		for k := range senders {
+			label := fmt.Sprintf("multiply %d", k)
+			senders[k], err = multiply.NewMultiplySender(seedOt, p.curve, dkg.PairSessionId(p.sessionId, label, p.id, peer))
+			if err != nil {
+				return nil, errors.Wrapf(err, "creating multiply sender %d for party %d in sign round 3", k, peer)
+			}
+			if input.KosRound1Outputs[k] == nil {
+				return nil, dkg.Blame(peer, "the well-formedness of its sign round 2 message", nil, nil)
+			}
+			round3Output.MultiplyRound2Outputs[k], err = senders[k].Round2Multiply(alphas[k], input.KosRound1Outputs[k])
+			if err != nil {
+				return nil, errors.Wrapf(err, "multiply round 2 %d with party %d in sign round 3", k, peer)
+			}
+		}
+		round3Output.GammaU = p.curve.ScalarBaseMult(senders[0].OutputAdditiveShare())
+		round3Output.GammaV = p.curve.ScalarBaseMult(senders[1].OutputAdditiveShare())
+		p.multiplySenders[peer] = senders
+		out[peer] = round3Output
+	}
+	return out, nil
+}
+
+// Round4Combine checks the echoes of the peers, finishes the multiplications, verifies the openings of the peers' R_j
+// and the consistency of their multiplication inputs, and computes the signer's shares u_i and w_i, which it
+// broadcasts. Protocol 3.6, step 3.
+func (p *Participant) Round4Combine(message []byte, inputs map[uint32]*Round3Output) (*Round4Output, error) {
+	if err := dkg.CheckInputs(p.peers, inputs); err != nil {
+		return nil, errors.Wrap(err, "sign round 4")
+	}
+	echoes := make(map[uint32]dkg.Echo, len(inputs))
+	for _, peer := range p.peers {
+		if inputs[peer] == nil {
+			return nil, dkg.Blame(peer, "the well-formedness of its sign round 3 message", nil, nil)
+		}
+		echoes[peer] = inputs[peer].Echo
+	}
+	if err := dkg.CheckEchoes(p.id, p.peers, p.echo, echoes, "commitment to its instance key share"); err != nil {
+		return nil, err
+	}
+	p.bigR = p.rProof.Statement
+	p.u = p.r.Mul(p.phi)
+	v := p.secretKeyShare.Mul(p.phi)
+	for _, peer := range p.peers {
+		input := inputs[peer]
+		if input.RSchnorrProof == nil || input.GammaU == nil || input.GammaV == nil {
+			return nil, dkg.Blame(peer, "the well-formedness of its sign round 3 message", nil, nil)
+		}
+		err := schnorr.DecommitVerify(input.RSchnorrProof, p.peerCommitments[peer], p.curve, nil, dkg.PartySessionId(p.sessionId, peer))
+		if err != nil {
+			return nil, dkg.Blame(peer, "the opening of its commitment to its instance key share", nil, err)
+		}
+		receivers := p.multiplyReceivers[peer]
+		for k, receiver := range receivers {
+			if input.MultiplyRound2Outputs[k] == nil {
+				return nil, dkg.Blame(peer, "the well-formedness of its sign round 3 message", nil, nil)
+			}
+			if err = receiver.Round3Multiply(input.MultiplyRound2Outputs[k]); err != nil {
+				return nil, dkg.Blame(peer, fmt.Sprintf("the consistency check of multiplication %d", k), nil, err)
+			}
+		}
+		// The peer's shares c of r_j . φ_i and sk_j . φ_i, and ours d, add up to the products, so
+		// φ_i . R_j - c . G = d . G, and likewise with the peer's public key share.
+		peerR := input.RSchnorrProof.Statement
+		dU := receivers[0].OutputAdditiveShare()
+		dV := receivers[1].OutputAdditiveShare()
+		if !peerR.Mul(p.phi).Sub(input.GammaU).Equal(p.curve.ScalarBaseMult(dU)) {
+			return nil, dkg.Blame(peer, "the check that it multiplied with the instance key share of its R", nil, nil)
+		}
+		if !p.publicKeyShares[peer].Mul(p.phi).Sub(input.GammaV).Equal(p.curve.ScalarBaseMult(dV)) {
+			return nil, dkg.Blame(peer, "the check that it multiplied with the key share of its public share", nil, nil)
+		}
+		senders := p.multiplySenders[peer]
+		p.u = p.u.Add(senders[0].OutputAdditiveShare()).Add(dU)
+		v = v.Add(senders[1].OutputAdditiveShare()).Add(dV)
+		p.bigR = p.bigR.Add(peerR)
+	}
+	if p.bigR.IsIdentity() {
+		return nil, errors.New("instance key is the identity")
+	}
+
+	if _, err := p.hash.Write(message); err != nil {
+		return nil, errors.Wrap(err, "writing message to hash in sign round 4")
+	}
+	p.digest = p.hash.Sum(nil)
+	// The digest is interpreted as an integer and reduced mod q, exactly as ECDSA verification does.
+	h, err := p.curve.Scalar.SetBigInt(new(big.Int).SetBytes(p.digest))
+	if err != nil {
+		return nil, errors.Wrap(err, "setting digest scalar from big int")
+	}
+	rX, err := p.rX()
+	if err != nil {
+		return nil, err
+	}
+	p.w = h.Mul(p.phi).Add(rX.Mul(v))
+	return &Round4Output{U: p.u, W: p.w}, nil
+}
+
+// Round5Final sums the shares of all the signers into the signature, normalizes it to low-S and verifies it.
+// Protocol 3.6, step 4.
+func (p *Participant) Round5Final(inputs map[uint32]*Round4Output) error {
+	if err := dkg.CheckInputs(p.peers, inputs); err != nil {
+		return errors.Wrap(err, "sign round 5")
+	}
+	u, w := p.u, p.w
+	for _, peer := range p.peers {
+		if inputs[peer].U == nil || inputs[peer].W == nil {
+			return dkg.Blame(peer, "the well-formedness of its sign round 4 message", nil, nil)
+		}
+		u = u.Add(inputs[peer].U)
+		w = w.Add(inputs[peer].W)
+	}
+	if u.IsZero() {
+		return errors.New("the masked instance key is zero")
+	}
+	signature, err := curves.FinalizeEcdsaSignature(p.curve, p.bigR, w.Div(u), p.publicKey, p.digest)
+	if err != nil {
+		return err
+	}
+	p.Signature = signature
+	return nil
+}
+
+// commitmentDigest is the digest of the commitment to the instance key share of signer from.
+func commitmentDigest(sessionId [simplest.DigestSize]byte, from uint32, commitment schnorr.Commitment) [simplest.DigestSize]byte {
+	return dkg.BroadcastDigest(sessionId, "sign round 2", from, commitment)
+}
+
+// rX returns the x coordinate of the instance key, reduced mod q.
+func (p *Participant) rX() (curves.Scalar, error) {
+	rX, _, err := curves.EcdsaR(p.curve, p.bigR)
+	return rX, err
+}
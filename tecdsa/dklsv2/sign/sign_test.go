@@ -0,0 +1,153 @@
+package sign_test
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/sonr-io/crypto/core/curves"
+	"github.com/sonr-io/crypto/core/protocol"
+	"github.com/sonr-io/crypto/ot/base/simplest"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/dkg"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/internal/dklstest"
+	"github.com/sonr-io/crypto/tecdsa/dklsv2/sign"
+	"github.com/sonr-io/crypto/zkp/schnorr"
+)
+
+func newSigners(t *testing.T, curve *curves.Curve, outputs map[uint32]*dkg.Output, signers []uint32) map[uint32]*sign.Participant {
+	t.Helper()
+	parties := make(map[uint32]*sign.Participant, len(signers))
+	for _, id := range signers {
+		party, err := sign.NewParticipant(curve, sha3.New256(), outputs[id], signers)
+		require.NoError(t, err)
+		parties[id] = party
+	}
+	return parties
+}
+
+// runToRound3 runs the signers up to the messages of round 3, addressed to their recipients.
+func runToRound3(t *testing.T, parties map[uint32]*sign.Participant) map[uint32]map[uint32]*sign.Round3Output {
+	t.Helper()
+	seeds := dklstest.RunRound(t, parties, map[uint32]struct{}{}, func(p *sign.Participant, _ struct{}) ([simplest.DigestSize]byte, error) {
+		return p.Round1GenerateRandomSeed()
+	})
+	round2 := dklstest.RunRound(t, parties, dklstest.Broadcast(seeds), (*sign.Participant).Round2Initialize)
+	round3 := dklstest.RunRound(t, parties, dklstest.Route(round2), (*sign.Participant).Round3Multiply)
+	return dklstest.Route(round3)
+}
+
+func verify(t *testing.T, curve *curves.Curve, publicKey curves.Point, message []byte, signature *curves.EcdsaSignature) {
+	t.Helper()
+	ellipticCurve, err := curve.ToEllipticCurve()
+	require.NoError(t, err)
+	uncompressed := publicKey.ToAffineUncompressed()
+	pk := &ecdsa.PublicKey{
+		Curve: ellipticCurve,
+		X:     new(big.Int).SetBytes(uncompressed[1:33]),
+		Y:     new(big.Int).SetBytes(uncompressed[33:]),
+	}
+	digest := sha3.Sum256(message)
+	require.True(t, ecdsa.Verify(pk, digest[:], signature.R, signature.S))
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		curve            *curves.Curve
+		threshold, limit uint32
+		signers          []uint32
+	}{
+		{curves.K256(), 2, 3, []uint32{1, 2}},
+		{curves.K256(), 2, 3, []uint32{1, 2, 3}},
+		{curves.K256(), 3, 5, []uint32{2, 4, 5}},
+		{curves.P256(), 2, 3, []uint32{3, 1}},
+	}
+	for _, test := range tests {
+		boundTest := test
+		t.Run(fmt.Sprintf("signers %v of %d of %d for curve %s", boundTest.signers, boundTest.threshold, boundTest.limit, boundTest.curve.Name), func(tt *testing.T) {
+			tt.Parallel()
+			outputs := dklstest.RunDkg(tt, boundTest.curve, boundTest.threshold, boundTest.limit)
+			parties := newSigners(tt, boundTest.curve, outputs, boundTest.signers)
+			message := []byte("t-of-n threshold ECDSA")
+			round4 := dklstest.RunRound(tt, parties, runToRound3(tt, parties), func(p *sign.Participant, input map[uint32]*sign.Round3Output) (*sign.Round4Output, error) {
+				return p.Round4Combine(message, input)
+			})
+			dklstest.RunRound(tt, parties, dklstest.Broadcast(round4), func(p *sign.Participant, input map[uint32]*sign.Round4Output) (struct{}, error) {
+				return struct{}{}, p.Round5Final(input)
+			})
+
+			signature := parties[boundTest.signers[0]].Signature
+			for _, party := range parties {
+				require.Equal(tt, signature, party.Signature)
+			}
+			verify(tt, boundTest.curve, outputs[1].PublicKey, message, signature)
+		})
+	}
+}
+
+func TestSignBlamesInconsistentMultiplication(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	outputs := dklstest.RunDkg(t, curve, 2, 3)
+	tests := []struct {
+		name   string
+		tamper func(*sign.Round3Output)
+	}{
+		{"gamma u", func(o *sign.Round3Output) { o.GammaU = o.GammaU.Add(curve.Point.Generator()) }},
+		{"gamma v", func(o *sign.Round3Output) { o.GammaV = o.GammaV.Add(curve.Point.Generator()) }},
+		{"instance key", func(o *sign.Round3Output) { o.RSchnorrProof.S = o.RSchnorrProof.S.Add(curve.Scalar.One()) }},
+	}
+	for _, test := range tests {
+		parties := newSigners(t, curve, outputs, []uint32{1, 2})
+		inputs := runToRound3(t, parties)
+
+		// party 2 claims other shares of its products, or another instance key, than the ones it computed
+		test.tamper(inputs[1][2])
+		_, err := parties[1].Round4Combine([]byte("message"), inputs[1])
+		var abort *protocol.AbortError
+		require.ErrorAs(t, err, &abort, test.name)
+		require.Equal(t, "2", abort.Party, test.name)
+	}
+}
+
+func TestSignCatchesEquivocatedCommitment(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	outputs := dklstest.RunDkg(t, curve, 2, 3)
+	parties := newSigners(t, curve, outputs, []uint32{1, 2, 3})
+	seeds := dklstest.RunRound(t, parties, map[uint32]struct{}{}, func(p *sign.Participant, _ struct{}) ([simplest.DigestSize]byte, error) {
+		return p.Round1GenerateRandomSeed()
+	})
+	inputs := dklstest.Route(dklstest.RunRound(t, parties, dklstest.Broadcast(seeds), (*sign.Participant).Round2Initialize))
+
+	// party 2 commits to another instance key towards party 3
+	commitment := append(schnorr.Commitment{}, inputs[3][2].Commitment...)
+	commitment[0] ^= 1
+	inputs[3][2] = &sign.Round2Output{Commitment: commitment, KosRound1Outputs: inputs[3][2].KosRound1Outputs}
+	round3 := dklstest.Route(dklstest.RunRound(t, parties, inputs, (*sign.Participant).Round3Multiply))
+
+	var abort *protocol.AbortError
+	for _, id := range []uint32{1, 3} {
+		_, err := parties[id].Round4Combine([]byte("message"), round3[id])
+		require.ErrorAs(t, err, &abort, "party %d", id)
+		require.Equal(t, "2", abort.Party, "party %d", id)
+	}
+}
+
+func TestNewParticipantValidatesSigners(t *testing.T) {
+	t.Parallel()
+	curve := curves.K256()
+	outputs := dklstest.RunDkg(t, curve, 2, 3)
+	_, err := sign.NewParticipant(curve, sha3.New256(), outputs[1], []uint32{1})
+	require.Error(t, err)
+	_, err = sign.NewParticipant(curve, sha3.New256(), outputs[1], []uint32{2, 3})
+	require.Error(t, err)
+	_, err = sign.NewParticipant(curve, sha3.New256(), outputs[1], []uint32{1, 4})
+	require.Error(t, err)
+	_, err = sign.NewParticipant(curve, sha3.New256(), outputs[1], []uint32{1, 1})
+	require.Error(t, err)
+}
@@ -38,7 +38,8 @@ LOOP:
 
 // AttenuationConstructorFunc is a function that creates an attenuation from a map
 // Users of this package provide an Attenuation Constructor to the parser to
-// bind attenuation logic to a UCAN
+// bind attenuation logic to a UCAN. UCAN 1.0 delegations reach it as {"cap": cmd, "sub": subject},
+// with a nil subject for a powerline delegation.
 type AttenuationConstructorFunc func(v map[string]any) (Attenuation, error)
 
 // Attenuation is a capability on a resource
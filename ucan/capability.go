@@ -0,0 +1,299 @@
+package ucan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+var (
+	// ErrInvalidHierarchy is returned when capability definitions do not form a tree
+	ErrInvalidHierarchy = errors.New("invalid capability hierarchy")
+	// ErrUnknownCapability is returned when a capability is not defined by a hierarchy
+	ErrUnknownCapability = errors.New("unknown capability")
+)
+
+// CapabilityHierarchy is a tree of capabilities loaded from a ucanv1.CapabilityHierarchy. A
+// capability contains itself and every capability below it, following Capability.parent up to the
+// root. It is both a capability and a capability factory with the .Cap method.
+type CapabilityHierarchy struct {
+	typ     string
+	root    string
+	caps    map[string]*ucanv1.Capability
+	order   []string
+	parents map[string]string
+	// commands maps command paths to capability names
+	commands map[string]string
+}
+
+// NewCapabilityHierarchy loads a capability hierarchy. Capability names must be unique and parents
+// must refer to another capability of the hierarchy by name or command. Capabilities without a
+// parent are children of the root; without a root, exactly one capability must have no parent.
+func NewCapabilityHierarchy(h *ucanv1.CapabilityHierarchy) (*CapabilityHierarchy, error) {
+	ch := &CapabilityHierarchy{
+		typ:      h.GetType(),
+		caps:     map[string]*ucanv1.Capability{},
+		parents:  map[string]string{},
+		commands: map[string]string{},
+	}
+	caps := h.GetCapabilities()
+	if root := h.GetRoot(); root != nil {
+		if root.GetParent() != "" {
+			return nil, fmt.Errorf("%w: root %q has a parent", ErrInvalidHierarchy, root.GetName())
+		}
+		ch.root = root.GetName()
+		caps = append([]*ucanv1.Capability{root}, caps...)
+	}
+	for _, c := range caps {
+		name := c.GetName()
+		if name == "" {
+			return nil, fmt.Errorf("%w: capability without a name", ErrInvalidHierarchy)
+		}
+		if prev, ok := ch.caps[name]; ok {
+			// the root may be listed among the capabilities too
+			if name == ch.root && proto.Equal(prev, c) {
+				continue
+			}
+			return nil, fmt.Errorf("%w: duplicate capability %q", ErrInvalidHierarchy, name)
+		}
+		ch.caps[name] = c
+		ch.order = append(ch.order, name)
+		if cmd := c.GetCommand(); cmd != "" {
+			if err := validateCommand(cmd); err != nil {
+				return nil, fmt.Errorf("%w: capability %q: %v", ErrInvalidHierarchy, name, err)
+			}
+			if other, ok := ch.commands[cmd]; ok {
+				return nil, fmt.Errorf("%w: %q and %q both map to %s", ErrInvalidHierarchy, other, name, cmd)
+			}
+			ch.commands[cmd] = name
+		}
+	}
+
+	var roots []string
+	for _, name := range ch.order {
+		parent := ch.caps[name].GetParent()
+		switch {
+		case name == ch.root:
+			continue
+		case parent == "" && ch.root != "":
+			parent = ch.root
+		case parent == "":
+			roots = append(roots, name)
+			continue
+		default:
+			resolved, ok := ch.resolve(parent)
+			if !ok {
+				return nil, fmt.Errorf("%w: %q has unknown parent %q", ErrInvalidHierarchy, name, parent)
+			}
+			parent = resolved
+		}
+		ch.parents[name] = parent
+	}
+	if ch.root == "" {
+		if len(roots) != 1 {
+			return nil, fmt.Errorf("%w: expected a single root, found %d", ErrInvalidHierarchy, len(roots))
+		}
+		ch.root = roots[0]
+	}
+	// every capability must reach the root without revisiting a capability
+	for _, name := range ch.order {
+		seen := map[string]bool{}
+		for c := name; c != ch.root; c = ch.parents[c] {
+			if seen[c] {
+				return nil, fmt.Errorf("%w: %q is its own ancestor", ErrInvalidHierarchy, c)
+			}
+			seen[c] = true
+		}
+	}
+	return ch, nil
+}
+
+// resolve returns the name of the capability called or mapped to s
+func (h *CapabilityHierarchy) resolve(s string) (string, bool) {
+	if _, ok := h.caps[s]; ok {
+		return s, true
+	}
+	name, ok := h.commands[s]
+	return name, ok
+}
+
+// Type returns the type of the hierarchy, e.g. "vault"
+func (h *CapabilityHierarchy) Type() string {
+	return h.typ
+}
+
+// Root returns the most powerful capability of the hierarchy
+func (h *CapabilityHierarchy) Root() HierarchicalCapability {
+	return HierarchicalCapability{name: h.root, h: h}
+}
+
+// Lookup returns the capability with the given name or command
+func (h *CapabilityHierarchy) Lookup(s string) (HierarchicalCapability, error) {
+	name, ok := h.resolve(s)
+	if !ok {
+		return HierarchicalCapability{}, fmt.Errorf("%w: %q is not a %s capability", ErrUnknownCapability, s, h.typ)
+	}
+	return HierarchicalCapability{name: name, h: h}, nil
+}
+
+// Cap creates a new capability from the hierarchy, panicking if it is not defined
+func (h *CapabilityHierarchy) Cap(s string) Capability {
+	c, err := h.Lookup(s)
+	if err != nil {
+		panic(err.Error())
+	}
+	return c
+}
+
+// Capabilities returns every capability of the hierarchy, root first
+func (h *CapabilityHierarchy) Capabilities() []HierarchicalCapability {
+	caps := make([]HierarchicalCapability, len(h.order))
+	for i, name := range h.order {
+		caps[i] = HierarchicalCapability{name: name, h: h}
+	}
+	return caps
+}
+
+// Proto returns the definition of the hierarchy, with resolved parents
+func (h *CapabilityHierarchy) Proto() *ucanv1.CapabilityHierarchy {
+	out := &ucanv1.CapabilityHierarchy{Type: h.typ}
+	for _, c := range h.Capabilities() {
+		if c.name == h.root {
+			out.Root = c.Proto()
+			continue
+		}
+		out.Capabilities = append(out.Capabilities, c.Proto())
+	}
+	return out
+}
+
+// HierarchicalCapability is a capability of a CapabilityHierarchy
+type HierarchicalCapability struct {
+	name string
+	h    *CapabilityHierarchy
+}
+
+// assert at compile-time HierarchicalCapability implements Capability
+var _ Capability = HierarchicalCapability{}
+
+// String returns the name of the capability
+func (c HierarchicalCapability) String() string {
+	return c.name
+}
+
+// Command returns the command path the capability maps to, if any
+func (c HierarchicalCapability) Command() string {
+	return c.h.caps[c.name].GetCommand()
+}
+
+// Description describes what the capability allows
+func (c HierarchicalCapability) Description() string {
+	return c.h.caps[c.name].GetDescription()
+}
+
+// Parent returns the capability directly above c, false for the root
+func (c HierarchicalCapability) Parent() (HierarchicalCapability, bool) {
+	parent, ok := c.h.parents[c.name]
+	if !ok {
+		return HierarchicalCapability{}, false
+	}
+	return HierarchicalCapability{name: parent, h: c.h}, true
+}
+
+// Contains returns true if b is c or a descendant of c. Capabilities of other hierarchy types are
+// never contained, other capabilities are looked up by name or command.
+func (c HierarchicalCapability) Contains(b Capability) bool {
+	if b == nil || c.h == nil {
+		return false
+	}
+	name := ""
+	if hc, ok := b.(HierarchicalCapability); ok {
+		if hc.h == nil || hc.h.typ != c.h.typ {
+			return false
+		}
+		name = hc.name
+	}
+	if name == "" {
+		var ok bool
+		if name, ok = c.h.resolve(b.String()); !ok {
+			return false
+		}
+	}
+	for {
+		if name == c.name {
+			return true
+		}
+		parent, ok := c.h.parents[name]
+		if !ok {
+			return false
+		}
+		name = parent
+	}
+}
+
+// Proto returns the definition of the capability with its resolved parent
+func (c HierarchicalCapability) Proto() *ucanv1.Capability {
+	def := proto.Clone(c.h.caps[c.name]).(*ucanv1.Capability)
+	def.Parent = c.h.parents[c.name]
+	return def
+}
+
+// resource is a Resource of a permission type. An id ending in "*" contains every id with the same
+// prefix.
+type resource struct {
+	kind Permissions
+	id   string
+}
+
+// NewResource returns the resource of the given permission type and id
+func NewResource(kind Permissions, id string) Resource {
+	return resource{kind: kind, id: id}
+}
+
+// ResourceFromProto converts a ucanv1.Resource
+func ResourceFromProto(r *ucanv1.Resource) (Resource, error) {
+	kind := Permissions(r.GetKind())
+	if kind.GetCapabilities() == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPermissions, r.GetKind())
+	}
+	if r.GetId() == "" {
+		return nil, errors.New("resource id is required")
+	}
+	return NewResource(kind, r.GetId()), nil
+}
+
+func (r resource) Type() string  { return string(r.kind) }
+func (r resource) Value() string { return r.id }
+
+func (r resource) Contains(b Resource) bool {
+	if b == nil || string(r.kind) != b.Type() {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.id, "*"); ok {
+		return strings.HasPrefix(b.Value(), prefix)
+	}
+	return r.id == b.Value()
+}
+
+// AttenuationFromProto converts a ucanv1.CapabilityAttenuation to an attenuation of the hierarchy
+// of its resource kind. Constraints are not part of the attenuation, they are checked with
+// EvaluatePolicy.
+func AttenuationFromProto(a *ucanv1.CapabilityAttenuation) (Attenuation, error) {
+	rsc, err := ResourceFromProto(a.GetResource())
+	if err != nil {
+		return EmptyAttenuation, err
+	}
+	name := a.GetCapability().GetName()
+	if name == "" {
+		name = a.GetCapability().GetCommand()
+	}
+	c, err := Permissions(rsc.Type()).GetCapabilities().Lookup(name)
+	if err != nil {
+		return EmptyAttenuation, err
+	}
+	return Attenuation{Cap: c, Rsc: rsc}, nil
+}
@@ -0,0 +1,204 @@
+package ucan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+func TestPermissions_GetCapabilities(t *testing.T) {
+	vault := VaultPermissions.GetCapabilities()
+	require.NotNil(t, vault)
+	assert.Equal(t, "vault", vault.Type())
+	assert.Equal(t, "vault", vault.Root().String())
+	assert.Equal(t, "/vault", vault.Root().Command())
+	assert.Len(t, vault.Capabilities(), 4)
+
+	sign := VaultPermissions.NewCap("sign")
+	assert.True(t, vault.Root().Contains(sign))
+	assert.True(t, sign.Contains(sign))
+	assert.False(t, sign.Contains(vault.Root()))
+	assert.False(t, sign.Contains(VaultPermissions.NewCap("verify")))
+	// capabilities are looked up by command too
+	assert.True(t, vault.Root().Contains(NewNestedCapabilities("/vault/refresh")))
+	assert.Equal(t, sign, VaultPermissions.NewCap("/vault/sign"))
+	// capabilities of other hierarchies are never contained
+	assert.False(t, AccountPermissions.GetCapabilities().Root().Contains(sign))
+
+	parent, ok := sign.(HierarchicalCapability).Parent()
+	require.True(t, ok)
+	assert.Equal(t, vault.Root(), parent)
+	_, ok = vault.Root().Parent()
+	assert.False(t, ok)
+
+	assert.Len(t, AccountPermissions.GetCapabilities().Capabilities(), 8)
+	assert.Len(t, ServicePermissions.GetCapabilities().Capabilities(), 4)
+	assert.Nil(t, Permissions("ibc").GetCapabilities())
+	assert.Panics(t, func() { VaultPermissions.NewCap("transfer") })
+}
+
+func TestNewCapabilityHierarchy(t *testing.T) {
+	h, err := NewCapabilityHierarchy(&ucanv1.CapabilityHierarchy{
+		Type: "ibc",
+		Root: &ucanv1.Capability{Name: "ibc", Command: "/ibc"},
+		Capabilities: []*ucanv1.Capability{
+			{Name: "broadcast", Command: "/ibc/broadcast"},
+			{Name: "chain", Parent: "/ibc", Command: "/ibc/chain"},
+			{Name: "nomic", Parent: "chain", Command: "/ibc/chain/nomic"},
+			{Name: "osmos", Parent: "/ibc/chain", Command: "/ibc/chain/osmos"},
+		},
+	})
+	require.NoError(t, err)
+	chain := h.Cap("chain")
+	assert.True(t, chain.Contains(h.Cap("nomic")))
+	assert.True(t, chain.Contains(h.Cap("osmos")))
+	assert.False(t, chain.Contains(h.Cap("broadcast")))
+	assert.True(t, h.Root().Contains(h.Cap("osmos")))
+	assert.False(t, h.Cap("nomic").Contains(chain))
+
+	// parents are resolved to names
+	def := h.Proto()
+	assert.Equal(t, "ibc", def.Capabilities[0].Parent)
+	assert.Equal(t, "chain", def.Capabilities[3].Parent)
+	again, err := NewCapabilityHierarchy(def)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(def, again.Proto()))
+
+	_, err = h.Lookup("query")
+	assert.ErrorIs(t, err, ErrUnknownCapability)
+
+	invalid := map[string]*ucanv1.CapabilityHierarchy{
+		"no root": {Type: "x"},
+		"two roots": {Type: "x", Capabilities: []*ucanv1.Capability{
+			{Name: "a"}, {Name: "b"},
+		}},
+		"duplicate": {Type: "x", Capabilities: []*ucanv1.Capability{
+			{Name: "a"}, {Name: "b", Parent: "a"}, {Name: "b", Parent: "a"},
+		}},
+		"unknown parent": {Type: "x", Capabilities: []*ucanv1.Capability{
+			{Name: "a"}, {Name: "b", Parent: "c"},
+		}},
+		"cycle": {Type: "x", Capabilities: []*ucanv1.Capability{
+			{Name: "a"}, {Name: "b", Parent: "c"}, {Name: "c", Parent: "b"},
+		}},
+		"unnamed":     {Type: "x", Capabilities: []*ucanv1.Capability{{Command: "/x"}}},
+		"bad command": {Type: "x", Capabilities: []*ucanv1.Capability{{Name: "a", Command: "x"}}},
+		"shared command": {Type: "x", Capabilities: []*ucanv1.Capability{
+			{Name: "a", Command: "/x"}, {Name: "b", Parent: "a", Command: "/x"},
+		}},
+		"root with parent": {Type: "x", Root: &ucanv1.Capability{Name: "a", Parent: "b"}},
+	}
+	for name, def := range invalid {
+		_, err := NewCapabilityHierarchy(def)
+		assert.ErrorIs(t, err, ErrInvalidHierarchy, name)
+	}
+}
+
+func TestParseAttenuationData(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]any
+		kind    string
+		cap     string
+		id      string
+		wantErr error
+	}{
+		{"resource key", map[string]any{CapKey: "sign", "vault": "keys/1"}, "vault", "sign", "keys/1", nil},
+		{"preset", map[string]any{"preset": "account", CapKey: "transfer", "account": "idx1abc"}, "account", "transfer", "idx1abc", nil},
+		{"command", map[string]any{CapKey: "/account/link", SubKey: "did:key:z6Mk"}, "account", "link", "did:key:z6Mk", nil},
+		{"powerline", map[string]any{CapKey: "/vault", SubKey: nil}, "vault", "vault", "*", nil},
+		{"preset without id", map[string]any{"preset": "service", CapKey: "register"}, "", "", "", nil},
+		{"resource without id", map[string]any{CapKey: "sign", "vault": ""}, "", "", "", nil},
+		{"null resource id", map[string]any{CapKey: "sign", "vault": nil}, "", "", "", nil},
+		{"command without sub", map[string]any{CapKey: "/vault/sign"}, "", "", "", nil},
+		{"empty sub", map[string]any{CapKey: "/vault/sign", SubKey: ""}, "", "", "", nil},
+		{"wildcard sub", map[string]any{CapKey: "/vault", SubKey: "*"}, "", "", "", nil},
+		{"unknown preset", map[string]any{"preset": "ibc", CapKey: "query"}, "", "", "", ErrUnknownPermissions},
+		{"unknown resource", map[string]any{CapKey: "read", "file": "a.txt"}, "", "", "", ErrUnknownPermissions},
+		{"unknown command", map[string]any{CapKey: "/ibc/query", SubKey: "x"}, "", "", "", ErrUnknownPermissions},
+		{"unknown capability", map[string]any{CapKey: "transfer", "vault": "keys/1"}, "", "", "", ErrUnknownCapability},
+		{"two resources", map[string]any{CapKey: "sign", "vault": "a", "account": "b"}, "", "", "", nil},
+		{"missing cap", map[string]any{"vault": "keys/1"}, "", "", "", nil},
+		{"non-string id", map[string]any{CapKey: "sign", "vault": 1}, "", "", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			att, err := ParseAttenuationData(tt.data)
+			if tt.kind == "" {
+				require.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, att.Rsc.Type())
+			assert.Equal(t, tt.id, att.Rsc.Value())
+			assert.Equal(t, tt.cap, att.Cap.String())
+		})
+	}
+}
+
+func TestAttenuation_Typed(t *testing.T) {
+	root, err := VaultPermissions.NewAttenuation("vault", "keys/*")
+	require.NoError(t, err)
+	sign, err := VaultPermissions.NewAttenuation("/vault/sign", "keys/1")
+	require.NoError(t, err)
+	other, err := AccountPermissions.NewAttenuation("account", "keys/1")
+	require.NoError(t, err)
+
+	assert.True(t, root.Contains(sign))
+	assert.False(t, sign.Contains(root))
+	assert.False(t, other.Contains(sign))
+	assert.True(t, Attenuations{other, root}.Contains(Attenuations{sign}))
+	assert.False(t, Attenuations{sign}.Contains(Attenuations{root}))
+
+	outside, err := VaultPermissions.NewAttenuation("sign", "wallets/1")
+	require.NoError(t, err)
+	assert.False(t, root.Contains(outside))
+
+	// attenuations parse back from their JSON form
+	raw, err := json.Marshal(sign)
+	require.NoError(t, err)
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(raw, &data))
+	parsed, err := ParseAttenuationData(data)
+	require.NoError(t, err)
+	assert.Equal(t, sign, parsed)
+
+	_, err = Permissions("ibc").NewAttenuation("query", "x")
+	assert.ErrorIs(t, err, ErrUnknownPermissions)
+}
+
+func TestAttenuationFromProto(t *testing.T) {
+	att, err := AttenuationFromProto(&ucanv1.CapabilityAttenuation{
+		Resource:   &ucanv1.Resource{Kind: "account", Id: "idx1abc"},
+		Capability: &ucanv1.Capability{Name: "delegate"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "delegate", att.Cap.String())
+	assert.Equal(t, NewResource(AccountPermissions, "idx1abc"), att.Rsc)
+
+	att, err = AttenuationFromProto(&ucanv1.CapabilityAttenuation{
+		Resource:   &ucanv1.Resource{Kind: "service", Id: "example.com"},
+		Capability: &ucanv1.Capability{Command: "/service/update"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "update", att.Cap.String())
+
+	_, err = AttenuationFromProto(&ucanv1.CapabilityAttenuation{
+		Resource:   &ucanv1.Resource{Kind: "ibc", Id: "x"},
+		Capability: &ucanv1.Capability{Name: "query"},
+	})
+	assert.ErrorIs(t, err, ErrUnknownPermissions)
+	_, err = AttenuationFromProto(&ucanv1.CapabilityAttenuation{
+		Resource:   &ucanv1.Resource{Kind: "vault"},
+		Capability: &ucanv1.Capability{Name: "sign"},
+	})
+	assert.Error(t, err)
+}
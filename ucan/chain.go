@@ -0,0 +1,161 @@
+package ucan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+const (
+	// DefaultMaxProofDepth bounds the length of a delegation chain the parser will follow
+	DefaultMaxProofDepth = 16
+	// DefaultMaxProofLinks bounds the number of proof links the parser will follow while parsing a
+	// single token, counting a proof once for every token that uses it
+	DefaultMaxProofLinks = 256
+)
+
+var (
+	// ErrProofNotFound is returned when a CID proof cannot be resolved
+	ErrProofNotFound = errors.New("proof not found")
+	// ErrProofCycle is returned when a proof chain refers back to a token already in the chain
+	ErrProofCycle = errors.New("proof chain contains a cycle")
+	// ErrProofDepth is returned when a proof chain is longer than the parser allows
+	ErrProofDepth = errors.New("proof chain is too deep")
+	// ErrProofLinks is returned when the proofs of a token have more links than the parser allows
+	ErrProofLinks = errors.New("proof chain has too many links")
+	// ErrDuplicateProof is returned when a token lists the same proof more than once
+	ErrDuplicateProof = errors.New("duplicate proof")
+	// ErrAudienceMismatch is returned when a proof was not delegated to the issuer of the token using it
+	ErrAudienceMismatch = errors.New("proof audience does not match token issuer")
+	// ErrTimeBounds is returned when a token is valid outside of the time bounds of its proofs
+	ErrTimeBounds = errors.New("token time bounds exceed its proof")
+	// ErrAttenuationEscalation is returned when a token claims attenuations its proofs do not grant
+	ErrAttenuationEscalation = errors.New("attenuations are not contained in proofs")
+	// ErrRevoked is returned when a token in the chain has been revoked
+	ErrRevoked = errors.New("token has been revoked")
+)
+
+// ChainError reports which link of a delegation chain failed validation and why
+type ChainError struct {
+	// Depth of the failing token, 0 is the token being parsed and its proofs are at depth 1
+	Depth int
+	// CID of the failing token
+	CID cid.Cid
+	// Issuer of the failing token, empty if it could not be parsed
+	Issuer string
+	// Err is the reason the link failed
+	Err error
+}
+
+func (e *ChainError) Error() string {
+	if e.Issuer == "" {
+		return fmt.Sprintf("ucan chain link %s at depth %d: %v", e.CID, e.Depth, e.Err)
+	}
+	return fmt.Sprintf("ucan chain link %s at depth %d issued by %s: %v", e.CID, e.Depth, e.Issuer, e.Err)
+}
+
+func (e *ChainError) Unwrap() error {
+	return e.Err
+}
+
+// RevocationChecker reports whether a token has been revoked. A revoked token invalidates every
+// token that uses it as a proof, directly or further down the chain.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, id cid.Cid) (bool, error)
+}
+
+// RevocationCheckerFunc adapts a function to the RevocationChecker interface
+type RevocationCheckerFunc func(ctx context.Context, id cid.Cid) (bool, error)
+
+// IsRevoked calls f
+func (f RevocationCheckerFunc) IsRevoked(ctx context.Context, id cid.Cid) (bool, error) {
+	return f(ctx, id)
+}
+
+// ParserOption configures a TokenParser
+type ParserOption func(*TokenParser)
+
+// WithRevocationChecker makes the parser reject chains that contain a revoked token
+func WithRevocationChecker(rc RevocationChecker) ParserOption {
+	return func(p *TokenParser) {
+		p.revoked = rc
+	}
+}
+
+// WithMaxProofDepth sets the longest delegation chain the parser will follow
+func WithMaxProofDepth(depth int) ParserOption {
+	return func(p *TokenParser) {
+		p.maxDepth = depth
+	}
+}
+
+// WithMaxProofLinks sets the most proof links the parser will follow while parsing a single token
+func WithMaxProofLinks(links int) ParserOption {
+	return func(p *TokenParser) {
+		p.maxLinks = links
+	}
+}
+
+// WithClock sets the time source the parser checks UCAN 1.0 time bounds against
+func WithClock(now func() time.Time) ParserOption {
+	return func(p *TokenParser) {
+		p.now = now
+	}
+}
+
+// chainState is shared by every link while a single token is parsed.
+type chainState struct {
+	// path holds the CIDs of the tokens between the root and the current link, to detect cycles
+	path map[cid.Cid]bool
+	// verified holds the tokens already verified, so that a proof shared by several tokens of the
+	// chain is verified once
+	verified map[cid.Cid]verifiedToken
+	// links counts the proof links followed so far
+	links int
+}
+
+// verifiedToken is a token whose proofs were verified, and the length of its longest proof chain.
+type verifiedToken struct {
+	token  *Token
+	height int
+}
+
+func newChainState() *chainState {
+	return &chainState{path: map[cid.Cid]bool{}, verified: map[cid.Cid]verifiedToken{}}
+}
+
+// resolveProof returns the raw token a proof refers to.
+func (p *TokenParser) resolveProof(ctx context.Context, prf Proof) (string, error) {
+	if !prf.IsCID() {
+		return string(prf), nil
+	}
+	id, err := cid.Decode(string(prf))
+	if err != nil {
+		return "", err
+	}
+	if p.cidr == nil {
+		return "", fmt.Errorf("%w: no CID resolver for %s", ErrProofNotFound, id)
+	}
+	raw, err := p.cidr.ResolveCIDBytes(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrProofNotFound, id, err)
+	}
+	return string(raw), nil
+}
+
+// verifyLink checks that child is a valid delegation of proof.
+func verifyLink(child, proof *Token) error {
+	if !proof.Audience.Equals(child.Issuer.PubKey) {
+		return fmt.Errorf("%w: proof audience %s, token issuer %s", ErrAudienceMismatch, proof.Audience, child.Issuer)
+	}
+	if proof.NotBefore != 0 && child.NotBefore < proof.NotBefore {
+		return fmt.Errorf("%w: not before %d precedes proof not before %d", ErrTimeBounds, child.NotBefore, proof.NotBefore)
+	}
+	if proof.ExpiresAt != 0 && (child.ExpiresAt == 0 || child.ExpiresAt > proof.ExpiresAt) {
+		return fmt.Errorf("%w: expiry %d exceeds proof expiry %d", ErrTimeBounds, child.ExpiresAt, proof.ExpiresAt)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package ucan
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/didfmt"
+)
+
+type testResource string
+
+func (r testResource) Type() string  { return "test" }
+func (r testResource) Value() string { return string(r) }
+func (r testResource) Contains(b Resource) bool {
+	return b != nil && (r == "*" || string(r) == b.Value())
+}
+
+var testCaps = NewNestedCapabilities("admin", "write", "read")
+
+func testAttenuation(cap, rsc string) Attenuations {
+	return Attenuations{{Cap: testCaps.Cap(cap), Rsc: testResource(rsc)}}
+}
+
+func testAttenuationConstructor(m map[string]any) (Attenuation, error) {
+	cap, ok := m[CapKey].(string)
+	if !ok {
+		return Attenuation{}, errors.New("missing cap")
+	}
+	rsc, ok := m["test"].(string)
+	if !ok {
+		return Attenuation{}, errors.New("missing resource")
+	}
+	return Attenuation{Cap: testCaps.Cap(cap), Rsc: testResource(rsc)}, nil
+}
+
+type testParty struct {
+	Source
+	did string
+}
+
+func newTestParty(t *testing.T) testParty {
+	t.Helper()
+	priv, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	src, err := NewPrivKeySource(priv)
+	require.NoError(t, err)
+	did, err := DIDStringFromPublicKey(priv.GetPublic())
+	require.NoError(t, err)
+	return testParty{Source: src, did: did}
+}
+
+func newTestParser(store TokenStore, opts ...ParserOption) *TokenParser {
+	return NewTokenParser(testAttenuationConstructor, StringDIDPubKeyResolver{}, store.(CIDBytesResolver), opts...)
+}
+
+func requireChainError(t *testing.T, err error, target error, depth int) {
+	t.Helper()
+	require.ErrorIs(t, err, target)
+	var ce *ChainError
+	require.True(t, errors.As(err, &ce), "expected a *ChainError, got %T", err)
+	require.Equal(t, depth, ce.Depth, ce.Error())
+}
+
+func TestChain_Valid(t *testing.T) {
+	alice, bob, carol := newTestParty(t), newTestParty(t), newTestParty(t)
+	exp := time.Now().Add(time.Hour)
+
+	root, err := alice.NewOriginToken(bob.did, testAttenuation("write", "*"), nil, time.Time{}, exp)
+	require.NoError(t, err)
+	child, err := bob.NewAttenuatedToken(root, carol.did, testAttenuation("read", "doc"), nil, time.Time{}, exp.Add(-time.Minute))
+	require.NoError(t, err)
+
+	tok, err := newTestParser(NewMemTokenStore()).ParseAndVerify(context.Background(), child.Raw)
+	require.NoError(t, err)
+	require.Equal(t, bob.did, tok.Issuer.String())
+	require.Equal(t, carol.did, tok.Audience.String())
+	require.Len(t, tok.Proofs, 1)
+}
+
+func TestChain_CIDProof(t *testing.T) {
+	ctx := context.Background()
+	alice, bob, carol := newTestParty(t), newTestParty(t), newTestParty(t)
+	store := NewMemTokenStore()
+
+	root, err := alice.NewOriginToken(bob.did, testAttenuation("write", "*"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	id, err := root.CID()
+	require.NoError(t, err)
+	require.NoError(t, store.PutToken(ctx, id.String(), root.Raw))
+
+	byCID := &Token{Raw: id.String(), Attenuations: root.Attenuations}
+	child, err := bob.NewAttenuatedToken(byCID, carol.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = newTestParser(store).ParseAndVerify(ctx, child.Raw)
+	require.NoError(t, err)
+
+	_, err = newTestParser(NewMemTokenStore()).ParseAndVerify(ctx, child.Raw)
+	requireChainError(t, err, ErrProofNotFound, 0)
+}
+
+func TestChain_AudienceMismatch(t *testing.T) {
+	alice, bob, carol := newTestParty(t), newTestParty(t), newTestParty(t)
+
+	// alice delegates to carol, but bob tries to use the delegation
+	root, err := alice.NewOriginToken(carol.did, testAttenuation("write", "*"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	child, err := bob.NewAttenuatedToken(root, carol.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	_, err = newTestParser(NewMemTokenStore()).ParseAndVerify(context.Background(), child.Raw)
+	requireChainError(t, err, ErrAudienceMismatch, 0)
+}
+
+func TestChain_TimeBounds(t *testing.T) {
+	alice, bob, carol := newTestParty(t), newTestParty(t), newTestParty(t)
+	exp := time.Now().Add(time.Hour)
+
+	root, err := alice.NewOriginToken(bob.did, testAttenuation("write", "*"), nil, time.Time{}, exp)
+	require.NoError(t, err)
+
+	outlives, err := bob.NewAttenuatedToken(root, carol.did, testAttenuation("read", "doc"), nil, time.Time{}, exp.Add(time.Hour))
+	require.NoError(t, err)
+	_, err = newTestParser(NewMemTokenStore()).ParseAndVerify(context.Background(), outlives.Raw)
+	requireChainError(t, err, ErrTimeBounds, 0)
+
+	unbounded, err := bob.NewAttenuatedToken(root, carol.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	_, err = newTestParser(NewMemTokenStore()).ParseAndVerify(context.Background(), unbounded.Raw)
+	requireChainError(t, err, ErrTimeBounds, 0)
+}
+
+func TestChain_AttenuationEscalation(t *testing.T) {
+	alice, bob, carol := newTestParty(t), newTestParty(t), newTestParty(t)
+
+	root, err := alice.NewOriginToken(bob.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	// sources refuse to escalate, so forge the claim by swapping the parent's attenuations
+	forgedParent := *root
+	forgedParent.Attenuations = testAttenuation("admin", "*")
+	child, err := bob.NewAttenuatedToken(&forgedParent, carol.did, testAttenuation("write", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	_, err = newTestParser(NewMemTokenStore()).ParseAndVerify(context.Background(), child.Raw)
+	requireChainError(t, err, ErrAttenuationEscalation, 0)
+}
+
+func TestChain_RevokedProofInvalidatesDownstream(t *testing.T) {
+	ctx := context.Background()
+	parties := []testParty{newTestParty(t), newTestParty(t), newTestParty(t), newTestParty(t)}
+
+	tok, err := parties[0].NewOriginToken(parties[1].did, testAttenuation("write", "*"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	chain := []*Token{tok}
+	for i := 1; i < len(parties)-1; i++ {
+		tok, err = parties[i].NewAttenuatedToken(tok, parties[i+1].did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		chain = append(chain, tok)
+	}
+
+	revokedID, err := chain[0].CID()
+	require.NoError(t, err)
+	revocations := RevocationCheckerFunc(func(_ context.Context, id cid.Cid) (bool, error) {
+		return id.Equals(revokedID), nil
+	})
+
+	leaf := chain[len(chain)-1]
+	_, err = newTestParser(NewMemTokenStore()).ParseAndVerify(ctx, leaf.Raw)
+	require.NoError(t, err)
+
+	_, err = newTestParser(NewMemTokenStore(), WithRevocationChecker(revocations)).ParseAndVerify(ctx, leaf.Raw)
+	requireChainError(t, err, ErrRevoked, len(chain)-1)
+	var ce *ChainError
+	require.True(t, errors.As(err, &ce))
+	require.True(t, ce.CID.Equals(revokedID))
+}
+
+func TestChain_DepthLimit(t *testing.T) {
+	a, b := newTestParty(t), newTestParty(t)
+	tok, err := a.NewOriginToken(b.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		tok, err = b.NewAttenuatedToken(tok, b.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+		require.NoError(t, err)
+	}
+
+	_, err = newTestParser(NewMemTokenStore(), WithMaxProofDepth(3)).ParseAndVerify(context.Background(), tok.Raw)
+	require.NoError(t, err)
+	_, err = newTestParser(NewMemTokenStore(), WithMaxProofDepth(2)).ParseAndVerify(context.Background(), tok.Raw)
+	requireChainError(t, err, ErrProofDepth, 3)
+}
+
+func TestChain_SharedProofs(t *testing.T) {
+	ctx := context.Background()
+	const width, layers = 4, 10
+	store := NewMemTokenStore()
+	parties := make([]testParty, layers+1)
+	for i := range parties {
+		parties[i] = newTestParty(t)
+	}
+	exp := time.Now().Add(time.Hour)
+
+	// every token of a layer uses all the tokens of the layer below as proofs, so that the chain
+	// has width^layers paths; tokens of a layer differ by their expiry
+	var below []Proof
+	for i := 0; i < layers; i++ {
+		layer := make([]Proof, width)
+		for j := range layer {
+			src := parties[i].Source.(*pkSource)
+			tok, err := src.newToken(parties[i+1].did, below, testAttenuation("read", "doc"), nil, time.Time{}, exp.Add(time.Duration(j-i*width)*time.Minute))
+			require.NoError(t, err)
+			id, err := tok.CID()
+			require.NoError(t, err)
+			require.NoError(t, store.PutToken(ctx, id.String(), tok.Raw))
+			layer[j] = Proof(id.String())
+		}
+		below = layer
+	}
+	leaf, err := parties[layers].Source.(*pkSource).newToken(parties[layers].did, below, testAttenuation("read", "doc"), nil, time.Time{}, exp.Add(-time.Duration(layers*width)*time.Minute))
+	require.NoError(t, err)
+
+	// each token is verified once
+	var checked int
+	counter := RevocationCheckerFunc(func(context.Context, cid.Cid) (bool, error) {
+		checked++
+		return false, nil
+	})
+	_, err = newTestParser(store, WithRevocationChecker(counter)).ParseAndVerify(ctx, leaf.Raw)
+	require.NoError(t, err)
+	require.Equal(t, layers*width+1, checked)
+
+	// the links to shared proofs still count towards the limit, and towards the depth limit
+	_, err = newTestParser(store, WithMaxProofLinks(layers*width)).ParseAndVerify(ctx, leaf.Raw)
+	require.ErrorIs(t, err, ErrProofLinks)
+	_, err = newTestParser(store, WithMaxProofDepth(layers-1)).ParseAndVerify(ctx, leaf.Raw)
+	requireChainError(t, err, ErrProofDepth, layers)
+
+	// a token cannot list the same proof twice
+	dup, err := parties[layers].Source.(*pkSource).newToken(parties[layers].did, []Proof{below[0], below[0]}, testAttenuation("read", "doc"), nil, time.Time{}, exp.Add(-time.Duration(layers*width)*time.Minute))
+	require.NoError(t, err)
+	_, err = newTestParser(store).ParseAndVerify(ctx, dup.Raw)
+	requireChainError(t, err, ErrDuplicateProof, 0)
+}
+
+// cidResolverFunc adapts a function to the CIDBytesResolver interface
+type cidResolverFunc func(ctx context.Context, id cid.Cid) ([]byte, error)
+
+func (f cidResolverFunc) ResolveCIDBytes(ctx context.Context, id cid.Cid) ([]byte, error) {
+	return f(ctx, id)
+}
+
+func TestChain_Cycle(t *testing.T) {
+	ctx := context.Background()
+	a := newTestParty(t)
+
+	// a token cannot contain its own CID, so build a cycle through a resolver that returns the
+	// child for the CID of the root
+	root, err := a.NewOriginToken(a.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	rootID, err := root.CID()
+	require.NoError(t, err)
+	child, err := a.NewAttenuatedToken(&Token{Raw: rootID.String(), Attenuations: root.Attenuations}, a.did, testAttenuation("read", "doc"), nil, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	resolver := cidResolverFunc(func(ctx context.Context, id cid.Cid) ([]byte, error) {
+		if id.Equals(rootID) {
+			return []byte(child.Raw), nil
+		}
+		return nil, ErrTokenNotFound
+	})
+
+	_, err = NewTokenParser(testAttenuationConstructor, StringDIDPubKeyResolver{}, resolver).ParseAndVerify(ctx, child.Raw)
+	requireChainError(t, err, ErrProofCycle, 1)
+}
+
+func TestChain_DocumentResolver(t *testing.T) {
+	alice, bob := newTestParty(t), newTestParty(t)
+	root, err := alice.NewOriginToken(bob.did, testAttenuation("write", "*"), nil, time.Time{}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	didr := DocumentDIDPubKeyResolver{Resolver: didfmt.NewRegistry()}
+	tok, err := NewTokenParser(testAttenuationConstructor, didr, NewMemTokenStore().(CIDBytesResolver)).ParseAndVerify(context.Background(), root.Raw)
+	require.NoError(t, err)
+	require.Equal(t, alice.did, tok.Issuer.String())
+
+	_, err = didr.ResolveDIDKey(context.Background(), "did:ethr:0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	require.ErrorIs(t, err, didfmt.ErrNoPublicKey)
+	_, err = didr.ResolveDIDKey(context.Background(), "did:web:example.com")
+	require.ErrorIs(t, err, didfmt.ErrMethodNotSupported)
+}
@@ -1,7 +1,11 @@
 package ucan
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
 )
 
 var EmptyAttenuation = Attenuation{
@@ -9,6 +13,9 @@ var EmptyAttenuation = Attenuation{
 	Rsc: Resource(nil),
 }
 
+// ErrUnknownPermissions is returned when attenuation data does not name a known permission type
+var ErrUnknownPermissions = errors.New("unknown permissions")
+
 // Permissions represents the type of attenuation
 type Permissions string
 
@@ -23,21 +30,120 @@ const (
 	VaultPermissions = Permissions("vault")
 )
 
+// presetKey names the permission type in attenuation data
+const presetKey = "preset"
+
+// hierarchies holds the capability hierarchy of each permission type
+var hierarchies = map[Permissions]*CapabilityHierarchy{
+	AccountPermissions: mustHierarchy(NewAccountHierarchy(DefaultAccountCapabilities())),
+	ServicePermissions: mustHierarchy(NewServiceHierarchy(DefaultServiceCapabilities())),
+	VaultPermissions:   mustHierarchy(NewVaultHierarchy(DefaultVaultCapabilities())),
+}
+
+func mustHierarchy(h *CapabilityHierarchy, err error) *CapabilityHierarchy {
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// DefaultVaultCapabilities returns the capabilities of the DWN module over vaults
+func DefaultVaultCapabilities() *ucanv1.VaultCapabilities {
+	return &ucanv1.VaultCapabilities{Capabilities: moduleCapabilities(VaultPermissions, map[string]string{
+		"sign":    "Sign a message using the vault",
+		"refresh": "Refresh vault access",
+		"verify":  "Verify a signature from the vault",
+	}, "sign", "refresh", "verify")}
+}
+
+// DefaultAccountCapabilities returns the capabilities of the DID module over smart accounts
+func DefaultAccountCapabilities() *ucanv1.AccountCapabilities {
+	return &ucanv1.AccountCapabilities{Capabilities: moduleCapabilities(AccountPermissions, map[string]string{
+		"execute":  "Execute messages on behalf of the account",
+		"deposit":  "Deposit funds into the account",
+		"transfer": "Transfer funds out of the account",
+		"derive":   "Derive child keys of the account",
+		"link":     "Link an authenticator or identity to the account",
+		"unlink":   "Unlink an authenticator or identity from the account",
+		"delegate": "Delegate account capabilities to another party",
+	}, "execute", "deposit", "transfer", "derive", "link", "unlink", "delegate")}
+}
+
+// DefaultServiceCapabilities returns the capabilities of the SVC module over services
+func DefaultServiceCapabilities() *ucanv1.ServiceCapabilities {
+	return &ucanv1.ServiceCapabilities{Capabilities: moduleCapabilities(ServicePermissions, map[string]string{
+		"register": "Register a service",
+		"update":   "Update a registered service",
+		"delete":   "Delete a registered service",
+	}, "register", "update", "delete")}
+}
+
+// moduleCapabilities returns a root capability named after the permission type with the given
+// children, each mapped to a command under /<type>
+func moduleCapabilities(typ Permissions, descriptions map[string]string, names ...string) []*ucanv1.Capability {
+	root := "/" + string(typ)
+	caps := []*ucanv1.Capability{{
+		Name:        string(typ),
+		Description: fmt.Sprintf("Every %s capability", typ),
+		Command:     root,
+	}}
+	for _, name := range names {
+		caps = append(caps, &ucanv1.Capability{
+			Name:        name,
+			Parent:      string(typ),
+			Description: descriptions[name],
+			Command:     root + "/" + name,
+		})
+	}
+	return caps
+}
+
+// NewVaultHierarchy loads the vault capability hierarchy from its definition
+func NewVaultHierarchy(caps *ucanv1.VaultCapabilities) (*CapabilityHierarchy, error) {
+	return NewCapabilityHierarchy(&ucanv1.CapabilityHierarchy{
+		Type:         string(VaultPermissions),
+		Capabilities: caps.GetCapabilities(),
+	})
+}
+
+// NewAccountHierarchy loads the account capability hierarchy from its definition
+func NewAccountHierarchy(caps *ucanv1.AccountCapabilities) (*CapabilityHierarchy, error) {
+	return NewCapabilityHierarchy(&ucanv1.CapabilityHierarchy{
+		Type:         string(AccountPermissions),
+		Capabilities: caps.GetCapabilities(),
+	})
+}
+
+// NewServiceHierarchy loads the service capability hierarchy from its definition
+func NewServiceHierarchy(caps *ucanv1.ServiceCapabilities) (*CapabilityHierarchy, error) {
+	return NewCapabilityHierarchy(&ucanv1.CapabilityHierarchy{
+		Type:         string(ServicePermissions),
+		Capabilities: caps.GetCapabilities(),
+	})
+}
+
 // Cap returns the capability for the given AttenuationPreset
 func (a Permissions) NewCap(c string) Capability {
 	return a.GetCapabilities().Cap(c)
 }
 
-// NestedCapabilities returns the nested capabilities for the given AttenuationPreset
-func (a Permissions) GetCapabilities() NestedCapabilities {
-	var caps []string
-	switch a {
-	case AccountPermissions:
-		// caps = SmartAccountCapabilities()
-	case VaultPermissions:
-		// caps = VaultCapabilities()
+// GetCapabilities returns the capability hierarchy of the permission type, nil if it is unknown
+func (a Permissions) GetCapabilities() *CapabilityHierarchy {
+	return hierarchies[a]
+}
+
+// NewAttenuation returns the attenuation granting the capability with the given name or command
+// over the resource id of this permission type
+func (a Permissions) NewAttenuation(cap, id string) (Attenuation, error) {
+	h := a.GetCapabilities()
+	if h == nil {
+		return EmptyAttenuation, fmt.Errorf("%w: %q", ErrUnknownPermissions, a)
+	}
+	c, err := h.Lookup(cap)
+	if err != nil {
+		return EmptyAttenuation, err
 	}
-	return NewNestedCapabilities(caps...)
+	return Attenuation{Cap: c, Rsc: NewResource(a, id)}, nil
 }
 
 // Equals returns true if the given AttenuationPreset is equal to the receiver
@@ -50,17 +156,68 @@ func (a Permissions) String() string {
 	return string(a)
 }
 
-// ParseAttenuationData parses raw attenuation data into a structured format
-func ParseAttenuationData(data map[string]interface{}) (Permissions, map[string]interface{}, error) {
-	typeRaw, ok := data["preset"]
-	if !ok {
-		return "", nil, fmt.Errorf("missing preset type in attenuation data")
+// ParseAttenuationData parses raw attenuation data into a typed attenuation. It is an
+// AttenuationConstructorFunc for the permission types of this package and reads:
+//   - {"cap": "sign", "vault": "<id>"}, the form Attenuation.MarshalJSON produces
+//   - {"preset": "vault", "cap": "sign", "vault": "<id>"}
+//   - {"cap": "/vault/sign", "sub": "<id>"}, the form UCAN 1.0 delegations are viewed as, the
+//     permission type being the first command segment
+//
+// Capabilities are looked up by name or command. The resource id must be a non-empty string; the
+// only way to select every resource is a null "sub", the view of a UCAN 1.0 powerline delegation.
+func ParseAttenuationData(data map[string]any) (Attenuation, error) {
+	cap, ok := data[CapKey].(string)
+	if !ok || cap == "" {
+		return EmptyAttenuation, fmt.Errorf("missing capability in attenuation data")
 	}
 
-	presetType, ok := typeRaw.(string)
-	if !ok {
-		return "", nil, fmt.Errorf("invalid preset type format")
+	var kind Permissions
+	if raw, ok := data[presetKey]; ok {
+		preset, ok := raw.(string)
+		if !ok {
+			return EmptyAttenuation, fmt.Errorf("invalid preset type format")
+		}
+		kind = Permissions(preset)
+	} else {
+		for k := range data {
+			if _, known := hierarchies[Permissions(k)]; !known {
+				continue
+			}
+			if kind != "" {
+				return EmptyAttenuation, fmt.Errorf("attenuation data names both %q and %q resources", kind, k)
+			}
+			kind = Permissions(k)
+		}
 	}
 
-	return Permissions(presetType), data, nil
+	idKey := string(kind)
+	if kind == "" {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(cap, "/"), "/")
+		if !strings.HasPrefix(cap, "/") || segment == "" {
+			return EmptyAttenuation, fmt.Errorf("%w: no resource type in attenuation data", ErrUnknownPermissions)
+		}
+		kind, idKey = Permissions(segment), SubKey
+	}
+	if kind.GetCapabilities() == nil {
+		return EmptyAttenuation, fmt.Errorf("%w: %q", ErrUnknownPermissions, kind)
+	}
+
+	raw, ok := data[idKey]
+	if !ok {
+		return EmptyAttenuation, fmt.Errorf("missing %s resource id in attenuation data", kind)
+	}
+	if raw == nil && idKey == SubKey {
+		return kind.NewAttenuation(cap, "*")
+	}
+	id, ok := raw.(string)
+	if !ok {
+		return EmptyAttenuation, fmt.Errorf("expected %s resource id to be a string", kind)
+	}
+	if id == "" {
+		return EmptyAttenuation, fmt.Errorf("empty %s resource id in attenuation data", kind)
+	}
+	if idKey == SubKey && strings.HasSuffix(id, "*") {
+		return EmptyAttenuation, fmt.Errorf("subject %q is not a DID; a powerline delegation has a null subject", id)
+	}
+	return kind.NewAttenuation(cap, id)
 }
@@ -0,0 +1,325 @@
+package ucan
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// cidTag is the CBOR tag DAG-CBOR uses for CID links
+const cidTag = 42
+
+// maxSafeInteger is the largest integer a float64 holds exactly
+const maxSafeInteger = 1<<53 - 1
+
+var (
+	// dagEnc encodes deterministic DAG-CBOR: map keys sorted length-first, 64-bit floats only, no
+	// NaN or infinities and no indefinite-length items
+	dagEnc cbor.EncMode
+	// dagDec rejects the encodings DAG-CBOR forbids
+	dagDec cbor.DecMode
+)
+
+func init() {
+	var err error
+	dagEnc, err = cbor.EncOptions{
+		Sort:          cbor.SortLengthFirst,
+		ShortestFloat: cbor.ShortestFloatNone,
+		NaNConvert:    cbor.NaNConvertReject,
+		InfConvert:    cbor.InfConvertReject,
+		IndefLength:   cbor.IndefLengthForbidden,
+	}.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	dagDec, err = cbor.DecOptions{
+		DupMapKey:      cbor.DupMapKeyEnforcedAPF,
+		IndefLength:    cbor.IndefLengthForbidden,
+		IntDec:         cbor.IntDecConvertSignedOrFail,
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+		NaN:            cbor.NaNDecodeForbidden,
+		Inf:            cbor.InfDecodeForbidden,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// dagCBORCID returns the CIDv1 of DAG-CBOR encoded data
+func dagCBORCID(data []byte) (cid.Cid, error) {
+	pref := cid.Prefix{
+		Version:  1,
+		Codec:    cid.DagCBOR,
+		MhType:   mh.SHA2_256,
+		MhLength: -1,
+	}
+	return pref.Sum(data)
+}
+
+// link is a CID encoded as a DAG-CBOR link
+type link struct {
+	cid.Cid
+}
+
+// MarshalCBOR encodes the CID as tag 42 over its binary form with the identity multibase prefix
+func (l link) MarshalCBOR() ([]byte, error) {
+	if !l.Defined() {
+		return nil, errors.New("cannot encode an undefined CID")
+	}
+	return dagEnc.Marshal(cbor.Tag{Number: cidTag, Content: append([]byte{0}, l.Bytes()...)})
+}
+
+// UnmarshalCBOR decodes a tag 42 CID link
+func (l *link) UnmarshalCBOR(data []byte) error {
+	var tag cbor.Tag
+	if err := dagDec.Unmarshal(data, &tag); err != nil {
+		return err
+	}
+	c, err := linkFromTag(tag)
+	if err != nil {
+		return err
+	}
+	l.Cid = c
+	return nil
+}
+
+// linkFromTag returns the CID of a decoded tag 42 link
+func linkFromTag(tag cbor.Tag) (cid.Cid, error) {
+	if tag.Number != cidTag {
+		return cid.Undef, fmt.Errorf("unexpected CBOR tag %d, expected a CID link", tag.Number)
+	}
+	content, ok := tag.Content.([]byte)
+	if !ok || len(content) == 0 || content[0] != 0 {
+		return cid.Undef, errors.New("CID link must be a byte string with the identity multibase prefix")
+	}
+	return cid.Cast(content[1:])
+}
+
+func parseLinks(ids []string) ([]link, error) {
+	links := make([]link, len(ids))
+	for i, s := range ids {
+		c, err := cid.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		links[i] = link{c}
+	}
+	return links, nil
+}
+
+func linkStrings(links []link) []string {
+	if len(links) == 0 {
+		return nil
+	}
+	ids := make([]string, len(links))
+	for i, l := range links {
+		ids[i] = l.String()
+	}
+	return ids
+}
+
+// anyToIPLD converts a protobuf Any into a DAG-CBOR value. Well-known JSON types are converted to
+// their plain value; any other message is kept as {"@type": url, "value": bytes}.
+func anyToIPLD(a *anypb.Any) (any, error) {
+	if a == nil {
+		return nil, nil
+	}
+	msg, err := a.UnmarshalNew()
+	if err != nil {
+		return map[string]any{"@type": a.TypeUrl, "value": a.Value}, nil
+	}
+	switch m := msg.(type) {
+	case *structpb.Value:
+		return valueToIPLD(m)
+	case *structpb.Struct:
+		return valueToIPLD(structpb.NewStructValue(m))
+	case *structpb.ListValue:
+		return valueToIPLD(structpb.NewListValue(m))
+	default:
+		return map[string]any{"@type": a.TypeUrl, "value": a.Value}, nil
+	}
+}
+
+// valueToIPLD converts a JSON value into a DAG-CBOR value. Integral numbers are encoded as integers
+// and {"/": cid} objects as CID links, following the DAG-JSON conventions.
+func valueToIPLD(v *structpb.Value) (any, error) {
+	switch k := v.GetKind().(type) {
+	case nil, *structpb.Value_NullValue:
+		return nil, nil
+	case *structpb.Value_BoolValue:
+		return k.BoolValue, nil
+	case *structpb.Value_StringValue:
+		return k.StringValue, nil
+	case *structpb.Value_NumberValue:
+		n := k.NumberValue
+		if n == math.Trunc(n) && math.Abs(n) <= maxSafeInteger {
+			return int64(n), nil
+		}
+		return n, nil
+	case *structpb.Value_ListValue:
+		out := make([]any, len(k.ListValue.GetValues()))
+		for i, item := range k.ListValue.GetValues() {
+			val, err := valueToIPLD(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+	case *structpb.Value_StructValue:
+		fields := k.StructValue.GetFields()
+		if s, ok := fields["/"]; ok && len(fields) == 1 {
+			if str, ok := s.GetKind().(*structpb.Value_StringValue); ok {
+				c, err := cid.Decode(str.StringValue)
+				if err != nil {
+					return nil, fmt.Errorf("invalid link: %w", err)
+				}
+				return link{c}, nil
+			}
+		}
+		out := make(map[string]any, len(fields))
+		for key, item := range fields {
+			val, err := valueToIPLD(item)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = val
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported value kind %T", k)
+	}
+}
+
+// ipldToAny converts a decoded DAG-CBOR value back into a protobuf Any
+func ipldToAny(x any) (*anypb.Any, error) {
+	if m, ok := x.(map[string]any); ok && len(m) == 2 {
+		url, urlOK := m["@type"].(string)
+		value, valueOK := m["value"].([]byte)
+		if urlOK && valueOK {
+			return &anypb.Any{TypeUrl: url, Value: value}, nil
+		}
+	}
+	v, err := ipldToValue(x)
+	if err != nil {
+		return nil, err
+	}
+	return anypb.New(v)
+}
+
+// ipldToValue converts a decoded DAG-CBOR value into a JSON value. Byte strings become base64
+// strings and CID links become {"/": cid} objects.
+func ipldToValue(x any) (*structpb.Value, error) {
+	switch v := x.(type) {
+	case nil:
+		return structpb.NewNullValue(), nil
+	case bool:
+		return structpb.NewBoolValue(v), nil
+	case int64:
+		return structpb.NewNumberValue(float64(v)), nil
+	case uint64:
+		return structpb.NewNumberValue(float64(v)), nil
+	case float64:
+		return structpb.NewNumberValue(v), nil
+	case string:
+		return structpb.NewStringValue(v), nil
+	case []byte:
+		return structpb.NewStringValue(base64.StdEncoding.EncodeToString(v)), nil
+	case cbor.Tag:
+		c, err := linkFromTag(v)
+		if err != nil {
+			return nil, err
+		}
+		return structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"/": structpb.NewStringValue(c.String()),
+		}}), nil
+	case []any:
+		list := &structpb.ListValue{Values: make([]*structpb.Value, len(v))}
+		for i, item := range v {
+			val, err := ipldToValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list.Values[i] = val
+		}
+		return structpb.NewListValue(list), nil
+	case map[string]any:
+		st := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(v))}
+		for key, item := range v {
+			val, err := ipldToValue(item)
+			if err != nil {
+				return nil, err
+			}
+			st.Fields[key] = val
+		}
+		return structpb.NewStructValue(st), nil
+	default:
+		return nil, fmt.Errorf("unsupported DAG-CBOR value %T", x)
+	}
+}
+
+// argumentsToIPLD converts invocation arguments into a DAG-CBOR map
+func argumentsToIPLD(args map[string]*anypb.Any) (map[string]any, error) {
+	out := make(map[string]any, len(args))
+	for key, a := range args {
+		v, err := anyToIPLD(a)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", key, err)
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// argumentsFromIPLD converts a decoded DAG-CBOR map into invocation arguments
+func argumentsFromIPLD(args map[string]any) (map[string]*anypb.Any, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	out := make(map[string]*anypb.Any, len(args))
+	for key, v := range args {
+		a, err := ipldToAny(v)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", key, err)
+		}
+		out[key] = a
+	}
+	return out, nil
+}
+
+// ArgumentValue unwraps an invocation argument into a plain Go value, as produced by
+// structpb.Value.AsInterface. Arguments that are not JSON values are returned as proto messages.
+func ArgumentValue(a *anypb.Any) (any, error) {
+	msg, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+	switch m := msg.(type) {
+	case *structpb.Value:
+		return m.AsInterface(), nil
+	case *structpb.Struct:
+		return m.AsMap(), nil
+	case *structpb.ListValue:
+		return m.AsSlice(), nil
+	default:
+		return proto.Message(m), nil
+	}
+}
+
+// NewArgument wraps a plain Go value as an invocation argument
+func NewArgument(v any) (*anypb.Any, error) {
+	val, err := structpb.NewValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return anypb.New(val)
+}
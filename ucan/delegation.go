@@ -0,0 +1,457 @@
+package ucan
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"google.golang.org/protobuf/proto"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+// nonceSize is the length of the random nonce given to envelopes created without one
+const nonceSize = 12
+
+// ErrInvalidCommand is returned when a command is not an absolute, lowercase, slash separated path
+var ErrInvalidCommand = errors.New("invalid ucan command")
+
+// Delegation is a UCAN 1.0 delegation envelope with its decoded payload
+type Delegation struct {
+	*Envelope
+	Claims *ucanv1.DelegationPayload
+}
+
+// delegationWire is the DAG-CBOR layout of a delegation payload
+type delegationWire struct {
+	Iss   string            `cbor:"iss"`
+	Aud   string            `cbor:"aud"`
+	Sub   *string           `cbor:"sub"`
+	Cmd   string            `cbor:"cmd"`
+	Pol   []any             `cbor:"pol"`
+	Nonce []byte            `cbor:"nonce"`
+	Meta  map[string][]byte `cbor:"meta,omitempty"`
+	Nbf   int64             `cbor:"nbf,omitempty"`
+	Exp   *int64            `cbor:"exp"`
+}
+
+// NewDelegation signs a delegation of payload with signer. The issuer defaults to the signer and a
+// random nonce is generated when none is given. An empty subject creates a powerline delegation
+// that applies to any subject the issuer holds authority over.
+func NewDelegation(signer Signer, payload *ucanv1.DelegationPayload) (*Delegation, error) {
+	claims := proto.Clone(payload).(*ucanv1.DelegationPayload)
+	if err := fillIssuer(signer, &claims.Issuer); err != nil {
+		return nil, err
+	}
+	if claims.Audience == "" {
+		return nil, errors.New("delegation audience is required")
+	}
+	if len(claims.ProofCids) > 0 {
+		return nil, errors.New("delegations carry no proofs, the delegation chain is listed by invocations")
+	}
+	if err := validateCommand(claims.Command); err != nil {
+		return nil, err
+	}
+	if err := fillNonce(&claims.Nonce); err != nil {
+		return nil, err
+	}
+
+	pol, err := encodePolicy(claims.Policy)
+	if err != nil {
+		return nil, err
+	}
+	wire := delegationWire{
+		Iss:   claims.Issuer,
+		Aud:   claims.Audience,
+		Cmd:   claims.Command,
+		Pol:   pol,
+		Nonce: claims.Nonce,
+		Meta:  claims.Meta,
+		Nbf:   claims.NotBefore,
+		Exp:   optionalUnix(claims.Expiration),
+	}
+	if claims.Subject != "" {
+		wire.Sub = &claims.Subject
+	}
+	env, err := sealEnvelope(signer, DelegationTag, wire)
+	if err != nil {
+		return nil, err
+	}
+	return &Delegation{Envelope: env, Claims: claims}, nil
+}
+
+// DecodeDelegation decodes a delegation envelope without verifying its signature
+func DecodeDelegation(data []byte) (*Delegation, error) {
+	env, err := DecodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	var wire delegationWire
+	if err := env.decodePayload(DelegationTag, &wire); err != nil {
+		return nil, err
+	}
+	pol, err := decodePolicy(wire.Pol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: pol: %v", ErrInvalidEnvelope, err)
+	}
+	claims := &ucanv1.DelegationPayload{
+		Issuer:    wire.Iss,
+		Audience:  wire.Aud,
+		Command:   wire.Cmd,
+		Policy:    pol,
+		Nonce:     wire.Nonce,
+		Meta:      wire.Meta,
+		NotBefore: wire.Nbf,
+	}
+	if wire.Sub != nil {
+		if *wire.Sub == "" {
+			return nil, fmt.Errorf("%w: sub must be a DID or null", ErrInvalidEnvelope)
+		}
+		claims.Subject = *wire.Sub
+	}
+	if wire.Exp != nil {
+		claims.Expiration = *wire.Exp
+	}
+	return &Delegation{Envelope: env, Claims: claims}, nil
+}
+
+// IsPowerline reports whether the delegation applies to any subject
+func (d *Delegation) IsPowerline() bool {
+	return d.Claims.Subject == ""
+}
+
+// ParseDelegation decodes a delegation envelope, verifies the issuer signature and checks that the
+// delegation is currently valid and has not been revoked. Delegations carry no proofs: the chain
+// they belong to is validated when an invocation uses them.
+func (p *TokenParser) ParseDelegation(ctx context.Context, data []byte) (*Delegation, error) {
+	d, err := DecodeDelegation(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verifyEnvelope(ctx, d.Envelope, d.Claims.Issuer, d.Claims.NotBefore, d.Claims.Expiration); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// verifyEnvelope checks the signature, revocation status and time bounds of an envelope
+func (p *TokenParser) verifyEnvelope(ctx context.Context, env *Envelope, issuer string, nbf, exp int64) error {
+	id, err := env.CID()
+	if err != nil {
+		return err
+	}
+	if err := p.checkRevoked(ctx, id); err != nil {
+		return err
+	}
+	if _, err := env.verifySignature(ctx, p.didr, issuer); err != nil {
+		return err
+	}
+	return checkTimeBounds(nbf, exp, p.now())
+}
+
+// checkRevoked fails if the parser's revocation checker lists id
+func (p *TokenParser) checkRevoked(ctx context.Context, id cid.Cid) error {
+	if p.revoked == nil {
+		return nil
+	}
+	revoked, err := p.revoked.IsRevoked(ctx, id)
+	if err != nil {
+		return fmt.Errorf("checking revocation: %w", err)
+	}
+	if revoked {
+		return ErrRevoked
+	}
+	return nil
+}
+
+// delegationToken returns the 0.7 token view of a verified delegation, so callers of
+// ParseAndVerify can consume both formats during migration. The command and subject become a
+// single attenuation, with a nil subject standing for the subject of a powerline delegation.
+func (p *TokenParser) delegationToken(ctx context.Context, d *Delegation) (*Token, error) {
+	iss, err := p.didr.ResolveDIDKey(ctx, d.Claims.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	aud, err := p.didr.ResolveDIDKey(ctx, d.Claims.Audience)
+	if err != nil {
+		return nil, err
+	}
+	var sub any
+	if !d.IsPowerline() {
+		sub = d.Claims.Subject
+	}
+	att, err := p.ap(map[string]any{CapKey: d.Claims.Command, SubKey: sub})
+	if err != nil {
+		return nil, err
+	}
+	return &Token{
+		Raw:          string(d.Raw),
+		Issuer:       iss,
+		Audience:     aud,
+		Attenuations: Attenuations{att},
+		NotBefore:    d.Claims.NotBefore,
+		ExpiresAt:    d.Claims.Expiration,
+	}, nil
+}
+
+// validateCommand checks that cmd is "/" or a slash separated path of lowercase segments without a
+// trailing slash, e.g. "/vault/sign"
+func validateCommand(cmd string) error {
+	if cmd == "/" {
+		return nil
+	}
+	if !strings.HasPrefix(cmd, "/") || strings.HasSuffix(cmd, "/") {
+		return fmt.Errorf("%w: %q must start and not end with a slash", ErrInvalidCommand, cmd)
+	}
+	for _, seg := range strings.Split(cmd[1:], "/") {
+		if seg == "" || seg != strings.ToLower(seg) {
+			return fmt.Errorf("%w: %q must be made of non-empty lowercase segments", ErrInvalidCommand, cmd)
+		}
+	}
+	return nil
+}
+
+// commandCovers reports whether a delegation of cmd grants invoking sub, which must be cmd itself
+// or one of its sub-commands
+func commandCovers(cmd, sub string) bool {
+	return cmd == "/" || sub == cmd || strings.HasPrefix(sub, cmd+"/")
+}
+
+// checkTimeBounds fails if now is outside [nbf, exp), a zero bound is unbounded
+func checkTimeBounds(nbf, exp int64, now time.Time) error {
+	if nbf != 0 && now.Unix() < nbf {
+		return fmt.Errorf("%w: not before %d", ErrNotValidYet, nbf)
+	}
+	if exp != 0 && now.Unix() >= exp {
+		return fmt.Errorf("%w: expired at %d", ErrExpired, exp)
+	}
+	return nil
+}
+
+func fillIssuer(signer Signer, issuer *string) error {
+	if *issuer == "" {
+		*issuer = signer.Issuer()
+	} else if *issuer != signer.Issuer() {
+		return fmt.Errorf("issuer %s does not match signer %s", *issuer, signer.Issuer())
+	}
+	return nil
+}
+
+func fillNonce(nonce *[]byte) error {
+	if len(*nonce) > 0 {
+		return nil
+	}
+	*nonce = make([]byte, nonceSize)
+	_, err := rand.Read(*nonce)
+	return err
+}
+
+// optionalUnix encodes a zero timestamp as null
+func optionalUnix(t int64) *int64 {
+	if t == 0 {
+		return nil
+	}
+	return &t
+}
+
+var comparisonOps = map[ucanv1.ComparisonOperator]string{
+	ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL:                 "==",
+	ucanv1.ComparisonOperator_COMPARISON_OPERATOR_NOT_EQUAL:             "!=",
+	ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN:          ">",
+	ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN_OR_EQUAL: ">=",
+	ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN:             "<",
+	ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN_OR_EQUAL:    "<=",
+}
+
+var connectiveOps = map[ucanv1.ConnectiveOperator]string{
+	ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_AND: "and",
+	ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_OR:  "or",
+}
+
+var quantifierOps = map[ucanv1.QuantifierOperator]string{
+	ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ALL: "all",
+	ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ANY: "any",
+}
+
+// encodePolicy converts policy statements to the UCAN 1.0 array form, e.g. ["==", ".a", 1],
+// ["and", [...]], ["not", s], ["all", ".xs", s] and ["like", ".email", "*@example.com"]
+func encodePolicy(pol []*ucanv1.PolicyStatement) ([]any, error) {
+	out := make([]any, len(pol))
+	for i, st := range pol {
+		enc, err := encodeStatement(st)
+		if err != nil {
+			return nil, fmt.Errorf("policy statement %d: %w", i, err)
+		}
+		out[i] = enc
+	}
+	return out, nil
+}
+
+func encodeStatement(st *ucanv1.PolicyStatement) ([]any, error) {
+	switch s := st.GetStatement().(type) {
+	case *ucanv1.PolicyStatement_Comparison:
+		op, ok := comparisonOps[s.Comparison.GetOperator()]
+		if !ok {
+			return nil, fmt.Errorf("unknown comparison operator %s", s.Comparison.GetOperator())
+		}
+		val, err := valueToIPLD(s.Comparison.GetValue())
+		if err != nil {
+			return nil, err
+		}
+		return []any{op, s.Comparison.GetSelector(), val}, nil
+	case *ucanv1.PolicyStatement_Connective:
+		op, ok := connectiveOps[s.Connective.GetOperator()]
+		if !ok {
+			return nil, fmt.Errorf("unknown connective operator %s", s.Connective.GetOperator())
+		}
+		inner, err := encodePolicy(s.Connective.GetStatements())
+		if err != nil {
+			return nil, err
+		}
+		return []any{op, inner}, nil
+	case *ucanv1.PolicyStatement_Negation:
+		inner, err := encodeStatement(s.Negation.GetStatement())
+		if err != nil {
+			return nil, err
+		}
+		return []any{"not", inner}, nil
+	case *ucanv1.PolicyStatement_Quantifier:
+		op, ok := quantifierOps[s.Quantifier.GetOperator()]
+		if !ok {
+			return nil, fmt.Errorf("unknown quantifier operator %s", s.Quantifier.GetOperator())
+		}
+		inner, err := encodeStatement(s.Quantifier.GetStatement())
+		if err != nil {
+			return nil, err
+		}
+		return []any{op, s.Quantifier.GetSelector(), inner}, nil
+	case *ucanv1.PolicyStatement_Pattern:
+		if s.Pattern.GetOperator() != ucanv1.PatternOperator_PATTERN_OPERATOR_LIKE {
+			return nil, fmt.Errorf("unknown pattern operator %s", s.Pattern.GetOperator())
+		}
+		return []any{"like", s.Pattern.GetSelector(), s.Pattern.GetPattern()}, nil
+	default:
+		return nil, errors.New("empty policy statement")
+	}
+}
+
+// decodePolicy converts the UCAN 1.0 array form back to policy statements
+func decodePolicy(pol []any) ([]*ucanv1.PolicyStatement, error) {
+	if len(pol) == 0 {
+		return nil, nil
+	}
+	out := make([]*ucanv1.PolicyStatement, len(pol))
+	for i, raw := range pol {
+		st, err := decodeStatement(raw)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i, err)
+		}
+		out[i] = st
+	}
+	return out, nil
+}
+
+func decodeStatement(raw any) (*ucanv1.PolicyStatement, error) {
+	arr, ok := raw.([]any)
+	if !ok || len(arr) < 2 {
+		return nil, errors.New("statement must be an array of an operator and its operands")
+	}
+	op, ok := arr[0].(string)
+	if !ok {
+		return nil, errors.New("statement operator must be a string")
+	}
+	for cmp, name := range comparisonOps {
+		if op != name {
+			continue
+		}
+		sel, err := selectorOperand(arr, 3)
+		if err != nil {
+			return nil, err
+		}
+		val, err := ipldToValue(arr[2])
+		if err != nil {
+			return nil, err
+		}
+		return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Comparison{
+			Comparison: &ucanv1.ComparisonStatement{Operator: cmp, Selector: sel, Value: val},
+		}}, nil
+	}
+	for conn, name := range connectiveOps {
+		if op != name {
+			continue
+		}
+		inner, ok := arr[1].([]any)
+		if !ok || len(arr) != 2 {
+			return nil, fmt.Errorf("%q takes a single array of statements", op)
+		}
+		stmts, err := decodePolicy(inner)
+		if err != nil {
+			return nil, err
+		}
+		return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Connective{
+			Connective: &ucanv1.ConnectiveStatement{Operator: conn, Statements: stmts},
+		}}, nil
+	}
+	for q, name := range quantifierOps {
+		if op != name {
+			continue
+		}
+		sel, err := selectorOperand(arr, 3)
+		if err != nil {
+			return nil, err
+		}
+		inner, err := decodeStatement(arr[2])
+		if err != nil {
+			return nil, err
+		}
+		return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Quantifier{
+			Quantifier: &ucanv1.QuantifierStatement{Operator: q, Selector: sel, Statement: inner},
+		}}, nil
+	}
+	switch op {
+	case "not":
+		if len(arr) != 2 {
+			return nil, errors.New(`"not" takes a single statement`)
+		}
+		inner, err := decodeStatement(arr[1])
+		if err != nil {
+			return nil, err
+		}
+		return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Negation{
+			Negation: &ucanv1.NegationStatement{Statement: inner},
+		}}, nil
+	case "like":
+		sel, err := selectorOperand(arr, 3)
+		if err != nil {
+			return nil, err
+		}
+		pattern, ok := arr[2].(string)
+		if !ok {
+			return nil, errors.New(`"like" pattern must be a string`)
+		}
+		return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Pattern{
+			Pattern: &ucanv1.PatternStatement{
+				Operator: ucanv1.PatternOperator_PATTERN_OPERATOR_LIKE,
+				Selector: sel,
+				Pattern:  pattern,
+			},
+		}}, nil
+	}
+	return nil, fmt.Errorf("unknown policy operator %q", op)
+}
+
+// selectorOperand returns the selector of a statement with n elements
+func selectorOperand(arr []any, n int) (string, error) {
+	if len(arr) != n {
+		return "", fmt.Errorf("%q takes %d operands", arr[0], n-1)
+	}
+	sel, ok := arr[1].(string)
+	if !ok {
+		return "", fmt.Errorf("%q selector must be a string", arr[0])
+	}
+	return sel, nil
+}
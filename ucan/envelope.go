@@ -0,0 +1,378 @@
+package ucan
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	btcecdsa "github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	varint "github.com/multiformats/go-varint"
+	"github.com/sonr-io/crypto/keys"
+)
+
+const (
+	// UCANSpecVersion is the version of the UCAN 1.0 envelope format
+	UCANSpecVersion = "1.0.0-rc.1"
+	// DelegationTag identifies a delegation payload inside an envelope
+	DelegationTag = "ucan/dlg@" + UCANSpecVersion
+	// InvocationTag identifies an invocation payload inside an envelope
+	InvocationTag = "ucan/inv@" + UCANSpecVersion
+	// ReceiptTag identifies a receipt payload inside an envelope
+	ReceiptTag = "ucan/rct@" + UCANSpecVersion
+
+	// varsigHeaderKey is the signature payload key holding the varsig header
+	varsigHeaderKey = "h"
+	// varsigPrefix is the multicodec of a varsig header
+	varsigPrefix = 0x34
+	// codecSHA256 and codecSHA512 are the multicodecs of the hash a varsig signs over
+	codecSHA256 = 0x12
+	codecSHA512 = 0x13
+)
+
+var (
+	// ErrInvalidEnvelope is returned when data is not a well formed UCAN 1.0 envelope
+	ErrInvalidEnvelope = errors.New("invalid ucan envelope")
+	// ErrInvalidSignature is returned when an envelope signature does not verify against its issuer
+	ErrInvalidSignature = errors.New("invalid ucan signature")
+	// ErrNotValidYet is returned when a token is used before its not before time
+	ErrNotValidYet = errors.New("token is not valid yet")
+	// ErrExpired is returned when a token is used after it expired
+	ErrExpired = errors.New("token has expired")
+)
+
+// VarsigAlgorithm is the multicodec of the key type that signed an envelope
+type VarsigAlgorithm uint64
+
+const (
+	// VarsigEd25519 is an Ed25519 signature over the payload
+	VarsigEd25519 VarsigAlgorithm = 0xed
+	// VarsigES256K is a 64 byte r || s secp256k1 signature over the SHA-256 of the payload
+	VarsigES256K VarsigAlgorithm = 0xe7
+	// VarsigRS256 is an RSASSA-PKCS1-v1_5 signature over the SHA-256 of the payload
+	VarsigRS256 VarsigAlgorithm = 0x1205
+)
+
+func (alg VarsigAlgorithm) String() string {
+	switch alg {
+	case VarsigEd25519:
+		return "EdDSA"
+	case VarsigES256K:
+		return "ES256K"
+	case VarsigRS256:
+		return "RS256"
+	default:
+		return fmt.Sprintf("varsig(%#x)", uint64(alg))
+	}
+}
+
+func (alg VarsigAlgorithm) hashCodec() (uint64, error) {
+	switch alg {
+	case VarsigEd25519:
+		return codecSHA512, nil
+	case VarsigES256K, VarsigRS256:
+		return codecSHA256, nil
+	default:
+		return 0, fmt.Errorf("unsupported signature algorithm %s", alg)
+	}
+}
+
+// header returns the varsig header of the algorithm: the varsig prefix, the key codec, the hash
+// codec and the DAG-CBOR payload encoding, each as an unsigned varint
+func (alg VarsigAlgorithm) header() ([]byte, error) {
+	hash, err := alg.hashCodec()
+	if err != nil {
+		return nil, err
+	}
+	var h []byte
+	for _, v := range []uint64{varsigPrefix, uint64(alg), hash, cid.DagCBOR} {
+		h = append(h, varint.ToUvarint(v)...)
+	}
+	return h, nil
+}
+
+// parseVarsigHeader returns the algorithm of a varsig header
+func parseVarsigHeader(h []byte) (VarsigAlgorithm, error) {
+	var fields [4]uint64
+	for i := range fields {
+		v, n, err := varint.FromUvarint(h)
+		if err != nil {
+			return 0, fmt.Errorf("%w: varsig header: %v", ErrInvalidEnvelope, err)
+		}
+		fields[i] = v
+		h = h[n:]
+	}
+	if len(h) != 0 || fields[0] != varsigPrefix || fields[3] != cid.DagCBOR {
+		return 0, fmt.Errorf("%w: unsupported varsig header", ErrInvalidEnvelope)
+	}
+	alg := VarsigAlgorithm(fields[1])
+	hash, err := alg.hashCodec()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if hash != fields[2] {
+		return 0, fmt.Errorf("%w: %s signatures must use hash %#x", ErrInvalidEnvelope, alg, hash)
+	}
+	return alg, nil
+}
+
+// Signer signs UCAN 1.0 envelopes
+type Signer interface {
+	// Issuer returns the DID envelopes are issued by
+	Issuer() string
+	// Algorithm returns the signature algorithm of the signer
+	Algorithm() VarsigAlgorithm
+	// Sign returns the signature of payload in the encoding of Algorithm
+	Sign(payload []byte) ([]byte, error)
+}
+
+type privKeySigner struct {
+	pk        crypto.PrivKey
+	alg       VarsigAlgorithm
+	issuerDID string
+}
+
+// NewPrivKeySigner creates an envelope signer backed by a single Ed25519, secp256k1 or RSA private
+// key. The issuer is the did:key of the public key.
+func NewPrivKeySigner(privKey crypto.PrivKey) (Signer, error) {
+	var alg VarsigAlgorithm
+	switch privKey.Type() {
+	case crypto.Ed25519:
+		alg = VarsigEd25519
+	case crypto.Secp256k1:
+		alg = VarsigES256K
+	case crypto.RSA:
+		alg = VarsigRS256
+	default:
+		return nil, fmt.Errorf("unsupported key type for envelope signing: %q", privKey.Type())
+	}
+	issuerDID, err := DIDStringFromPublicKey(privKey.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	return &privKeySigner{pk: privKey, alg: alg, issuerDID: issuerDID}, nil
+}
+
+func (s *privKeySigner) Issuer() string             { return s.issuerDID }
+func (s *privKeySigner) Algorithm() VarsigAlgorithm { return s.alg }
+
+func (s *privKeySigner) Sign(payload []byte) ([]byte, error) {
+	if s.alg != VarsigES256K {
+		// libp2p signs Ed25519 over the payload and RSA with PKCS#1 v1.5 over its SHA-256
+		return s.pk.Sign(payload)
+	}
+	// libp2p produces DER secp256k1 signatures, envelopes carry the compact r || s form
+	raw, err := s.pk.Raw()
+	if err != nil {
+		return nil, err
+	}
+	priv, _ := btcec.PrivKeyFromBytes(raw)
+	digest := sha256.Sum256(payload)
+	compact := btcecdsa.SignCompact(priv, digest[:], true)
+	return compact[1:], nil
+}
+
+// Envelope is a signed UCAN 1.0 container: a DAG-CBOR array of the signature and the signature
+// payload {"h": varsig header, <tag>: payload}
+type Envelope struct {
+	// Raw is the DAG-CBOR encoding of the whole envelope
+	Raw []byte
+	// Signature is the issuer's signature over the signature payload
+	Signature []byte
+	// Algorithm is the signature algorithm named by the varsig header
+	Algorithm VarsigAlgorithm
+	// Tag identifies the payload type, one of DelegationTag, InvocationTag or ReceiptTag
+	Tag string
+	// Payload is the DAG-CBOR encoded payload
+	Payload cbor.RawMessage
+
+	sigPayload []byte
+}
+
+// envelopeWire is the DAG-CBOR layout of an envelope
+type envelopeWire struct {
+	_          struct{} `cbor:",toarray"`
+	Signature  []byte
+	SigPayload cbor.RawMessage
+}
+
+// IsEnvelope reports whether data looks like a UCAN 1.0 envelope rather than a 0.7 JWT. It only
+// checks the leading byte, which is a two element CBOR array for envelopes.
+func IsEnvelope(data []byte) bool {
+	return len(data) > 0 && data[0] == 0x82
+}
+
+// CID returns the DAG-CBOR CID of the envelope
+func (e *Envelope) CID() (cid.Cid, error) {
+	return dagCBORCID(e.Raw)
+}
+
+// sealEnvelope encodes payload under tag and signs it with signer
+func sealEnvelope(signer Signer, tag string, payload any) (*Envelope, error) {
+	payloadBytes, err := dagEnc.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s payload: %w", tag, err)
+	}
+	alg := signer.Algorithm()
+	header, err := alg.header()
+	if err != nil {
+		return nil, err
+	}
+	headerBytes, err := dagEnc.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	sigPayload, err := dagEnc.Marshal(map[string]cbor.RawMessage{
+		varsigHeaderKey: headerBytes,
+		tag:             payloadBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(sigPayload)
+	if err != nil {
+		return nil, fmt.Errorf("signing envelope: %w", err)
+	}
+	raw, err := dagEnc.Marshal(envelopeWire{Signature: sig, SigPayload: sigPayload})
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		Raw:        raw,
+		Signature:  sig,
+		Algorithm:  alg,
+		Tag:        tag,
+		Payload:    payloadBytes,
+		sigPayload: sigPayload,
+	}, nil
+}
+
+// DecodeEnvelope decodes a UCAN 1.0 envelope without verifying its signature. The envelope must be
+// in canonical DAG-CBOR, so that a token has a single encoding and thus a single CID, which
+// revocations refer to.
+func DecodeEnvelope(data []byte) (*Envelope, error) {
+	if err := checkCanonical(data); err != nil {
+		return nil, err
+	}
+	var wire envelopeWire
+	if err := dagDec.Unmarshal(data, &wire); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	var fields map[string]cbor.RawMessage
+	if err := dagDec.Unmarshal(wire.SigPayload, &fields); err != nil {
+		return nil, fmt.Errorf("%w: signature payload: %v", ErrInvalidEnvelope, err)
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("%w: signature payload must hold a header and a single payload", ErrInvalidEnvelope)
+	}
+	var header []byte
+	if err := dagDec.Unmarshal(fields[varsigHeaderKey], &header); err != nil {
+		return nil, fmt.Errorf("%w: varsig header: %v", ErrInvalidEnvelope, err)
+	}
+	alg, err := parseVarsigHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	env := &Envelope{
+		Raw:        data,
+		Signature:  wire.Signature,
+		Algorithm:  alg,
+		sigPayload: wire.SigPayload,
+	}
+	for key, payload := range fields {
+		if key != varsigHeaderKey {
+			env.Tag, env.Payload = key, payload
+		}
+	}
+	return env, nil
+}
+
+// checkCanonical checks that data is the canonical DAG-CBOR encoding of the value it holds
+func checkCanonical(data []byte) error {
+	var v any
+	if err := dagDec.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	canonical, err := dagEnc.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+	if !bytes.Equal(data, canonical) {
+		return fmt.Errorf("%w: not canonical DAG-CBOR", ErrInvalidEnvelope)
+	}
+	return nil
+}
+
+// decodePayload decodes the payload of an envelope carrying tag
+func (e *Envelope) decodePayload(tag string, v any) error {
+	if e.Tag != tag {
+		return fmt.Errorf("%w: expected a %s payload, got %q", ErrInvalidEnvelope, tag, e.Tag)
+	}
+	if err := dagDec.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("%w: %s payload: %v", ErrInvalidEnvelope, tag, err)
+	}
+	return nil
+}
+
+// verifySignature resolves the issuer DID and checks the envelope signature against its key
+func (e *Envelope) verifySignature(ctx context.Context, didr DIDPubKeyResolver, issuer string) (keys.DID, error) {
+	id, err := didr.ResolveDIDKey(ctx, issuer)
+	if err != nil {
+		return keys.DID{}, fmt.Errorf("resolving issuer %s: %w", issuer, err)
+	}
+	if err := verifyVarsig(id, e.Algorithm, e.sigPayload, e.Signature); err != nil {
+		return keys.DID{}, err
+	}
+	return id, nil
+}
+
+// verifyVarsig checks sig over payload with the public key of id
+func verifyVarsig(id keys.DID, alg VarsigAlgorithm, payload, sig []byte) error {
+	if id.PubKey == nil {
+		return fmt.Errorf("%w: issuer has no public key", ErrInvalidSignature)
+	}
+	var valid bool
+	switch {
+	case alg == VarsigEd25519 && id.Type() == crypto.Ed25519,
+		alg == VarsigRS256 && id.Type() == crypto.RSA:
+		ok, err := id.Verify(payload, sig)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+		valid = ok
+	case alg == VarsigES256K && id.Type() == crypto.Secp256k1:
+		raw, err := id.Raw()
+		if err != nil {
+			return err
+		}
+		pub, err := btcec.ParsePubKey(raw)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+		if len(sig) != 64 {
+			return fmt.Errorf("%w: ES256K signatures must be 64 bytes", ErrInvalidSignature)
+		}
+		var r, s btcec.ModNScalar
+		if r.SetByteSlice(sig[:32]) || s.SetByteSlice(sig[32:]) {
+			return fmt.Errorf("%w: signature scalar overflows the group order", ErrInvalidSignature)
+		}
+		// (r, n - s) verifies as well as (r, s), only the low-S form is accepted so that a signature
+		// cannot be malleated into another token CID
+		if s.IsOverHalfOrder() {
+			return fmt.Errorf("%w: ES256K signature is not in low-S form", ErrInvalidSignature)
+		}
+		digest := sha256.Sum256(payload)
+		valid = btcecdsa.NewSignature(&r, &s).Verify(digest[:], pub)
+	default:
+		return fmt.Errorf("%w: %s header does not match %s issuer key", ErrInvalidSignature, alg, id.Type())
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
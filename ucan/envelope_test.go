@@ -0,0 +1,443 @@
+package ucan
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+func newTestSigner(t *testing.T, typ int) Signer {
+	t.Helper()
+	priv, _, err := crypto.GenerateKeyPairWithReader(typ, 2048, rand.Reader)
+	require.NoError(t, err)
+	signer, err := NewPrivKeySigner(priv)
+	require.NoError(t, err)
+	return signer
+}
+
+// storeEnvelope puts an envelope in store under its CID and returns the CID string
+func storeEnvelope(t *testing.T, store TokenStore, env *Envelope) string {
+	t.Helper()
+	id, err := env.CID()
+	require.NoError(t, err)
+	require.NoError(t, store.PutToken(context.Background(), id.String(), string(env.Raw)))
+	return id.String()
+}
+
+func newEnvelopeParser(store TokenStore, opts ...ParserOption) *TokenParser {
+	ac := func(m map[string]any) (Attenuation, error) {
+		sub, ok := m[SubKey].(string)
+		if !ok {
+			sub = "*"
+		}
+		return Attenuation{Cap: testCaps.Cap("read"), Rsc: testResource(sub)}, nil
+	}
+	return NewTokenParser(ac, StringDIDPubKeyResolver{}, store.(CIDBytesResolver), opts...)
+}
+
+func testPolicy(t *testing.T) []*ucanv1.PolicyStatement {
+	t.Helper()
+	limit, err := structpb.NewValue(100)
+	require.NoError(t, err)
+	return []*ucanv1.PolicyStatement{
+		{Statement: &ucanv1.PolicyStatement_Comparison{Comparison: &ucanv1.ComparisonStatement{
+			Operator: ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN_OR_EQUAL,
+			Selector: ".amount",
+			Value:    limit,
+		}}},
+		{Statement: &ucanv1.PolicyStatement_Connective{Connective: &ucanv1.ConnectiveStatement{
+			Operator: ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_OR,
+			Statements: []*ucanv1.PolicyStatement{
+				{Statement: &ucanv1.PolicyStatement_Pattern{Pattern: &ucanv1.PatternStatement{
+					Operator: ucanv1.PatternOperator_PATTERN_OPERATOR_LIKE,
+					Selector: ".to",
+					Pattern:  "*@example.com",
+				}}},
+				{Statement: &ucanv1.PolicyStatement_Negation{Negation: &ucanv1.NegationStatement{
+					Statement: &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Quantifier{
+						Quantifier: &ucanv1.QuantifierStatement{
+							Operator: ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ANY,
+							Selector: ".cc",
+							Statement: &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Comparison{
+								Comparison: &ucanv1.ComparisonStatement{
+									Operator: ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL,
+									Selector: ".",
+									Value:    structpb.NewStringValue("root"),
+								},
+							}},
+						},
+					}},
+				}}},
+			},
+		}}},
+	}
+}
+
+func TestDelegation_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for name, typ := range map[string]int{"Ed25519": crypto.Ed25519, "Secp256k1": crypto.Secp256k1, "RSA": crypto.RSA} {
+		t.Run(name, func(t *testing.T) {
+			signer, aud := newTestSigner(t, typ), newTestParty(t)
+			d, err := NewDelegation(signer, &ucanv1.DelegationPayload{
+				Audience:   aud.did,
+				Subject:    signer.Issuer(),
+				Command:    "/vault/sign",
+				Policy:     testPolicy(t),
+				Meta:       map[string][]byte{"note": []byte("hi")},
+				Expiration: time.Now().Add(time.Hour).Unix(),
+			})
+			require.NoError(t, err)
+			require.Len(t, d.Claims.Nonce, nonceSize)
+			require.True(t, IsEnvelope(d.Raw))
+
+			id, err := d.CID()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(cid.DagCBOR), id.Prefix().Codec)
+
+			parsed, err := newEnvelopeParser(NewMemTokenStore()).ParseDelegation(ctx, d.Raw)
+			require.NoError(t, err)
+			assert.True(t, proto.Equal(d.Claims, parsed.Claims), "decoded %v\nwant %v", parsed.Claims, d.Claims)
+			assert.Equal(t, signer.Algorithm(), parsed.Algorithm)
+
+			// re-encoding the decoded payload is byte for byte identical
+			again, err := NewDelegation(signer, parsed.Claims)
+			require.NoError(t, err)
+			assert.Equal(t, d.Payload, again.Payload)
+		})
+	}
+}
+
+func TestDelegation_Tampered(t *testing.T) {
+	ctx := context.Background()
+	signer, other := newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Ed25519)
+	payload := &ucanv1.DelegationPayload{Audience: other.Issuer(), Subject: signer.Issuer(), Command: "/"}
+	d, err := NewDelegation(signer, payload)
+	require.NoError(t, err)
+
+	forged := bytes.Replace(d.Raw, []byte(`/`), []byte(`*`), 1)
+	_, err = newEnvelopeParser(NewMemTokenStore()).ParseDelegation(ctx, forged)
+	require.Error(t, err)
+
+	// a valid signature by a different key does not verify for the claimed issuer
+	env, err := sealEnvelope(other, DelegationTag, delegationWire{Iss: signer.Issuer(), Aud: other.Issuer(), Cmd: "/", Pol: []any{}, Nonce: []byte{1}})
+	require.NoError(t, err)
+	_, err = newEnvelopeParser(NewMemTokenStore()).ParseDelegation(ctx, env.Raw)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// an empty subject is neither a DID nor the null subject of a powerline delegation
+	empty := ""
+	env, err = sealEnvelope(signer, DelegationTag, delegationWire{Iss: signer.Issuer(), Aud: other.Issuer(), Sub: &empty, Cmd: "/", Pol: []any{}, Nonce: []byte{1}})
+	require.NoError(t, err)
+	_, err = DecodeDelegation(env.Raw)
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	_, err = NewDelegation(signer, &ucanv1.DelegationPayload{Audience: other.Issuer(), Command: "/Vault/"})
+	require.ErrorIs(t, err, ErrInvalidCommand)
+	_, err = NewDelegation(signer, &ucanv1.DelegationPayload{Issuer: other.Issuer(), Audience: other.Issuer(), Command: "/"})
+	require.Error(t, err)
+}
+
+func TestDelegation_MalleatedRevoked(t *testing.T) {
+	ctx := context.Background()
+	for name, typ := range map[string]int{"Ed25519": crypto.Ed25519, "Secp256k1": crypto.Secp256k1} {
+		t.Run(name, func(t *testing.T) {
+			signer, aud := newTestSigner(t, typ), newTestSigner(t, crypto.Ed25519)
+			d, err := NewDelegation(signer, &ucanv1.DelegationPayload{Audience: aud.Issuer(), Subject: signer.Issuer(), Command: "/"})
+			require.NoError(t, err)
+			id, err := d.CID()
+			require.NoError(t, err)
+			revoked := RevocationCheckerFunc(func(_ context.Context, c cid.Cid) (bool, error) { return c.Equals(id), nil })
+			parser := newEnvelopeParser(NewMemTokenStore(), WithRevocationChecker(revoked))
+			_, err = parser.ParseDelegation(ctx, d.Raw)
+			require.ErrorIs(t, err, ErrRevoked)
+
+			malleated := [][]byte{
+				// the signature length in two bytes instead of one
+				append([]byte{0x82, 0x59, 0x00, 0x40}, d.Raw[3:]...),
+			}
+			require.Equal(t, []byte{0x82, 0x58, 0x40}, d.Raw[:3])
+			if typ == crypto.Secp256k1 {
+				// (r, n - s) is a valid signature of the same payload
+				var s btcec.ModNScalar
+				s.SetByteSlice(d.Signature[32:])
+				s.Negate()
+				sBytes := s.Bytes()
+				sig := append(append([]byte{}, d.Signature[:32]...), sBytes[:]...)
+				raw, err := dagEnc.Marshal(envelopeWire{Signature: sig, SigPayload: d.sigPayload})
+				require.NoError(t, err)
+				malleated = append(malleated, raw)
+			}
+			for _, raw := range malleated {
+				other, err := dagCBORCID(raw)
+				require.NoError(t, err)
+				require.False(t, other.Equals(id))
+				_, err = parser.ParseDelegation(ctx, raw)
+				require.Error(t, err)
+				require.NotErrorIs(t, err, ErrRevoked)
+			}
+		})
+	}
+}
+
+func TestDelegation_TimeBounds(t *testing.T) {
+	ctx := context.Background()
+	signer, aud := newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Ed25519)
+	now := time.Now()
+	d, err := NewDelegation(signer, &ucanv1.DelegationPayload{
+		Audience:   aud.Issuer(),
+		Command:    "/",
+		NotBefore:  now.Add(time.Minute).Unix(),
+		Expiration: now.Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	at := func(t time.Time) ParserOption { return WithClock(func() time.Time { return t }) }
+	_, err = newEnvelopeParser(NewMemTokenStore(), at(now)).ParseDelegation(ctx, d.Raw)
+	require.ErrorIs(t, err, ErrNotValidYet)
+	_, err = newEnvelopeParser(NewMemTokenStore(), at(now.Add(2*time.Minute))).ParseDelegation(ctx, d.Raw)
+	require.NoError(t, err)
+	_, err = newEnvelopeParser(NewMemTokenStore(), at(now.Add(2*time.Hour))).ParseDelegation(ctx, d.Raw)
+	require.ErrorIs(t, err, ErrExpired)
+}
+
+func TestTokenParser_ReadsEnvelopes(t *testing.T) {
+	ctx := context.Background()
+	signer, aud := newTestSigner(t, crypto.Ed25519), newTestParty(t)
+	d, err := NewDelegation(signer, &ucanv1.DelegationPayload{Audience: aud.did, Command: "/vault"})
+	require.NoError(t, err)
+
+	parser := newEnvelopeParser(NewMemTokenStore())
+	tok, err := parser.ParseAndVerify(ctx, string(d.Raw))
+	require.NoError(t, err)
+	assert.Equal(t, signer.Issuer(), tok.Issuer.String())
+	assert.Equal(t, aud.did, tok.Audience.String())
+	require.Len(t, tok.Attenuations, 1)
+	assert.Equal(t, "*", tok.Attenuations[0].Rsc.Value())
+
+	tokID, err := tok.CID()
+	require.NoError(t, err)
+	envID, err := d.CID()
+	require.NoError(t, err)
+	assert.True(t, tokID.Equals(envID))
+
+	revoked := RevocationCheckerFunc(func(_ context.Context, id cid.Cid) (bool, error) { return id.Equals(envID), nil })
+	_, err = newEnvelopeParser(NewMemTokenStore(), WithRevocationChecker(revoked)).ParseAndVerify(ctx, string(d.Raw))
+	require.ErrorIs(t, err, ErrRevoked)
+}
+
+// delegationChain creates delegations of cmd from parties[0], the subject, down to the last party
+func delegationChain(t *testing.T, store TokenStore, parties []Signer, cmd string, mutate func(i int, p *ucanv1.DelegationPayload)) []string {
+	t.Helper()
+	var ids []string
+	for i := 0; i < len(parties)-1; i++ {
+		p := &ucanv1.DelegationPayload{
+			Audience: parties[i+1].Issuer(),
+			Subject:  parties[0].Issuer(),
+			Command:  cmd,
+		}
+		if mutate != nil {
+			mutate(i, p)
+		}
+		d, err := NewDelegation(parties[i], p)
+		require.NoError(t, err)
+		ids = append(ids, storeEnvelope(t, store, d.Envelope))
+	}
+	return ids
+}
+
+func newTestInvocation(t *testing.T, invoker Signer, subject string, cmd string, prf []string) *Invocation {
+	t.Helper()
+	amount, err := NewArgument(42)
+	require.NoError(t, err)
+	inv, err := NewInvocation(invoker, &ucanv1.InvocationPayload{
+		Subject:    subject,
+		Command:    cmd,
+		Arguments:  map[string]*anypb.Any{"amount": amount},
+		ProofCids:  prf,
+		IssuedAt:   time.Now().Unix(),
+		Expiration: time.Now().Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+	return inv
+}
+
+func TestInvocation_Chain(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemTokenStore()
+	parties := []Signer{newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Secp256k1), newTestSigner(t, crypto.Ed25519)}
+	prf := delegationChain(t, store, parties, "/vault", nil)
+	inv := newTestInvocation(t, parties[2], parties[0].Issuer(), "/vault/sign", prf)
+
+	parsed, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+	require.NoError(t, err)
+	require.Len(t, parsed.Proofs, 2)
+	assert.Equal(t, prf, parsed.Claims.ProofCids)
+	amount, err := ArgumentValue(parsed.Claims.Arguments["amount"])
+	require.NoError(t, err)
+	assert.Equal(t, float64(42), amount)
+
+	// the subject can invoke itself without proofs
+	self := newTestInvocation(t, parties[0], parties[0].Issuer(), "/vault/sign", nil)
+	_, err = newEnvelopeParser(store).ParseInvocation(ctx, self.Raw)
+	require.NoError(t, err)
+
+	// but nobody else can
+	unproven := newTestInvocation(t, parties[2], parties[0].Issuer(), "/vault/sign", nil)
+	_, err = newEnvelopeParser(store).ParseInvocation(ctx, unproven.Raw)
+	requireChainError(t, err, ErrSubjectMismatch, 0)
+}
+
+func TestInvocation_ChainFailures(t *testing.T) {
+	ctx := context.Background()
+	a, b, c := newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Ed25519)
+	parties := []Signer{a, b, c}
+
+	t.Run("command", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/vault/sign", nil)
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault/export", prf)
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrCommandMismatch, 2)
+	})
+	t.Run("order", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", nil)
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", []string{prf[1], prf[0]})
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrSubjectMismatch, 2)
+	})
+	t.Run("audience", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", nil)
+		inv := newTestInvocation(t, b, a.Issuer(), "/vault", prf)
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrAudienceMismatch, 1)
+	})
+	t.Run("subject", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", func(i int, p *ucanv1.DelegationPayload) {
+			if i == 1 {
+				p.Subject = b.Issuer()
+			}
+		})
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrSubjectMismatch, 1)
+	})
+	t.Run("powerline", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", func(i int, p *ucanv1.DelegationPayload) {
+			if i == 1 {
+				p.Subject = ""
+			}
+		})
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		require.NoError(t, err)
+	})
+	t.Run("expired", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", func(i int, p *ucanv1.DelegationPayload) {
+			if i == 0 {
+				p.Expiration = time.Now().Add(-time.Minute).Unix()
+			}
+		})
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrExpired, 2)
+	})
+	t.Run("missing", func(t *testing.T) {
+		prf := delegationChain(t, NewMemTokenStore(), parties, "/", nil)
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+		_, err := newEnvelopeParser(NewMemTokenStore()).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrProofNotFound, 2)
+	})
+	t.Run("revoked", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", nil)
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+		revoked := RevocationCheckerFunc(func(_ context.Context, id cid.Cid) (bool, error) {
+			return id.String() == prf[0], nil
+		})
+		_, err := newEnvelopeParser(store, WithRevocationChecker(revoked)).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrRevoked, 2)
+	})
+	t.Run("policy", func(t *testing.T) {
+		store := NewMemTokenStore()
+		prf := delegationChain(t, store, parties, "/", func(i int, p *ucanv1.DelegationPayload) {
+			p.Policy = testPolicy(t)
+		})
+		inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+		// the arguments have no ".to", so neither side of the disjunction holds
+		_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrPolicyViolation, 2)
+		_, err = newEnvelopeParser(store, WithPolicyEvaluator(nil)).ParseInvocation(ctx, inv.Raw)
+		requireChainError(t, err, ErrPolicyUnsupported, 2)
+
+		var evaluated int
+		eval := func(pol []*ucanv1.PolicyStatement, args map[string]*anypb.Any) error {
+			evaluated++
+			require.Len(t, pol, 2)
+			require.Contains(t, args, "amount")
+			return nil
+		}
+		_, err = newEnvelopeParser(store, WithPolicyEvaluator(eval)).ParseInvocation(ctx, inv.Raw)
+		require.NoError(t, err)
+		assert.Equal(t, 2, evaluated)
+	})
+}
+
+func TestReceipt_Issue(t *testing.T) {
+	ctx := context.Background()
+	subject, executor := newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Secp256k1)
+	inv := newTestInvocation(t, subject, subject.Issuer(), "/vault/sign", nil)
+
+	result, err := NewArgument(map[string]any{"signature": "abcd"})
+	require.NoError(t, err)
+	next, err := NewArgument("again")
+	require.NoError(t, err)
+	r, err := IssueReceipt(executor, inv, &ucanv1.ReceiptPayload{
+		Status: ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS,
+		Result: result,
+		Next: []*ucanv1.Task{{
+			Subject:   subject.Issuer(),
+			Command:   "/vault/sign",
+			Arguments: map[string]*anypb.Any{"msg": next},
+			Nonce:     []byte{1, 2, 3},
+		}},
+	})
+	require.NoError(t, err)
+	invID, err := inv.CID()
+	require.NoError(t, err)
+	assert.Equal(t, invID.String(), r.Claims.Invocation)
+	assert.Equal(t, subject.Issuer(), r.Claims.Audience)
+
+	parsed, err := newEnvelopeParser(NewMemTokenStore()).ParseReceipt(ctx, r.Raw)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(r.Claims, parsed.Claims), "decoded %v\nwant %v", parsed.Claims, r.Claims)
+
+	failed, err := IssueReceipt(executor, inv, &ucanv1.ReceiptPayload{
+		Status: ucanv1.ReceiptStatus_RECEIPT_STATUS_ERROR,
+		Error:  &ucanv1.ErrorInfo{Code: "denied", Message: "no", Details: map[string]string{"why": "policy"}},
+	})
+	require.NoError(t, err)
+	parsed, err = newEnvelopeParser(NewMemTokenStore()).ParseReceipt(ctx, failed.Raw)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(failed.Claims, parsed.Claims))
+
+	_, err = DecodeInvocation(r.Raw)
+	require.ErrorIs(t, err, ErrInvalidEnvelope)
+}
@@ -0,0 +1,318 @@
+package ucan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+// directories of a file token store
+const (
+	fileStoreTokensDir  = "tokens"
+	fileStoreKeysDir    = "keys"
+	fileStoreRevokedDir = "revoked"
+)
+
+// fileTokenStore is a token store backed by a directory. Tokens are content-addressed files named
+// by their CID, keys are files named by the SHA-256 of the key pointing at a token CID, and the
+// revocation set is a directory of empty files named by the revoked CIDs. Indexes are rebuilt in
+// memory when the store is opened.
+type fileTokenStore struct {
+	dir    string
+	toksLk sync.RWMutex
+	toks   *tokenIndex
+}
+
+var _ IndexedTokenStore = (*fileTokenStore)(nil)
+
+// fileKeyEntry is the content of a key file
+type fileKeyEntry struct {
+	Key string `json:"key"`
+	CID string `json:"cid"`
+}
+
+// NewFileTokenStore opens the token store in dir, creating it if it does not exist. Every stored
+// token is read and checked against its CID when the store is opened.
+func NewFileTokenStore(dir string) (IndexedTokenStore, error) {
+	for _, sub := range []string{fileStoreTokensDir, fileStoreKeysDir, fileStoreRevokedDir} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+	st := &fileTokenStore{dir: dir, toks: newTokenIndex()}
+
+	keyFiles, err := os.ReadDir(filepath.Join(dir, fileStoreKeysDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range keyFiles {
+		if f.IsDir() || filepath.Ext(f.Name()) == ".tmp" {
+			continue
+		}
+		rec, err := st.readKey(f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("opening token store: %w", err)
+		}
+		st.toks.put(rec)
+	}
+
+	revoked, err := os.ReadDir(filepath.Join(dir, fileStoreRevokedDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range revoked {
+		if f.IsDir() || filepath.Ext(f.Name()) == ".tmp" {
+			continue
+		}
+		id, err := cid.Decode(f.Name())
+		if err != nil {
+			return nil, fmt.Errorf("opening token store: revocation %q: %w", f.Name(), err)
+		}
+		st.toks.revoked[id] = struct{}{}
+	}
+	return st, nil
+}
+
+// readKey loads the token a key file points at
+func (st *fileTokenStore) readKey(name string) (*tokenRecord, error) {
+	data, err := os.ReadFile(filepath.Join(st.dir, fileStoreKeysDir, name))
+	if err != nil {
+		return nil, err
+	}
+	var entry fileKeyEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("key file %s: %w", name, err)
+	}
+	if name != keyFileName(entry.Key) {
+		return nil, fmt.Errorf("key file %s holds key %q", name, entry.Key)
+	}
+	raw, err := os.ReadFile(filepath.Join(st.dir, fileStoreTokensDir, entry.CID))
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", entry.Key, err)
+	}
+	rec, err := newTokenRecord(entry.Key, string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("token %s: %w", entry.CID, err)
+	}
+	if rec.id.String() != entry.CID {
+		return nil, fmt.Errorf("token %s does not match its CID %s", entry.CID, rec.id)
+	}
+	return rec, nil
+}
+
+// keyFileName names the file of a key, keys being arbitrary strings
+func keyFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFile atomically replaces the file at path
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// removeFile removes the file at path, if it exists
+func removeFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (st *fileTokenStore) tokenPath(id cid.Cid) string {
+	return filepath.Join(st.dir, fileStoreTokensDir, id.String())
+}
+
+func (st *fileTokenStore) PutToken(ctx context.Context, key string, raw string) error {
+	rec, err := newTokenRecord(key, raw)
+	if err != nil {
+		return err
+	}
+	entry, err := json.Marshal(fileKeyEntry{Key: key, CID: rec.id.String()})
+	if err != nil {
+		return err
+	}
+
+	st.toksLk.Lock()
+	defer st.toksLk.Unlock()
+
+	if !st.toks.referenced(rec.id) {
+		if err := writeFile(st.tokenPath(rec.id), []byte(raw)); err != nil {
+			return err
+		}
+	}
+	if err := writeFile(filepath.Join(st.dir, fileStoreKeysDir, keyFileName(key)), entry); err != nil {
+		return err
+	}
+	if prev := st.toks.put(rec); prev != nil {
+		return st.release(prev.id)
+	}
+	return nil
+}
+
+// release removes the file of a token no key stores anymore
+func (st *fileTokenStore) release(id cid.Cid) error {
+	if st.toks.referenced(id) {
+		return nil
+	}
+	return removeFile(st.tokenPath(id))
+}
+
+// deleteLocked removes the token stored under key, the caller holding the write lock
+func (st *fileTokenStore) deleteLocked(key string) error {
+	if _, ok := st.toks.keys[key]; !ok {
+		return ErrTokenNotFound
+	}
+	if err := removeFile(filepath.Join(st.dir, fileStoreKeysDir, keyFileName(key))); err != nil {
+		return err
+	}
+	return st.release(st.toks.remove(key).id)
+}
+
+func (st *fileTokenStore) ResolveCIDBytes(ctx context.Context, id cid.Cid) ([]byte, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	raw, ok := st.toks.byCID(id)
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return []byte(raw), nil
+}
+
+func (st *fileTokenStore) RawToken(ctx context.Context, key string) (string, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	rec, ok := st.toks.keys[key]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return rec.raw, nil
+}
+
+func (st *fileTokenStore) DeleteToken(ctx context.Context, key string) error {
+	st.toksLk.Lock()
+	defer st.toksLk.Unlock()
+
+	return st.deleteLocked(key)
+}
+
+func (st *fileTokenStore) ListTokens(ctx context.Context, offset, limit int) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return page(st.toks.all(), offset, limit), nil
+}
+
+func (st *fileTokenStore) TokensByIssuer(ctx context.Context, issuer string) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return st.toks.tokens(st.toks.issuers[issuer]), nil
+}
+
+func (st *fileTokenStore) TokensByAudience(ctx context.Context, audience string) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return st.toks.tokens(st.toks.audiences[audience]), nil
+}
+
+func (st *fileTokenStore) TokensExpiringBefore(ctx context.Context, t time.Time) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	var toks []RawToken
+	for _, rec := range st.toks.expiredBy(t) {
+		toks = append(toks, RawToken{Key: rec.key, Raw: rec.raw})
+	}
+	return toks, nil
+}
+
+// CollectGarbage deletes expired tokens, and token files no key points at, which an interrupted
+// write can leave behind
+func (st *fileTokenStore) CollectGarbage(ctx context.Context, now time.Time) (int, error) {
+	st.toksLk.Lock()
+	defer st.toksLk.Unlock()
+
+	expired := st.toks.expiredBy(now)
+	for i, rec := range expired {
+		if err := st.deleteLocked(rec.key); err != nil {
+			return i, err
+		}
+	}
+
+	files, err := os.ReadDir(filepath.Join(st.dir, fileStoreTokensDir))
+	if err != nil {
+		return len(expired), err
+	}
+	for _, f := range files {
+		id, err := cid.Decode(f.Name())
+		if err == nil && st.toks.referenced(id) {
+			continue
+		}
+		if err := removeFile(filepath.Join(st.dir, fileStoreTokensDir, f.Name())); err != nil {
+			return len(expired), err
+		}
+	}
+	return len(expired), nil
+}
+
+func (st *fileTokenStore) IsRevoked(ctx context.Context, id cid.Cid) (bool, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	_, ok := st.toks.revoked[id]
+	return ok, nil
+}
+
+func (st *fileTokenStore) Revoke(ctx context.Context, id cid.Cid) error {
+	if !id.Defined() {
+		return errUndefinedRevocation
+	}
+	st.toksLk.Lock()
+	defer st.toksLk.Unlock()
+
+	if _, ok := st.toks.revoked[id]; ok {
+		return nil
+	}
+	if err := writeFile(filepath.Join(st.dir, fileStoreRevokedDir, id.String()), nil); err != nil {
+		return err
+	}
+	st.toks.revoked[id] = struct{}{}
+	return nil
+}
+
+func (st *fileTokenStore) Revocations(ctx context.Context) ([]cid.Cid, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return st.toks.revocations(), nil
+}
@@ -0,0 +1,257 @@
+package ucan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+var (
+	// ErrSubjectMismatch is returned when a delegation chain does not grant authority over the
+	// subject of an invocation
+	ErrSubjectMismatch = errors.New("delegation chain does not grant authority over the invocation subject")
+	// ErrCommandMismatch is returned when an invoked command is not covered by a delegation
+	ErrCommandMismatch = errors.New("command is not covered by delegation")
+	// ErrPolicyUnsupported is returned when a delegation carries a policy and the parser has no
+	// evaluator to check it with
+	ErrPolicyUnsupported = errors.New("delegation policy cannot be evaluated")
+)
+
+// PolicyEvaluator checks invocation arguments against the policy of a delegation
+type PolicyEvaluator func(policy []*ucanv1.PolicyStatement, args map[string]*anypb.Any) error
+
+// WithPolicyEvaluator sets how the parser checks delegation policies when verifying invocations,
+// EvaluatePolicy by default. With a nil evaluator, invocations backed by a delegation with a
+// non-empty policy are rejected.
+func WithPolicyEvaluator(eval PolicyEvaluator) ParserOption {
+	return func(p *TokenParser) {
+		p.policy = eval
+	}
+}
+
+// WithStrictPolicyAttenuation makes the parser require every delegation in an invocation chain to
+// carry a policy at least as restrictive as the delegation before it, see CheckPolicyAttenuation
+func WithStrictPolicyAttenuation() ParserOption {
+	return func(p *TokenParser) {
+		p.strictPolicy = true
+	}
+}
+
+// Invocation is a UCAN 1.0 invocation envelope with its decoded payload
+type Invocation struct {
+	*Envelope
+	Claims *ucanv1.InvocationPayload
+	// Proofs are the delegations listed by the invocation, from the root delegation issued by the
+	// subject to the delegation to the invoker. They are only set by TokenParser.ParseInvocation.
+	Proofs []*Delegation
+}
+
+// invocationWire is the DAG-CBOR layout of an invocation payload
+type invocationWire struct {
+	Iss   string            `cbor:"iss"`
+	Sub   string            `cbor:"sub"`
+	Aud   string            `cbor:"aud,omitempty"`
+	Cmd   string            `cbor:"cmd"`
+	Args  map[string]any    `cbor:"args"`
+	Prf   []link            `cbor:"prf"`
+	Meta  map[string][]byte `cbor:"meta,omitempty"`
+	Nonce []byte            `cbor:"nonce"`
+	Exp   *int64            `cbor:"exp"`
+	Iat   int64             `cbor:"iat,omitempty"`
+	Cause *link             `cbor:"cause,omitempty"`
+}
+
+// NewInvocation signs an invocation of payload with signer. ProofCids must list the CIDs of the
+// delegation chain from the subject to the signer, root first. The issuer defaults to the signer and
+// a random nonce is generated when none is given.
+func NewInvocation(signer Signer, payload *ucanv1.InvocationPayload) (*Invocation, error) {
+	claims := proto.Clone(payload).(*ucanv1.InvocationPayload)
+	if err := fillIssuer(signer, &claims.Issuer); err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invocation subject is required")
+	}
+	if err := validateCommand(claims.Command); err != nil {
+		return nil, err
+	}
+	if err := fillNonce(&claims.Nonce); err != nil {
+		return nil, err
+	}
+
+	args, err := argumentsToIPLD(claims.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	prf, err := parseLinks(claims.ProofCids)
+	if err != nil {
+		return nil, err
+	}
+	wire := invocationWire{
+		Iss:   claims.Issuer,
+		Sub:   claims.Subject,
+		Aud:   claims.Audience,
+		Cmd:   claims.Command,
+		Args:  args,
+		Prf:   prf,
+		Meta:  claims.Meta,
+		Nonce: claims.Nonce,
+		Exp:   optionalUnix(claims.Expiration),
+		Iat:   claims.IssuedAt,
+	}
+	if claims.Cause != "" {
+		c, err := cid.Decode(claims.Cause)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cause: %w", err)
+		}
+		wire.Cause = &link{c}
+	}
+	env, err := sealEnvelope(signer, InvocationTag, wire)
+	if err != nil {
+		return nil, err
+	}
+	return &Invocation{Envelope: env, Claims: claims}, nil
+}
+
+// DecodeInvocation decodes an invocation envelope without verifying its signature or proofs
+func DecodeInvocation(data []byte) (*Invocation, error) {
+	env, err := DecodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	var wire invocationWire
+	if err := env.decodePayload(InvocationTag, &wire); err != nil {
+		return nil, err
+	}
+	args, err := argumentsFromIPLD(wire.Args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: args: %v", ErrInvalidEnvelope, err)
+	}
+	claims := &ucanv1.InvocationPayload{
+		Issuer:    wire.Iss,
+		Subject:   wire.Sub,
+		Audience:  wire.Aud,
+		Command:   wire.Cmd,
+		Arguments: args,
+		ProofCids: linkStrings(wire.Prf),
+		Meta:      wire.Meta,
+		Nonce:     wire.Nonce,
+		IssuedAt:  wire.Iat,
+	}
+	if wire.Exp != nil {
+		claims.Expiration = *wire.Exp
+	}
+	if wire.Cause != nil {
+		claims.Cause = wire.Cause.String()
+	}
+	return &Invocation{Envelope: env, Claims: claims}, nil
+}
+
+// ParseInvocation decodes an invocation, verifies its signature and validates it against the
+// delegation chain it lists. The chain must start with a delegation issued by the subject, each
+// delegation must be addressed to the issuer of the next one and the last to the invoker, every
+// delegation must cover the invoked command and subject, and the arguments must satisfy every
+// delegation policy. An invocation without proofs is only valid when the subject invokes itself.
+// Failures of a delegation are reported as a *ChainError, the delegation to the invoker being at
+// depth 1.
+func (p *TokenParser) ParseInvocation(ctx context.Context, data []byte) (*Invocation, error) {
+	inv, err := DecodeInvocation(data)
+	if err != nil {
+		return nil, err
+	}
+	id, err := inv.CID()
+	if err != nil {
+		return nil, err
+	}
+	fail := func(depth int, id cid.Cid, issuer string, err error) error {
+		return &ChainError{Depth: depth, CID: id, Issuer: issuer, Err: err}
+	}
+	claims := inv.Claims
+	if err := p.verifyEnvelope(ctx, inv.Envelope, claims.Issuer, 0, claims.Expiration); err != nil {
+		return nil, fail(0, id, claims.Issuer, err)
+	}
+
+	n := len(claims.ProofCids)
+	if n == 0 {
+		if claims.Issuer != claims.Subject {
+			return nil, fail(0, id, claims.Issuer, fmt.Errorf("%w: no proofs for %s", ErrSubjectMismatch, claims.Subject))
+		}
+		return inv, nil
+	}
+	if n > p.maxDepth {
+		return nil, fail(n, id, claims.Issuer, ErrProofDepth)
+	}
+
+	seen := map[cid.Cid]bool{}
+	proofs := make([]*Delegation, n)
+	for i, s := range claims.ProofCids {
+		depth := n - i
+		prfID, err := cid.Decode(s)
+		if err != nil {
+			return nil, fail(depth, cid.Undef, "", err)
+		}
+		if seen[prfID] {
+			return nil, fail(depth, prfID, "", ErrProofCycle)
+		}
+		seen[prfID] = true
+		raw, err := p.resolveProof(ctx, Proof(s))
+		if err != nil {
+			return nil, fail(depth, prfID, "", err)
+		}
+		d, err := p.ParseDelegation(ctx, []byte(raw))
+		if err != nil {
+			return nil, fail(depth, prfID, "", err)
+		}
+		proofs[i] = d
+	}
+
+	for i, d := range proofs {
+		depth := n - i
+		prfID, _ := d.CID()
+		if err := p.verifyInvocationLink(inv, proofs, i); err != nil {
+			return nil, fail(depth, prfID, d.Claims.Issuer, err)
+		}
+	}
+	inv.Proofs = proofs
+	return inv, nil
+}
+
+// verifyInvocationLink checks the delegation at position i of the chain of inv
+func (p *TokenParser) verifyInvocationLink(inv *Invocation, chain []*Delegation, i int) error {
+	d := chain[i].Claims
+	claims := inv.Claims
+	if i == 0 && d.Issuer != claims.Subject {
+		return fmt.Errorf("%w: root delegation issued by %s", ErrSubjectMismatch, d.Issuer)
+	}
+	if d.Subject != "" && d.Subject != claims.Subject {
+		return fmt.Errorf("%w: delegation subject %s", ErrSubjectMismatch, d.Subject)
+	}
+	next := claims.Issuer
+	if i+1 < len(chain) {
+		next = chain[i+1].Claims.Issuer
+	}
+	if d.Audience != next {
+		return fmt.Errorf("%w: delegation audience %s, next issuer %s", ErrAudienceMismatch, d.Audience, next)
+	}
+	if !commandCovers(d.Command, claims.Command) {
+		return fmt.Errorf("%w: %s does not cover %s", ErrCommandMismatch, d.Command, claims.Command)
+	}
+	if p.strictPolicy && i > 0 {
+		if err := CheckPolicyAttenuation(chain[i-1].Claims.Policy, d.Policy); err != nil {
+			return err
+		}
+	}
+	if len(d.Policy) == 0 {
+		return nil
+	}
+	if p.policy == nil {
+		return ErrPolicyUnsupported
+	}
+	return p.policy(d.Policy, claims.Arguments)
+}
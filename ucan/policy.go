@@ -0,0 +1,569 @@
+package ucan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+var (
+	// ErrPolicyViolation is returned when invocation arguments do not satisfy a policy
+	ErrPolicyViolation = errors.New("policy violation")
+	// ErrPolicyEscalation is returned when a delegation policy is less restrictive than its parent's
+	ErrPolicyEscalation = errors.New("policy is less restrictive than its parent")
+)
+
+// PolicyFailure is a structured trace of why a policy statement did not hold. Connectives and
+// quantifiers record the failures of the statements they contain as causes.
+type PolicyFailure struct {
+	// Path locates the statement in the policy, e.g. "pol[1].or[0]"
+	Path string
+	// Statement is the failing statement in its UCAN array form
+	Statement string
+	// Value is the value the statement was evaluated against, if the selector resolved
+	Value any
+	// Reason explains the failure
+	Reason string
+	// Causes are the failures of nested statements
+	Causes []*PolicyFailure
+
+	// unresolved is set when a selector failed to resolve, which negation cannot turn into success
+	unresolved bool
+}
+
+func (f *PolicyFailure) Error() string {
+	return fmt.Sprintf("%v: %s %s: %s", ErrPolicyViolation, f.Path, f.Statement, f.Reason)
+}
+
+// Unwrap makes failures match ErrPolicyViolation
+func (f *PolicyFailure) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// Trace formats the failure and its causes as an indented tree
+func (f *PolicyFailure) Trace() string {
+	var sb strings.Builder
+	f.trace(&sb, 0)
+	return sb.String()
+}
+
+func (f *PolicyFailure) trace(sb *strings.Builder, depth int) {
+	fmt.Fprintf(sb, "%s%s %s: %s\n", strings.Repeat("  ", depth), f.Path, f.Statement, f.Reason)
+	for _, c := range f.Causes {
+		c.trace(sb, depth+1)
+	}
+}
+
+// EvaluatePolicy checks every statement of policy against args and returns a *PolicyFailure for the
+// first statement that does not hold. args may be invocation arguments (map[string]*anypb.Any), a
+// *structpb.Struct or *structpb.Value, raw JSON ([]byte or json.RawMessage) or plain Go values as
+// produced by encoding/json. An empty policy always holds.
+func EvaluatePolicy(policy []*ucanv1.PolicyStatement, args any) error {
+	input, err := policyInput(args)
+	if err != nil {
+		return err
+	}
+	for i, st := range policy {
+		if f := evalStatement(st, input, fmt.Sprintf("pol[%d]", i)); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// evaluateInvocationPolicy is the PolicyEvaluator parsers use by default
+func evaluateInvocationPolicy(policy []*ucanv1.PolicyStatement, args map[string]*anypb.Any) error {
+	return EvaluatePolicy(policy, args)
+}
+
+// policyInput converts the supported argument forms to plain Go values
+func policyInput(args any) (any, error) {
+	switch a := args.(type) {
+	case map[string]*anypb.Any:
+		out := make(map[string]any, len(a))
+		for key, v := range a {
+			val, err := ArgumentValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("argument %q: %w", key, err)
+			}
+			out[key] = val
+		}
+		return out, nil
+	case *structpb.Struct:
+		return a.AsMap(), nil
+	case *structpb.Value:
+		return a.AsInterface(), nil
+	case json.RawMessage:
+		return policyInput([]byte(a))
+	case []byte:
+		var v any
+		if err := json.Unmarshal(a, &v); err != nil {
+			return nil, fmt.Errorf("decoding policy arguments: %w", err)
+		}
+		return v, nil
+	default:
+		return args, nil
+	}
+}
+
+// evalStatement returns nil if st holds for v
+func evalStatement(st *ucanv1.PolicyStatement, v any, path string) *PolicyFailure {
+	fail := func(value any, reason string, causes ...*PolicyFailure) *PolicyFailure {
+		return &PolicyFailure{Path: path, Statement: statementString(st), Value: value, Reason: reason, Causes: causes}
+	}
+	selectValues := func(selector string) ([]any, bool, *PolicyFailure) {
+		sel, err := ParseSelector(selector)
+		if err != nil {
+			return nil, false, fail(nil, err.Error())
+		}
+		values, err := sel.Select(v)
+		if err != nil {
+			f := fail(nil, err.Error())
+			f.unresolved = true
+			return nil, false, f
+		}
+		return values, sel.Iterates(), nil
+	}
+
+	switch s := st.GetStatement().(type) {
+	case *ucanv1.PolicyStatement_Comparison:
+		values, _, f := selectValues(s.Comparison.GetSelector())
+		if f != nil {
+			return f
+		}
+		want := s.Comparison.GetValue().AsInterface()
+		for _, got := range values {
+			if ok, reason := compare(s.Comparison.GetOperator(), got, want); !ok {
+				return fail(got, reason)
+			}
+		}
+		return nil
+	case *ucanv1.PolicyStatement_Pattern:
+		values, _, f := selectValues(s.Pattern.GetSelector())
+		if f != nil {
+			return f
+		}
+		for _, got := range values {
+			str, ok := got.(string)
+			if !ok {
+				return fail(got, fmt.Sprintf("expected a string, got %s", kindOf(got)))
+			}
+			if !globMatch(s.Pattern.GetPattern(), str) {
+				return fail(got, "does not match pattern")
+			}
+		}
+		return nil
+	case *ucanv1.PolicyStatement_Connective:
+		stmts := s.Connective.GetStatements()
+		var causes []*PolicyFailure
+		for i, inner := range stmts {
+			name := connectiveOps[s.Connective.GetOperator()]
+			f := evalStatement(inner, v, fmt.Sprintf("%s.%s[%d]", path, name, i))
+			switch s.Connective.GetOperator() {
+			case ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_AND:
+				if f != nil {
+					return fail(nil, "a conjunct does not hold", f)
+				}
+			case ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_OR:
+				if f == nil {
+					return nil
+				}
+				causes = append(causes, f)
+			default:
+				return fail(nil, fmt.Sprintf("unknown connective %s", s.Connective.GetOperator()))
+			}
+		}
+		if len(causes) > 0 {
+			return fail(nil, "no disjunct holds", causes...)
+		}
+		return nil
+	case *ucanv1.PolicyStatement_Negation:
+		f := evalStatement(s.Negation.GetStatement(), v, path+".not")
+		if f == nil {
+			return fail(nil, "negated statement holds")
+		}
+		if f.unresolved {
+			nf := fail(nil, "negated statement could not be evaluated", f)
+			nf.unresolved = true
+			return nf
+		}
+		return nil
+	case *ucanv1.PolicyStatement_Quantifier:
+		q := s.Quantifier
+		values, iterates, f := selectValues(q.GetSelector())
+		if f != nil {
+			return f
+		}
+		var elems []any
+		if iterates {
+			elems = values
+		} else {
+			collection, ok := values[0].([]any)
+			if !ok {
+				if m, isMap := values[0].(map[string]any); isMap {
+					collection, _ = iterateSegment.apply(m)
+				} else {
+					return fail(values[0], fmt.Sprintf("expected a list, got %s", kindOf(values[0])))
+				}
+			}
+			elems = collection
+		}
+		name := quantifierOps[q.GetOperator()]
+		var causes []*PolicyFailure
+		for i, elem := range elems {
+			ef := evalStatement(q.GetStatement(), elem, fmt.Sprintf("%s.%s[%d]", path, name, i))
+			switch q.GetOperator() {
+			case ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ALL:
+				if ef != nil {
+					return fail(elem, fmt.Sprintf("element %d does not satisfy the statement", i), ef)
+				}
+			case ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ANY:
+				if ef == nil {
+					return nil
+				}
+				causes = append(causes, ef)
+			default:
+				return fail(nil, fmt.Sprintf("unknown quantifier %s", q.GetOperator()))
+			}
+		}
+		if q.GetOperator() == ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ANY {
+			return fail(nil, "no element satisfies the statement", causes...)
+		}
+		return nil
+	default:
+		return fail(nil, "empty policy statement")
+	}
+}
+
+// iterateSegment iterates over the values of a map
+var iterateSegment = selectorSegment{kind: segmentIterate}
+
+// compare applies a comparison operator. Ordering operators only apply to numbers.
+func compare(op ucanv1.ComparisonOperator, got, want any) (bool, string) {
+	switch op {
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL:
+		if valuesEqual(got, want) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%s is not equal to %s", formatValue(got), formatValue(want))
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_NOT_EQUAL:
+		if !valuesEqual(got, want) {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%s is equal to %s", formatValue(got), formatValue(want))
+	}
+	a, aok := toNumber(got)
+	b, bok := toNumber(want)
+	if !aok || !bok {
+		return false, fmt.Sprintf("cannot order %s and %s", kindOf(got), kindOf(want))
+	}
+	var ok bool
+	switch op {
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN:
+		ok = a > b
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN_OR_EQUAL:
+		ok = a >= b
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN:
+		ok = a < b
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN_OR_EQUAL:
+		ok = a <= b
+	default:
+		return false, fmt.Sprintf("unknown comparison %s", op)
+	}
+	if ok {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%s is not %s %s", formatValue(got), comparisonOps[op], formatValue(want))
+}
+
+// valuesEqual compares values structurally, treating all numeric types alike
+func valuesEqual(a, b any) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	switch av := a.(type) {
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !valuesEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			w, ok := bv[k]
+			if !ok || !valuesEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// toNumber returns v as a float64 if it is numeric
+func toNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// globMatch matches s against a pattern where "*" matches any run of characters and "\*" is a
+// literal asterisk
+func globMatch(pattern, s string) bool {
+	var (
+		parts []string
+		sb    strings.Builder
+	)
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern) && pattern[i+1] == '*':
+			sb.WriteByte('*')
+			i++
+		case pattern[i] == '*':
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(pattern[i])
+		}
+	}
+	parts = append(parts, sb.String())
+
+	if len(parts) == 1 {
+		return s == parts[0]
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(s, part)
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
+
+func statementString(st *ucanv1.PolicyStatement) string {
+	enc, err := encodeStatement(st)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	b, err := json.Marshal(enc)
+	if err != nil {
+		return fmt.Sprint(enc)
+	}
+	return string(b)
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// CheckPolicyAttenuation checks that a child policy is at least as restrictive as its parent's:
+// every parent statement must be implied by the conjunction of the child statements. The check is
+// syntactic and conservative, it may reject child policies that are in fact narrower, but never
+// accepts one that is broader.
+func CheckPolicyAttenuation(parent, child []*ucanv1.PolicyStatement) error {
+	conj := &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Connective{
+		Connective: &ucanv1.ConnectiveStatement{
+			Operator:   ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_AND,
+			Statements: child,
+		},
+	}}
+	for i, p := range parent {
+		if !implies(conj, p) {
+			return fmt.Errorf("%w: pol[%d] %s is not enforced", ErrPolicyEscalation, i, statementString(p))
+		}
+	}
+	return nil
+}
+
+// implies reports whether c holding guarantees that p holds
+func implies(c, p *ucanv1.PolicyStatement) bool {
+	if proto.Equal(c, p) {
+		return true
+	}
+	// decompose the parent first, then the child
+	switch ps := p.GetStatement().(type) {
+	case *ucanv1.PolicyStatement_Connective:
+		switch ps.Connective.GetOperator() {
+		case ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_AND:
+			for _, inner := range ps.Connective.GetStatements() {
+				if !implies(c, inner) {
+					return false
+				}
+			}
+			return true
+		case ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_OR:
+			for _, inner := range ps.Connective.GetStatements() {
+				if implies(c, inner) {
+					return true
+				}
+			}
+		}
+	}
+	switch cs := c.GetStatement().(type) {
+	case *ucanv1.PolicyStatement_Connective:
+		stmts := cs.Connective.GetStatements()
+		switch cs.Connective.GetOperator() {
+		case ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_AND:
+			for _, inner := range stmts {
+				if implies(inner, p) {
+					return true
+				}
+			}
+			return false
+		case ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_OR:
+			if len(stmts) == 0 {
+				return false
+			}
+			for _, inner := range stmts {
+				if !implies(inner, p) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	case *ucanv1.PolicyStatement_Comparison:
+		return comparisonImplies(cs.Comparison, p)
+	case *ucanv1.PolicyStatement_Pattern:
+		pp, ok := p.GetStatement().(*ucanv1.PolicyStatement_Pattern)
+		return ok && sameSelector(cs.Pattern.GetSelector(), pp.Pattern.GetSelector()) &&
+			cs.Pattern.GetPattern() == pp.Pattern.GetPattern()
+	case *ucanv1.PolicyStatement_Negation:
+		pn, ok := p.GetStatement().(*ucanv1.PolicyStatement_Negation)
+		// not a => not b when b => a
+		return ok && implies(pn.Negation.GetStatement(), cs.Negation.GetStatement())
+	case *ucanv1.PolicyStatement_Quantifier:
+		pq, ok := p.GetStatement().(*ucanv1.PolicyStatement_Quantifier)
+		return ok && cs.Quantifier.GetOperator() == pq.Quantifier.GetOperator() &&
+			sameSelector(cs.Quantifier.GetSelector(), pq.Quantifier.GetSelector()) &&
+			implies(cs.Quantifier.GetStatement(), pq.Quantifier.GetStatement())
+	}
+	return false
+}
+
+// comparisonImplies reports whether the comparison c guarantees p
+func comparisonImplies(c *ucanv1.ComparisonStatement, p *ucanv1.PolicyStatement) bool {
+	cv := c.GetValue().AsInterface()
+	switch ps := p.GetStatement().(type) {
+	case *ucanv1.PolicyStatement_Pattern:
+		// an exact value that matches the pattern
+		str, ok := cv.(string)
+		return ok && c.GetOperator() == ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL &&
+			sameSelector(c.GetSelector(), ps.Pattern.GetSelector()) && globMatch(ps.Pattern.GetPattern(), str)
+	case *ucanv1.PolicyStatement_Comparison:
+		pc := ps.Comparison
+		if !sameSelector(c.GetSelector(), pc.GetSelector()) {
+			return false
+		}
+		pv := pc.GetValue().AsInterface()
+		if c.GetOperator() == ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL {
+			// an exact value implies every comparison it satisfies
+			ok, _ := compare(pc.GetOperator(), cv, pv)
+			return ok
+		}
+		if c.GetOperator() == ucanv1.ComparisonOperator_COMPARISON_OPERATOR_NOT_EQUAL {
+			return pc.GetOperator() == c.GetOperator() && valuesEqual(cv, pv)
+		}
+		x, xok := toNumber(cv)
+		y, yok := toNumber(pv)
+		if !xok || !yok {
+			return false
+		}
+		cLower, cStrict := orderBound(c.GetOperator())
+		pLower, pStrict := orderBound(pc.GetOperator())
+		if pc.GetOperator() == ucanv1.ComparisonOperator_COMPARISON_OPERATOR_NOT_EQUAL {
+			// a bound excludes every value on the far side of it
+			if cLower {
+				return y < x || y == x && cStrict
+			}
+			return y > x || y == x && cStrict
+		}
+		if pc.GetOperator() == ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL || cLower != pLower {
+			return false
+		}
+		if x == y {
+			return cStrict || !pStrict
+		}
+		if cLower {
+			return x > y
+		}
+		return x < y
+	}
+	return false
+}
+
+// orderBound describes an ordering operator as a lower or upper bound, strict or not
+func orderBound(op ucanv1.ComparisonOperator) (lower, strict bool) {
+	switch op {
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN:
+		return true, true
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN_OR_EQUAL:
+		return true, false
+	case ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// sameSelector compares selectors by their canonical form
+func sameSelector(a, b string) bool {
+	sa, err := ParseSelector(a)
+	if err != nil {
+		return false
+	}
+	sb, err := ParseSelector(b)
+	if err != nil {
+		return false
+	}
+	return sa.String() == sb.String()
+}
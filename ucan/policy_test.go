@@ -0,0 +1,328 @@
+package ucan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+func cmpStatement(t *testing.T, op ucanv1.ComparisonOperator, sel string, v any) *ucanv1.PolicyStatement {
+	t.Helper()
+	value, err := structpb.NewValue(v)
+	require.NoError(t, err)
+	return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Comparison{
+		Comparison: &ucanv1.ComparisonStatement{Operator: op, Selector: sel, Value: value},
+	}}
+}
+
+func likeStatement(sel, pattern string) *ucanv1.PolicyStatement {
+	return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Pattern{Pattern: &ucanv1.PatternStatement{
+		Operator: ucanv1.PatternOperator_PATTERN_OPERATOR_LIKE,
+		Selector: sel,
+		Pattern:  pattern,
+	}}}
+}
+
+func connective(op ucanv1.ConnectiveOperator, stmts ...*ucanv1.PolicyStatement) *ucanv1.PolicyStatement {
+	return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Connective{
+		Connective: &ucanv1.ConnectiveStatement{Operator: op, Statements: stmts},
+	}}
+}
+
+func negation(st *ucanv1.PolicyStatement) *ucanv1.PolicyStatement {
+	return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Negation{
+		Negation: &ucanv1.NegationStatement{Statement: st},
+	}}
+}
+
+func quantifier(op ucanv1.QuantifierOperator, sel string, st *ucanv1.PolicyStatement) *ucanv1.PolicyStatement {
+	return &ucanv1.PolicyStatement{Statement: &ucanv1.PolicyStatement_Quantifier{
+		Quantifier: &ucanv1.QuantifierStatement{Operator: op, Selector: sel, Statement: st},
+	}}
+}
+
+const (
+	opEQ  = ucanv1.ComparisonOperator_COMPARISON_OPERATOR_EQUAL
+	opNE  = ucanv1.ComparisonOperator_COMPARISON_OPERATOR_NOT_EQUAL
+	opGT  = ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN
+	opGTE = ucanv1.ComparisonOperator_COMPARISON_OPERATOR_GREATER_THAN_OR_EQUAL
+	opLT  = ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN
+	opLTE = ucanv1.ComparisonOperator_COMPARISON_OPERATOR_LESS_THAN_OR_EQUAL
+	opAnd = ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_AND
+	opOr  = ucanv1.ConnectiveOperator_CONNECTIVE_OPERATOR_OR
+	opAll = ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ALL
+	opAny = ucanv1.QuantifierOperator_QUANTIFIER_OPERATOR_ANY
+)
+
+func TestParseSelector(t *testing.T) {
+	valid := map[string]string{
+		".":              ".",
+		".foo":           ".foo",
+		".foo.bar":       ".foo.bar",
+		".foo[0]":        ".foo[0]",
+		".foo[-1]":       ".foo[-1]",
+		".[]":            ".[]",
+		".foo[]":         ".foo[]",
+		".foo.[1]":       ".foo[1]",
+		`.["foo"]`:       ".foo",
+		`.["a b"].c`:     `.["a b"].c`,
+		`.["a\"]b"]`:     `.["a\"]b"]`,
+		".foo?.bar[0]?":  ".foo?.bar[0]?",
+		".items[]?.name": ".items[]?.name",
+	}
+	for in, want := range valid {
+		sel, err := ParseSelector(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, sel.String(), in)
+	}
+
+	for _, in := range []string{"", "foo", "..", ".foo.", ".foo[", ".foo[x]", `.["foo]`, `.["foo"`, ".?", ".foo??", ".foo bar", ".1abc"} {
+		_, err := ParseSelector(in)
+		assert.ErrorIs(t, err, ErrInvalidSelector, in)
+	}
+}
+
+func TestSelector_Select(t *testing.T) {
+	v := map[string]any{
+		"name":  "alice",
+		"tags":  []any{"a", "b", "c"},
+		"limit": map[string]any{"b": 2.0, "a": 1.0},
+		"items": []any{map[string]any{"n": 1.0}, map[string]any{"n": 2.0}},
+	}
+	tests := []struct {
+		sel  string
+		want []any
+	}{
+		{".", []any{v}},
+		{".name", []any{"alice"}},
+		{".tags[0]", []any{"a"}},
+		{".tags[-1]", []any{"c"}},
+		{".tags[]", []any{"a", "b", "c"}},
+		{".limit[]", []any{1.0, 2.0}},
+		{".items[].n", []any{1.0, 2.0}},
+		{".missing?", []any{nil}},
+		{".tags[9]?", []any{nil}},
+		{".name[]?", nil},
+	}
+	for _, tt := range tests {
+		sel, err := ParseSelector(tt.sel)
+		require.NoError(t, err)
+		got, err := sel.Select(v)
+		require.NoError(t, err, tt.sel)
+		assert.Equal(t, tt.want, got, tt.sel)
+	}
+
+	for _, in := range []string{".missing", ".tags[3]", ".name.first", ".name[]", ".tags.a"} {
+		sel, err := ParseSelector(in)
+		require.NoError(t, err)
+		_, err = sel.Select(v)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*@example.com", "alice@example.com", true},
+		{"*@example.com", "alice@example.org", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"*", "", true},
+		{"", "", true},
+		{"", "a", false},
+		{`\*`, "*", true},
+		{`\*`, "a", false},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{`*\*`, "stars*", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, globMatch(tt.pattern, tt.s), "%q ~ %q", tt.pattern, tt.s)
+	}
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	args := []byte(`{"amount": 42, "to": "bob@example.com", "cc": ["carol@example.com", "dave@example.org"], "memo": null, "flags": {"a": true, "b": false}, "none": []}`)
+	tests := []struct {
+		name string
+		st   *ucanv1.PolicyStatement
+		ok   bool
+	}{
+		{"equal", cmpStatement(t, opEQ, ".amount", 42), true},
+		{"equal null", cmpStatement(t, opEQ, ".memo", nil), true},
+		{"equal list", cmpStatement(t, opEQ, ".cc[0]", "carol@example.com"), true},
+		{"not equal", cmpStatement(t, opNE, ".amount", 42), false},
+		{"greater", cmpStatement(t, opGT, ".amount", 41), true},
+		{"greater equal", cmpStatement(t, opGTE, ".amount", 43), false},
+		{"less", cmpStatement(t, opLT, ".amount", 42), false},
+		{"less equal", cmpStatement(t, opLTE, ".amount", 42), true},
+		{"order on string", cmpStatement(t, opLT, ".to", 1), false},
+		{"like", likeStatement(".to", "*@example.com"), true},
+		{"like non-string", likeStatement(".amount", "*"), false},
+		{"and", connective(opAnd, cmpStatement(t, opGT, ".amount", 0), likeStatement(".to", "bob@*")), true},
+		{"and failing", connective(opAnd, cmpStatement(t, opGT, ".amount", 0), likeStatement(".to", "eve@*")), false},
+		{"empty and", connective(opAnd), true},
+		{"or", connective(opOr, cmpStatement(t, opGT, ".amount", 100), likeStatement(".to", "bob@*")), true},
+		{"or failing", connective(opOr, cmpStatement(t, opGT, ".amount", 100), likeStatement(".to", "eve@*")), false},
+		{"empty or", connective(opOr), true},
+		{"not", negation(cmpStatement(t, opEQ, ".amount", 0)), true},
+		{"not failing", negation(cmpStatement(t, opEQ, ".amount", 42)), false},
+		{"not missing", negation(cmpStatement(t, opEQ, ".missing", 1)), false},
+		{"not not missing", negation(negation(cmpStatement(t, opEQ, ".missing", 1))), false},
+		{"not optional", negation(cmpStatement(t, opEQ, ".missing?", 1)), true},
+		{"missing", cmpStatement(t, opEQ, ".missing", 1), false},
+		{"all", quantifier(opAll, ".cc", likeStatement(".", "*@example.*")), true},
+		{"all failing", quantifier(opAll, ".cc", likeStatement(".", "*@example.com")), false},
+		{"any", quantifier(opAny, ".cc", likeStatement(".", "*.org")), true},
+		{"any failing", quantifier(opAny, ".cc", likeStatement(".", "*.net")), false},
+		{"any empty", quantifier(opAny, ".none", likeStatement(".", "*")), false},
+		{"all over map", quantifier(opAll, ".flags", negation(cmpStatement(t, opEQ, ".", nil))), true},
+		{"any over map", quantifier(opAny, ".flags", cmpStatement(t, opEQ, ".", false)), true},
+		{"all over scalar", quantifier(opAll, ".amount", cmpStatement(t, opEQ, ".", 42)), false},
+		{"iterating selector", cmpStatement(t, opNE, ".cc[]", "eve@example.com"), true},
+		{"iterating selector failing", likeStatement(".cc[]", "*.com"), false},
+		{"empty statement", &ucanv1.PolicyStatement{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := EvaluatePolicy([]*ucanv1.PolicyStatement{tt.st}, args)
+			if tt.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrPolicyViolation)
+			var f *PolicyFailure
+			require.True(t, errors.As(err, &f))
+			assert.Equal(t, "pol[0]", f.Path)
+		})
+	}
+}
+
+func TestEvaluatePolicy_Arguments(t *testing.T) {
+	policy := []*ucanv1.PolicyStatement{
+		cmpStatement(t, opLTE, ".amount", 100),
+		likeStatement(".to", "*@example.com"),
+	}
+
+	amount, err := NewArgument(42)
+	require.NoError(t, err)
+	to, err := NewArgument("bob@example.com")
+	require.NoError(t, err)
+	require.NoError(t, EvaluatePolicy(policy, map[string]*anypb.Any{"amount": amount, "to": to}))
+
+	st, err := structpb.NewStruct(map[string]any{"amount": 42, "to": "bob@example.com"})
+	require.NoError(t, err)
+	require.NoError(t, EvaluatePolicy(policy, st))
+	require.NoError(t, EvaluatePolicy(policy, structpb.NewStructValue(st)))
+	require.NoError(t, EvaluatePolicy(policy, map[string]any{"amount": 42, "to": "bob@example.com"}))
+
+	err = EvaluatePolicy(policy, []byte(`{"amount": 420, "to": "bob@example.com"}`))
+	require.ErrorIs(t, err, ErrPolicyViolation)
+
+	_, err = policyInput([]byte(`{`))
+	require.Error(t, err)
+	require.NoError(t, EvaluatePolicy(nil, []byte(`{}`)))
+}
+
+func TestPolicyFailure_Trace(t *testing.T) {
+	err := EvaluatePolicy(testPolicy(t), []byte(`{"amount": 42, "to": "eve@example.org", "cc": ["root"]}`))
+	var f *PolicyFailure
+	require.True(t, errors.As(err, &f))
+	assert.Equal(t, "pol[1]", f.Path)
+	require.Len(t, f.Causes, 2)
+	assert.Equal(t, "pol[1].or[0]", f.Causes[0].Path)
+	assert.Equal(t, "eve@example.org", f.Causes[0].Value)
+	assert.Equal(t, "pol[1].or[1]", f.Causes[1].Path)
+
+	trace := f.Trace()
+	assert.Contains(t, trace, `pol[1] ["or",[`)
+	assert.Contains(t, trace, "\n  pol[1].or[0] [\"like\",\".to\",\"*@example.com\"]: does not match pattern\n")
+	assert.Contains(t, trace, "negated statement holds")
+	assert.Contains(t, err.Error(), "no disjunct holds")
+}
+
+func TestCheckPolicyAttenuation(t *testing.T) {
+	tests := []struct {
+		name          string
+		parent, child []*ucanv1.PolicyStatement
+		ok            bool
+	}{
+		{"empty parent", nil, []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".a", 1)}, true},
+		{"empty child", []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".a", 1)}, nil, false},
+		{"same", testPolicy(t), testPolicy(t), true},
+		{"extra statement", testPolicy(t), append(testPolicy(t), likeStatement(".to", "a*")), true},
+		{"dropped statement", testPolicy(t), testPolicy(t)[:1], false},
+		{"tighter upper bound", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".amount", 50)}, true},
+		{"strict at same bound", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".amount", 100)}, true},
+		{"inclusive at strict bound", []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, false},
+		{"looser upper bound", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 200)}, false},
+		{"tighter lower bound", []*ucanv1.PolicyStatement{cmpStatement(t, opGT, ".amount", 0)}, []*ucanv1.PolicyStatement{cmpStatement(t, opGTE, ".amount", 10)}, true},
+		{"opposite bound", []*ucanv1.PolicyStatement{cmpStatement(t, opGT, ".amount", 0)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".amount", 10)}, false},
+		{"other selector", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".fee", 50)}, false},
+		{"equivalent selector", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, `.["amount"]`, 50)}, true},
+		{"equal within bound", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opEQ, ".amount", 10)}, true},
+		{"equal outside bound", []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}, []*ucanv1.PolicyStatement{cmpStatement(t, opEQ, ".amount", 1000)}, false},
+		{"equal matches pattern", []*ucanv1.PolicyStatement{likeStatement(".to", "*@example.com")}, []*ucanv1.PolicyStatement{cmpStatement(t, opEQ, ".to", "bob@example.com")}, true},
+		{"equal misses pattern", []*ucanv1.PolicyStatement{likeStatement(".to", "*@example.com")}, []*ucanv1.PolicyStatement{cmpStatement(t, opEQ, ".to", "bob@example.org")}, false},
+		{"bound excludes value", []*ucanv1.PolicyStatement{cmpStatement(t, opNE, ".amount", 500)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".amount", 500)}, true},
+		{"bound includes value", []*ucanv1.PolicyStatement{cmpStatement(t, opNE, ".amount", 500)}, []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 500)}, false},
+		{"narrower disjunction", []*ucanv1.PolicyStatement{connective(opOr, likeStatement(".to", "a*"), likeStatement(".to", "b*"))}, []*ucanv1.PolicyStatement{likeStatement(".to", "b*")}, true},
+		{"wider disjunction", []*ucanv1.PolicyStatement{likeStatement(".to", "b*")}, []*ucanv1.PolicyStatement{connective(opOr, likeStatement(".to", "a*"), likeStatement(".to", "b*"))}, false},
+		{"disjunction of narrower", []*ucanv1.PolicyStatement{cmpStatement(t, opLT, ".amount", 100)}, []*ucanv1.PolicyStatement{connective(opOr, cmpStatement(t, opLT, ".amount", 10), cmpStatement(t, opEQ, ".amount", 50))}, true},
+		{"conjunction in parent", []*ucanv1.PolicyStatement{connective(opAnd, cmpStatement(t, opGT, ".amount", 0), cmpStatement(t, opLT, ".amount", 100))}, []*ucanv1.PolicyStatement{cmpStatement(t, opGT, ".amount", 1), cmpStatement(t, opLT, ".amount", 99)}, true},
+		{"negated pattern", []*ucanv1.PolicyStatement{negation(cmpStatement(t, opEQ, ".to", "root"))}, []*ucanv1.PolicyStatement{negation(likeStatement(".to", "r*"))}, true},
+		{"negated value", []*ucanv1.PolicyStatement{negation(likeStatement(".to", "r*"))}, []*ucanv1.PolicyStatement{negation(cmpStatement(t, opEQ, ".to", "root"))}, false},
+		{"narrower negation", []*ucanv1.PolicyStatement{negation(cmpStatement(t, opGT, ".amount", 100))}, []*ucanv1.PolicyStatement{negation(cmpStatement(t, opGT, ".amount", 50))}, true},
+		{"quantifier", []*ucanv1.PolicyStatement{quantifier(opAll, ".cc", cmpStatement(t, opLT, ".n", 10))}, []*ucanv1.PolicyStatement{quantifier(opAll, ".cc", cmpStatement(t, opLT, ".n", 5))}, true},
+		{"other quantifier", []*ucanv1.PolicyStatement{quantifier(opAll, ".cc", cmpStatement(t, opLT, ".n", 10))}, []*ucanv1.PolicyStatement{quantifier(opAny, ".cc", cmpStatement(t, opLT, ".n", 5))}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPolicyAttenuation(tt.parent, tt.child)
+			if tt.ok {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrPolicyEscalation)
+		})
+	}
+}
+
+func TestInvocation_StrictPolicyAttenuation(t *testing.T) {
+	ctx := context.Background()
+	a, b, c := newTestSigner(t, 0), newTestSigner(t, 0), newTestSigner(t, 0)
+	parties := []Signer{a, b, c}
+
+	store := NewMemTokenStore()
+	prf := delegationChain(t, store, parties, "/", func(i int, p *ucanv1.DelegationPayload) {
+		limit := 100
+		if i == 1 {
+			limit = 1000
+		}
+		p.Policy = []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", limit)}
+	})
+	inv := newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+
+	_, err := newEnvelopeParser(store).ParseInvocation(ctx, inv.Raw)
+	require.NoError(t, err)
+	_, err = newEnvelopeParser(store, WithStrictPolicyAttenuation()).ParseInvocation(ctx, inv.Raw)
+	requireChainError(t, err, ErrPolicyEscalation, 1)
+
+	store = NewMemTokenStore()
+	prf = delegationChain(t, store, parties, "/", func(i int, p *ucanv1.DelegationPayload) {
+		p.Policy = []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100-10*i)}
+	})
+	inv = newTestInvocation(t, c, a.Issuer(), "/vault", prf)
+	_, err = newEnvelopeParser(store, WithStrictPolicyAttenuation()).ParseInvocation(ctx, inv.Raw)
+	require.NoError(t, err)
+}
@@ -0,0 +1,193 @@
+package ucan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"google.golang.org/protobuf/proto"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+// receiptStatusPrefix is stripped from ReceiptStatus names in their envelope encoding
+const receiptStatusPrefix = "RECEIPT_STATUS_"
+
+// Receipt is a UCAN 1.0 receipt envelope with its decoded payload
+type Receipt struct {
+	*Envelope
+	Claims *ucanv1.ReceiptPayload
+}
+
+// receiptWire is the DAG-CBOR layout of a receipt payload. Out holds {"ok": result} for successful
+// invocations and {"err": error} for failed ones.
+type receiptWire struct {
+	Iss    string            `cbor:"iss"`
+	Aud    string            `cbor:"aud,omitempty"`
+	Ran    link              `cbor:"ran"`
+	Status string            `cbor:"status"`
+	Out    map[string]any    `cbor:"out"`
+	Next   []taskWire        `cbor:"next"`
+	Iat    int64             `cbor:"iat,omitempty"`
+	Meta   map[string][]byte `cbor:"meta,omitempty"`
+}
+
+type taskWire struct {
+	Sub   string         `cbor:"sub"`
+	Cmd   string         `cbor:"cmd"`
+	Args  map[string]any `cbor:"args"`
+	Nonce []byte         `cbor:"nonce"`
+}
+
+type errorWire struct {
+	Code    string            `cbor:"code"`
+	Message string            `cbor:"message"`
+	Details map[string]string `cbor:"details,omitempty"`
+}
+
+// IssueReceipt signs a receipt for inv with signer. The invocation CID is filled in from inv, the
+// audience defaults to the invoker and the issue time to now.
+func IssueReceipt(signer Signer, inv *Invocation, payload *ucanv1.ReceiptPayload) (*Receipt, error) {
+	claims := proto.Clone(payload).(*ucanv1.ReceiptPayload)
+	id, err := inv.CID()
+	if err != nil {
+		return nil, err
+	}
+	if claims.Invocation != "" && claims.Invocation != id.String() {
+		return nil, fmt.Errorf("receipt is for invocation %s, not %s", claims.Invocation, id)
+	}
+	claims.Invocation = id.String()
+	if claims.Audience == "" {
+		claims.Audience = inv.Claims.Issuer
+	}
+	if claims.IssuedAt == 0 {
+		claims.IssuedAt = time.Now().Unix()
+	}
+	return NewReceipt(signer, claims)
+}
+
+// NewReceipt signs a receipt of payload with signer. The issuer defaults to the signer.
+func NewReceipt(signer Signer, payload *ucanv1.ReceiptPayload) (*Receipt, error) {
+	claims := proto.Clone(payload).(*ucanv1.ReceiptPayload)
+	if err := fillIssuer(signer, &claims.Issuer); err != nil {
+		return nil, err
+	}
+	ran, err := cid.Decode(claims.Invocation)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invocation CID: %w", err)
+	}
+	if claims.Status == ucanv1.ReceiptStatus_RECEIPT_STATUS_UNSPECIFIED {
+		return nil, errors.New("receipt status is required")
+	}
+	if claims.Result != nil && claims.Error != nil {
+		return nil, errors.New("receipt cannot carry both a result and an error")
+	}
+
+	out := map[string]any{}
+	switch {
+	case claims.Error != nil:
+		out["err"] = errorWire{Code: claims.Error.Code, Message: claims.Error.Message, Details: claims.Error.Details}
+	case claims.Result != nil || claims.Status == ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS:
+		result, err := anyToIPLD(claims.Result)
+		if err != nil {
+			return nil, fmt.Errorf("result: %w", err)
+		}
+		out["ok"] = result
+	}
+	next := make([]taskWire, len(claims.Next))
+	for i, task := range claims.Next {
+		if err := validateCommand(task.Command); err != nil {
+			return nil, fmt.Errorf("next task %d: %w", i, err)
+		}
+		args, err := argumentsToIPLD(task.Arguments)
+		if err != nil {
+			return nil, fmt.Errorf("next task %d: %w", i, err)
+		}
+		next[i] = taskWire{Sub: task.Subject, Cmd: task.Command, Args: args, Nonce: task.Nonce}
+	}
+	wire := receiptWire{
+		Iss:    claims.Issuer,
+		Aud:    claims.Audience,
+		Ran:    link{ran},
+		Status: strings.ToLower(strings.TrimPrefix(claims.Status.String(), receiptStatusPrefix)),
+		Out:    out,
+		Next:   next,
+		Iat:    claims.IssuedAt,
+		Meta:   claims.Meta,
+	}
+	env, err := sealEnvelope(signer, ReceiptTag, wire)
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{Envelope: env, Claims: claims}, nil
+}
+
+// DecodeReceipt decodes a receipt envelope without verifying its signature
+func DecodeReceipt(data []byte) (*Receipt, error) {
+	env, err := DecodeEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	var wire receiptWire
+	if err := env.decodePayload(ReceiptTag, &wire); err != nil {
+		return nil, err
+	}
+	status, ok := ucanv1.ReceiptStatus_value[receiptStatusPrefix+strings.ToUpper(wire.Status)]
+	if !ok || status == int32(ucanv1.ReceiptStatus_RECEIPT_STATUS_UNSPECIFIED) {
+		return nil, fmt.Errorf("%w: unknown receipt status %q", ErrInvalidEnvelope, wire.Status)
+	}
+	claims := &ucanv1.ReceiptPayload{
+		Issuer:     wire.Iss,
+		Audience:   wire.Aud,
+		Invocation: wire.Ran.String(),
+		Status:     ucanv1.ReceiptStatus(status),
+		IssuedAt:   wire.Iat,
+		Meta:       wire.Meta,
+	}
+	if result, ok := wire.Out["ok"]; ok && result != nil {
+		claims.Result, err = ipldToAny(result)
+		if err != nil {
+			return nil, fmt.Errorf("%w: out: %v", ErrInvalidEnvelope, err)
+		}
+	}
+	if e, ok := wire.Out["err"].(map[string]any); ok {
+		info := &ucanv1.ErrorInfo{}
+		info.Code, _ = e["code"].(string)
+		info.Message, _ = e["message"].(string)
+		if details, ok := e["details"].(map[string]any); ok {
+			info.Details = make(map[string]string, len(details))
+			for k, v := range details {
+				info.Details[k], _ = v.(string)
+			}
+		}
+		claims.Error = info
+	}
+	for i, task := range wire.Next {
+		args, err := argumentsFromIPLD(task.Args)
+		if err != nil {
+			return nil, fmt.Errorf("%w: next task %d: %v", ErrInvalidEnvelope, i, err)
+		}
+		claims.Next = append(claims.Next, &ucanv1.Task{
+			Subject:   task.Sub,
+			Command:   task.Cmd,
+			Arguments: args,
+			Nonce:     task.Nonce,
+		})
+	}
+	return &Receipt{Envelope: env, Claims: claims}, nil
+}
+
+// ParseReceipt decodes a receipt and verifies the signature of its issuer
+func (p *TokenParser) ParseReceipt(ctx context.Context, data []byte) (*Receipt, error) {
+	r, err := DecodeReceipt(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verifyEnvelope(ctx, r.Envelope, r.Claims.Issuer, 0, 0); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
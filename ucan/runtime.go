@@ -0,0 +1,276 @@
+package ucan
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+// Error codes of the receipts a Runtime issues for invocations it does not run to success
+const (
+	// ReceiptCodeUnauthorized rejects invocations that fail validation against their proofs
+	ReceiptCodeUnauthorized = "unauthorized"
+	// ReceiptCodeWrongExecutor rejects invocations addressed to another executor
+	ReceiptCodeWrongExecutor = "wrong_executor"
+	// ReceiptCodeUnknownCommand rejects invocations of commands without a handler
+	ReceiptCodeUnknownCommand = "unknown_command"
+	// ReceiptCodeExecutionFailed reports handler errors that are not a *HandlerError
+	ReceiptCodeExecutionFailed = "execution_failed"
+	// ReceiptCodeInternal reports handlers that panicked
+	ReceiptCodeInternal = "internal"
+)
+
+// DefaultMaxInvocationLifetime bounds how far in the future the expiration of an invocation a
+// Runtime executes may be
+const DefaultMaxInvocationLifetime = 10 * time.Minute
+
+// ErrHandlerExists is returned when a command already has a handler
+var ErrHandlerExists = errors.New("command already has a handler")
+
+// Handler executes an invocation that was validated against its delegation chain. A returned
+// error fails the invocation, use a *HandlerError to control the error reported in the receipt.
+type Handler func(ctx context.Context, inv *Invocation) (*Outcome, error)
+
+// Outcome is the result of a handler
+type Outcome struct {
+	// Status defaults to RECEIPT_STATUS_SUCCESS, handlers that finish work asynchronously may report
+	// RECEIPT_STATUS_ACCEPTED or RECEIPT_STATUS_PROCESSING
+	Status ucanv1.ReceiptStatus
+	// Result is the output of the command
+	Result *anypb.Any
+	// Next are tasks the invoker should run next, they are given a nonce if they have none
+	Next []*ucanv1.Task
+	// Meta is copied to the receipt
+	Meta map[string][]byte
+}
+
+// HandlerError is a handler failure reported in the receipt with its code and details
+type HandlerError struct {
+	Code    string
+	Message string
+	Details map[string]string
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Runtime validates UCAN 1.0 invocations, dispatches them to the handler registered for their
+// command and signs a receipt of the outcome. Each invocation is executed at most once: the runtime
+// remembers the invocations it executed until they expire, and rejects them when they are replayed.
+// Invocations must expire within the maximum lifetime of the runtime, so that it remembers each of
+// them for a bounded time.
+type Runtime struct {
+	signer      Signer
+	parser      *TokenParser
+	maxLifetime time.Duration
+
+	handlersLk sync.RWMutex
+	handlers   map[string]Handler
+
+	executedLk sync.Mutex
+	executed   map[string]struct{} // executed invocations, keyed by issuer and nonce
+	expiries   executedHeap        // executed invocations, soonest expiration first
+}
+
+// RuntimeOption configures a Runtime
+type RuntimeOption func(*Runtime)
+
+// WithMaxInvocationLifetime sets how far in the future the expiration of an invocation the runtime
+// executes may be, DefaultMaxInvocationLifetime by default
+func WithMaxInvocationLifetime(d time.Duration) RuntimeOption {
+	return func(r *Runtime) {
+		r.maxLifetime = d
+	}
+}
+
+// NewRuntime creates a runtime that executes invocations addressed to signer, validating them with
+// parser
+func NewRuntime(signer Signer, parser *TokenParser, opts ...RuntimeOption) *Runtime {
+	r := &Runtime{
+		signer:      signer,
+		parser:      parser,
+		maxLifetime: DefaultMaxInvocationLifetime,
+		handlers:    map[string]Handler{},
+		executed:    map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Handle registers the handler of a command, e.g. "/vault/sign"
+func (r *Runtime) Handle(command string, h Handler) error {
+	if err := validateCommand(command); err != nil {
+		return err
+	}
+	r.handlersLk.Lock()
+	defer r.handlersLk.Unlock()
+
+	if _, ok := r.handlers[command]; ok {
+		return fmt.Errorf("%w: %s", ErrHandlerExists, command)
+	}
+	r.handlers[command] = h
+	return nil
+}
+
+// HandleCapability registers the handler of the command a capability maps to
+func (r *Runtime) HandleCapability(c HierarchicalCapability, h Handler) error {
+	if c.Command() == "" {
+		return fmt.Errorf("capability %q maps to no command", c)
+	}
+	return r.Handle(c.Command(), h)
+}
+
+func (r *Runtime) handler(command string) (Handler, bool) {
+	r.handlersLk.RLock()
+	defer r.handlersLk.RUnlock()
+
+	h, ok := r.handlers[command]
+	return h, ok
+}
+
+// Execute validates an invocation envelope, runs its handler and returns the signed receipt.
+// Invocations are executed by their audience, or their subject when they have none. Invocations
+// that fail validation, are addressed to another executor or invoke an unknown command are
+// rejected, handler errors are reported as failures. An error is only returned when no receipt can
+// be issued, because data is not an invocation or signing fails. An invocation that was already
+// executed, or that does not expire within the maximum lifetime, is rejected as unauthorized.
+func (r *Runtime) Execute(ctx context.Context, data []byte) (*Receipt, error) {
+	decoded, err := DecodeInvocation(data)
+	if err != nil {
+		return nil, err
+	}
+	executor := decoded.Claims.Audience
+	if executor == "" {
+		executor = decoded.Claims.Subject
+	}
+	if executor != r.signer.Issuer() {
+		return r.reject(decoded, ReceiptCodeWrongExecutor, fmt.Sprintf("invocation is addressed to %s", executor))
+	}
+	inv, err := r.parser.ParseInvocation(ctx, data)
+	if err != nil {
+		return r.reject(decoded, ReceiptCodeUnauthorized, err.Error())
+	}
+	h, ok := r.handler(inv.Claims.Command)
+	if !ok {
+		return r.reject(inv, ReceiptCodeUnknownCommand, fmt.Sprintf("no handler for %s", inv.Claims.Command))
+	}
+	if exp := inv.Claims.Expiration; exp == 0 || exp > r.parser.now().Add(r.maxLifetime).Unix() {
+		return r.reject(inv, ReceiptCodeUnauthorized, fmt.Sprintf("invocation must expire within %s", r.maxLifetime))
+	}
+	if !r.markExecuted(inv) {
+		return r.reject(inv, ReceiptCodeUnauthorized, "invocation was already executed")
+	}
+
+	out, err := runHandler(ctx, h, inv)
+	if err != nil {
+		info := &ucanv1.ErrorInfo{Code: ReceiptCodeExecutionFailed, Message: err.Error()}
+		var he *HandlerError
+		if errors.As(err, &he) {
+			info = &ucanv1.ErrorInfo{Code: he.Code, Message: he.Message, Details: he.Details}
+		}
+		return r.issue(inv, &ucanv1.ReceiptPayload{Status: ucanv1.ReceiptStatus_RECEIPT_STATUS_ERROR, Error: info})
+	}
+
+	payload := &ucanv1.ReceiptPayload{Status: ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS}
+	if out != nil {
+		if out.Status != ucanv1.ReceiptStatus_RECEIPT_STATUS_UNSPECIFIED {
+			payload.Status = out.Status
+		}
+		payload.Result = out.Result
+		payload.Meta = out.Meta
+		for _, task := range out.Next {
+			task = proto.Clone(task).(*ucanv1.Task)
+			if err := fillNonce(&task.Nonce); err != nil {
+				return nil, err
+			}
+			payload.Next = append(payload.Next, task)
+		}
+	}
+	return r.issue(inv, payload)
+}
+
+// markExecuted records that inv is executed, and reports false if it already was. Invocations are
+// identified by their issuer and nonce, which the signature covers whatever the encoding of the
+// envelope, or by their CID when they have no nonce. They are forgotten once expired, since the
+// parser rejects them from then on.
+func (r *Runtime) markExecuted(inv *Invocation) bool {
+	r.executedLk.Lock()
+	defer r.executedLk.Unlock()
+
+	now := r.parser.now().Unix()
+	for len(r.expiries) > 0 && now >= r.expiries[0].exp {
+		delete(r.executed, heap.Pop(&r.expiries).(executedEntry).key)
+	}
+	key := inv.Claims.Issuer + "\x00" + string(inv.Claims.Nonce)
+	if len(inv.Claims.Nonce) == 0 {
+		// without a nonce, only the exact same payload is the same invocation
+		id, err := inv.CID()
+		if err != nil {
+			return false
+		}
+		key = id.String()
+	}
+	if _, ok := r.executed[key]; ok {
+		return false
+	}
+	r.executed[key] = struct{}{}
+	heap.Push(&r.expiries, executedEntry{key: key, exp: inv.Claims.Expiration})
+	return true
+}
+
+// executedEntry is an executed invocation and its expiration
+type executedEntry struct {
+	key string
+	exp int64
+}
+
+// executedHeap is a heap.Interface of executed invocations ordered by expiration
+type executedHeap []executedEntry
+
+func (h executedHeap) Len() int           { return len(h) }
+func (h executedHeap) Less(i, j int) bool { return h[i].exp < h[j].exp }
+func (h executedHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *executedHeap) Push(x any) {
+	*h = append(*h, x.(executedEntry))
+}
+
+func (h *executedHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// runHandler calls h, turning a panic into a HandlerError
+func runHandler(ctx context.Context, h Handler, inv *Invocation) (out *Outcome, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			out, err = nil, &HandlerError{Code: ReceiptCodeInternal, Message: fmt.Sprint(v)}
+		}
+	}()
+	return h(ctx, inv)
+}
+
+func (r *Runtime) reject(inv *Invocation, code, msg string) (*Receipt, error) {
+	return r.issue(inv, &ucanv1.ReceiptPayload{
+		Status: ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED,
+		Error:  &ucanv1.ErrorInfo{Code: code, Message: msg},
+	})
+}
+
+func (r *Runtime) issue(inv *Invocation, payload *ucanv1.ReceiptPayload) (*Receipt, error) {
+	payload.IssuedAt = r.parser.now().Unix()
+	return IssueReceipt(r.signer, inv, payload)
+}
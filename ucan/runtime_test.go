@@ -0,0 +1,238 @@
+package ucan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+func TestRuntime_Execute(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemTokenStore()
+	subject, invoker := newTestSigner(t, crypto.Ed25519), newTestSigner(t, crypto.Secp256k1)
+	prf := delegationChain(t, store, []Signer{subject, invoker}, "/vault", func(i int, p *ucanv1.DelegationPayload) {
+		p.Policy = []*ucanv1.PolicyStatement{cmpStatement(t, opLTE, ".amount", 100)}
+	})
+
+	rt := NewRuntime(subject, newEnvelopeParser(store))
+	var calls int
+	require.NoError(t, rt.HandleCapability(VaultPermissions.NewCap("sign").(HierarchicalCapability), func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		calls++
+		amount, err := ArgumentValue(inv.Claims.Arguments["amount"])
+		require.NoError(t, err)
+		result, err := NewArgument(map[string]any{"signed": amount})
+		require.NoError(t, err)
+		return &Outcome{
+			Result: result,
+			Next:   []*ucanv1.Task{{Subject: inv.Claims.Subject, Command: "/vault/verify"}},
+			Meta:   map[string][]byte{"trace": []byte("1")},
+		}, nil
+	}))
+	require.NoError(t, rt.Handle("/vault/refresh", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		return nil, errors.New("enclave offline")
+	}))
+	require.NoError(t, rt.Handle("/vault/verify", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		return nil, &HandlerError{Code: "bad_signature", Message: "signature does not verify", Details: map[string]string{"alg": "es256k"}}
+	}))
+	require.NoError(t, rt.Handle("/vault/export", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		panic("boom")
+	}))
+	assert.ErrorIs(t, rt.Handle("/vault/sign", nil), ErrHandlerExists)
+	assert.Error(t, rt.Handle("vault", nil))
+
+	parser := newEnvelopeParser(NewMemTokenStore())
+	execute := func(inv *Invocation) *Receipt {
+		t.Helper()
+		r, err := rt.Execute(ctx, inv.Raw)
+		require.NoError(t, err)
+		parsed, err := parser.ParseReceipt(ctx, r.Raw)
+		require.NoError(t, err)
+		id, err := inv.CID()
+		require.NoError(t, err)
+		assert.Equal(t, id.String(), parsed.Claims.Invocation)
+		assert.Equal(t, subject.Issuer(), parsed.Claims.Issuer)
+		assert.Equal(t, invoker.Issuer(), parsed.Claims.Audience)
+		return parsed
+	}
+
+	r := execute(newTestInvocation(t, invoker, subject.Issuer(), "/vault/sign", prf))
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS, r.Claims.Status)
+	result, err := ArgumentValue(r.Claims.Result)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"signed": float64(42)}, result)
+	require.Len(t, r.Claims.Next, 1)
+	assert.Equal(t, "/vault/verify", r.Claims.Next[0].Command)
+	assert.Len(t, r.Claims.Next[0].Nonce, nonceSize)
+	assert.Equal(t, []byte("1"), r.Claims.Meta["trace"])
+	assert.Equal(t, 1, calls)
+
+	failures := []struct {
+		name   string
+		inv    *Invocation
+		status ucanv1.ReceiptStatus
+		code   string
+	}{
+		{"handler error", newTestInvocation(t, invoker, subject.Issuer(), "/vault/refresh", prf), ucanv1.ReceiptStatus_RECEIPT_STATUS_ERROR, ReceiptCodeExecutionFailed},
+		{"handler error code", newTestInvocation(t, invoker, subject.Issuer(), "/vault/verify", prf), ucanv1.ReceiptStatus_RECEIPT_STATUS_ERROR, "bad_signature"},
+		{"panic", newTestInvocation(t, invoker, subject.Issuer(), "/vault/export", prf), ucanv1.ReceiptStatus_RECEIPT_STATUS_ERROR, ReceiptCodeInternal},
+		{"unknown command", newTestInvocation(t, invoker, subject.Issuer(), "/vault/burn", prf), ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, ReceiptCodeUnknownCommand},
+		{"unauthorized command", newTestInvocation(t, invoker, subject.Issuer(), "/account/transfer", prf), ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, ReceiptCodeUnauthorized},
+		{"missing proofs", newTestInvocation(t, invoker, subject.Issuer(), "/vault/sign", nil), ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, ReceiptCodeUnauthorized},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			r := execute(tt.inv)
+			assert.Equal(t, tt.status, r.Claims.Status)
+			require.NotNil(t, r.Claims.Error)
+			assert.Equal(t, tt.code, r.Claims.Error.Code)
+			assert.Nil(t, r.Claims.Result)
+		})
+	}
+	assert.Equal(t, 1, calls)
+
+	t.Run("handler error details", func(t *testing.T) {
+		r := execute(newTestInvocation(t, invoker, subject.Issuer(), "/vault/verify", prf))
+		assert.Equal(t, map[string]string{"alg": "es256k"}, r.Claims.Error.Details)
+		assert.Equal(t, "signature does not verify", r.Claims.Error.Message)
+	})
+
+	t.Run("policy violation", func(t *testing.T) {
+		amount, err := NewArgument(1000)
+		require.NoError(t, err)
+		inv, err := NewInvocation(invoker, &ucanv1.InvocationPayload{
+			Subject:   subject.Issuer(),
+			Command:   "/vault/sign",
+			Arguments: map[string]*anypb.Any{"amount": amount},
+			ProofCids: prf,
+		})
+		require.NoError(t, err)
+		r := execute(inv)
+		assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, r.Claims.Status)
+		assert.Equal(t, ReceiptCodeUnauthorized, r.Claims.Error.Code)
+		assert.Contains(t, r.Claims.Error.Message, ErrPolicyViolation.Error())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("wrong executor", func(t *testing.T) {
+		inv, err := NewInvocation(invoker, &ucanv1.InvocationPayload{
+			Subject:   subject.Issuer(),
+			Audience:  invoker.Issuer(),
+			Command:   "/vault/sign",
+			ProofCids: prf,
+		})
+		require.NoError(t, err)
+		r := execute(inv)
+		assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, r.Claims.Status)
+		assert.Equal(t, ReceiptCodeWrongExecutor, r.Claims.Error.Code)
+	})
+
+	t.Run("not an invocation", func(t *testing.T) {
+		d, err := NewDelegation(subject, &ucanv1.DelegationPayload{Audience: invoker.Issuer(), Command: "/"})
+		require.NoError(t, err)
+		_, err = rt.Execute(ctx, d.Raw)
+		assert.ErrorIs(t, err, ErrInvalidEnvelope)
+	})
+}
+
+func TestRuntime_Outcome(t *testing.T) {
+	ctx := context.Background()
+	subject := newTestSigner(t, crypto.Ed25519)
+	rt := NewRuntime(subject, newEnvelopeParser(NewMemTokenStore()))
+	require.NoError(t, rt.Handle("/vault/refresh", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		return &Outcome{Status: ucanv1.ReceiptStatus_RECEIPT_STATUS_ACCEPTED}, nil
+	}))
+	require.NoError(t, rt.Handle("/vault/verify", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		return nil, nil
+	}))
+
+	// the subject invokes itself without proofs
+	r, err := rt.Execute(ctx, newTestInvocation(t, subject, subject.Issuer(), "/vault/refresh", nil).Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_ACCEPTED, r.Claims.Status)
+
+	r, err = rt.Execute(ctx, newTestInvocation(t, subject, subject.Issuer(), "/vault/verify", nil).Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS, r.Claims.Status)
+	assert.Nil(t, r.Claims.Error)
+}
+
+func TestRuntime_Replay(t *testing.T) {
+	ctx := context.Background()
+	subject := newTestSigner(t, crypto.Ed25519)
+	now := time.Now()
+	rt := NewRuntime(subject, newEnvelopeParser(NewMemTokenStore(), WithClock(func() time.Time { return now })))
+	var calls int
+	require.NoError(t, rt.Handle("/vault/sign", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		calls++
+		return nil, nil
+	}))
+	invocation := func(exp time.Time) *Invocation {
+		t.Helper()
+		payload := &ucanv1.InvocationPayload{Subject: subject.Issuer(), Command: "/vault/sign"}
+		if !exp.IsZero() {
+			payload.Expiration = exp.Unix()
+		}
+		inv, err := NewInvocation(subject, payload)
+		require.NoError(t, err)
+		return inv
+	}
+
+	inv := invocation(now.Add(time.Minute))
+	r, err := rt.Execute(ctx, inv.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS, r.Claims.Status)
+
+	// the same invocation is not executed twice
+	r, err = rt.Execute(ctx, inv.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, r.Claims.Status)
+	assert.Equal(t, ReceiptCodeUnauthorized, r.Claims.Error.Code)
+	assert.Equal(t, 1, calls)
+
+	// another invocation of the same command is
+	r, err = rt.Execute(ctx, invocation(now.Add(5*time.Minute)).Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS, r.Claims.Status)
+	assert.Equal(t, 2, calls)
+
+	// invocations that would have to be remembered forever or for too long are not executed
+	for _, exp := range []time.Time{{}, now.Add(DefaultMaxInvocationLifetime + time.Minute)} {
+		r, err = rt.Execute(ctx, invocation(exp).Raw)
+		require.NoError(t, err)
+		assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_REJECTED, r.Claims.Status)
+		assert.Equal(t, ReceiptCodeUnauthorized, r.Claims.Error.Code)
+	}
+	assert.Equal(t, 2, calls)
+	assert.Len(t, rt.executed, 2)
+
+	// once expired, the invocation is forgotten and rejected by the parser instead, while the
+	// invocation that expires later is still remembered
+	now = now.Add(2 * time.Minute)
+	r, err = rt.Execute(ctx, inv.Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ReceiptCodeUnauthorized, r.Claims.Error.Code)
+	assert.Contains(t, r.Claims.Error.Message, ErrExpired.Error())
+	assert.Equal(t, 2, calls)
+	r, err = rt.Execute(ctx, invocation(now.Add(time.Minute)).Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ucanv1.ReceiptStatus_RECEIPT_STATUS_SUCCESS, r.Claims.Status)
+	assert.Len(t, rt.executed, 2)
+	assert.Len(t, rt.expiries, 2)
+
+	// the lifetime is configurable
+	short := NewRuntime(subject, newEnvelopeParser(NewMemTokenStore(), WithClock(func() time.Time { return now })), WithMaxInvocationLifetime(30*time.Second))
+	require.NoError(t, short.Handle("/vault/sign", func(ctx context.Context, inv *Invocation) (*Outcome, error) {
+		return nil, nil
+	}))
+	r, err = short.Execute(ctx, invocation(now.Add(time.Minute)).Raw)
+	require.NoError(t, err)
+	assert.Equal(t, ReceiptCodeUnauthorized, r.Claims.Error.Code)
+}
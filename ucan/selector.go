@@ -0,0 +1,281 @@
+package ucan
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSelector is returned when a policy selector cannot be parsed
+var ErrInvalidSelector = errors.New("invalid policy selector")
+
+type segmentKind int
+
+const (
+	segmentField segmentKind = iota
+	segmentIndex
+	segmentIterate
+)
+
+// selectorSegment is a single step of a selector
+type selectorSegment struct {
+	kind     segmentKind
+	field    string
+	index    int
+	optional bool
+}
+
+func (s selectorSegment) String() string {
+	var str string
+	switch s.kind {
+	case segmentField:
+		str = "." + s.field
+		if !isIdentifier(s.field) {
+			str = "[" + strconv.Quote(s.field) + "]"
+		}
+	case segmentIndex:
+		str = "[" + strconv.Itoa(s.index) + "]"
+	case segmentIterate:
+		str = "[]"
+	}
+	if s.optional {
+		str += "?"
+	}
+	return str
+}
+
+// Selector is a parsed jq-like path into invocation arguments, e.g. ".foo.bar[0]", ".items[]" or
+// ".maybe?". The identity selector "." selects the whole value.
+type Selector []selectorSegment
+
+// ParseSelector parses a policy selector. Selectors start with ".", followed by fields (.name or
+// ["quoted name"]), list indices ([0], negative indices count from the end) and iterators ([]) over
+// every element of a list or value of a map. A "?" after a segment makes it optional: a missing
+// field or index selects null instead of failing, and an iterator over a non-collection selects
+// nothing.
+func ParseSelector(s string) (Selector, error) {
+	if !strings.HasPrefix(s, ".") {
+		return nil, fmt.Errorf("%w: %q must start with '.'", ErrInvalidSelector, s)
+	}
+	var (
+		sel Selector
+		pos = 1
+	)
+	fail := func(reason string) (Selector, error) {
+		return nil, fmt.Errorf("%w: %q at offset %d: %s", ErrInvalidSelector, s, pos, reason)
+	}
+	// the leading '.' may be followed directly by a field name
+	if pos < len(s) && s[pos] != '[' {
+		end := identifierEnd(s, pos)
+		if end == pos {
+			return fail("expected a field name or '['")
+		}
+		sel = append(sel, selectorSegment{kind: segmentField, field: s[pos:end]})
+		pos = end
+	}
+	for pos < len(s) {
+		switch s[pos] {
+		case '?':
+			if len(sel) == 0 || sel[len(sel)-1].optional {
+				return fail("unexpected '?'")
+			}
+			sel[len(sel)-1].optional = true
+			pos++
+		case '.':
+			pos++
+			if pos < len(s) && s[pos] == '[' {
+				continue
+			}
+			end := identifierEnd(s, pos)
+			if end == pos {
+				return fail("expected a field name")
+			}
+			sel = append(sel, selectorSegment{kind: segmentField, field: s[pos:end]})
+			pos = end
+		case '[':
+			seg, end, err := parseBracket(s, pos)
+			if err != nil {
+				return fail(err.Error())
+			}
+			sel = append(sel, seg)
+			pos = end
+		default:
+			return fail(fmt.Sprintf("unexpected %q", s[pos]))
+		}
+	}
+	return sel, nil
+}
+
+// parseBracket parses the bracketed segment starting at s[pos] == '['
+func parseBracket(s string, pos int) (selectorSegment, int, error) {
+	rest := s[pos+1:]
+	switch {
+	case strings.HasPrefix(rest, "]"):
+		return selectorSegment{kind: segmentIterate}, pos + 2, nil
+	case strings.HasPrefix(rest, `"`):
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return selectorSegment{}, 0, errors.New("unterminated quoted field")
+		}
+		field, err := strconv.Unquote(quoted)
+		if err != nil {
+			return selectorSegment{}, 0, err
+		}
+		end := pos + 1 + len(quoted)
+		if end >= len(s) || s[end] != ']' {
+			return selectorSegment{}, 0, errors.New("expected ']' after quoted field")
+		}
+		return selectorSegment{kind: segmentField, field: field}, end + 1, nil
+	default:
+		closing := strings.IndexByte(rest, ']')
+		if closing < 0 {
+			return selectorSegment{}, 0, errors.New("expected ']'")
+		}
+		idx, err := strconv.Atoi(rest[:closing])
+		if err != nil {
+			return selectorSegment{}, 0, fmt.Errorf("invalid index %q", rest[:closing])
+		}
+		return selectorSegment{kind: segmentIndex, index: idx}, pos + closing + 2, nil
+	}
+}
+
+func identifierEnd(s string, pos int) int {
+	end := pos
+	for end < len(s) {
+		c := s[end]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || end > pos && c >= '0' && c <= '9' {
+			end++
+			continue
+		}
+		break
+	}
+	return end
+}
+
+func isIdentifier(s string) bool {
+	return s != "" && identifierEnd(s, 0) == len(s)
+}
+
+// String formats the selector in its canonical form
+func (sel Selector) String() string {
+	if len(sel) == 0 {
+		return "."
+	}
+	var sb strings.Builder
+	for i, seg := range sel {
+		str := seg.String()
+		if i == 0 && !strings.HasPrefix(str, ".") {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(str)
+	}
+	return sb.String()
+}
+
+// Iterates reports whether the selector can select more than one value
+func (sel Selector) Iterates() bool {
+	for _, seg := range sel {
+		if seg.kind == segmentIterate {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the values the selector picks out of v. v is a plain Go value as produced by
+// encoding/json or structpb.Value.AsInterface. Without iterators a selector selects exactly one
+// value.
+func (sel Selector) Select(v any) ([]any, error) {
+	values := []any{v}
+	for i, seg := range sel {
+		var next []any
+		for _, val := range values {
+			selected, err := seg.apply(val)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", Selector(sel[:i+1]), err)
+			}
+			next = append(next, selected...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+func (seg selectorSegment) apply(v any) ([]any, error) {
+	switch seg.kind {
+	case segmentField:
+		m, ok := v.(map[string]any)
+		if !ok {
+			return seg.missing(fmt.Errorf("cannot select field %q of %s", seg.field, kindOf(v)))
+		}
+		val, ok := m[seg.field]
+		if !ok {
+			return seg.missing(fmt.Errorf("missing field %q", seg.field))
+		}
+		return []any{val}, nil
+	case segmentIndex:
+		list, ok := v.([]any)
+		if !ok {
+			return seg.missing(fmt.Errorf("cannot index %s", kindOf(v)))
+		}
+		idx := seg.index
+		if idx < 0 {
+			idx += len(list)
+		}
+		if idx < 0 || idx >= len(list) {
+			return seg.missing(fmt.Errorf("index %d out of range for list of %d", seg.index, len(list)))
+		}
+		return []any{list[idx]}, nil
+	default:
+		switch c := v.(type) {
+		case []any:
+			return c, nil
+		case map[string]any:
+			keys := make([]string, 0, len(c))
+			for k := range c {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			values := make([]any, len(keys))
+			for i, k := range keys {
+				values[i] = c[k]
+			}
+			return values, nil
+		default:
+			if seg.optional {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("cannot iterate over %s", kindOf(v))
+		}
+	}
+}
+
+// missing returns null for optional segments and err otherwise
+func (seg selectorSegment) missing(err error) ([]any, error) {
+	if seg.optional {
+		return []any{nil}, nil
+	}
+	return nil, err
+}
+
+func kindOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case string:
+		return "a string"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a map"
+	default:
+		if _, ok := toNumber(v); ok {
+			return "a number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
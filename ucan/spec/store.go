@@ -0,0 +1,310 @@
+// Package spec holds the conformance tests implementations of ucan interfaces must pass. The
+// assertions are meant to be run from the tests of each implementation.
+package spec
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/ucan"
+	ucanv1 "github.com/sonr-io/crypto/ucan/types/v1"
+)
+
+// party issues test tokens
+type party struct {
+	src    ucan.Source
+	signer ucan.Signer
+	did    string
+}
+
+func newParty(t *testing.T) party {
+	t.Helper()
+	priv, _, err := crypto.GenerateEd25519Key(rand.Reader)
+	require.NoError(t, err)
+	src, err := ucan.NewPrivKeySource(priv)
+	require.NoError(t, err)
+	signer, err := ucan.NewPrivKeySigner(priv)
+	require.NoError(t, err)
+	return party{src: src, signer: signer, did: signer.Issuer()}
+}
+
+// jwt issues a 0.7 token to aud
+func (p party) jwt(t *testing.T, aud party, exp time.Time) string {
+	t.Helper()
+	tok, err := p.src.NewOriginToken(aud.did, nil, nil, time.Time{}, exp)
+	require.NoError(t, err)
+	return tok.Raw
+}
+
+// envelope issues a UCAN 1.0 delegation to aud
+func (p party) envelope(t *testing.T, aud party, exp time.Time) string {
+	t.Helper()
+	payload := &ucanv1.DelegationPayload{Audience: aud.did, Subject: p.did, Command: "/"}
+	if !exp.IsZero() {
+		payload.Expiration = exp.Unix()
+	}
+	d, err := ucan.NewDelegation(p.signer, payload)
+	require.NoError(t, err)
+	return string(d.Raw)
+}
+
+func tokenCID(t *testing.T, raw string) cid.Cid {
+	t.Helper()
+	id, err := (&ucan.Token{Raw: raw}).CID()
+	require.NoError(t, err)
+	return id
+}
+
+func keys(toks []ucan.RawToken) []string {
+	out := make([]string, len(toks))
+	for i, tok := range toks {
+		out[i] = tok.Key
+	}
+	return out
+}
+
+// AssertTokenStore checks the behaviour every TokenStore must have. newStore must return a new,
+// empty store each time it is called.
+func AssertTokenStore(t *testing.T, newStore func(t *testing.T) ucan.TokenStore) {
+	ctx := context.Background()
+	alice, bob := newParty(t), newParty(t)
+
+	t.Run("put and get", func(t *testing.T) {
+		store := newStore(t)
+		jwt, env := alice.jwt(t, bob, time.Time{}), alice.envelope(t, bob, time.Time{})
+		require.NoError(t, store.PutToken(ctx, "jwt", jwt))
+		require.NoError(t, store.PutToken(ctx, "env", env))
+
+		raw, err := store.RawToken(ctx, "jwt")
+		require.NoError(t, err)
+		assert.Equal(t, jwt, raw)
+		raw, err = store.RawToken(ctx, "env")
+		require.NoError(t, err)
+		assert.Equal(t, env, raw)
+
+		// putting a key again replaces its token
+		other := bob.jwt(t, alice, time.Time{})
+		require.NoError(t, store.PutToken(ctx, "jwt", other))
+		raw, err = store.RawToken(ctx, "jwt")
+		require.NoError(t, err)
+		assert.Equal(t, other, raw)
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		store := newStore(t)
+		for _, raw := range []string{"", "not a token", "a.b.c", "\x82\x00"} {
+			assert.ErrorIs(t, store.PutToken(ctx, "key", raw), ucan.ErrInvalidToken, "%q", raw)
+		}
+		_, err := store.RawToken(ctx, "key")
+		assert.ErrorIs(t, err, ucan.ErrTokenNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.PutToken(ctx, "a", alice.jwt(t, bob, time.Time{})))
+		require.NoError(t, store.DeleteToken(ctx, "a"))
+		_, err := store.RawToken(ctx, "a")
+		assert.ErrorIs(t, err, ucan.ErrTokenNotFound)
+		assert.ErrorIs(t, store.DeleteToken(ctx, "a"), ucan.ErrTokenNotFound)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		store := newStore(t)
+		toks, err := store.ListTokens(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, toks)
+
+		for _, key := range []string{"c", "a", "d", "b"} {
+			require.NoError(t, store.PutToken(ctx, key, alice.jwt(t, bob, time.Time{})))
+		}
+		toks, err = store.ListTokens(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, keys(toks))
+		toks, err = store.ListTokens(ctx, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c"}, keys(toks))
+		toks, err = store.ListTokens(ctx, 3, 5)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"d"}, keys(toks))
+		toks, err = store.ListTokens(ctx, 5, 0)
+		require.NoError(t, err)
+		assert.Empty(t, toks)
+	})
+
+	t.Run("resolve CID", func(t *testing.T) {
+		store := newStore(t)
+		resolver, ok := store.(ucan.CIDBytesResolver)
+		if !ok {
+			t.Skip("store does not resolve CIDs")
+		}
+		jwt, env := alice.jwt(t, bob, time.Time{}), alice.envelope(t, bob, time.Time{})
+		require.NoError(t, store.PutToken(ctx, tokenCID(t, jwt).String(), jwt))
+		// tokens resolve by CID whatever key they are stored under
+		require.NoError(t, store.PutToken(ctx, "envelope", env))
+
+		raw, err := resolver.ResolveCIDBytes(ctx, tokenCID(t, jwt))
+		require.NoError(t, err)
+		assert.Equal(t, jwt, string(raw))
+		raw, err = resolver.ResolveCIDBytes(ctx, tokenCID(t, env))
+		require.NoError(t, err)
+		assert.Equal(t, env, string(raw))
+
+		require.NoError(t, store.DeleteToken(ctx, "envelope"))
+		_, err = resolver.ResolveCIDBytes(ctx, tokenCID(t, env))
+		assert.ErrorIs(t, err, ucan.ErrTokenNotFound)
+	})
+}
+
+// AssertIndexedTokenStore checks the behaviour every IndexedTokenStore must have, including that of
+// a TokenStore. newStore must return a new, empty store each time it is called.
+func AssertIndexedTokenStore(t *testing.T, newStore func(t *testing.T) ucan.IndexedTokenStore) {
+	AssertTokenStore(t, func(t *testing.T) ucan.TokenStore { return newStore(t) })
+
+	ctx := context.Background()
+	alice, bob, carol := newParty(t), newParty(t), newParty(t)
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("issuer and audience", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.PutToken(ctx, "ab", alice.jwt(t, bob, time.Time{})))
+		require.NoError(t, store.PutToken(ctx, "ac", alice.envelope(t, carol, time.Time{})))
+		require.NoError(t, store.PutToken(ctx, "bc", bob.jwt(t, carol, time.Time{})))
+
+		toks, err := store.TokensByIssuer(ctx, alice.did)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ab", "ac"}, keys(toks))
+		toks, err = store.TokensByAudience(ctx, carol.did)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ac", "bc"}, keys(toks))
+		toks, err = store.TokensByIssuer(ctx, carol.did)
+		require.NoError(t, err)
+		assert.Empty(t, toks)
+
+		// indexes follow replaced and deleted tokens
+		require.NoError(t, store.PutToken(ctx, "ab", carol.jwt(t, bob, time.Time{})))
+		require.NoError(t, store.DeleteToken(ctx, "bc"))
+		toks, err = store.TokensByIssuer(ctx, alice.did)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ac"}, keys(toks))
+		toks, err = store.TokensByIssuer(ctx, carol.did)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ab"}, keys(toks))
+		toks, err = store.TokensByAudience(ctx, carol.did)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ac"}, keys(toks))
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.PutToken(ctx, "later", alice.jwt(t, bob, now.Add(time.Hour))))
+		require.NoError(t, store.PutToken(ctx, "soon", alice.envelope(t, bob, now.Add(time.Minute))))
+		require.NoError(t, store.PutToken(ctx, "expired", alice.jwt(t, bob, now.Add(-time.Minute))))
+		require.NoError(t, store.PutToken(ctx, "forever", alice.envelope(t, bob, time.Time{})))
+
+		toks, err := store.TokensExpiringBefore(ctx, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"expired", "soon", "later"}, keys(toks))
+
+		n, err := store.CollectGarbage(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		_, err = store.RawToken(ctx, "expired")
+		assert.ErrorIs(t, err, ucan.ErrTokenNotFound)
+
+		n, err = store.CollectGarbage(ctx, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		toks, err = store.ListTokens(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"forever"}, keys(toks))
+		toks, err = store.TokensExpiringBefore(ctx, now.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Empty(t, toks)
+	})
+
+	t.Run("revocation", func(t *testing.T) {
+		store := newStore(t)
+		raw := alice.jwt(t, bob, now.Add(time.Hour))
+		require.NoError(t, store.PutToken(ctx, "tok", raw))
+		parser := ucan.NewTokenParser(ucan.ParseAttenuationData, ucan.StringDIDPubKeyResolver{}, store)
+		_, err := parser.ParseAndVerify(ctx, raw)
+		require.NoError(t, err)
+
+		id := tokenCID(t, raw)
+		revoked, err := store.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		assert.False(t, revoked)
+		require.NoError(t, store.Revoke(ctx, id))
+		require.NoError(t, store.Revoke(ctx, id))
+		revoked, err = store.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		assert.Error(t, store.Revoke(ctx, cid.Undef))
+
+		// revoked tokens stay in the store, the parser rejects them
+		_, err = store.RawToken(ctx, "tok")
+		require.NoError(t, err)
+		_, err = parser.ParseAndVerify(ctx, raw)
+		assert.ErrorIs(t, err, ucan.ErrRevoked)
+
+		// tokens that are not stored can be revoked too
+		other := tokenCID(t, bob.jwt(t, alice, time.Time{}))
+		require.NoError(t, store.Revoke(ctx, other))
+		ids, err := store.Revocations(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []cid.Cid{id, other}, ids)
+	})
+}
+
+// AssertPersistentTokenStore checks that an IndexedTokenStore keeps its tokens, indexes and
+// revocations when reopened. open must return a store over the same backing storage each time it is
+// called, empty the first time.
+func AssertPersistentTokenStore(t *testing.T, open func(t *testing.T) ucan.IndexedTokenStore) {
+	ctx := context.Background()
+	alice, bob := newParty(t), newParty(t)
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	store := open(t)
+	jwt, env := alice.jwt(t, bob, exp), bob.envelope(t, alice, time.Time{})
+	require.NoError(t, store.PutToken(ctx, "jwt", jwt))
+	require.NoError(t, store.PutToken(ctx, "env", env))
+	require.NoError(t, store.PutToken(ctx, "deleted", alice.jwt(t, alice, time.Time{})))
+	require.NoError(t, store.DeleteToken(ctx, "deleted"))
+	require.NoError(t, store.Revoke(ctx, tokenCID(t, env)))
+
+	store = open(t)
+	toks, err := store.ListTokens(ctx, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []ucan.RawToken{{Key: "env", Raw: env}, {Key: "jwt", Raw: jwt}}, toks)
+
+	raw, err := store.ResolveCIDBytes(ctx, tokenCID(t, jwt))
+	require.NoError(t, err)
+	assert.Equal(t, jwt, string(raw))
+	toks, err = store.TokensByIssuer(ctx, alice.did)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jwt"}, keys(toks))
+	toks, err = store.TokensByAudience(ctx, alice.did)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env"}, keys(toks))
+	toks, err = store.TokensExpiringBefore(ctx, exp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jwt"}, keys(toks))
+
+	revoked, err := store.IsRevoked(ctx, tokenCID(t, env))
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	n, err := store.CollectGarbage(ctx, exp)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	store = open(t)
+	_, err = store.RawToken(ctx, "jwt")
+	assert.ErrorIs(t, err, ucan.ErrTokenNotFound)
+}
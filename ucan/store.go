@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/ipfs/go-cid"
@@ -16,13 +17,15 @@ import (
 // for a given key
 var ErrTokenNotFound = errors.New("access token not found")
 
+var errUndefinedRevocation = errors.New("cannot revoke an undefined CID")
+
 // TokenStore is a store intended for clients, who need to persist jwts.
 // It deals in raw, string-formatted json web tokens, which are more useful
 // when working with APIs, but validates the tokens are well-formed when placed
 // in the store
 //
 // implementations of TokenStore must conform to the assertion test defined
-// in the spec subpackage
+// in the spec subpackage, see spec.AssertTokenStore
 type TokenStore interface {
 	PutToken(ctx context.Context, key, rawToken string) error
 	RawToken(ctx context.Context, key string) (rawToken string, err error)
@@ -30,6 +33,33 @@ type TokenStore interface {
 	ListTokens(ctx context.Context, offset, limit int) (results []RawToken, err error)
 }
 
+// RevocationStore is a RevocationChecker that records revocations. Revoking a token does not
+// remove it from a store, parsers reject it and every token it is a proof of.
+type RevocationStore interface {
+	RevocationChecker
+	Revoke(ctx context.Context, id cid.Cid) error
+	Revocations(ctx context.Context) ([]cid.Cid, error)
+}
+
+// IndexedTokenStore is a TokenStore that indexes tokens by CID, issuer, audience and expiry and
+// keeps a revocation set. Both 0.7 JWTs and UCAN 1.0 envelopes are indexed. Passed to
+// NewTokenParser as the CIDBytesResolver, its revocation set is consulted by the parser.
+//
+// implementations must also conform to spec.AssertIndexedTokenStore
+type IndexedTokenStore interface {
+	TokenStore
+	CIDBytesResolver
+	RevocationStore
+	// TokensByIssuer lists the tokens issued by a DID, sorted by key
+	TokensByIssuer(ctx context.Context, issuer string) ([]RawToken, error)
+	// TokensByAudience lists the tokens addressed to a DID, sorted by key
+	TokensByAudience(ctx context.Context, audience string) ([]RawToken, error)
+	// TokensExpiringBefore lists the tokens that expire at or before t, soonest first
+	TokensExpiringBefore(ctx context.Context, t time.Time) ([]RawToken, error)
+	// CollectGarbage deletes the tokens that expired at or before now and returns how many
+	CollectGarbage(ctx context.Context, now time.Time) (int, error)
+}
+
 // RawToken is a struct that binds a key to a raw token string
 type RawToken struct {
 	Key string
@@ -43,99 +73,354 @@ func (rts RawTokens) Len() int           { return len(rts) }
 func (rts RawTokens) Less(a, b int) bool { return rts[a].Key < rts[b].Key }
 func (rts RawTokens) Swap(i, j int)      { rts[i], rts[j] = rts[j], rts[i] }
 
+// tokenRecord is the index entry of a stored token
+type tokenRecord struct {
+	key      string
+	raw      string
+	id       cid.Cid
+	issuer   string
+	audience string
+	// expires is the unix expiry of the token, zero if it does not expire
+	expires int64
+}
+
+// newTokenRecord checks raw is a well-formed token and reads the claims it is indexed by, without
+// verifying its signature
+func newTokenRecord(key, raw string) (*tokenRecord, error) {
+	rec := &tokenRecord{key: key, raw: raw}
+	if IsEnvelope([]byte(raw)) {
+		env, err := DecodeEnvelope([]byte(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+		}
+		switch env.Tag {
+		case DelegationTag:
+			d, err := DecodeDelegation([]byte(raw))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+			}
+			rec.issuer, rec.audience, rec.expires = d.Claims.Issuer, d.Claims.Audience, d.Claims.Expiration
+		case InvocationTag:
+			inv, err := DecodeInvocation([]byte(raw))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+			}
+			rec.issuer, rec.audience, rec.expires = inv.Claims.Issuer, inv.Claims.Audience, inv.Claims.Expiration
+		case ReceiptTag:
+			r, err := DecodeReceipt([]byte(raw))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+			}
+			rec.issuer, rec.audience = r.Claims.Issuer, r.Claims.Audience
+		}
+	} else {
+		p := &jwt.Parser{
+			UseJSONNumber:        true,
+			SkipClaimsValidation: false,
+		}
+		mc := jwt.MapClaims{}
+		if _, _, err := p.ParseUnverified(raw, mc); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err)
+		}
+		rec.issuer, _ = mc["iss"].(string)
+		rec.audience, _ = mc["aud"].(string)
+		rec.expires = unixClaim(mc, "exp")
+	}
+
+	id, err := (&Token{Raw: raw}).CID()
+	if err != nil {
+		return nil, err
+	}
+	rec.id = id
+	return rec, nil
+}
+
+// keySets maps an indexed value to the keys of the tokens that have it
+type keySets[K comparable] map[K]map[string]struct{}
+
+func (s keySets[K]) add(v K, key string) {
+	if s[v] == nil {
+		s[v] = map[string]struct{}{}
+	}
+	s[v][key] = struct{}{}
+}
+
+func (s keySets[K]) remove(v K, key string) {
+	delete(s[v], key)
+	if len(s[v]) == 0 {
+		delete(s, v)
+	}
+}
+
+// tokenIndex indexes tokens by key, CID, issuer, audience and expiry and holds the revocation set.
+// It is not safe for concurrent use.
+type tokenIndex struct {
+	keys      map[string]*tokenRecord
+	cids      keySets[cid.Cid]
+	issuers   keySets[string]
+	audiences keySets[string]
+	// expiring holds the tokens that expire, soonest first
+	expiring []*tokenRecord
+	revoked  map[cid.Cid]struct{}
+}
+
+func newTokenIndex() *tokenIndex {
+	return &tokenIndex{
+		keys:      map[string]*tokenRecord{},
+		cids:      keySets[cid.Cid]{},
+		issuers:   keySets[string]{},
+		audiences: keySets[string]{},
+		revoked:   map[cid.Cid]struct{}{},
+	}
+}
+
+// expiringBefore reports whether a sorts before b in the expiry index
+func expiringBefore(a, b *tokenRecord) bool {
+	if a.expires != b.expires {
+		return a.expires < b.expires
+	}
+	return a.key < b.key
+}
+
+// put indexes rec, replacing the token stored under the same key, which is returned
+func (idx *tokenIndex) put(rec *tokenRecord) *tokenRecord {
+	prev := idx.remove(rec.key)
+	idx.keys[rec.key] = rec
+	idx.cids.add(rec.id, rec.key)
+	if rec.issuer != "" {
+		idx.issuers.add(rec.issuer, rec.key)
+	}
+	if rec.audience != "" {
+		idx.audiences.add(rec.audience, rec.key)
+	}
+	if rec.expires != 0 {
+		i := sort.Search(len(idx.expiring), func(i int) bool { return expiringBefore(rec, idx.expiring[i]) })
+		idx.expiring = append(idx.expiring, nil)
+		copy(idx.expiring[i+1:], idx.expiring[i:])
+		idx.expiring[i] = rec
+	}
+	return prev
+}
+
+// remove drops the token stored under key from the index and returns it, nil if there is none
+func (idx *tokenIndex) remove(key string) *tokenRecord {
+	rec, ok := idx.keys[key]
+	if !ok {
+		return nil
+	}
+	delete(idx.keys, key)
+	idx.cids.remove(rec.id, key)
+	idx.issuers.remove(rec.issuer, key)
+	idx.audiences.remove(rec.audience, key)
+	if rec.expires != 0 {
+		i := sort.Search(len(idx.expiring), func(i int) bool { return !expiringBefore(idx.expiring[i], rec) })
+		if i < len(idx.expiring) && idx.expiring[i] == rec {
+			idx.expiring = append(idx.expiring[:i], idx.expiring[i+1:]...)
+		}
+	}
+	return rec
+}
+
+// referenced reports whether any key still stores the token with CID id
+func (idx *tokenIndex) referenced(id cid.Cid) bool {
+	return len(idx.cids[id]) > 0
+}
+
+// byCID returns the raw token with CID id
+func (idx *tokenIndex) byCID(id cid.Cid) (string, bool) {
+	for key := range idx.cids[id] {
+		return idx.keys[key].raw, true
+	}
+	return "", false
+}
+
+// tokens returns the tokens stored under keys, sorted by key
+func (idx *tokenIndex) tokens(keys map[string]struct{}) []RawToken {
+	toks := make(RawTokens, 0, len(keys))
+	for key := range keys {
+		toks = append(toks, RawToken{Key: key, Raw: idx.keys[key].raw})
+	}
+	sort.Sort(toks)
+	return toks
+}
+
+// all returns every token, sorted by key
+func (idx *tokenIndex) all() RawTokens {
+	toks := make(RawTokens, 0, len(idx.keys))
+	for key, rec := range idx.keys {
+		toks = append(toks, RawToken{Key: key, Raw: rec.raw})
+	}
+	sort.Sort(toks)
+	return toks
+}
+
+// expiredBy returns the tokens that expire at or before t, soonest first
+func (idx *tokenIndex) expiredBy(t time.Time) []*tokenRecord {
+	n := sort.Search(len(idx.expiring), func(i int) bool { return idx.expiring[i].expires > t.Unix() })
+	return append([]*tokenRecord(nil), idx.expiring[:n]...)
+}
+
+// revocations returns the revoked CIDs sorted by their string form
+func (idx *tokenIndex) revocations() []cid.Cid {
+	ids := make([]cid.Cid, 0, len(idx.revoked))
+	for id := range idx.revoked {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+// page applies ListTokens offset and limit semantics to toks
+func page(toks []RawToken, offset, limit int) []RawToken {
+	var results []RawToken
+	for i := 0; i < len(toks); i++ {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		results = append(results, toks[i])
+		if limit > 0 && len(results) == limit {
+			break
+		}
+	}
+	return results
+}
+
 type memTokenStore struct {
-	toksLk sync.Mutex
-	toks   map[string]string
+	toksLk sync.RWMutex
+	toks   *tokenIndex
 }
 
 var (
-	_ TokenStore       = (*memTokenStore)(nil)
-	_ CIDBytesResolver = (*memTokenStore)(nil)
+	_ TokenStore        = (*memTokenStore)(nil)
+	_ CIDBytesResolver  = (*memTokenStore)(nil)
+	_ IndexedTokenStore = (*memTokenStore)(nil)
 )
 
-// NewMemTokenStore creates an in-memory token store
+// NewMemTokenStore creates an in-memory token store. The store is an IndexedTokenStore.
 func NewMemTokenStore() TokenStore {
 	return &memTokenStore{
-		toks: map[string]string{},
+		toks: newTokenIndex(),
 	}
 }
 
 // MarshalJSON implements the json.Marshaller interface
 func (st *memTokenStore) MarshalJSON() ([]byte, error) {
-	return json.Marshal(st.toRawTokens())
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+	return json.Marshal(st.toks.all())
 }
 
 func (st *memTokenStore) PutToken(ctx context.Context, key string, raw string) error {
-	p := &jwt.Parser{
-		UseJSONNumber:        true,
-		SkipClaimsValidation: false,
-	}
-	if _, _, err := p.ParseUnverified(raw, jwt.MapClaims{}); err != nil {
-		return fmt.Errorf("%w: %s", ErrInvalidToken, err)
+	rec, err := newTokenRecord(key, raw)
+	if err != nil {
+		return err
 	}
 
 	st.toksLk.Lock()
 	defer st.toksLk.Unlock()
 
-	st.toks[key] = raw
+	st.toks.put(rec)
 	return nil
 }
 
 func (st *memTokenStore) ResolveCIDBytes(ctx context.Context, id cid.Cid) ([]byte, error) {
-	rt, err := st.RawToken(ctx, id.String())
-	if err != nil {
-		return nil, err
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	raw, ok := st.toks.byCID(id)
+	if !ok {
+		return nil, ErrTokenNotFound
 	}
-	return []byte(rt), nil
+	return []byte(raw), nil
 }
 
 func (st *memTokenStore) RawToken(ctx context.Context, key string) (rawToken string, err error) {
-	t, ok := st.toks[key]
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	rec, ok := st.toks.keys[key]
 	if !ok {
 		return "", ErrTokenNotFound
 	}
-	return t, nil
+	return rec.raw, nil
 }
 
 func (st *memTokenStore) DeleteToken(ctx context.Context, key string) (err error) {
 	st.toksLk.Lock()
 	defer st.toksLk.Unlock()
 
-	if _, ok := st.toks[key]; !ok {
+	if st.toks.remove(key) == nil {
 		return ErrTokenNotFound
 	}
-	delete(st.toks, key)
 	return nil
 }
 
 func (st *memTokenStore) ListTokens(ctx context.Context, offset, limit int) ([]RawToken, error) {
-	var results []RawToken
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
 
-	toks := st.toRawTokens()
-	for i := 0; i < len(toks); i++ {
-		if offset > 0 {
-			offset--
-			continue
-		}
-		results = append(results, toks[i])
-		if limit > 0 && len(results) == limit {
-			break
-		}
+	return page(st.toks.all(), offset, limit), nil
+}
+
+func (st *memTokenStore) TokensByIssuer(ctx context.Context, issuer string) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return st.toks.tokens(st.toks.issuers[issuer]), nil
+}
+
+func (st *memTokenStore) TokensByAudience(ctx context.Context, audience string) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return st.toks.tokens(st.toks.audiences[audience]), nil
+}
+
+func (st *memTokenStore) TokensExpiringBefore(ctx context.Context, t time.Time) ([]RawToken, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	var toks []RawToken
+	for _, rec := range st.toks.expiredBy(t) {
+		toks = append(toks, RawToken{Key: rec.key, Raw: rec.raw})
+	}
+	return toks, nil
+}
+
+func (st *memTokenStore) CollectGarbage(ctx context.Context, now time.Time) (int, error) {
+	st.toksLk.Lock()
+	defer st.toksLk.Unlock()
+
+	expired := st.toks.expiredBy(now)
+	for _, rec := range expired {
+		st.toks.remove(rec.key)
 	}
+	return len(expired), nil
+}
 
-	return results, nil
+func (st *memTokenStore) IsRevoked(ctx context.Context, id cid.Cid) (bool, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	_, ok := st.toks.revoked[id]
+	return ok, nil
 }
 
-func (st *memTokenStore) toRawTokens() RawTokens {
-	toks := make(RawTokens, len(st.toks))
-	i := 0
-	for key, t := range st.toks {
-		toks[i] = RawToken{
-			Key: key,
-			Raw: t,
-		}
-		i++
+func (st *memTokenStore) Revoke(ctx context.Context, id cid.Cid) error {
+	if !id.Defined() {
+		return errUndefinedRevocation
 	}
-	sort.Sort(toks)
-	return toks
+	st.toksLk.Lock()
+	defer st.toksLk.Unlock()
+
+	st.toks.revoked[id] = struct{}{}
+	return nil
+}
+
+func (st *memTokenStore) Revocations(ctx context.Context) ([]cid.Cid, error) {
+	st.toksLk.RLock()
+	defer st.toksLk.RUnlock()
+
+	return st.toks.revocations(), nil
 }
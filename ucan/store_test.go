@@ -0,0 +1,72 @@
+package ucan_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonr-io/crypto/ucan"
+	"github.com/sonr-io/crypto/ucan/spec"
+)
+
+func TestMemTokenStore(t *testing.T) {
+	spec.AssertIndexedTokenStore(t, func(t *testing.T) ucan.IndexedTokenStore {
+		return ucan.NewMemTokenStore().(ucan.IndexedTokenStore)
+	})
+}
+
+func newFileTokenStore(t *testing.T, dir string) ucan.IndexedTokenStore {
+	t.Helper()
+	store, err := ucan.NewFileTokenStore(dir)
+	require.NoError(t, err)
+	return store
+}
+
+func TestFileTokenStore(t *testing.T) {
+	spec.AssertIndexedTokenStore(t, func(t *testing.T) ucan.IndexedTokenStore {
+		return newFileTokenStore(t, t.TempDir())
+	})
+	dir := t.TempDir()
+	spec.AssertPersistentTokenStore(t, func(t *testing.T) ucan.IndexedTokenStore {
+		return newFileTokenStore(t, dir)
+	})
+}
+
+func TestFileTokenStore_Files(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := newFileTokenStore(t, dir)
+
+	const raw = "eyJhbGciOiJFZERTQSIsInR5cCI6IkpXVCIsInVhdiI6IjAuNy4wIn0.eyJpc3MiOiJkaWQ6a2V5OnoifQ.c2ln"
+	tok := &ucan.Token{Raw: raw}
+	id, err := tok.CID()
+	require.NoError(t, err)
+
+	// a token stored under two keys is written once and kept until both are deleted
+	require.NoError(t, store.PutToken(ctx, "a", raw))
+	require.NoError(t, store.PutToken(ctx, "b", raw))
+	tokenFile := filepath.Join(dir, "tokens", id.String())
+	require.FileExists(t, tokenFile)
+	require.NoError(t, store.DeleteToken(ctx, "a"))
+	require.FileExists(t, tokenFile)
+	require.NoError(t, store.DeleteToken(ctx, "b"))
+	require.NoFileExists(t, tokenFile)
+
+	// token files without a key are collected
+	orphan := filepath.Join(dir, "tokens", id.String())
+	require.NoError(t, os.WriteFile(orphan, []byte(raw), 0o600))
+	_, err = store.CollectGarbage(ctx, time.Now())
+	require.NoError(t, err)
+	assert.NoFileExists(t, orphan)
+
+	// stores refuse to open over tampered tokens
+	require.NoError(t, store.PutToken(ctx, "a", raw))
+	require.NoError(t, os.WriteFile(tokenFile, []byte(raw+"x"), 0o600))
+	_, err = ucan.NewFileTokenStore(dir)
+	assert.Error(t, err)
+}
@@ -13,6 +13,7 @@ import (
 	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -21,6 +22,8 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	mh "github.com/multiformats/go-multihash"
+
+	"github.com/sonr-io/crypto/didfmt"
 	"github.com/sonr-io/crypto/keys"
 )
 
@@ -40,6 +43,8 @@ const (
 	AttKey = "att"
 	// CapKey indicates a resource Capability. Used in an attenuation
 	CapKey = "cap"
+	// SubKey indicates the subject of a UCAN 1.0 delegation when it is read as an attenuation
+	SubKey = "sub"
 )
 
 // Token is a JSON Web Token (JWT) that contains special keys that make the
@@ -57,13 +62,21 @@ type Token struct {
 	Attenuations Attenuations `json:"att,omitempty"`
 	// Facts are facts, jack.
 	Facts []Fact `json:"fct,omitempty"`
+	// NotBefore and ExpiresAt are the unix time bounds of the token, zero if unbounded
+	NotBefore int64 `json:"nbf,omitempty"`
+	ExpiresAt int64 `json:"exp,omitempty"`
 }
 
-// CID calculates the cid of a UCAN using the default prefix
+// CID calculates the cid of a UCAN using the default prefix. UCAN 1.0 envelopes are addressed as
+// DAG-CBOR, JWTs as raw bytes.
 func (t *Token) CID() (cid.Cid, error) {
+	codec := uint64(cid.Raw)
+	if IsEnvelope([]byte(t.Raw)) {
+		codec = cid.DagCBOR
+	}
 	pref := cid.Prefix{
 		Version:  1,
-		Codec:    cid.Raw,
+		Codec:    codec,
 		MhType:   mh.SHA2_256,
 		MhLength: -1, // default length
 	}
@@ -204,7 +217,7 @@ func (a *pkSource) NewAttenuatedToken(parent *Token, audienceDID string, att Att
 	if !parent.Attenuations.Contains(att) {
 		return nil, fmt.Errorf("scope of ucan attenuations must be less than it's parent")
 	}
-	return a.newToken(audienceDID, append(parent.Proofs, Proof(parent.Raw)), att, fct, nbf, exp)
+	return a.newToken(audienceDID, []Proof{Proof(parent.Raw)}, att, fct, nbf, exp)
 }
 
 // CreateToken returns a new JWT token
@@ -283,28 +296,171 @@ func (StringDIDPubKeyResolver) ResolveDIDKey(ctx context.Context, didStr string)
 	return keys.Parse(didStr)
 }
 
+// DocumentDIDPubKeyResolver implements the DIDPubKeyResolver interface with a DID document
+// resolver, such as a didfmt.Registry. The key of a DID is the first public key among the
+// assertion and authentication methods of its document.
+type DocumentDIDPubKeyResolver struct {
+	Resolver didfmt.Resolver
+}
+
+// ResolveDIDKey resolves the document of a DID and returns its signing key
+func (r DocumentDIDPubKeyResolver) ResolveDIDKey(ctx context.Context, didStr string) (keys.DID, error) {
+	doc, err := r.Resolver.Resolve(ctx, didStr)
+	if err != nil {
+		return keys.DID{}, err
+	}
+	pub, err := doc.SigningKey()
+	if err != nil {
+		return keys.DID{}, err
+	}
+	return keys.NewDID(pub)
+}
+
 // TokenParser parses a raw string into a Token
 type TokenParser struct {
-	ap   AttenuationConstructorFunc
-	cidr CIDBytesResolver
-	didr DIDPubKeyResolver
+	ap       AttenuationConstructorFunc
+	cidr     CIDBytesResolver
+	didr     DIDPubKeyResolver
+	revoked  RevocationChecker
+	policy   PolicyEvaluator
+	maxDepth int
+	maxLinks int
+	now      func() time.Time
+
+	strictPolicy bool
 }
 
-// NewTokenParser constructs a token parser
-func NewTokenParser(ap AttenuationConstructorFunc, didr DIDPubKeyResolver, cidr CIDBytesResolver) *TokenParser {
-	return &TokenParser{
-		ap:   ap,
-		cidr: cidr,
-		didr: didr,
+// NewTokenParser constructs a token parser. When cidr is also a RevocationChecker, such as the
+// stores returned by NewMemTokenStore and NewFileTokenStore, the parser consults it for revoked
+// tokens unless WithRevocationChecker is given.
+func NewTokenParser(ap AttenuationConstructorFunc, didr DIDPubKeyResolver, cidr CIDBytesResolver, opts ...ParserOption) *TokenParser {
+	p := &TokenParser{
+		ap:       ap,
+		cidr:     cidr,
+		didr:     didr,
+		policy:   evaluateInvocationPolicy,
+		maxDepth: DefaultMaxProofDepth,
+		maxLinks: DefaultMaxProofLinks,
+		now:      time.Now,
+	}
+	if rc, ok := cidr.(RevocationChecker); ok {
+		p.revoked = rc
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	return p
 }
 
-// ParseAndVerify will parse, validate and return a token
+// ParseAndVerify will parse, validate and return a token. Both 0.7 JWTs and UCAN 1.0 delegation
+// envelopes are accepted, delegations are returned as their token view. Every proof is resolved and validated
+// recursively: each proof must be delegated to the issuer of the token using it, must outlast it,
+// and together the proofs of a token must grant all of its attenuations. A token may not list the
+// same proof twice, and a proof shared by several tokens of the chain is verified once. Failures
+// are reported as a *ChainError naming the failing link.
 func (p *TokenParser) ParseAndVerify(ctx context.Context, raw string) (*Token, error) {
-	return p.parseAndVerify(ctx, raw, nil)
+	return p.parseAndVerify(ctx, raw, newChainState(), 0)
+}
+
+func (p *TokenParser) parseAndVerify(ctx context.Context, raw string, state *chainState, depth int) (*Token, error) {
+	id, err := (&Token{Raw: raw}).CID()
+	if err != nil {
+		return nil, err
+	}
+	fail := func(issuer string, err error) error {
+		var ce *ChainError
+		if errors.As(err, &ce) {
+			return err
+		}
+		return &ChainError{Depth: depth, CID: id, Issuer: issuer, Err: err}
+	}
+
+	if depth > p.maxDepth {
+		return nil, fail("", ErrProofDepth)
+	}
+	if state.path[id] {
+		return nil, fail("", ErrProofCycle)
+	}
+	if state.links++; state.links > p.maxLinks {
+		return nil, fail("", ErrProofLinks)
+	}
+	if v, ok := state.verified[id]; ok {
+		if depth+v.height > p.maxDepth {
+			return nil, fail(v.token.Issuer.String(), ErrProofDepth)
+		}
+		return v.token, nil
+	}
+	state.path[id] = true
+	defer delete(state.path, id)
+
+	if p.revoked != nil {
+		revoked, err := p.revoked.IsRevoked(ctx, id)
+		if err != nil {
+			return nil, fail("", fmt.Errorf("checking revocation: %w", err))
+		}
+		if revoked {
+			return nil, fail("", ErrRevoked)
+		}
+	}
+
+	tok, err := p.parseToken(ctx, raw)
+	if err != nil {
+		return nil, fail("", err)
+	}
+	issuer := tok.Issuer.String()
+
+	var (
+		granted Attenuations
+		height  int
+		seen    = make(map[cid.Cid]bool, len(tok.Proofs))
+	)
+	for i, prf := range tok.Proofs {
+		proofRaw, err := p.resolveProof(ctx, prf)
+		if err != nil {
+			return nil, fail(issuer, fmt.Errorf("prf[%d]: %w", i, err))
+		}
+		proofID, err := (&Token{Raw: proofRaw}).CID()
+		if err != nil {
+			return nil, fail(issuer, fmt.Errorf("prf[%d]: %w", i, err))
+		}
+		if seen[proofID] {
+			return nil, fail(issuer, fmt.Errorf("prf[%d]: %w: %s", i, ErrDuplicateProof, proofID))
+		}
+		seen[proofID] = true
+		proof, err := p.parseAndVerify(ctx, proofRaw, state, depth+1)
+		if err != nil {
+			return nil, fail(issuer, err)
+		}
+		if err := verifyLink(tok, proof); err != nil {
+			return nil, fail(issuer, fmt.Errorf("prf[%d]: %w", i, err))
+		}
+		granted = append(granted, proof.Attenuations...)
+		height = max(height, state.verified[proofID].height+1)
+	}
+	// tokens without proofs are self-evident: the issuer owns the resources it grants
+	if len(tok.Proofs) > 0 && !granted.Contains(tok.Attenuations) {
+		return nil, fail(issuer, ErrAttenuationEscalation)
+	}
+	state.verified[id] = verifiedToken{token: tok, height: height}
+	return tok, nil
 }
 
-func (p *TokenParser) parseAndVerify(ctx context.Context, raw string, _ *Token) (*Token, error) {
+// parseToken verifies the signature and time bounds of a single token and parses its claims.
+func (p *TokenParser) parseToken(ctx context.Context, raw string) (*Token, error) {
+	if IsEnvelope([]byte(raw)) {
+		d, err := DecodeDelegation([]byte(raw))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := d.verifySignature(ctx, p.didr, d.Claims.Issuer); err != nil {
+			return nil, err
+		}
+		if err := checkTimeBounds(d.Claims.NotBefore, d.Claims.Expiration, p.now()); err != nil {
+			return nil, err
+		}
+		return p.delegationToken(ctx, d)
+	}
+
 	tok, err := jwt.Parse(raw, p.matchVerifyKeyFunc(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("parsing UCAN: %w", err)
@@ -319,7 +475,7 @@ func (p *TokenParser) parseAndVerify(ctx context.Context, raw string, _ *Token)
 	// TODO(b5): we're double parsing here b/c the jwt lib we're using doesn't expose
 	// an API (that I know of) for storing parsed issuer / audience
 	if issStr, ok := mc["iss"].(string); ok {
-		iss, err = keys.Parse(issStr)
+		iss, err = p.didr.ResolveDIDKey(ctx, issStr)
 		if err != nil {
 			return nil, err
 		}
@@ -331,7 +487,7 @@ func (p *TokenParser) parseAndVerify(ctx context.Context, raw string, _ *Token)
 	// TODO(b5): we're double parsing here b/c the jwt lib we're using doesn't expose
 	// an API (that I know of) for storing parsed issuer / audience
 	if audStr, ok := mc["aud"].(string); ok {
-		aud, err = keys.Parse(audStr)
+		aud, err = p.didr.ResolveDIDKey(ctx, audStr)
 		if err != nil {
 			return nil, err
 		}
@@ -352,7 +508,7 @@ func (p *TokenParser) parseAndVerify(ctx context.Context, raw string, _ *Token)
 				return nil, fmt.Errorf(`"att[%d]" is not an object`, i)
 			}
 		}
-	} else {
+	} else if mc[AttKey] != nil {
 		return nil, fmt.Errorf(`"att" key is not an array`)
 	}
 
@@ -375,9 +531,24 @@ func (p *TokenParser) parseAndVerify(ctx context.Context, raw string, _ *Token)
 		Audience:     aud,
 		Attenuations: att,
 		Proofs:       prf,
+		NotBefore:    unixClaim(mc, "nbf"),
+		ExpiresAt:    unixClaim(mc, "exp"),
 	}, nil
 }
 
+// unixClaim returns a numeric time claim, or zero if it is not set.
+func unixClaim(mc jwt.MapClaims, key string) int64 {
+	switch v := mc[key].(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	default:
+		return 0
+	}
+}
+
 func (p *TokenParser) matchVerifyKeyFunc(ctx context.Context) func(tok *jwt.Token) (any, error) {
 	return func(tok *jwt.Token) (any, error) {
 		mc, ok := tok.Claims.(jwt.MapClaims)